@@ -6,8 +6,10 @@ X: x.com/pedro_infosec
 package main
 
 import (
-	_ "net/http/pprof"
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/slicingmelon/gobypass403/core/cli"
 	"github.com/slicingmelon/gobypass403/core/engine/payload"
@@ -16,18 +18,24 @@ import (
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs gobypass403 and returns the process exit code, deferred cleanups (profiler, signal
+// handler) run before main exits with it
+func run() int {
 	GB403Logger.Info().Msgf("Initializing GoByPASS403 v%s...\n", cli.GOBYPASS403_VERSION)
 
 	if err := payload.InitializePayloadsDir(); err != nil {
 		GB403Logger.Error().Msgf("Failed to initialize payloads: %v", err)
-		os.Exit(1)
+		return cli.ExitCodeError
 	}
 
 	// Initialize CLI runner which processes all flags (including the -profile flag)
 	runner := cli.NewRunner()
 	if err := runner.Initialize(); err != nil {
 		GB403Logger.Error().Msgf("Initialization failed: %v", err)
-		os.Exit(1)
+		return cli.ExitCodeError
 	}
 
 	// If profile option is enabled, start the profiler
@@ -40,8 +48,20 @@ func main() {
 		}
 	}
 
-	if err := runner.Run(); err != nil {
+	// Ctrl-C (or SIGTERM) cancels the scan, in-flight workers are stopped and reports still get written.
+	// A second Ctrl-C kills the process right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := runner.Run(ctx); err != nil {
 		GB403Logger.Error().Msgf("Execution failed: %v", err)
-		os.Exit(1)
+		return cli.ExitCodeError
 	}
+
+	// -fail-on: findings exit with their own code, for CI gating
+	return runner.ExitCode()
 }
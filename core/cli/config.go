@@ -0,0 +1,125 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyConfigFile sets the flags listed in a -config file (YAML, or JSON which YAML parses
+// too), keyed by any of their flag names. Flags set on the command line are skipped, so they
+// override the file. Values go through fs.Set, the same parsing and validation as the CLI
+func applyConfigFile(fs *flag.FlagSet, path string, flags []multiFlag) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var values map[string]any
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("invalid config file %s: %v", path, err)
+		}
+	}
+
+	// Flag name (and aliases) -> index in flags
+	flagIdx := make(map[string]int)
+	for i, f := range flags {
+		for _, name := range strings.Split(f.name, ",") {
+			flagIdx[strings.TrimSpace(name)] = i
+		}
+	}
+
+	setOnCLI := make(map[int]bool)
+	fs.Visit(func(f *flag.Flag) {
+		if i, ok := flagIdx[f.Name]; ok {
+			setOnCLI[i] = true
+		}
+	})
+
+	// Sorted for a deterministic first error
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[int]string)
+	for _, key := range keys {
+		i, ok := flagIdx[key]
+		if !ok || key == "config" {
+			return fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+		if other, ok := seen[i]; ok {
+			return fmt.Errorf("keys %q and %q in config file %s set the same option", other, key, path)
+		}
+		seen[i] = key
+
+		if setOnCLI[i] {
+			continue
+		}
+
+		strs, err := configValueStrings(values[key])
+		if err != nil {
+			return fmt.Errorf("invalid value for %q in config file %s: %v", key, path, err)
+		}
+
+		// Repeatable flags (-H, -mh...) take one value per list item, the others a comma
+		// separated list
+		if _, ok := flags[i].value.(*stringSliceFlag); !ok {
+			strs = []string{strings.Join(strs, ",")}
+		}
+		for _, s := range strs {
+			if err := fs.Set(key, s); err != nil {
+				return fmt.Errorf("invalid value for %q in config file %s: %v", key, path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// configValueStrings converts a config file value (scalar or list of scalars) to flag values
+func configValueStrings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("missing value")
+	case []any:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configScalarString(item)
+			if err != nil {
+				return nil, err
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	default:
+		s, err := configScalarString(v)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+}
+
+func configScalarString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("expected a string, number, boolean or a list of them")
+	}
+}
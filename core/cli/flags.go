@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
 )
 
 type multiFlag struct {
@@ -65,46 +67,135 @@ func (f *stringSliceFlag) Set(value string) error {
 	return nil
 }
 
-func parseFlags() (*CliOptions, error) {
+// ParseArgs parses the command line arguments args (without the program name) into the CLI options,
+// with the -config file applied, defaults set and validated. Errors are returned instead of exiting.
+func ParseArgs(args []string) (*CliOptions, error) {
+	return parseFlags(flag.NewFlagSet("gobypass403", flag.ContinueOnError), args)
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) (*CliOptions, error) {
 	opts := &CliOptions{}
 
 	flags := []multiFlag{
 		{name: "u,url", usage: "Target URL (example: https://cms.facebook.com/login)", value: &opts.URL},
 		{name: "l,urls-file", usage: "File containing list of target URLs (one per line)", value: &opts.URLsFile},
 		{name: "shf,substitute-hosts-file", usage: "File containing a list of hosts to substitute target URL's hostname (mostly used in CDN bypasses by providing a list of CDNs)", value: &opts.SubstituteHostsFile},
-		{name: "m,module", usage: "Bypass module (all,path_prefix,mid_paths,end_paths,http_methods,case_substitution,char_encode,nginx_bypasses,unicode_path_normalization,headers_scheme,headers_ip,headers_port,headers_url,headers_host)", value: &opts.Module, defVal: "all"},
+		{name: "config", usage: "YAML or JSON file with scan options keyed by flag name, flags given on the command line override it (example: -config scan.yaml)", value: &opts.ConfigFile},
+		{name: "m,module", usage: "Bypass module (all,path_prefix,mid_paths,end_paths,extension_confusion,http_methods,webdav,method_override,api_gateway,case_substitution,char_encode,nginx_bypasses,unicode_path_normalization,separator,overlong_encode,path_params,path_normalization,headers_scheme,headers_ip,headers_port,headers_url,headers_host,header_case,http_host_mutations,http_absolute_uri,http_headers_accept,http_cookies,proxy_path_rewrite,request_smuggling_probe,header_crlf_injection), prefix a module with - to exclude it (example: -m all,-char_encode,-unicode_path_normalization)", value: &opts.Module, defVal: "all"},
 		{name: "o,outdir", usage: "Output directory", value: &opts.OutDir},
+		{name: "db", usage: "Findings store: sqlite (results DB, can be queried with SQL afterward), bolt (bbolt key/value file of JSON findings, results.bolt) or none (kept in memory for the results tables and reports, nothing written to disk)", value: &opts.DBBackend, defVal: scanner.DBBackendSQLite},
+		{name: "db-path", usage: "Path of the sqlite or bolt results DB (default: results.db or results.bolt in the output directory)", value: &opts.ResultsDBFile},
+		{name: "capture", usage: "Optional result fields to capture and store, to reduce memory on large scans (headers,preview,title,server,redirect,all), the status, length, type, time, curl command and token are always stored", value: &opts.Capture, defVal: "all"},
+		{name: "webhook", usage: "POST each finding as JSON to this URL as soon as it's found (example: -webhook https://hooks.slack.com/...)", value: &opts.Webhook},
+		{name: "sarif", usage: "Also write findings as a SARIF 2.1.0 report to this file, for CI / GitHub code scanning (example: -sarif results.sarif)", value: &opts.SarifFile},
+		{name: "burp", usage: "Also write findings as a Burp Suite saved items XML file, with the rebuilt requests and captured responses (example: -burp findings.xml)", value: &opts.BurpFile},
+		{name: "csv", usage: "Also stream findings as CSV rows to this file, as they are found (example: -csv findings.csv)", value: &opts.CSVFile},
+		{name: "jsonl", usage: "Also append findings as JSON lines to findings.jsonl in the output directory, as they are found", value: &opts.JSONL, defVal: false},
+		{name: "save-bodies", usage: "Resend each finding's request and save its full response body to bodies/<debug-token>.bin in the output directory (up to 50MB per body, can use a lot of disk)", value: &opts.SaveBodies, defVal: false},
+		{name: "split-output", usage: "Write the findings of each target host to <host>/findings.jsonl in the output directory, and its -save-bodies bodies to <host>/bodies (the results DB still holds all findings)", value: &opts.SplitOutput, defVal: false},
+		{name: "export-http", usage: "Write each finding's request as a replayable .http file (VS Code REST Client / JetBrains format) to this directory (example: -export-http requests)", value: &opts.ExportHTTPDir},
+		{name: "html", usage: "Write a self-contained HTML report of the findings to this file once the scan is done (example: -html report.html)", value: &opts.HTMLReportFile},
+		{name: "json", usage: "Write a JSON report of the findings and the request errors (timeouts, DNS failures, resets...) of each target URL to this file once the scan is done (example: -json report.json)", value: &opts.JSONReportFile},
+		{name: "output-version", usage: "Schema version of the JSON findings written by -webhook, -jsonl and -json, to keep the layout an integration expects (1 = original layout)", value: &opts.OutputVersion, defVal: scanner.OutputSchemaVersion},
+		{name: "sort-by", usage: "Order of the results tables and the -html/-json reports: time (slowest first), length (largest first), status or confidence (highest first), instead of grouping by status code and module (example: -sort-by time)", value: &opts.SortBy},
 		{name: "cr,concurrent-requests", usage: "Number of max concurrent requests", value: &opts.ConcurrentRequests, defVal: 15},
+		{name: "host-concurrency", usage: "Max concurrent requests per host, capped by -cr (0 means no per-host limit)", value: &opts.HostConcurrency, defVal: 0},
+		{name: "url-concurrency", usage: "Number of target URLs scanned concurrently, each with its own -cr workers, -host-concurrency and -rate stay shared (disables progress bars when > 1)", value: &opts.URLConcurrency, defVal: 1},
 		{name: "T,timeout", usage: "Total timeout (in milliseconds)", value: &opts.Timeout, defVal: 20000},
 		{name: "delay", usage: "Delay between requests (in milliseconds) (0 means no delay)", value: &opts.Delay, defVal: 0},
+		{name: "jitter", usage: "Randomize the delay of each request within ± this percentage of -delay, so requests are not evenly spaced (example: -delay 500 -jitter 30 waits 350-650ms)", value: &opts.Jitter, defVal: 0},
+		{name: "rate", usage: "Maximum number of requests per second, shared by all workers, takes precedence over -delay (0 means no limit)", value: &opts.Rate, defVal: 0},
+		{name: "module-delay", usage: "Delay between bypass modules (in seconds) (0 means no delay)", value: &opts.ModuleDelay, defVal: 0},
 		{name: "max-retries", usage: "Maximum number of retries for failed requests (0 means no retries)", value: &opts.MaxRetries, defVal: 2},
+		{name: "retry-status", usage: "Retry requests answered with these status codes as transient, up to -max-retries times, waiting for the Retry-After of 429 and 503 responses (example: -retry-status 429,503)", value: &opts.RetryStatusStr},
 		{name: "retry-delay", usage: "Delay between retries (in milliseconds)", value: &opts.RetryDelay, defVal: 500},
 		{name: "max-cfr,max-consecutive-fails", usage: "Maximum number of consecutive failed requests before cancelling the current bypass module", value: &opts.MaxConsecutiveFailedReqs, defVal: 15},
+		{name: "max-host-fails", usage: "Back off a host for 30s after this many of its requests failed in a row (timeouts, resets, refused connections), or once its average response time reaches half of -T, across modules and URLs: its requests are sent one at a time until it recovers, healthy hosts are unaffected (0 = off)", value: &opts.MaxHostFails, defVal: 0},
+		{name: "max-requests", usage: "Maximum number of requests sent per target URL across all modules, remaining payloads are skipped once reached (0 means no limit)", value: &opts.MaxRequests, defVal: 0},
+		{name: "recon-concurrency", usage: "Number of hosts probed in parallel during recon", value: &opts.ReconConcurrency, defVal: 50},
+		{name: "recon-timeout", usage: "Overall recon timeout (in seconds) (0 means no timeout)", value: &opts.ReconTimeout, defVal: 0},
+		{name: "no-probe", usage: "Skip recon (DNS resolution and HTTP/HTTPS probing) and scan the input URLs as given, for internal targets or hosts that don't resolve publicly (headers_host falls back to Host header mutations, without the recon IPs/CNAMEs)", value: &opts.NoProbe, defVal: false},
 		{name: "at,auto-throttle", usage: "Enable automatic request throttling (on/off, 1/0)",
 			value: &onOffFlag{val: &opts.AutoThrottle}, defVal: "on"},
+		{name: "max-retry-after", usage: "Maximum delay honored from a Retry-After header on 429/503 responses (in seconds), when auto-throttle is on or with -retry-status", value: &opts.MaxRetryAfter, defVal: 30},
+		{name: "max-duration", usage: "Stop the whole scan after this duration, the findings collected so far are still saved (Go duration, example: -max-duration 10m, 1h30m)", value: &opts.MaxDurationStr},
+		{name: "passive", usage: "Lower the blast radius on sensitive targets: GET requests without a body only (skips http_methods, method_override, haproxy_bypasses and request_smuggling_probe), no double/triple encodings and at most -passive-max-payloads payloads per module", value: &opts.Passive, defVal: false},
+		{name: "passive-max-payloads", usage: "Maximum number of payloads sent per bypass module and URL with -passive", value: &opts.PassiveMaxPayloads, defVal: 100},
+		{name: "allow-smuggling", usage: "Enable the opt-in request_smuggling_probe module (CL.TE/TE.CL desync detection probes), also added to -m all when set", value: &opts.AllowSmuggling, defVal: false},
+		{name: "allow-crlf-injection", usage: "Enable the opt-in header_crlf_injection module (encoded CR/LF and a second header in IP/URL spoof header values), also added to -m all when set", value: &opts.AllowCRLFInjection, defVal: false},
+		{name: "fail-on", usage: "Exit with code 2 when the scan records a finding, for CI gating: any, or only findings with these status codes (example: -fail-on 200,3xx). Errors exit with code 1", value: &opts.FailOn},
+		{name: "stop-all-on-find", usage: "Stop all scans (all modules, all URLs) as soon as the first finding is found", value: &opts.StopAllOnFind, defVal: false},
+		{name: "resume", usage: "Resume an interrupted scan, skipping the URL/module pairs completed according to checkpoint.json in the output directory (same URLs and modules required)", value: &opts.Resume, defVal: false},
+		{name: "dry-run", usage: "Print the payloads generated by each module for every URL (request line, headers, debug token) without sending any request", value: &opts.DryRun, defVal: false},
+		{name: "dry-run-json", usage: "Same as -dry-run, but write the payloads as JSON lines to payloads.jsonl in the output directory", value: &opts.DryRunJSON, defVal: false},
+		{name: "deterministic-tokens", usage: "Derive the debug token nonce from the payload instead of random, so the same payload always gets the same token (stable -dry-run output, diffing runs)", value: &opts.DeterministicTokens, defVal: false},
 		{name: "v,verbose", usage: "Verbose output", value: &opts.Verbose, defVal: false},
 		{name: "d,debug", usage: "Debug mode with request canaries", value: &opts.Debug, defVal: false},
+		{name: "dump-wire", usage: "Log the exact raw request bytes and the response head (status line and headers) of every request (all) or only of the requests matching the result filters (matched), to debug why a payload did not work (example: -dump-wire matched)", value: &opts.DumpWire},
+		{name: "no-color", usage: "Disable colored output (logs, tables, progress bars), also disabled when stdout is not a terminal or NO_COLOR is set", value: &opts.NoColor, defVal: false},
+		{name: "log-json", usage: "Write log messages to stderr as JSON lines (level, timestamp, module, message, debug_token) instead of the colored console output", value: &opts.LogJSON, defVal: false},
+		{name: "progress-json", usage: "Write module progress events to stderr as JSON lines (module_start, module_progress every second, module_end) with completed/total requests, request rate and findings so far, for orchestration tools", value: &opts.ProgressJSON, defVal: false},
 		{name: "mc,match-status-code", usage: "Filter results by HTTP status codes (example: -mc 200, 301, 5xx, all). Default: All status codes", value: &opts.MatchStatusCodesStr},
 		{name: "mct,match-content-type", usage: "Filter results by content type(s) substring (example: -mct application/json,text/html)", value: &opts.MatchContentType},
-		{name: "min-cl,min-content-length", usage: "Filter results by minimum Content-Length (example: -min-cl 100)", value: &opts.MinContentLengthStr},
+		{name: "mm,match-magic", usage: "Filter results by response body magic bytes: jpeg, png, pdf, gif or a hex prefix (example: -mm png, -mm 504b0304)", value: &opts.MatchMagic},
+		{name: "min-cl,min-content-length", usage: "Filter results by minimum Content-Length, using the body bytes read when there is none (example: -min-cl 100)", value: &opts.MinContentLengthStr},
+		{name: "min-confidence", usage: "Filter out findings with a confidence score below this value, from 0 to 1, combining the status and length change from the original request, the content type change, block page detection and the -calibrate check (example: -min-confidence 0.6)", value: &opts.MinConfidence, defVal: 0.0},
 		{name: "max-cl,max-content-length", usage: "Filter results by maximum Content-Length (example: -max-cl 5000)", value: &opts.MaxContentLengthStr},
+		{name: "fs,filter-size", usage: "Filter out responses by size, exact values or ranges (example: -fs 1234,100-200)", value: &opts.FilterSizesStr},
+		{name: "ms,match-size", usage: "Only match responses by size, exact values or ranges (example: -ms 1234,100-200)", value: &opts.MatchSizesStr},
+		{name: "mre,match-regex", usage: "Only match responses whose headers or body preview match this regex (example: -mre \"admin|dashboard\")", value: &opts.MatchRegex},
+		{name: "fre,filter-regex", usage: "Filter out responses whose headers or body preview match this regex (example: -fre \"Access Denied\")", value: &opts.FilterRegex},
+		{name: "mh,match-header", usage: "Only match responses with this header, name (case-insensitive) and optional value substring (example: -mh \"Server: nginx\"), can be used multiple times to match any of them", value: &stringSliceFlag{values: &opts.MatchHeaderStrs}},
+		{name: "fh,filter-header", usage: "Filter out responses with this header, name (case-insensitive) and optional value substring (example: -fh \"X-Accel-Redirect\"), can be used multiple times", value: &stringSliceFlag{values: &opts.FilterHeaderStrs}},
+		{name: "suppress-baseline", usage: "Drop findings identical to the original (dumb_check) response, ignoring dynamic content", value: &opts.SuppressBaseline, defVal: false},
+		{name: "ignore-pattern", usage: "Regex stripped from response bodies before baseline comparison, on top of built-in date/nonce patterns (example: -ignore-pattern \"csrf=[a-f0-9]+\"), can be used multiple times", value: &stringSliceFlag{values: &opts.IgnorePatterns}},
+		{name: "calibrate", usage: "Send control requests (original URL and bogus paths) first and flag findings matching them as likely false positives (is_likely_bypass=0)", value: &opts.Calibrate, defVal: false},
+		{name: "dedupe-responses", usage: "Cluster near-identical findings of a module (same status, title, similar length and body) and keep one per cluster", value: &opts.DedupeResponses, defVal: false},
+		{name: "unique", usage: "Keep only the first finding per status code, length and title of each target URL, across all modules (exact match, cheaper than -dedupe-responses)", value: &opts.Unique, defVal: false},
+		{name: "show-denied", usage: "Keep findings with the same 401/403 status as the original request (dumb_check or -calibrate), hidden by default even if -mc matches them", value: &opts.ShowDenied, defVal: false},
+		{name: "dedupe-payloads", usage: "Send identical requests (same method, URI, headers as written and in order, and body) produced by several modules only once, credited to the first module", value: &opts.DedupePayloads, defVal: false},
 		{name: "H,header", usage: "Custom HTTP header (example: -H \"X-My-Header: value\"), can be used multiple times", value: &stringSliceFlag{values: &opts.CustomHTTPHeaders}},
-		{name: "http2", usage: "Enable HTTP2 client", value: &opts.EnableHTTP2, defVal: false},
-		{name: "x,proxy", usage: "Proxy URL (format: http://proxy:port) (Example: -x http://127.0.0.1:8080)", value: &opts.Proxy},
+		{name: "auth-header", usage: "Credentials header sent with every request, the original request is also sent without it to tell authorization bypasses from access granted by the session (example: -auth-header \"Authorization: Bearer eyJ...\"), can be used multiple times", value: &stringSliceFlag{values: &opts.AuthHeaderStrs}},
+		{name: "auth-file", usage: "File with credential headers sent with every request, one \"Name: Value\" per line, same as -auth-header (example: -auth-file session.txt)", value: &opts.AuthFile},
+		{name: "user-agents", usage: "File with User-Agents (one per line) rotated round-robin per request, instead of the default Chrome User-Agent, ignored if -H sets one (example: -user-agents uas.txt)", value: &opts.UserAgentsFile},
+		{name: "preserve-header-order", usage: "Send payload headers exactly in the order the module generated them, a -H header replaces the payload header it overrides in place instead of being sent first", value: &opts.PreserveHeaderOrder, defVal: false},
+		{name: "accept-encoding", usage: "Accept-Encoding header sent with every request, compressed responses (gzip, deflate, br, zstd) are decoded before the body preview and title (example: -accept-encoding \"gzip, deflate, br, zstd\")", value: &opts.AcceptEncoding},
+		{name: "normalize-path", usage: "Let the HTTP client normalize request paths (percent-decoding, dot segments, duplicate slashes) instead of sending payloads verbatim, path based modules such as nginx_bypasses lose most payloads", value: &opts.NormalizePath, defVal: false},
+		{name: "http2", usage: "Send https requests over HTTP/2 (h2 negotiated via ALPN, hosts without h2 fall back to HTTP/1.1)", value: &opts.EnableHTTP2, defVal: false},
+		{name: "no-tls-resumption", usage: "Disable TLS session resumption, forcing a full handshake on every connection", value: &opts.NoTLSResumption, defVal: false},
+		{name: "client-cert", usage: "Client certificate (PEM) presented to targets requiring mTLS, requires -client-key (example: -client-cert client.crt)", value: &opts.ClientCertFile},
+		{name: "client-key", usage: "Private key (PEM) of the -client-cert certificate (example: -client-key client.key)", value: &opts.ClientKeyFile},
+		{name: "sni", usage: "TLS server name (SNI) sent to https targets instead of their host, the connection still goes to the target and the Host header is unchanged, for domain fronting tests (example: -sni allowed.example.com)", value: &opts.SNI},
+		{name: "tls-fingerprint", usage: "Mimic a browser TLS ClientHello (JA3) on https targets: chrome, firefox, safari, edge, ios or random (composes with -x)", value: &opts.TLSFingerprint},
+		{name: "resolvers", usage: "DNS servers used instead of the default ones (public resolvers, system resolver, DoH), as ip:port, comma separated or a file with one per line (example: -resolvers 10.0.0.53:53)", value: &opts.Resolvers},
+		{name: "x,proxy", usage: "Proxy URL, HTTP or SOCKS5 (format: http://proxy:port, socks5://proxy:port) (Example: -x http://127.0.0.1:8080)", value: &opts.Proxy},
+		{name: "proxy-file", usage: "File with proxy URLs (one per line, HTTP or SOCKS5) rotated round-robin per request, a proxy failing 5 times in a row is dropped (example: -proxy-file proxies.txt)", value: &opts.ProxyFile},
+		{name: "replay-findings-proxy", usage: "At scan end, re-send every finding through this proxy (example: -replay-findings-proxy http://127.0.0.1:8080)", value: &opts.ReplayFindingsProxy},
 		{name: "spoof-header", usage: "Add more headers used to spoof IPs (example: X-SecretIP-Header,X-GO-IP)", value: &opts.SpoofHeader},
 		{name: "spoof-ip", usage: "Add more spoof IPs (example: 10.10.20.20,172.16.30.10)", value: &opts.SpoofIP},
+		{name: "url-header-level", usage: "headers_url module variations: 1 = base path only, 2 = + parent paths, 3 = + full URLs with parent paths", value: &opts.URLHeaderLevel, defVal: 3},
+		{name: "both-forms", usage: "Path modules also generate payloads from the percent-decoded target path (when it differs)", value: &opts.BothForms, defVal: false},
+		{name: "mutate-query", usage: "case_substitution and char_encode also mutate the query string (case of parameter names/values, URL-encoded names/values), not only the path", value: &opts.MutateQuery, defVal: false},
+		{name: "w,wordlist", usage: "Custom payload wordlists per bypass module, replacing the built-in list (example: -w mid_paths=midpaths.txt,end_paths=endpaths.txt)", value: &opts.Wordlist},
+		{name: "append-endpaths", usage: "Extra end_paths suffixes merged with the built-in (or -w) list, comma separated or a file with one per line (example: -append-endpaths '.json,;.css,%00')", value: &opts.AppendEndPaths},
+		{name: "append-midpaths", usage: "Extra mid_paths payloads merged with the built-in (or -w) list, comma separated or a file with one per line (example: -append-midpaths '..;/,%2e%2e/')", value: &opts.AppendMidPaths},
 		{name: "fr,follow-redirects", usage: "Follow HTTP redirects", value: &opts.FollowRedirects},
-		{name: "rbps,response-body-preview-size", usage: "Maximum number of bytes to retrieve from response body", value: &opts.ResponseBodyPreviewSize, defVal: 1024},
+		{name: "rbps,preview-size,response-body-preview-size", usage: "Maximum number of bytes to retrieve from response body", value: &opts.ResponseBodyPreviewSize, defVal: 1024},
+		{name: "max-body,max-response-body-size", usage: "Maximum number of response body bytes buffered in memory, larger bodies are streamed, independent of the preview size (0 = 8192 + preview size + 1024)", value: &opts.MaxResponseBodySize, defVal: 0},
 		{name: "drbs,disable-response-body-streaming", usage: "Disables streaming of response body (default: False)", value: &opts.DisableStreamResponseBody, defVal: false},
 		{name: "dpb,disable-progress-bar", usage: "Disable progress bar", value: &opts.DisableProgressBar, defVal: false},
-		{name: "r,resend,resend-request", usage: "Resend the exact request using the debug token (example: -r xyzdebugtoken)", value: &opts.ResendRequest},
-		{name: "rn,resend-num,resend-request-num", usage: "Number of times to resend the debugged request", value: &opts.ResendNum, defVal: 1},
+		{name: "r,replay,resend,resend-request", usage: "Replay the exact request of a finding using its debug token and print the full request and responses, without running a scan (example: -replay xyzdebugtoken)", value: &opts.ResendRequest},
+		{name: "rn,replay-count,resend-num,resend-request-num", usage: "Number of times to send the replayed request", value: &opts.ResendNum, defVal: 1},
+		{name: "decode-token", usage: "Decode a debug token and print the request it stands for (module, method, URL, headers, body) as text and JSON, without sending it (example: -decode-token xyzdebugtoken)", value: &opts.DecodeToken},
+		{name: "diff", usage: "Compare the findings of two scans and print the added, removed and changed ones, as results.db or findings.jsonl files (example: -diff old/results.db new/results.db)", value: &opts.Diff},
+		{name: "diff-json", usage: "Also write the -diff result as JSON to this file (example: -diff-json diff.json)", value: &opts.DiffJSONFile},
 		{name: "profile", usage: "Enable pprof profiler", value: &opts.Profile, defVal: false},
+		{name: "metrics-addr", usage: "Serve the pprof endpoints (/debug/pprof/) and live scan stats as JSON (/stats: active workers, request rate, findings) on this address while scanning (example: -metrics-addr :6060)", value: &opts.MetricsAddr},
 		{name: "update-payloads", usage: "Update payload files to latest version", value: &opts.UpdatePayloads, defVal: false},
 	}
 
 	// Set up custom usage
-	flag.Usage = func() {
+	usage := func() {
 		fmt.Fprintf(os.Stderr, "GoByPASS403 v%s\n\n", GOBYPASS403_VERSION)
 		fmt.Fprintf(os.Stderr, "Usage:\n")
 		for _, f := range flags {
@@ -122,6 +213,8 @@ func parseFlags() (*CliOptions, error) {
 			}
 		}
 	}
+	fs.Usage = usage
+	flag.Usage = usage // printUsage
 
 	// Register all flags
 	for _, f := range flags {
@@ -130,32 +223,64 @@ func parseFlags() (*CliOptions, error) {
 			switch v := f.value.(type) {
 			case *string: // Handles opts.URL, opts.URLsFile, etc. AND the new Str flags
 				if def, ok := f.defVal.(string); ok {
-					flag.StringVar(v, name, def, f.usage)
+					fs.StringVar(v, name, def, f.usage)
 				} else {
-					flag.StringVar(v, name, "", f.usage) // Default empty string ""
+					fs.StringVar(v, name, "", f.usage) // Default empty string ""
 				}
 			case *int:
 				if def, ok := f.defVal.(int); ok {
-					flag.IntVar(v, name, def, f.usage)
+					fs.IntVar(v, name, def, f.usage)
+				} else {
+					fs.IntVar(v, name, 0, f.usage)
+				}
+			case *float64:
+				if def, ok := f.defVal.(float64); ok {
+					fs.Float64Var(v, name, def, f.usage)
 				} else {
-					flag.IntVar(v, name, 0, f.usage)
+					fs.Float64Var(v, name, 0, f.usage)
 				}
 			case *bool:
 				if def, ok := f.defVal.(bool); ok {
-					flag.BoolVar(v, name, def, f.usage)
+					fs.BoolVar(v, name, def, f.usage)
 				} else {
-					flag.BoolVar(v, name, false, f.usage)
+					fs.BoolVar(v, name, false, f.usage)
 				}
 			case *onOffFlag: // Handle the custom on/off flag type
-				flag.Var(v, name, f.usage) // Register using flag.Var
+				fs.Var(v, name, f.usage) // Register using flag.Var
 			case *stringSliceFlag: // Handle the string slice flag type
-				flag.Var(v, name, f.usage) // Register using flag.Var
+				fs.Var(v, name, f.usage) // Register using flag.Var
 			}
 		}
 	}
 
 	// Parse flags
-	flag.Parse()
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// -diff old new: flag parsing stops at the second file, resume after it (-diff old,new works too)
+	var diffNewFile string
+	if opts.Diff != "" && fs.NArg() > 0 {
+		diffNewFile = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	// -config: options from the file, the flags given on the command line take precedence
+	if opts.ConfigFile != "" {
+		if err := applyConfigFile(fs, opts.ConfigFile, flags); err != nil {
+			return nil, err
+		}
+	}
+
+	// Once the config file is applied, -diff may come from it
+	if opts.Diff != "" {
+		opts.DiffFiles = strings.Split(opts.Diff, ",")
+		if diffNewFile != "" {
+			opts.DiffFiles = append(opts.DiffFiles, diffNewFile)
+		}
+	}
 
 	// Set defaults and validate
 	opts.setDefaults()
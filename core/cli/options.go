@@ -7,17 +7,23 @@ package cli
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/hex"
 	"flag"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
@@ -28,40 +34,152 @@ type CliOptions struct {
 	URLsFile            string
 	SubstituteHostsFile string
 
+	// Config file with options, overridden by the command line flags
+	ConfigFile string
+
 	// Scan configuration
 	Module                   string
 	MatchStatusCodesStr      string
 	MatchStatusCodes         []int
 	MatchContentType         string   // New field for multiple types
 	MatchContentTypeBytes    [][]byte // Multiple byte slices for efficient matching
-	MinContentLengthStr      string   // Minimum Content-Length to match (as string)
-	MaxContentLengthStr      string   // Maximum Content-Length to match (as string)
-	MinContentLength         int      // Parsed min content length value
-	MaxContentLength         int      // Parsed max content length value
+	MatchMagic               string   // Known file type (jpeg, png, pdf, gif) or hex prefix
+	MatchMagicBytes          []byte   // Parsed magic bytes the response body must start with
+	FilterSizesStr           string   // Response sizes/ranges to hide (example: 1234,100-200)
+	MatchSizesStr            string   // Response sizes/ranges to match
+	FilterSizes              []scanner.SizeRange
+	MatchSizes               []scanner.SizeRange
+	MatchRegex               string         // Regex the response headers or body preview must match
+	FilterRegex              string         // Regex hiding responses whose headers or body preview match
+	MatchRegexp              *regexp.Regexp // Compiled -match-regex
+	FilterRegexp             *regexp.Regexp // Compiled -filter-regex
+	MatchHeaderStrs          []string       // Response headers to match, "Name: value" or "Name"
+	FilterHeaderStrs         []string       // Response headers to hide
+	MatchHeaders             []scanner.HeaderMatcher
+	FilterHeaders            []scanner.HeaderMatcher
+	MinContentLengthStr      string  // Minimum Content-Length to match (as string)
+	MaxContentLengthStr      string  // Maximum Content-Length to match (as string)
+	MinContentLength         int     // Parsed min content length value
+	MaxContentLength         int     // Parsed max content length value
+	MinConfidence            float64 // Drop findings with a lower confidence score (0 to 1)
 	ConcurrentRequests       int
+	HostConcurrency          int // Max in-flight requests per host (0 = no per-host limit)
+	URLConcurrency           int // Target URLs scanned concurrently, each with its own -cr workers
 	Timeout                  int
 	Delay                    int
+	Rate                     int // Max requests per second, takes precedence over Delay
+	Jitter                   int // Delay randomized by up to ± this percentage per request
 	MaxRetries               int
 	RetryDelay               int // in milliseconds
 	RequestDelay             int // in milliseconds
+	ModuleDelay              int // in seconds, pause between bypass modules
 	MaxConsecutiveFailedReqs int
+	MaxHostFails             int // Failed requests in a row after which a host is backed off
+	MaxRequests              int // Max requests per target URL across all modules, 0 = no limit
 	AutoThrottle             bool
-	ResponseBodyPreviewSize  int // in bytes, we don't need too much, Response Headers and a small body preview is enough
+	MaxRetryAfter            int           // in seconds, cap for Retry-After delays honored by auto-throttle and -retry-status
+	RetryStatusStr           string        // Raw -retry-status value (e.g. 429,503)
+	RetryStatusCodes         []int         // Parsed -retry-status, response status codes retried as transient
+	StopAllOnFind            bool          // Abort the whole run on the first finding
+	FailOn                   string        // Exit with code 2 on findings: "any" or status codes (-fail-on)
+	FailOnStatusCodes        []int         // Parsed -fail-on status codes, nil for any finding
+	MaxDurationStr           string        // Overall scan deadline as a Go duration (e.g. 10m, 1h30m)
+	MaxDuration              time.Duration // Parsed -max-duration, 0 = no limit
+	AllowSmuggling           bool          // Enable the request_smuggling_probe module (opt-in)
+	AllowCRLFInjection       bool          // Enable the header_crlf_injection module (opt-in)
+	Passive                  bool          // GET only, capped payloads per module, for sensitive targets
+	PassiveMaxPayloads       int           // Max payloads per bypass module and URL with -passive
+	Resume                   bool          // Skip (URL, module) pairs completed according to OutDir/checkpoint.json
+	DryRun                   bool          // Print the generated payloads, send no request
+	DryRunJSON               bool          // Write the generated payloads as JSON lines to OutDir/payloads.jsonl (implies DryRun)
+	DeterministicTokens      bool          // Same payload -> same debug token, across runs
+	ResponseBodyPreviewSize  int           // in bytes, we don't need too much, Response Headers and a small body preview is enough
+	MaxResponseBodySize      int           // in bytes, body buffered in memory, 0 = computed from the preview size
+
+	// Recon options
+	ReconConcurrency int  // Number of hosts probed in parallel
+	ReconTimeout     int  // Overall recon timeout in seconds (0 means no timeout)
+	NoProbe          bool // Skip recon, the input URLs are scanned as given
+
+	// headers_url module variation level (1-3)
+	URLHeaderLevel int
+
+	// Run path modules against both the raw and the percent-decoded path
+	BothForms bool
+
+	// case_substitution and char_encode also mutate the query string
+	MutateQuery bool
+
+	// Custom payload wordlists (-w module=file,...)
+	Wordlist        string
+	CustomWordlists map[string]string // Parsed -w, bypass module -> wordlist file
+
+	// Extra payloads merged with the built-in lists (-append-endpaths, -append-midpaths)
+	AppendEndPaths string
+	AppendMidPaths string
+	AppendPayloads map[string][]string // Parsed appends, bypass module -> payloads
 
 	// Custom HTTP Headers
-	CustomHTTPHeaders []string // Stores custom headers in "Name: Value" format
+	CustomHTTPHeaders   []string // Stores custom headers in "Name: Value" format
+	AuthHeaderStrs      []string // Credential headers sent with every request (-auth-header)
+	AuthFile            string   // File with credential headers, one "Name: Value" per line (-auth-file)
+	AuthHeaders         []string // -auth-header and -auth-file headers
+	UserAgentsFile      string   // File with User-Agents rotated per request (-user-agents)
+	UserAgents          []string // Parsed -user-agents
+	PreserveHeaderOrder bool     // Send payload headers in slice order, -H headers in the slot they override
+	AcceptEncoding      string   // Accept-Encoding sent with every request (-accept-encoding)
+	NormalizePath       bool     // Let the client normalize request paths instead of sending them verbatim
+
+	// Baseline suppression
+	SuppressBaseline bool             // Drop findings identical to the dumb_check response
+	IgnorePatterns   []string         // Regexes stripped from bodies before baseline comparison
+	IgnoreRegexps    []*regexp.Regexp // Compiled -ignore-pattern
+	Calibrate        bool             // Flag findings matching the control responses
+	DedupeResponses  bool             // Keep one finding per cluster of near-identical responses
+	Unique           bool             // Keep the first finding per status code, length and title of a target URL
+	ShowDenied       bool             // Keep findings with the same 401/403 status as the original request
+	DedupePayloads   bool             // Send identical requests produced by several modules only once
 
 	// Output options
-	OutDir        string
-	ResultsDBFile string
-	Verbose       bool
-	Debug         bool
+	Capture        string // Comma separated list of result fields to capture
+	CaptureFields  int    // Parsed scanner.Capture* flags
+	Webhook        string // POST each finding as JSON to this URL
+	SarifFile      string // Write findings as a SARIF report to this file
+	BurpFile       string // Write findings as Burp Suite saved items (XML) to this file
+	CSVFile        string // Stream findings as CSV rows to this file
+	JSONL          bool   // Append findings as JSON lines to OutDir/findings.jsonl
+	SaveBodies     bool   // Save the full response body of each finding to OutDir/bodies
+	SplitOutput    bool   // Write findings and bodies per target host, under OutDir/<host>
+	ExportHTTPDir  string // Write each finding's request as a .http file to this directory
+	HTMLReportFile string // Write an HTML report of the findings to this file
+	JSONReportFile string // Write a JSON report of the findings and request errors to this file
+	OutputVersion  int    // Schema version of the JSON findings (-webhook, -jsonl, -json)
+	SortBy         string // Order of the results tables and reports: time, length, status or confidence (empty = default grouping)
+	OutDir         string
+	DBBackend      string // Findings store: sqlite, bolt or none
+	ResultsDBFile  string // Results DB of the sqlite store (-db-path)
+	Verbose        bool
+	Debug          bool
+	DumpWire       string // Log raw request bytes and response heads: all or matched
+	LogJSON        bool   // Log messages as JSON lines to stderr
+	NoColor        bool   // No ANSI colors, also implied by NO_COLOR or a non-terminal stdout
+	ProgressJSON   bool   // Emit module progress events as JSON lines on stderr
 
 	// Network options
-	Proxy           string
-	ParsedProxy     *url.URL
-	EnableHTTP2     bool // not implemented yet
-	FollowRedirects bool // not implemented yet
+	Proxy               string
+	ParsedProxy         *url.URL
+	ProxyFile           string   // File with proxy URLs rotated per request (-proxy-file)
+	ProxyURLs           []string // Parsed -proxy-file
+	ReplayFindingsProxy string   // Proxy used to replay all findings at scan end
+	EnableHTTP2         bool     // Send https requests over HTTP/2, falls back to HTTP/1.1 per host
+	NoTLSResumption     bool     // Disable TLS session resumption (full handshake per connection)
+	ClientCertFile      string   // PEM client certificate for mTLS
+	ClientKeyFile       string   // PEM private key of the client certificate
+	TLSFingerprint      string   // Browser TLS ClientHello to mimic (chrome, firefox, ..., random)
+	SNI                 string   // TLS ServerName sent instead of the target host (domain fronting)
+	Resolvers           string   // DNS servers (ip:port), comma separated or a file with one per line
+	ParsedResolvers     []string // Parsed -resolvers, empty means the default resolvers
+	FollowRedirects     bool     // not implemented yet
 
 	// Spoofing options
 	SpoofIP     string
@@ -75,11 +193,22 @@ type CliOptions struct {
 	ResendRequest string
 	ResendNum     int
 
+	// Diff
+	Diff         string   // Findings files of the two scans to compare
+	DiffFiles    []string // Parsed -diff files: old, new
+	DiffJSONFile string   // Also write the diff as JSON to this file
+
+	// DecodeToken, print the request of a debug token and exit
+	DecodeToken string
+
 	//UpdatePayloads
 	UpdatePayloads bool
 
 	// Enable profiler
 	Profile bool
+
+	// Serve pprof and live scan stats (/stats) on this address during the scan
+	MetricsAddr string
 }
 
 // AvailableModes defines all bypass modes and their status, true if enabled, false if disabled
@@ -99,6 +228,28 @@ var AvailableModules = map[string]bool{
 	"headers_url":                true,
 	"headers_host":               true,
 	"unicode_path_normalization": true,
+	"separator":                  true,
+	"method_override":            true,
+	"path_params":                true,
+	"proxy_path_rewrite":         true,
+	"overlong_encode":            true,
+	"http_host_mutations":        true,
+	"request_smuggling_probe":    true,
+	"path_normalization":         true,
+	"http_headers_accept":        true,
+	"http_cookies":               true,
+	"header_crlf_injection":      true,
+	"http_absolute_uri":          true,
+	"webdav":                     true,
+	"header_case":                true,
+	"extension_confusion":        true,
+	"api_gateway":                true,
+}
+
+// optInModules are left out of -m all, they must be enabled with their flag
+var optInModules = map[string]string{
+	"request_smuggling_probe": "allow-smuggling",
+	"header_crlf_injection":   "allow-crlf-injection",
 }
 
 func (o *CliOptions) printUsage(flagName ...string) {
@@ -149,11 +300,37 @@ func (o *CliOptions) setDefaults() {
 	if o.Delay <= 0 {
 		o.Delay = 0
 	}
+	if o.Rate < 0 {
+		o.Rate = 0
+	}
+	if o.ModuleDelay < 0 {
+		o.ModuleDelay = 0
+	}
+	if o.HostConcurrency < 0 {
+		o.HostConcurrency = 0
+	}
+	if o.URLConcurrency < 1 {
+		o.URLConcurrency = 1
+	}
+	if o.MaxRequests < 0 {
+		o.MaxRequests = 0
+	}
+	if o.PassiveMaxPayloads <= 0 {
+		o.PassiveMaxPayloads = 100
+	}
 
 	if o.RetryDelay == 0 {
 		o.RetryDelay = 500
 	}
 
+	if o.URLHeaderLevel == 0 {
+		o.URLHeaderLevel = payload.MaxURLHeaderLevel
+	}
+
+	if o.ReconConcurrency <= 0 {
+		o.ReconConcurrency = 50
+	}
+
 	// Status codes default - accept all codes
 	if o.MatchStatusCodesStr == "" {
 		o.MatchStatusCodes = nil // nil means match all status codes
@@ -163,6 +340,9 @@ func (o *CliOptions) setDefaults() {
 	if !o.AutoThrottle {
 		o.AutoThrottle = true
 	}
+	if o.MaxRetryAfter <= 0 {
+		o.MaxRetryAfter = 30
+	}
 
 	// Output directory default
 	if o.OutDir == "" {
@@ -170,7 +350,7 @@ func (o *CliOptions) setDefaults() {
 	}
 
 	if o.ResultsDBFile == "" {
-		o.ResultsDBFile = filepath.Join(o.OutDir, "results.db")
+		o.ResultsDBFile = filepath.Join(o.OutDir, scanner.DBFileName(strings.ToLower(strings.TrimSpace(o.DBBackend))))
 	}
 
 	// Max response body size default
@@ -190,10 +370,37 @@ func (o *CliOptions) validate() error {
 		os.Exit(0)
 	}
 
+	// -decode-token only decodes a debug token, no target needed
+	if o.DecodeToken != "" {
+		return nil
+	}
+
+	// -diff only compares two previous scans, no target needed
+	if o.Diff != "" {
+		if len(o.DiffFiles) != 2 {
+			o.printUsage("diff")
+			fmt.Println()
+			return fmt.Errorf("-diff requires exactly two findings files (old and new)")
+		}
+		return nil
+	}
+
 	if o.ResendRequest != "" {
 		data, err := payload.DecodePayloadToken(o.ResendRequest)
 		if err != nil {
-			return fmt.Errorf("invalid debug token: %v", err)
+			o.printUsage("replay")
+			fmt.Println()
+			return fmt.Errorf("invalid debug token %q: %v (copy it from the debug_token column of the results DB or the -jsonl output)", o.ResendRequest, err)
+		}
+		if data.Scheme == "" || data.Host == "" || data.Method == "" {
+			o.printUsage("replay")
+			fmt.Println()
+			return fmt.Errorf("invalid debug token %q: missing scheme, host or method, the token may be truncated", o.ResendRequest)
+		}
+		if o.ResendNum < 1 {
+			o.printUsage("replay-count")
+			fmt.Println()
+			return fmt.Errorf("invalid -replay-count %d: must be at least 1", o.ResendNum)
 		}
 		// Print the decoded information
 		targetURL := fmt.Sprintf("%s://%s%s", data.Scheme, data.Host, data.RawURI)
@@ -208,6 +415,9 @@ func (o *CliOptions) validate() error {
 		for _, h := range data.Headers {
 			GB403Logger.PrintYellow("  %s: %s\n", h.Header, h.Value)
 		}
+		if data.Body != "" {
+			GB403Logger.PrintYellow("Body: %q\n", data.Body)
+		}
 		GB403Logger.PrintYellow("Bypass Module: %s\n\n", data.BypassModule)
 	}
 
@@ -221,11 +431,58 @@ func (o *CliOptions) validate() error {
 		return err
 	}
 
+	// Process the credential headers (-auth-header, -auth-file)
+	if err := o.processAuthHeaders(); err != nil {
+		return err
+	}
+
 	// Process and validate status codes
 	if err := o.processStatusCodes(); err != nil {
 		return err
 	}
 
+	if err := o.processFailOn(); err != nil {
+		return err
+	}
+
+	if o.MaxResponseBodySize < 0 {
+		o.printUsage("max-body")
+		fmt.Println()
+		return fmt.Errorf("invalid -max-body %d, expected a size in bytes or 0 for the default", o.MaxResponseBodySize)
+	}
+
+	if o.MaxDurationStr != "" {
+		maxDuration, err := time.ParseDuration(o.MaxDurationStr)
+		if err != nil || maxDuration <= 0 {
+			o.printUsage("max-duration")
+			fmt.Println()
+			return fmt.Errorf("invalid -max-duration %q, expected a positive duration such as 30m or 1h30m", o.MaxDurationStr)
+		}
+		o.MaxDuration = maxDuration
+	}
+
+	// Parse the status codes retried as transient
+	if o.RetryStatusStr != "" {
+		for _, code := range strings.Split(o.RetryStatusStr, ",") {
+			statusCode, err := strconv.Atoi(strings.TrimSpace(code))
+			if err != nil || statusCode < 100 || statusCode > 599 {
+				o.printUsage("retry-status")
+				fmt.Println()
+				return fmt.Errorf("invalid -retry-status code %q, expected a comma separated list such as 429,503", code)
+			}
+			o.RetryStatusCodes = append(o.RetryStatusCodes, statusCode)
+		}
+	}
+
+	// Validate the metrics server address (host:port, host optional)
+	if o.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(o.MetricsAddr); err != nil {
+			o.printUsage("metrics-addr")
+			fmt.Println()
+			return fmt.Errorf("invalid -metrics-addr %q, expected host:port such as :6060 or 127.0.0.1:6060", o.MetricsAddr)
+		}
+	}
+
 	// Validate content length options
 	if o.MinContentLengthStr != "" {
 		minCL, err := strconv.Atoi(o.MinContentLengthStr)
@@ -243,12 +500,97 @@ func (o *CliOptions) validate() error {
 		o.MaxContentLength = maxCL
 	}
 
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		o.printUsage("min-confidence")
+		fmt.Println()
+		return fmt.Errorf("invalid -min-confidence %v, expected a score from 0 to 1", o.MinConfidence)
+	}
+
 	// Check min > max only if both are set
 	if o.MinContentLength > 0 && o.MaxContentLength > 0 && o.MinContentLength > o.MaxContentLength {
 		return fmt.Errorf("minimum content length (%d) cannot be greater than maximum content length (%d)",
 			o.MinContentLength, o.MaxContentLength)
 	}
 
+	// Process response size filters
+	if o.FilterSizesStr != "" {
+		sizes, err := scanner.ParseSizeRanges(o.FilterSizesStr)
+		if err != nil {
+			o.printUsage("filter-size")
+			return fmt.Errorf("invalid -fs value: %w", err)
+		}
+		o.FilterSizes = sizes
+	}
+
+	if o.MatchSizesStr != "" {
+		sizes, err := scanner.ParseSizeRanges(o.MatchSizesStr)
+		if err != nil {
+			o.printUsage("match-size")
+			return fmt.Errorf("invalid -ms value: %w", err)
+		}
+		o.MatchSizes = sizes
+	}
+
+	// Process response header matchers
+	o.MatchHeaders, o.FilterHeaders = nil, nil
+	for _, h := range o.MatchHeaderStrs {
+		m, err := scanner.ParseHeaderMatcher(h)
+		if err != nil {
+			o.printUsage("match-header")
+			return fmt.Errorf("invalid -match-header value: %w", err)
+		}
+		o.MatchHeaders = append(o.MatchHeaders, m)
+	}
+	for _, h := range o.FilterHeaderStrs {
+		m, err := scanner.ParseHeaderMatcher(h)
+		if err != nil {
+			o.printUsage("filter-header")
+			return fmt.Errorf("invalid -filter-header value: %w", err)
+		}
+		o.FilterHeaders = append(o.FilterHeaders, m)
+	}
+
+	// Compile response regex matchers once
+	if o.MatchRegex != "" {
+		rx, err := regexp.Compile(o.MatchRegex)
+		if err != nil {
+			o.printUsage("match-regex")
+			return fmt.Errorf("invalid regex for -match-regex %q: %w", o.MatchRegex, err)
+		}
+		o.MatchRegexp = rx
+	}
+
+	if o.FilterRegex != "" {
+		rx, err := regexp.Compile(o.FilterRegex)
+		if err != nil {
+			o.printUsage("filter-regex")
+			return fmt.Errorf("invalid regex for -filter-regex %q: %w", o.FilterRegex, err)
+		}
+		o.FilterRegexp = rx
+	}
+
+	// Compile baseline ignore patterns once
+	ignoreRegexps, err := scanner.CompileIgnorePatterns(o.IgnorePatterns)
+	if err != nil {
+		o.printUsage("ignore-pattern")
+		return fmt.Errorf("-ignore-pattern: %w", err)
+	}
+	o.IgnoreRegexps = ignoreRegexps
+
+	if o.URLHeaderLevel < 1 || o.URLHeaderLevel > payload.MaxURLHeaderLevel {
+		o.printUsage("url-header-level")
+		return fmt.Errorf("invalid -url-header-level %d: must be between 1 and %d", o.URLHeaderLevel, payload.MaxURLHeaderLevel)
+	}
+
+	// Process custom wordlists if provided
+	if err := o.processWordlists(); err != nil {
+		return err
+	}
+
+	if err := o.processAppendPayloads(); err != nil {
+		return err
+	}
+
 	// Validate module
 	if err := o.validateModule(); err != nil {
 		return err
@@ -264,6 +606,105 @@ func (o *CliOptions) validate() error {
 		return err
 	}
 
+	// Process proxy rotation file if provided
+	if err := o.processProxyFile(); err != nil {
+		return err
+	}
+
+	// Process custom DNS resolvers if provided
+	if err := o.processResolvers(); err != nil {
+		return err
+	}
+
+	// Process User-Agents file if provided
+	if err := o.processUserAgents(); err != nil {
+		return err
+	}
+
+	// Process replay findings proxy if provided
+	if err := o.processReplayFindingsProxy(); err != nil {
+		return err
+	}
+
+	// Process client certificate (mTLS) if provided
+	if err := o.processClientCert(); err != nil {
+		return err
+	}
+
+	// -dry-run-json is a dry run too
+	if o.DryRunJSON {
+		o.DryRun = true
+	}
+
+	// Validate TLS fingerprint if provided
+	if o.TLSFingerprint != "" {
+		if !rawhttp.IsValidTLSFingerprint(o.TLSFingerprint) {
+			o.printUsage("tls-fingerprint")
+			fmt.Println()
+			return fmt.Errorf("unsupported TLS fingerprint %q (supported: %s)", o.TLSFingerprint, strings.Join(rawhttp.TLSFingerprints, ", "))
+		}
+		o.TLSFingerprint = strings.ToLower(o.TLSFingerprint)
+	}
+	// Validate SNI override if provided, crypto/tls never sends an IP address as SNI
+	if o.SNI != "" {
+		sni := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(o.SNI)), ".")
+		if sni == "" || strings.ContainsAny(sni, " /:") || net.ParseIP(sni) != nil {
+			o.printUsage("sni")
+			fmt.Println()
+			return fmt.Errorf("invalid -sni value %q: must be a host name, without scheme or port", o.SNI)
+		}
+		o.SNI = sni
+	}
+	if o.TLSFingerprint != "" && o.EnableHTTP2 {
+		GB403Logger.Warning().Msgf("-http2 is ignored with -tls-fingerprint, requests are sent over HTTP/1.1\n")
+	}
+	if o.Rate > 0 && o.Delay > 0 {
+		GB403Logger.Warning().Msgf("-delay is ignored with -rate, requests are limited to %d req/s\n", o.Rate)
+	}
+	if o.Jitter < 0 || o.Jitter > 100 {
+		o.printUsage("jitter")
+		fmt.Println()
+		return fmt.Errorf("invalid -jitter value %d: must be a percentage between 0 and 100", o.Jitter)
+	}
+	if o.Jitter > 0 && (o.Delay == 0 || o.Rate > 0) {
+		GB403Logger.Warning().Msgf("-jitter only applies to -delay and is ignored with -rate\n")
+	}
+	if o.NormalizePath && slices.Contains(strings.Split(o.Module, ","), "nginx_bypasses") {
+		GB403Logger.Warning().Msgf("-normalize-path resolves the dot segments and encoded bytes nginx_bypasses payloads depend on, most of them are sent as the original path\n")
+	}
+
+	// Validate webhook URL if provided
+	if o.Webhook != "" {
+		parsedWebhook, err := url.Parse(o.Webhook)
+		if err != nil || (parsedWebhook.Scheme != "http" && parsedWebhook.Scheme != "https") || parsedWebhook.Host == "" {
+			o.printUsage("webhook")
+			return fmt.Errorf("invalid webhook URL: %s", o.Webhook)
+		}
+	}
+
+	if err := scanner.ValidateOutputSchemaVersion(o.OutputVersion); err != nil {
+		o.printUsage("output-version")
+		return fmt.Errorf("invalid -output-version: %w", err)
+	}
+
+	o.DumpWire = strings.ToLower(strings.TrimSpace(o.DumpWire))
+	if o.DumpWire != "" && !slices.Contains(rawhttp.WireDumpModes, o.DumpWire) {
+		o.printUsage("dump-wire")
+		return fmt.Errorf("invalid -dump-wire value %q: must be one of %s", o.DumpWire, strings.Join(rawhttp.WireDumpModes, ", "))
+	}
+
+	o.DBBackend = strings.ToLower(strings.TrimSpace(o.DBBackend))
+	if !slices.Contains(scanner.DBBackends, o.DBBackend) {
+		o.printUsage("db")
+		return fmt.Errorf("invalid -db value %q: must be one of %s", o.DBBackend, strings.Join(scanner.DBBackends, ", "))
+	}
+
+	o.SortBy = strings.ToLower(strings.TrimSpace(o.SortBy))
+	if o.SortBy != "" && !slices.Contains(scanner.SortByValues, o.SortBy) {
+		o.printUsage("sort-by")
+		return fmt.Errorf("invalid -sort-by value %q: must be one of %s", o.SortBy, strings.Join(scanner.SortByValues, ", "))
+	}
+
 	if o.MatchContentType != "" {
 		// Split by comma, allowing for spaces
 		types := strings.Split(o.MatchContentType, ",")
@@ -275,6 +716,21 @@ func (o *CliOptions) validate() error {
 		}
 	}
 
+	// Process captured result fields
+	if o.Capture != "" {
+		captureFields, err := scanner.ParseCaptureFields(o.Capture)
+		if err != nil {
+			o.printUsage("capture")
+			return fmt.Errorf("invalid -capture value: %w", err)
+		}
+		o.CaptureFields = captureFields
+	}
+
+	// Process magic bytes matcher
+	if err := o.processMatchMagic(); err != nil {
+		return err
+	}
+
 	// Check if payloads are outdated
 	if !o.UpdatePayloads && o.ResendRequest == "" {
 		consistent, err := payload.CheckOutdatedPayloads()
@@ -316,6 +772,38 @@ func (o *CliOptions) validateInputURLs() error {
 	return nil
 }
 
+// knownMagicBytes maps file types to the signature their body starts with
+var knownMagicBytes = map[string][]byte{
+	"jpeg": {0xFF, 0xD8, 0xFF},
+	"jpg":  {0xFF, 0xD8, 0xFF},
+	"png":  {0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A},
+	"gif":  []byte("GIF8"),
+	"pdf":  []byte("%PDF-"),
+}
+
+// processMatchMagic parses -match-magic into the magic bytes to match
+func (o *CliOptions) processMatchMagic() error {
+	if o.MatchMagic == "" {
+		return nil
+	}
+
+	magic := strings.ToLower(strings.TrimSpace(o.MatchMagic))
+	if known, ok := knownMagicBytes[magic]; ok {
+		o.MatchMagicBytes = known
+		return nil
+	}
+
+	// Otherwise expect a hex prefix (e.g. 504b0304 or 0x504b0304)
+	magic = strings.TrimPrefix(magic, "0x")
+	decoded, err := hex.DecodeString(magic)
+	if err != nil || len(decoded) == 0 {
+		o.printUsage("match-magic")
+		return fmt.Errorf("invalid -match-magic value %q: expected jpeg, png, pdf, gif or a hex prefix", o.MatchMagic)
+	}
+	o.MatchMagicBytes = decoded
+	return nil
+}
+
 // processStatusCodes processes the status codes string
 func (o *CliOptions) processStatusCodes() error {
 	if o.MatchStatusCodesStr == "" {
@@ -368,7 +856,48 @@ func (o *CliOptions) processStatusCodes() error {
 	return nil
 }
 
-// validateModule checks if the specified module is valid
+// processFailOn parses -fail-on: "any", or a comma separated list of status codes and
+// status classes (e.g. 200,3xx). Unlike -mc, invalid values are rejected, a typo would
+// silently pass a CI gate.
+func (o *CliOptions) processFailOn() error {
+	o.FailOnStatusCodes = nil
+	if o.FailOn == "" || strings.EqualFold(strings.TrimSpace(o.FailOn), "any") {
+		return nil
+	}
+
+	for _, part := range strings.Split(o.FailOn, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if len(part) == 3 && strings.HasSuffix(strings.ToLower(part), "xx") && part[0] >= '1' && part[0] <= '5' {
+			firstDigit := int(part[0] - '0')
+			for i := 0; i < 100; i++ {
+				o.FailOnStatusCodes = append(o.FailOnStatusCodes, firstDigit*100+i)
+			}
+			continue
+		}
+
+		code, err := strconv.Atoi(part)
+		if err != nil || code < 100 || code > 599 {
+			o.printUsage("fail-on")
+			fmt.Println()
+			return fmt.Errorf("invalid -fail-on value '%s': must be 'any' or status codes (example: -fail-on 200,3xx)", part)
+		}
+		o.FailOnStatusCodes = append(o.FailOnStatusCodes, code)
+	}
+
+	if len(o.FailOnStatusCodes) == 0 {
+		o.printUsage("fail-on")
+		fmt.Println()
+		return fmt.Errorf("invalid -fail-on value '%s': must be 'any' or status codes (example: -fail-on 200,3xx)", o.FailOn)
+	}
+	return nil
+}
+
+// validateModule checks if the specified module is valid.
+// Modules prefixed with "-" are excluded, e.g. -m all,-char_encode,-unicode_path_normalization
 func (o *CliOptions) validateModule() error {
 	if o.Module == "" {
 		o.printUsage("module")
@@ -379,35 +908,66 @@ func (o *CliOptions) validateModule() error {
 	modules := strings.Split(o.Module, ",")
 	finalModules := make([]string, 0, len(modules))
 
-	// Check for "all" first
+	// Split the excluded modules (-module) from the requested ones
+	var requested []string
+	excluded := make(map[string]bool)
 	for _, m := range modules {
-		if strings.TrimSpace(m) == "all" {
-			// Expand to all available modules except "dumb_check"
-			for moduleName := range AvailableModules {
-				if moduleName != "dumb_check" {
-					finalModules = append(finalModules, moduleName)
-				}
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(m, "-"); ok {
+			if enabled, exists := AvailableModules[name]; !exists || !enabled {
+				return fmt.Errorf("invalid excluded module: %s", name)
 			}
-			break
+			excluded[name] = true
+			continue
 		}
+		requested = append(requested, m)
 	}
 
-	// If not "all", validate individual modules
-	if len(finalModules) == 0 {
-		for _, m := range modules {
-			m = strings.TrimSpace(m)
-			if m == "" {
+	// Only exclusions given, they apply to all modules
+	if len(requested) == 0 && len(excluded) > 0 {
+		requested = []string{"all"}
+	}
+
+	// Check for "all" first
+	if slices.Contains(requested, "all") {
+		// Expand to all available modules except "dumb_check" and the opt-in ones not enabled
+		for _, moduleName := range payload.BypassModulesRegistry {
+			if moduleName == "dumb_check" || !AvailableModules[moduleName] || excluded[moduleName] {
+				continue
+			}
+			if _, optIn := optInModules[moduleName]; optIn && !o.optInModuleEnabled(moduleName) {
 				continue
 			}
+			finalModules = append(finalModules, moduleName)
+		}
+	} else {
+		// If not "all", validate individual modules
+		for _, m := range requested {
 			if enabled, exists := AvailableModules[m]; !exists || !enabled {
 				return fmt.Errorf("invalid module: %s", m)
 			}
+			if excluded[m] || slices.Contains(finalModules, m) {
+				continue
+			}
+			if flagName, optIn := optInModules[m]; optIn && !o.optInModuleEnabled(m) {
+				o.printUsage(flagName)
+				fmt.Println()
+				return fmt.Errorf("module %s is opt-in, enable it explicitly with -%s", m, flagName)
+			}
 			finalModules = append(finalModules, m)
 		}
 	}
 
+	if len(finalModules) == 0 {
+		o.printUsage("module")
+		return fmt.Errorf("all the requested bypass modules are excluded")
+	}
+
 	// Always prepend dumb_check unless explicitly excluded
-	if !slices.Contains(finalModules, "dumb_check") {
+	if !slices.Contains(finalModules, "dumb_check") && !excluded["dumb_check"] {
 		finalModules = append([]string{"dumb_check"}, finalModules...)
 	}
 
@@ -416,6 +976,17 @@ func (o *CliOptions) validateModule() error {
 	return nil
 }
 
+// optInModuleEnabled reports whether the flag of an opt-in module was set
+func (o *CliOptions) optInModuleEnabled(module string) bool {
+	switch module {
+	case "request_smuggling_probe":
+		return o.AllowSmuggling
+	case "header_crlf_injection":
+		return o.AllowCRLFInjection
+	}
+	return false
+}
+
 // setupOutputDir creates the output directory
 func (o *CliOptions) setupOutputDir() error {
 	if err := os.MkdirAll(o.OutDir, 0o755); err != nil {
@@ -429,17 +1000,334 @@ func (o *CliOptions) processProxy() error {
 		return nil
 	}
 
-	parsedProxy, err := url.Parse(o.Proxy)
+	parsedProxy, err := parseProxyURL(o.Proxy)
 	if err != nil {
 		o.printUsage("proxy")
 		fmt.Println()
-		return fmt.Errorf("invalid proxy URL: %v", err)
+		return err
 	}
 
 	o.ParsedProxy = parsedProxy
 	return nil
 }
 
+// processProxyFile loads the -proxy-file proxies, one URL per line, rotated per request
+func (o *CliOptions) processProxyFile() error {
+	if o.ProxyFile == "" {
+		return nil
+	}
+
+	if o.Proxy != "" {
+		o.printUsage("proxy-file")
+		fmt.Println()
+		return fmt.Errorf("-proxy-file and -x are mutually exclusive")
+	}
+
+	data, err := os.ReadFile(o.ProxyFile)
+	if err != nil {
+		o.printUsage("proxy-file")
+		fmt.Println()
+		return fmt.Errorf("failed to read proxy file: %v", err)
+	}
+
+	o.ProxyURLs = nil
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parsedProxy, err := parseProxyURL(line)
+		if err != nil {
+			o.printUsage("proxy-file")
+			fmt.Println()
+			return fmt.Errorf("%s line %d: %v", o.ProxyFile, i+1, err)
+		}
+		if proxyURL := parsedProxy.String(); !seen[proxyURL] {
+			seen[proxyURL] = true
+			o.ProxyURLs = append(o.ProxyURLs, proxyURL)
+		}
+	}
+
+	if len(o.ProxyURLs) == 0 {
+		o.printUsage("proxy-file")
+		fmt.Println()
+		return fmt.Errorf("no proxies found in %s", o.ProxyFile)
+	}
+	return nil
+}
+
+// parseProxyURL parses and validates a proxy URL (HTTP or SOCKS5)
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	parsedProxy, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %v", err)
+	}
+
+	switch strings.ToLower(parsedProxy.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q: use http, https, socks5 or socks5h", parsedProxy.Scheme)
+	}
+
+	if parsedProxy.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %s: missing host", rawURL)
+	}
+	return parsedProxy, nil
+}
+
+// processResolvers parses -resolvers, a comma separated list of ip:port or a file with one per line
+func (o *CliOptions) processResolvers() error {
+	if o.Resolvers == "" {
+		return nil
+	}
+
+	entries := strings.Split(o.Resolvers, ",")
+	if info, err := os.Stat(o.Resolvers); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(o.Resolvers)
+		if err != nil {
+			return fmt.Errorf("failed to read resolvers file: %v", err)
+		}
+		entries = strings.Split(string(data), "\n")
+	}
+
+	o.ParsedResolvers = nil
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		if !isIPPort(entry) {
+			o.printUsage("resolvers")
+			fmt.Println()
+			return fmt.Errorf("invalid resolver %q: expected ip:port (example: 10.0.0.53:53, [fd00::53]:53)", entry)
+		}
+
+		o.ParsedResolvers = append(o.ParsedResolvers, entry)
+	}
+	return nil
+}
+
+// isIPPort reports whether s is an IP address and port, e.g. 10.0.0.53:53 or [fd00::53]:53
+func isIPPort(s string) bool {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || net.ParseIP(host) == nil {
+		return false
+	}
+	portNum, err := strconv.Atoi(port)
+	return err == nil && portNum >= 1 && portNum <= 65535
+}
+
+// processWordlists parses -w (module=file,module=file) into CustomWordlists
+func (o *CliOptions) processWordlists() error {
+	if o.Wordlist == "" {
+		return nil
+	}
+
+	o.CustomWordlists = make(map[string]string)
+	for _, entry := range strings.Split(o.Wordlist, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		module, file, ok := strings.Cut(entry, "=")
+		module, file = strings.TrimSpace(module), strings.TrimSpace(file)
+		if !ok || module == "" || file == "" {
+			o.printUsage("wordlist")
+			fmt.Println()
+			return fmt.Errorf("invalid -w entry %q: expected module=file", entry)
+		}
+
+		if _, ok := payload.ModuleWordlists[module]; !ok {
+			supported := make([]string, 0, len(payload.ModuleWordlists))
+			for m := range payload.ModuleWordlists {
+				supported = append(supported, m)
+			}
+			slices.Sort(supported)
+			o.printUsage("wordlist")
+			fmt.Println()
+			return fmt.Errorf("bypass module %q doesn't use a wordlist (supported: %s)", module, strings.Join(supported, ", "))
+		}
+
+		if _, err := os.Stat(file); err != nil {
+			o.printUsage("wordlist")
+			fmt.Println()
+			return fmt.Errorf("wordlist for %s not found: %v", module, err)
+		}
+
+		o.CustomWordlists[module] = file
+	}
+	return nil
+}
+
+// processAppendPayloads parses -append-endpaths and -append-midpaths, each a comma separated
+// list or a file with one payload per line, into AppendPayloads
+func (o *CliOptions) processAppendPayloads() error {
+	o.AppendPayloads = nil
+	for _, a := range []struct{ flag, module, value string }{
+		{"append-endpaths", "end_paths", o.AppendEndPaths},
+		{"append-midpaths", "mid_paths", o.AppendMidPaths},
+	} {
+		if a.value == "" {
+			continue
+		}
+
+		entries := strings.Split(a.value, ",")
+		if info, err := os.Stat(a.value); err == nil && !info.IsDir() {
+			data, err := os.ReadFile(a.value)
+			if err != nil {
+				return fmt.Errorf("failed to read -%s file: %v", a.flag, err)
+			}
+			entries = strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+		}
+
+		var payloads []string
+		for _, entry := range entries {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				payloads = append(payloads, entry)
+			}
+		}
+		if len(payloads) == 0 {
+			o.printUsage(a.flag)
+			fmt.Println()
+			return fmt.Errorf("no payloads in -%s", a.flag)
+		}
+
+		if o.AppendPayloads == nil {
+			o.AppendPayloads = make(map[string][]string)
+		}
+		o.AppendPayloads[a.module] = payloads
+	}
+	return nil
+}
+
+// processClientCert checks -client-cert and -client-key are set together and form a valid key pair
+func (o *CliOptions) processClientCert() error {
+	if o.ClientCertFile == "" && o.ClientKeyFile == "" {
+		return nil
+	}
+
+	if o.ClientCertFile == "" || o.ClientKeyFile == "" {
+		o.printUsage("client-cert")
+		fmt.Println()
+		return fmt.Errorf("-client-cert and -client-key must be used together")
+	}
+
+	if _, err := tls.LoadX509KeyPair(o.ClientCertFile, o.ClientKeyFile); err != nil {
+		o.printUsage("client-cert")
+		fmt.Println()
+		return fmt.Errorf("invalid client certificate: %v", err)
+	}
+	return nil
+}
+
+// processReplayFindingsProxy validates the proxy used to replay findings at scan end
+func (o *CliOptions) processReplayFindingsProxy() error {
+	if o.ReplayFindingsProxy == "" {
+		return nil
+	}
+
+	parsedProxy, err := url.Parse(o.ReplayFindingsProxy)
+	if err != nil || parsedProxy.Host == "" {
+		o.printUsage("replay-findings-proxy")
+		fmt.Println()
+		return fmt.Errorf("invalid replay findings proxy URL: %s", o.ReplayFindingsProxy)
+	}
+
+	o.ReplayFindingsProxy = parsedProxy.String()
+	return nil
+}
+
+// processUserAgents loads the -user-agents file, one User-Agent per line
+func (o *CliOptions) processUserAgents() error {
+	if o.UserAgentsFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(o.UserAgentsFile)
+	if err != nil {
+		o.printUsage("user-agents")
+		fmt.Println()
+		return fmt.Errorf("failed to read user agents file: %v", err)
+	}
+
+	o.UserAgents = nil
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		o.UserAgents = append(o.UserAgents, line)
+	}
+
+	if len(o.UserAgents) == 0 {
+		o.printUsage("user-agents")
+		fmt.Println()
+		return fmt.Errorf("no user agents found in %s", o.UserAgentsFile)
+	}
+
+	// A User-Agent set with -H wins over the rotation
+	for _, header := range o.CustomHTTPHeaders {
+		if name, _, ok := strings.Cut(header, ":"); ok && strings.EqualFold(strings.TrimSpace(name), "User-Agent") {
+			GB403Logger.Warning().Msgf("-user-agents is ignored, the User-Agent is set with -H\n")
+			o.UserAgents = nil
+			break
+		}
+	}
+	return nil
+}
+
+// processAuthHeaders collects the -auth-header headers and the -auth-file ones (one per line,
+// # comments), they are sent with every request like -H headers
+func (o *CliOptions) processAuthHeaders() error {
+	o.AuthHeaders = append([]string(nil), o.AuthHeaderStrs...)
+
+	if o.AuthFile != "" {
+		data, err := os.ReadFile(o.AuthFile)
+		if err != nil {
+			o.printUsage("auth-file")
+			fmt.Println()
+			return fmt.Errorf("failed to read auth file: %v", err)
+		}
+
+		headers := 0
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			o.AuthHeaders = append(o.AuthHeaders, line)
+			headers++
+		}
+		if headers == 0 {
+			o.printUsage("auth-file")
+			fmt.Println()
+			return fmt.Errorf("no headers found in %s", o.AuthFile)
+		}
+	}
+
+	for i, header := range o.AuthHeaders {
+		name, _, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			o.printUsage("auth-header")
+			fmt.Println()
+			return fmt.Errorf("invalid auth header #%d '%s': must be in 'Header: Value' format", i+1, header)
+		}
+
+		// A header set with -H wins over the credentials
+		for _, custom := range o.CustomHTTPHeaders {
+			if customName, _, _ := strings.Cut(custom, ":"); strings.EqualFold(strings.TrimSpace(customName), strings.TrimSpace(name)) {
+				GB403Logger.Warning().Msgf("Auth header %s is ignored, it is set with -H\n", strings.TrimSpace(name))
+				break
+			}
+		}
+	}
+	return nil
+}
+
 // validateCustomHeaders checks and pre-processes custom headers
 func (o *CliOptions) validateCustomHeaders() error {
 	if len(o.CustomHTTPHeaders) == 0 {
@@ -0,0 +1,56 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// passiveSkippedModules send requests other than a plain GET, or ask the backend to handle
+// the request as another method (method_override), -passive leaves them out
+var passiveSkippedModules = []string{
+	"http_methods",
+	"method_override",
+	"haproxy_bypasses",
+	"request_smuggling_probe",
+}
+
+// applyPassiveModules removes the passiveSkippedModules from the module set (-passive)
+func (r *Runner) applyPassiveModules() error {
+	var modules, skipped []string
+	for _, m := range strings.Split(r.RunnerOptions.Module, ",") {
+		if slices.Contains(passiveSkippedModules, m) {
+			skipped = append(skipped, m)
+			continue
+		}
+		modules = append(modules, m)
+	}
+
+	if len(modules) == 0 || (len(modules) == 1 && modules[0] == "dumb_check") {
+		r.RunnerOptions.printUsage("passive")
+		fmt.Println()
+		return fmt.Errorf("all the requested bypass modules are skipped by -passive (%s)", strings.Join(skipped, ","))
+	}
+	if len(skipped) > 0 {
+		GB403Logger.Verbose().Msgf("Passive mode, skipping modules: %s\n", strings.Join(skipped, ","))
+	}
+
+	r.RunnerOptions.Module = strings.Join(modules, ",")
+	return nil
+}
+
+// applyPassiveProfile restricts the scan to GET requests without a body and without
+// double/triple encodings, capped to -passive-max-payloads payloads per module (-passive)
+func (r *Runner) applyPassiveProfile(scannerOpts *scanner.ScannerOpts) {
+	scannerOpts.Passive = true
+	scannerOpts.MaxModulePayloads = r.RunnerOptions.PassiveMaxPayloads
+	GB403Logger.Info().Msgf("Passive mode: GET requests only, at most %d payloads per module\n", scannerOpts.MaxModulePayloads)
+}
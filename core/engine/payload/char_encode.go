@@ -23,6 +23,11 @@ preserved during the letter-encoding process, this function also generates
 additional payloads where these specific '?' and '#' characters are
 percent-encoded (%3F and %23 respectively). This ensures that the original
 query string can always be appended correctly.
+
+With -mutate-query, the query string is also single and double URL-encoded
+(see queryEncodeVariants) and appended to the original path.
+
+With -passive, only the single encoding payloads are generated.
 */
 func (pg *PayloadGenerator) GenerateCharEncodePayloads(targetURL string, bypassModule string) []BypassPayload {
 	var allJobs []BypassPayload
@@ -276,6 +281,17 @@ func (pg *PayloadGenerator) GenerateCharEncodePayloads(targetURL string, bypassM
 		}
 	}
 
+	// 5. Query string encoding (-mutate-query), original path
+	if pg.mutateQuery && parsedURL.Query != "" {
+		single, double := queryEncodeVariants(parsedURL.Query)
+		for _, q := range single {
+			singlePaths[basePath+"?"+q] = struct{}{}
+		}
+		for _, q := range double {
+			doublePaths[basePath+"?"+q] = struct{}{}
+		}
+	}
+
 	// Create final jobs from the deduplicated maps
 	createJobs := func(paths map[string]struct{}, moduleType string) {
 		for rawURI := range paths {
@@ -288,8 +304,11 @@ func (pg *PayloadGenerator) GenerateCharEncodePayloads(targetURL string, bypassM
 	}
 
 	createJobs(singlePaths, "char_encode")
-	createJobs(doublePaths, "char_encode_double")
-	createJobs(triplePaths, "char_encode_triple")
+	// -passive: no double/triple encodings, they multiply the request count
+	if !pg.passive {
+		createJobs(doublePaths, "char_encode_double")
+		createJobs(triplePaths, "char_encode_triple")
+	}
 
 	// Log the total number of unique jobs created for this module group
 	GB403Logger.Debug().BypassModule("char_encode").Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
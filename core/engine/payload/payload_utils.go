@@ -7,12 +7,14 @@ package payload
 
 // This file contains various payload related utilities.
 import (
+	"bytes"
 	"crypto/sha256"
 	"embed"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -232,6 +234,71 @@ func ReadPayloadsFromFile(filename string) ([]string, error) {
 	return embeddedPayloads, nil
 }
 
+// ModuleWordlists maps the bypass modules accepting a custom wordlist (-w) to the payload file it replaces
+var ModuleWordlists = map[string]string{
+	"mid_paths":           "internal_midpaths.lst",
+	"end_paths":           "internal_endpaths.lst",
+	"http_methods":        "internal_http_methods.lst",
+	"method_override":     "internal_http_methods.lst",
+	"separator":           "separators.lst",
+	"overlong_encode":     "overlong_encodings.lst",
+	"headers_scheme":      "internal_proto_schemes.lst",
+	"headers_ip":          "internal_ip_hosts.lst",
+	"headers_port":        "internal_ports.lst",
+	"headers_url":         "header_urls.lst",
+	"proxy_path_rewrite":  "header_path_rewrite.lst",
+	"http_headers_accept": "header_content_negotiation.lst",
+	"http_cookies":        "cookies.lst",
+}
+
+// ReadPayloadsFromFileWithOverride reads the user wordlist at overridePath if set,
+// otherwise the payload file filename (local or embedded)
+func ReadPayloadsFromFileWithOverride(filename string, overridePath string) ([]string, error) {
+	if overridePath == "" {
+		return ReadPayloadsFromFile(filename)
+	}
+
+	content, err := os.ReadFile(overridePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read custom wordlist %s: %w", overridePath, err)
+	}
+
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	var payloads []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			payloads = append(payloads, line)
+		}
+	}
+
+	GB403Logger.Verbose().Msgf("Read %d payloads from custom wordlist %s (instead of %s)", len(payloads), overridePath, filename)
+	return payloads, nil
+}
+
+// MergePayloads appends the extra payloads missing from payloads, in order
+func MergePayloads(payloads []string, extra []string) []string {
+	if len(extra) == 0 {
+		return payloads
+	}
+
+	seen := make(map[string]struct{}, len(payloads)+len(extra))
+	for _, p := range payloads {
+		seen[p] = struct{}{}
+	}
+
+	merged := make([]string, 0, len(payloads)+len(extra))
+	merged = append(merged, payloads...)
+	for _, p := range extra {
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
 // ReadMaxPayloadsFromFile reads up to maxNum payloads from the specified file
 // -1 means all payloads (lines)
 func ReadMaxPayloadsFromFile(filename string, maxNum int) ([]string, error) {
@@ -494,6 +561,112 @@ func BypassPayloadToFullURL(bypassPayload BypassPayload) string {
 	return sb.String()
 }
 
+/*
+BypassPayloadToRawHTTPFile renders the bypass payload as a .http request file
+(VS Code REST Client / JetBrains HTTP Client format):
+
+	# <bypass module>: <original URL>
+	GET https://example.com/admin;/ HTTP/1.1
+	X-Forwarded-For: 127.0.0.1
+	Host: example.com
+
+	<body>
+
+Headers follow the order BuildRawHTTPRequest sends them in: the payload headers, the
+default Host header when the payload has none, then Content-Length when there is a
+body. Deferred Content-Length headers (haproxy_bypasses) are written last.
+Client defaults (User-Agent, Accept, Connection) are left to the replaying client.
+*/
+func BypassPayloadToRawHTTPFile(bypassPayload BypassPayload) []byte {
+	var buf bytes.Buffer
+
+	if bypassPayload.BypassModule != "" || bypassPayload.OriginalURL != "" {
+		buf.WriteString("# ")
+		buf.WriteString(bypassPayload.BypassModule)
+		if bypassPayload.OriginalURL != "" {
+			buf.WriteString(": ")
+			buf.WriteString(bypassPayload.OriginalURL)
+		}
+		buf.WriteByte('\n')
+	}
+
+	writeRawHTTPRequestHead(&buf, bypassPayload, BypassPayloadToFullURL(bypassPayload), "\n")
+
+	if len(bypassPayload.Body) > 0 {
+		buf.WriteByte('\n')
+		buf.WriteString(bypassPayload.Body)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+/*
+BypassPayloadToRawRequest renders the bypass payload as it goes on the wire, with CRLF line
+endings and the raw URI on the request line (-burp):
+
+	GET /admin;/ HTTP/1.1
+	X-Forwarded-For: 127.0.0.1
+	Host: example.com
+
+	<body>
+
+Headers are ordered like in BypassPayloadToRawHTTPFile, client defaults are left out as well.
+*/
+func BypassPayloadToRawRequest(bypassPayload BypassPayload) []byte {
+	var buf bytes.Buffer
+
+	writeRawHTTPRequestHead(&buf, bypassPayload, bypassPayload.RawURI, "\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(bypassPayload.Body)
+
+	return buf.Bytes()
+}
+
+// writeRawHTTPRequestHead writes the request line with requestTarget and the headers of the
+// payload, in the order BuildRawHTTPRequest sends them, each line ending with eol
+func writeRawHTTPRequestHead(buf *bytes.Buffer, bypassPayload BypassPayload, requestTarget string, eol string) {
+	buf.WriteString(bypassPayload.Method)
+	buf.WriteByte(' ')
+	buf.WriteString(requestTarget)
+	buf.WriteString(" HTTP/1.1")
+	buf.WriteString(eol)
+
+	writeHeader := func(name, value string) {
+		buf.WriteString(name)
+		buf.WriteString(": ")
+		buf.WriteString(value)
+		buf.WriteString(eol)
+	}
+
+	hasHost := false
+	hasContentLength := false
+	var deferred []Headers
+	for _, h := range bypassPayload.Headers {
+		switch {
+		case strings.EqualFold(h.Header, "Host"):
+			hasHost = true
+		case strings.EqualFold(h.Header, "Content-Length"):
+			hasContentLength = true
+			if bypassPayload.BypassModule == "haproxy_bypasses" && h.Header == "Content-Length" {
+				deferred = append(deferred, h)
+				continue
+			}
+		}
+		writeHeader(h.Header, h.Value)
+	}
+
+	if !hasHost {
+		writeHeader("Host", bypassPayload.Host)
+	}
+	if len(bypassPayload.Body) > 0 && !hasContentLength {
+		writeHeader("Content-Length", strconv.Itoa(len(bypassPayload.Body)))
+	}
+	for _, h := range deferred {
+		writeHeader(h.Header, h.Value)
+	}
+}
+
 // NormalizeHeaderKey canonicalizes a header key string.
 // Example: "x-abc-test" becomes "X-Abc-Test"
 // func NormalizeHeaderKey(key string) string {
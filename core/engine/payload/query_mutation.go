@@ -0,0 +1,140 @@
+package payload
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Query string mutations (-mutate-query), used by case_substitution and char_encode
+on top of their path payloads, for ACLs that also match on query parameters.
+
+The query is split on '&' into parameters and each parameter on the first '='
+into name and value. Only one parameter is mutated per variant, the others and
+the '&' / '=' separators are kept as-is so the query still parses the same.
+*/
+
+// queryParam is a parameter of a query string, hasValue is false for "name" without '='
+type queryParam struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+func splitQuery(query string) []queryParam {
+	var params []queryParam
+	for _, part := range strings.Split(query, "&") {
+		name, value, hasValue := strings.Cut(part, "=")
+		params = append(params, queryParam{name: name, value: value, hasValue: hasValue})
+	}
+	return params
+}
+
+func joinQuery(params []queryParam) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = p.name
+		if p.hasValue {
+			parts[i] += "=" + p.value
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// withParam returns the query rebuilt with params[i] replaced by p
+func withParam(params []queryParam, i int, p queryParam) string {
+	mutated := append([]queryParam(nil), params...)
+	mutated[i] = p
+	return joinQuery(mutated)
+}
+
+// invertCaseASCII inverts the case of every ASCII letter of s
+func invertCaseASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if isLetterASCII(c) {
+			b[i] = c ^ 0x20
+		}
+	}
+	return string(b)
+}
+
+/*
+queryCaseVariants returns the query strings (without '?') obtained by:
+1. Uppercasing the whole query.
+2. Uppercasing each parameter name, and inverting its case.
+3. Uppercasing each parameter value.
+
+The original query is never returned.
+*/
+func queryCaseVariants(query string) []string {
+	unique := make(map[string]struct{})
+	unique[strings.ToUpper(query)] = struct{}{}
+
+	params := splitQuery(query)
+	for i, p := range params {
+		for _, name := range []string{strings.ToUpper(p.name), invertCaseASCII(p.name)} {
+			mutated := p
+			mutated.name = name
+			unique[withParam(params, i, mutated)] = struct{}{}
+		}
+		if p.hasValue {
+			mutated := p
+			mutated.value = strings.ToUpper(p.value)
+			unique[withParam(params, i, mutated)] = struct{}{}
+		}
+	}
+
+	delete(unique, query)
+	variants := make([]string, 0, len(unique))
+	for q := range unique {
+		variants = append(variants, q)
+	}
+	return variants
+}
+
+/*
+queryEncodeVariants returns the single and double URL-encoded query strings (without '?') obtained by:
+1. Encoding each letter of each parameter name, one at a time.
+2. Encoding the whole value of each parameter.
+*/
+func queryEncodeVariants(query string) (single []string, double []string) {
+	singleSet := make(map[string]struct{})
+	doubleSet := make(map[string]struct{})
+
+	params := splitQuery(query)
+	for i, p := range params {
+		for j := 0; j < len(p.name); j++ {
+			if !isLetterASCII(p.name[j]) {
+				continue
+			}
+			encodedHex := fmt.Sprintf("%02x", p.name[j])
+
+			mutated := p
+			mutated.name = p.name[:j] + "%" + encodedHex + p.name[j+1:]
+			singleSet[withParam(params, i, mutated)] = struct{}{}
+
+			mutated.name = p.name[:j] + "%25" + encodedHex + p.name[j+1:]
+			doubleSet[withParam(params, i, mutated)] = struct{}{}
+		}
+
+		if p.hasValue && p.value != "" {
+			encodedValue := URLEncodeAll(p.value)
+
+			mutated := p
+			mutated.value = encodedValue
+			singleSet[withParam(params, i, mutated)] = struct{}{}
+
+			mutated.value = strings.ReplaceAll(encodedValue, "%", "%25")
+			doubleSet[withParam(params, i, mutated)] = struct{}{}
+		}
+	}
+
+	for q := range singleSet {
+		single = append(single, q)
+	}
+	for q := range doubleSet {
+		double = append(double, q)
+	}
+	return single, double
+}
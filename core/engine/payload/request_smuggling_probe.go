@@ -0,0 +1,125 @@
+package payload
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// smugglingProbes are the CL.TE and TE.CL desync probes of request_smuggling_probe
+var smugglingProbes = []struct {
+	name          string
+	body          string
+	contentLength int
+}{
+	{name: "CL.TE", body: "1\r\nZ\r\nQ", contentLength: 4},
+	{name: "TE.CL", body: "0\r\n\r\nX", contentLength: 6},
+}
+
+/*
+GenerateRequestSmugglingProbePayloads generates minimal CL.TE and TE.CL desync detection
+probes: requests with conflicting Content-Length and Transfer-Encoding headers, sent to the
+original URL. Opt-in only (-allow-smuggling), it is never part of -m all on its own.
+
+The probes are the timing-based detection variants, they don't smuggle a prefixed request
+and can't poison the responses of other users:
+
+1. CL.TE: the front-end honors Content-Length and forwards a truncated chunked body, a
+back-end honoring Transfer-Encoding waits for the next chunk (timeout).
+  - Content-Length: 4
+  - Transfer-Encoding: chunked
+  - body "1\r\nZ\r\nQ"
+
+2. TE.CL: the front-end honors Transfer-Encoding and forwards the terminating chunk only,
+a back-end honoring Content-Length waits for the missing bytes (timeout).
+  - Content-Length: 6
+  - Transfer-Encoding: chunked
+  - body "0\r\n\r\nX"
+
+Each probe is sent with these Transfer-Encoding obfuscations, so that only one of the two
+servers recognizes it:
+  - Transfer-Encoding: chunked
+  - Transfer-Encoding: xchunked
+  - Transfer-Encoding: chunked, identity
+  - "Transfer-Encoding: \tchunked" (tab before the value)
+  - "Transfer-Encoding : chunked" (space before the colon)
+  - "Transfer-Encoding: chunked" followed by "Transfer-encoding: x"
+
+All headers are sent verbatim with Connection: close, so no connection is reused after a probe.
+*/
+func (pg *PayloadGenerator) GenerateRequestSmugglingProbePayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	pathAndQuery := parsedURL.Path
+	if pathAndQuery == "" {
+		pathAndQuery = "/"
+	}
+	if parsedURL.Query != "" {
+		pathAndQuery += "?" + parsedURL.Query
+	}
+
+	teVariants := [][]Headers{
+		{{Header: "Transfer-Encoding", Value: "chunked"}},
+		{{Header: "Transfer-Encoding", Value: "xchunked"}},
+		{{Header: "Transfer-Encoding", Value: "chunked, identity"}},
+		{{Header: "Transfer-Encoding", Value: "\tchunked"}},
+		{{Header: "Transfer-Encoding ", Value: "chunked"}},
+		{{Header: "Transfer-Encoding", Value: "chunked"}, {Header: "Transfer-encoding", Value: "x"}},
+	}
+
+	for _, probe := range smugglingProbes {
+		for _, te := range teVariants {
+			headers := []Headers{{Header: "Content-Length", Value: strconv.Itoa(probe.contentLength)}}
+			headers = append(headers, te...)
+			headers = append(headers, Headers{Header: "Connection", Value: "close"})
+
+			job := BypassPayload{
+				OriginalURL:  targetURL,
+				Method:       "POST",
+				Scheme:       parsedURL.Scheme,
+				Host:         parsedURL.Host,
+				RawURI:       pathAndQuery,
+				Headers:      headers,
+				Body:         probe.body,
+				BypassModule: bypassModule,
+			}
+			job.PayloadToken = GeneratePayloadToken(job)
+			allJobs = append(allJobs, job)
+		}
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
+
+// SmugglingProbeVariant names the probe of a request_smuggling_probe payload, CL.TE or TE.CL
+// followed by its Transfer-Encoding headers as sent (e.g. `TE.CL "Transfer-Encoding: \tchunked"`).
+// Returns an empty string for any other payload.
+func SmugglingProbeVariant(bypassPayload BypassPayload) string {
+	name := ""
+	for _, probe := range smugglingProbes {
+		if bypassPayload.Body == probe.body {
+			name = probe.name
+			break
+		}
+	}
+	if name == "" {
+		return ""
+	}
+
+	var te []string
+	for _, h := range bypassPayload.Headers {
+		if strings.EqualFold(strings.TrimSpace(h.Header), "Transfer-Encoding") {
+			te = append(te, h.Header+": "+h.Value)
+		}
+	}
+	return name + " " + strconv.Quote(strings.Join(te, ", "))
+}
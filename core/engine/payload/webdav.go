@@ -0,0 +1,117 @@
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// WebDAVRequest is a WebDAV method with its XML body, read from webdav_requests.json
+type WebDAVRequest struct {
+	Method      string   `json:"method"`
+	ContentType string   `json:"content_type"`
+	Depths      []string `json:"depths"`
+	Body        string   `json:"body"` // {{path}} is replaced with the (XML escaped) target path
+}
+
+// ReadWebDAVRequests reads the webdav_requests.json file
+func ReadWebDAVRequests() ([]WebDAVRequest, error) {
+	content, err := ReadPayloadsFromJSONFile("webdav_requests.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webdav_requests.json: %w", err)
+	}
+
+	var requests []WebDAVRequest
+	if err := json.Unmarshal(content, &requests); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webdav_requests.json: %w", err)
+	}
+	return requests, nil
+}
+
+/*
+GenerateWebDAVPayloads generates WebDAV requests (PROPFIND, SEARCH) with a minimal valid XML
+body, read from webdav_requests.json. Some servers and proxies only filter the common methods,
+or hand WebDAV methods to a different handler, and answer them where GET is 403.
+
+Unlike http_methods, which sends these methods with Content-Length: 0, every payload carries
+its XML body and Content-Type. For each request in the file, it creates:
+ 1. One payload per Depth value listed for it (e.g. Depth: 0, Depth: 1)
+ 2. One payload with the first Depth value and a Destination header set to the target URL
+
+The original URL's scheme, host, path and query string are preserved in all generated payloads.
+*/
+func (pg *PayloadGenerator) GenerateWebDAVPayloads(targetURL string, bypassModule string) []BypassPayload {
+	var allJobs []BypassPayload
+
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to parse URL: %s", targetURL)
+		return allJobs
+	}
+
+	requests, err := ReadWebDAVRequests()
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to read WebDAV requests: %v", err)
+		return allJobs
+	}
+
+	path := parsedURL.Path
+	if path == "" {
+		path = "/"
+	}
+	rawURI := path
+	if parsedURL.Query != "" {
+		rawURI += "?" + parsedURL.Query
+	}
+	pathReplacer := strings.NewReplacer("{{path}}", html.EscapeString(path))
+
+	baseJob := BypassPayload{
+		OriginalURL:  targetURL,
+		Scheme:       parsedURL.Scheme,
+		Host:         parsedURL.Host,
+		RawURI:       rawURI,
+		BypassModule: bypassModule,
+	}
+
+	addJob := func(req WebDAVRequest, body string, headers ...Headers) {
+		job := baseJob
+		job.Method = req.Method
+		job.Body = body
+		job.Headers = append([]Headers{
+			{Header: "Content-Type", Value: req.ContentType},
+			{Header: "Content-Length", Value: strconv.Itoa(len(body))},
+		}, headers...)
+		job.PayloadToken = GeneratePayloadToken(job)
+		allJobs = append(allJobs, job)
+	}
+
+	for _, req := range requests {
+		if req.Method == "" || req.Body == "" {
+			continue
+		}
+		body := pathReplacer.Replace(req.Body)
+
+		depths := req.Depths
+		if len(depths) == 0 {
+			depths = []string{"0"}
+		}
+
+		// 1. One payload per Depth
+		for _, depth := range depths {
+			addJob(req, body, Headers{Header: "Depth", Value: depth})
+		}
+
+		// 2. Destination header pointing at the target itself
+		addJob(req, body,
+			Headers{Header: "Depth", Value: depths[0]},
+			Headers{Header: "Destination", Value: parsedURL.Scheme + "://" + parsedURL.Host + rawURI})
+	}
+
+	GB403Logger.Debug().BypassModule(bypassModule).Msgf("Generated %d payloads for %s", len(allJobs), targetURL)
+	return allJobs
+}
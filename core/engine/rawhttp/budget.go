@@ -0,0 +1,65 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrRequestBudgetExhausted is returned for jobs not sent because the request budget is spent
+var ErrRequestBudgetExhausted = errors.New("request budget exhausted")
+
+// RequestBudget caps the number of requests sent to a target, shared by the worker pools of all its modules
+type RequestBudget struct {
+	max       int64
+	used      atomic.Int64
+	exhausted atomic.Bool
+}
+
+// NewRequestBudget creates a RequestBudget allowing up to max requests.
+// Returns nil if max <= 0 (no budget).
+func NewRequestBudget(max int) *RequestBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &RequestBudget{max: int64(max)}
+}
+
+// Take reserves one request, returns false once the budget is spent.
+// A nil budget never runs out.
+func (b *RequestBudget) Take() bool {
+	if b == nil {
+		return true
+	}
+	if b.used.Add(1) > b.max {
+		b.used.Add(-1)
+		b.exhausted.Store(true)
+		return false
+	}
+	return true
+}
+
+// Used returns the number of requests taken so far
+func (b *RequestBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.used.Load()
+}
+
+// Exhausted reports whether a request was refused for lack of budget
+func (b *RequestBudget) Exhausted() bool {
+	return b != nil && b.exhausted.Load()
+}
+
+// Max returns the max number of requests of the budget
+func (b *RequestBudget) Max() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.max)
+}
@@ -9,6 +9,8 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"math/rand"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -27,14 +29,29 @@ var (
 var (
 	ErrReqFailedMaxRetries          = errors.New("request failed after all retry attempts")
 	ErrReqFailedMaxConsecutiveFails = errors.New("target reached max consecutive fails")
+	ErrReqTimedOut                  = errors.New("no response before the timeout")
 )
 
 // Constants for buffer sizes used throughout the package
 const (
-	DefaultHeadersBuffSize = 8192
-	DefaultBufferPadding   = 1024
+	DefaultHeadersBuffSize         = 8192
+	DefaultBufferPadding           = 1024
+	DefaultResponseBodyPreviewSize = 1024
+	DefaultReadWriteBufferSize     = DefaultHeadersBuffSize + DefaultResponseBodyPreviewSize + 2*DefaultBufferPadding
 )
 
+/*
+Response sizing, every value set by the user is honored as is, 0 picks the default:
+  - ResponseBodyPreviewSize: (decompressed) body bytes kept per response, used for titles,
+    matching and the results DB. Default DefaultResponseBodyPreviewSize.
+  - MaxResponseBodySize: body bytes fasthttp buffers in memory. Larger bodies are streamed
+    (StreamResponseBody) so the preview is not bound by it, but it caps the compressed bytes
+    read before decoding. Default DefaultHeadersBuffSize + preview + DefaultBufferPadding.
+  - ReadBufferSize/WriteBufferSize: per connection buffers, they only have to fit the response
+    and request headers and don't depend on the body sizes. Default DefaultReadWriteBufferSize,
+    a warning is logged when the read buffer is smaller than DefaultHeadersBuffSize.
+*/
+
 // ParsedHeader represents a pre-processed custom header
 type ParsedHeader struct {
 	Name  string
@@ -47,10 +64,12 @@ type HTTPClientOptions struct {
 	Timeout                  time.Duration // ScannerCliOpts
 	DialTimeout              time.Duration // Custom Dial Timeout
 	MaxConnsPerHost          int           // fasthttp core
+	HostConcurrency          int           // ScannerCliOpts, max in-flight requests per host (0 = no per-host limit)
 	MaxIdleConnDuration      time.Duration // fasthttp core
 	MaxConnWaitTimeout       time.Duration // fasthttp core
 	NoDefaultUserAgent       bool          // fasthttp core
 	ProxyURL                 string        // ScannerCliOpts
+	ProxyURLs                []string      // ScannerCliOpts, proxies rotated per connection (-proxy-file), takes precedence over ProxyURL
 	MaxResponseBodySize      int           // fasthttp core
 	ReadBufferSize           int           // fasthttp core
 	WriteBufferSize          int           // fasthttp core
@@ -59,40 +78,53 @@ type HTTPClientOptions struct {
 	StreamResponseBody       bool          // fasthttp core
 	MatchStatusCodes         []int         // ScannerCliOpts
 	DisableKeepAlive         bool
-	EnableHTTP2              bool
+	DisableTLSResumption     bool   // Force a full TLS handshake on every connection
+	ClientCertFile           string // PEM client certificate presented during the TLS handshake (mTLS)
+	ClientKeyFile            string // PEM private key of ClientCertFile
+	TLSFingerprint           string // Mimic a browser TLS ClientHello via uTLS (see TLSFingerprints), empty = Go default
+	SNI                      string // TLS ServerName sent instead of the target host (domain fronting), empty = target host
+	EnableHTTP2              bool   // Send https requests over HTTP/2 (h2 via ALPN), falls back to HTTP/1.1 per host
 	Dialer                   fasthttp.DialFunc
 	RequestDelay             time.Duration // ScannerCliOpts
+	RequestDelayJitter       int           // ScannerCliOpts, RequestDelay randomized by up to ± this percentage per request (0-100)
+	RequestRate              int           // ScannerCliOpts, max requests per second across the worker pool (0 = no limit)
 	RetryDelay               time.Duration // ScannerCliOpts
 	MaxConsecutiveFailedReqs int           // ScannerCliOpts
 	AutoThrottle             bool          // ScannerCliOpts
+	MaxRetryAfter            time.Duration // ScannerCliOpts, cap for Retry-After delays honored by the throttler
+	RetryStatusCodes         []int         // ScannerCliOpts, response status codes retried as transient (e.g. 429, 503)
 	DisablePathNormalizing   bool
 	CustomHTTPHeaders        []string        // Raw header strings from CLI
+	AuthHeaders              []string        // Credentials sent with every request (-auth-header, -auth-file), handled like -H headers
 	ParsedHeaders            []ParsedHeader  // Pre-processed headers for fast access
 	HeaderOverrides          map[string]bool // Track which headers are overridden by CLI (lowercase keys)
+	UserAgents               []string        // ScannerCliOpts, User-Agents rotated per request, empty = CustomUserAgent
+	userAgentIndex           atomic.Uint64   // Next UserAgents entry, shared by all workers
+	PreserveHeaderOrder      bool            // ScannerCliOpts, write payload headers in slice order, -H headers in the slot they override
+	AcceptEncoding           string          // ScannerCliOpts, Accept-Encoding sent with every request, empty = none
+	HostLimiter              *HostLimiter    // Per-host cap shared with other worker pools (set by the scanner), replaces HostConcurrency
+	RateLimiter              *RateLimiter    // Rate limit shared with other worker pools (-url-concurrency), replaces RequestRate
+	HostHealth               *HostHealth     // Failures and response times per host, shared with other worker pools (-max-host-fails)
+	DumpWire                 string          // Log the raw request bytes and response head of each request (WireDumpAll) or keep them for matched results (WireDumpMatched)
+	ReportTimeouts           bool            // Requests without a response before Timeout are not retried and fail with ErrReqTimedOut (request_smuggling_probe)
 }
 
 // HTTPClient represents a reusable HTTP client
 type HTTPClient struct {
 	client                *fasthttp.Client
+	h2                    *http2Client // nil unless EnableHTTP2
 	options               *HTTPClientOptions
 	retryConfig           *RetryConfig
 	throttler             *Throttler
 	mu                    sync.RWMutex
 	lastResponseTime      atomic.Int64
 	consecutiveFailedReqs atomic.Int32
+	randSource            *rand.Rand // Request delay jitter
+	randMu                sync.Mutex
 }
 
 // DefaultHTTPClientOptions returns the default HTTP client options
 func DefaultHTTPClientOptions() *HTTPClientOptions {
-	// Default response preview size (if not specified by user)
-	defaultPreviewSize := 1024
-
-	// Calculate default max body size based on headers + preview + margin
-	maxBodySize := DefaultHeadersBuffSize + defaultPreviewSize + DefaultBufferPadding
-
-	// Calculate RW buffer size with additional margin
-	rwBufferSize := maxBodySize + DefaultBufferPadding
-
 	return &HTTPClientOptions{
 		BypassModule:             "",
 		Timeout:                  20000 * time.Millisecond,
@@ -101,11 +133,11 @@ func DefaultHTTPClientOptions() *HTTPClientOptions {
 		MaxIdleConnDuration:      1 * time.Minute,
 		MaxConnWaitTimeout:       1 * time.Second,
 		NoDefaultUserAgent:       true,
-		MaxResponseBodySize:      maxBodySize,
-		ReadBufferSize:           rwBufferSize,
-		WriteBufferSize:          rwBufferSize,
+		MaxResponseBodySize:      DefaultHeadersBuffSize + DefaultResponseBodyPreviewSize + DefaultBufferPadding,
+		ReadBufferSize:           DefaultReadWriteBufferSize,
+		WriteBufferSize:          DefaultReadWriteBufferSize,
 		StreamResponseBody:       true,
-		ResponseBodyPreviewSize:  defaultPreviewSize,
+		ResponseBodyPreviewSize:  DefaultResponseBodyPreviewSize,
 		MaxRetries:               2,
 		RetryDelay:               500 * time.Millisecond,
 		RequestDelay:             0,
@@ -117,6 +149,27 @@ func DefaultHTTPClientOptions() *HTTPClientOptions {
 	}
 }
 
+// applyResponseSizeDefaults sets the defaults of unset response sizes, explicit values are kept
+func (opts *HTTPClientOptions) applyResponseSizeDefaults() {
+	if opts.ResponseBodyPreviewSize <= 0 {
+		opts.ResponseBodyPreviewSize = DefaultResponseBodyPreviewSize
+	}
+	if opts.MaxResponseBodySize <= 0 {
+		opts.MaxResponseBodySize = DefaultHeadersBuffSize + opts.ResponseBodyPreviewSize + DefaultBufferPadding
+	}
+	if opts.ReadBufferSize <= 0 {
+		opts.ReadBufferSize = DefaultReadWriteBufferSize
+	}
+	if opts.WriteBufferSize <= 0 {
+		opts.WriteBufferSize = DefaultReadWriteBufferSize
+	}
+
+	if opts.ReadBufferSize < DefaultHeadersBuffSize {
+		GB403Logger.Warning().Msgf("Read buffer of %d bytes can't fit response headers up to %d bytes, larger headers fail with \"small read buffer\"\n",
+			opts.ReadBufferSize, DefaultHeadersBuffSize)
+	}
+}
+
 // NewHTTPClient creates a new HTTP client instance
 func NewHTTPClient(opts *HTTPClientOptions) *HTTPClient {
 	if opts == nil {
@@ -126,30 +179,15 @@ func NewHTTPClient(opts *HTTPClientOptions) *HTTPClient {
 	// Preprocess custom headers for fast access in hot path
 	opts.PreprocessCustomHeaders()
 
-	// Calculate appropriate sizes based on preview size
-	previewSize := opts.ResponseBodyPreviewSize
-	if previewSize <= 0 {
-		previewSize = 1024 // Default if not specified
-	}
-
-	// Ensure MaxResponseBodySize is large enough for headers + preview
-	requiredBodySize := DefaultHeadersBuffSize + previewSize + DefaultBufferPadding
-	if opts.MaxResponseBodySize < requiredBodySize {
-		opts.MaxResponseBodySize = requiredBodySize
-	}
+	// Fill in unset response sizes, see the sizing notes above
+	opts.applyResponseSizeDefaults()
 
-	// Ensure read/write buffers are sized appropriately
-	requiredBufferSize := opts.MaxResponseBodySize + DefaultBufferPadding
-	if opts.ReadBufferSize <= 0 || opts.ReadBufferSize < requiredBufferSize {
-		opts.ReadBufferSize = requiredBufferSize
-	}
-	if opts.WriteBufferSize <= 0 || opts.WriteBufferSize < requiredBufferSize {
-		opts.WriteBufferSize = requiredBufferSize
-	}
-
-	// Continue with existing initialization...
 	if opts.Dialer == nil {
-		opts.Dialer = CreateHTTPClientDialer(opts.DialTimeout, opts.ProxyURL)
+		if len(opts.ProxyURLs) > 0 {
+			opts.Dialer = GetProxyRotator(opts.ProxyURLs, opts.DialTimeout).Dial
+		} else {
+			opts.Dialer = CreateHTTPClientDialer(opts.DialTimeout, opts.ProxyURL)
+		}
 	}
 
 	retryConfig := DefaultRetryConfig()
@@ -158,13 +196,18 @@ func NewHTTPClient(opts *HTTPClientOptions) *HTTPClient {
 
 	var throttler *Throttler
 	if opts.AutoThrottle {
-		throttler = NewThrottler(DefaultThrottleConfig())
+		throttleConfig := DefaultThrottleConfig()
+		if opts.MaxRetryAfter > 0 {
+			throttleConfig.MaxRetryAfter = opts.MaxRetryAfter
+		}
+		throttler = NewThrottler(throttleConfig)
 	}
 
 	c := &HTTPClient{
 		options:     opts,
 		retryConfig: retryConfig,
 		throttler:   throttler,
+		randSource:  rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 
 	// reset failed consecutive requests
@@ -190,10 +233,37 @@ func NewHTTPClient(opts *HTTPClientOptions) *HTTPClient {
 			MaxVersion:         tls.VersionTLS13,
 			Renegotiation:      tls.RenegotiateOnceAsClient,
 			ClientSessionCache: tls.NewLRUClientSessionCache(1024),
+			ServerName:         opts.SNI, // empty = host of the dial address, set per connection by fasthttp
 		},
 	}
 
+	// Drop the session cache and tickets so every connection does a full handshake
+	if opts.DisableTLSResumption {
+		client.TLSConfig.ClientSessionCache = nil
+		client.TLSConfig.SessionTicketsDisabled = true
+	}
+
+	// Present a client certificate for mTLS, the key pair is validated by the cli beforehand
+	if opts.ClientCertFile != "" && opts.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			GB403Logger.Error().Msgf("Failed to load client certificate: %v\n", err)
+		} else {
+			client.TLSConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	// https connections go through a uTLS handshake on top of the regular (direct or proxy) dialer
+	if opts.TLSFingerprint != "" {
+		client.DialTLS = c.createTLSFingerprintDialer(opts.Dialer)
+	}
+
 	c.client = client
+
+	// HTTP/2 uses the Go TLS handshake, a TLS fingerprint takes precedence (HTTP/1.1 only)
+	if opts.EnableHTTP2 && opts.TLSFingerprint == "" {
+		c.h2 = newHTTP2Client(c)
+	}
 	return c
 }
 
@@ -208,6 +278,9 @@ func NewDefaultHTTPClient(httpClientOpts *HTTPClientOptions) *HTTPClient {
 		if httpClientOpts.DisableKeepAlive {
 			opts.DisableKeepAlive = true
 		}
+		if httpClientOpts.DisableTLSResumption {
+			opts.DisableTLSResumption = true
+		}
 		if httpClientOpts.NoDefaultUserAgent {
 			opts.NoDefaultUserAgent = true
 		}
@@ -240,6 +313,21 @@ func NewDefaultHTTPClient(httpClientOpts *HTTPClientOptions) *HTTPClient {
 		if httpClientOpts.ProxyURL != "" {
 			opts.ProxyURL = httpClientOpts.ProxyURL
 		}
+		if len(httpClientOpts.ProxyURLs) > 0 {
+			opts.ProxyURLs = httpClientOpts.ProxyURLs
+		}
+		if httpClientOpts.ClientCertFile != "" {
+			opts.ClientCertFile = httpClientOpts.ClientCertFile
+		}
+		if httpClientOpts.ClientKeyFile != "" {
+			opts.ClientKeyFile = httpClientOpts.ClientKeyFile
+		}
+		if httpClientOpts.TLSFingerprint != "" {
+			opts.TLSFingerprint = httpClientOpts.TLSFingerprint
+		}
+		if httpClientOpts.SNI != "" {
+			opts.SNI = httpClientOpts.SNI
+		}
 		if httpClientOpts.BypassModule != "" {
 			opts.BypassModule = httpClientOpts.BypassModule
 		}
@@ -252,15 +340,36 @@ func NewDefaultHTTPClient(httpClientOpts *HTTPClientOptions) *HTTPClient {
 		if httpClientOpts.RequestDelay > 0 {
 			opts.RequestDelay = httpClientOpts.RequestDelay
 		}
+		if httpClientOpts.RequestDelayJitter > 0 {
+			opts.RequestDelayJitter = httpClientOpts.RequestDelayJitter
+		}
 		if httpClientOpts.RetryDelay > 0 {
 			opts.RetryDelay = httpClientOpts.RetryDelay
 		}
 		if httpClientOpts.MaxConsecutiveFailedReqs > 0 {
 			opts.MaxConsecutiveFailedReqs = httpClientOpts.MaxConsecutiveFailedReqs
 		}
+		if httpClientOpts.MaxRetryAfter > 0 {
+			opts.MaxRetryAfter = httpClientOpts.MaxRetryAfter
+		}
+		if len(httpClientOpts.RetryStatusCodes) > 0 {
+			opts.RetryStatusCodes = httpClientOpts.RetryStatusCodes
+		}
 		if len(httpClientOpts.CustomHTTPHeaders) > 0 {
 			opts.CustomHTTPHeaders = httpClientOpts.CustomHTTPHeaders
 		}
+		if len(httpClientOpts.AuthHeaders) > 0 {
+			opts.AuthHeaders = httpClientOpts.AuthHeaders
+		}
+		if len(httpClientOpts.UserAgents) > 0 {
+			opts.UserAgents = httpClientOpts.UserAgents
+		}
+		if httpClientOpts.PreserveHeaderOrder {
+			opts.PreserveHeaderOrder = true
+		}
+		if httpClientOpts.AcceptEncoding != "" {
+			opts.AcceptEncoding = httpClientOpts.AcceptEncoding
+		}
 
 		// Handle ResponseBodyPreviewSize and associated buffer sizes
 		if httpClientOpts.ResponseBodyPreviewSize > 0 {
@@ -299,10 +408,46 @@ func (c *HTTPClient) SetDialer(dialer fasthttp.DialFunc) *HTTPClient {
 
 	if c.client != nil {
 		c.client.Dial = dialer
+		if c.options.TLSFingerprint != "" {
+			c.client.DialTLS = c.createTLSFingerprintDialer(dialer)
+		}
 	}
 	return c
 }
 
+// dialer returns the current (direct or proxy) dialer of the client
+func (c *HTTPClient) dialer() fasthttp.DialFunc {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.client.Dial
+}
+
+// do sends req over HTTP/2 if enabled and negotiated by the host, over HTTP/1.1 otherwise
+func (c *HTTPClient) do(req *fasthttp.Request, resp *fasthttp.Response, bypassPayload payload.BypassPayload) error {
+	if c.h2 != nil && c.h2.supports(bypassPayload) {
+		err := c.h2.Do(req, resp, bypassPayload)
+		if !errors.Is(err, ErrHTTP2NotNegotiated) {
+			return err
+		}
+		GB403Logger.Verbose().Msgf("%s did not negotiate HTTP/2, falling back to HTTP/1.1\n", bypassPayload.Host)
+		resp.Reset()
+	}
+	return c.client.Do(req, resp)
+}
+
+// createTLSFingerprintDialer wraps dialer with the uTLS handshake of options.TLSFingerprint,
+// returns nil (Go TLS handshake) if the fingerprint can't be used
+func (c *HTTPClient) createTLSFingerprintDialer(dialer fasthttp.DialFunc) fasthttp.DialFunc {
+	dialTLS, err := CreateTLSFingerprintDialer(dialer, c.options.TLSFingerprint, c.options.Timeout,
+		c.options.ClientCertFile, c.options.ClientKeyFile, c.options.SNI)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to set up TLS fingerprint, using the default Go TLS handshake: %v\n", err)
+		return nil
+	}
+	return dialTLS
+}
+
 func (c *HTTPClient) handleRetries(req *fasthttp.Request, resp *fasthttp.Response, bypassPayload payload.BypassPayload, retryAction RetryAction) (int64, error) {
 	c.retryConfig.ResetPerReqAttempts()
 
@@ -327,7 +472,7 @@ func (c *HTTPClient) handleRetries(req *fasthttp.Request, resp *fasthttp.Respons
 			reqCopy.Header.Del("Connection")
 			reqCopy.SetConnectionClose()
 			start = time.Now()
-			err = c.client.Do(reqCopy, resp)
+			err = c.do(reqCopy, resp, bypassPayload)
 
 		case RetryWithoutResponseStreaming:
 			noStreamOpts := c.GetHTTPClientOptions()
@@ -339,7 +484,7 @@ func (c *HTTPClient) handleRetries(req *fasthttp.Request, resp *fasthttp.Respons
 
 		default:
 			start = time.Now()
-			err = c.client.Do(reqCopy, resp)
+			err = c.do(reqCopy, resp, bypassPayload)
 		}
 
 		requestTime := time.Since(start)
@@ -365,6 +510,7 @@ func (c *HTTPClient) handleRetries(req *fasthttp.Request, resp *fasthttp.Respons
 		// Handle successful response
 		if c.throttler.IsThrottableRespCode(resp.StatusCode()) {
 			c.throttler.EnableThrottler()
+			c.throttler.SetRetryAfter(parseRetryAfter(resp))
 		}
 
 		return requestTime.Milliseconds(), nil
@@ -392,10 +538,32 @@ If you see this error, then either fix the server by returning
 or add 'Connection: close' request header before sending requests
 to broken server.
 */
+// RequestDelay returns the delay before the next request: RequestDelay, randomized within
+// ± RequestDelayJitter percent so the requests are not evenly spaced
+func (c *HTTPClient) RequestDelay() time.Duration {
+	opts := c.GetHTTPClientOptions()
+	delay := opts.RequestDelay
+	if delay <= 0 || opts.RequestDelayJitter <= 0 {
+		return delay
+	}
+
+	jitterPercent := min(opts.RequestDelayJitter, 100)
+	maxJitter := int64(delay) * int64(jitterPercent) / 100
+	if maxJitter <= 0 {
+		return delay
+	}
+
+	c.randMu.Lock()
+	jitter := c.randSource.Int63n(2*maxJitter+1) - maxJitter
+	c.randMu.Unlock()
+
+	return delay + time.Duration(jitter)
+}
+
 func (c *HTTPClient) DoRequest(req *fasthttp.Request, resp *fasthttp.Response, bypassPayload payload.BypassPayload) (int64, error) {
 
-	if c.GetHTTPClientOptions().RequestDelay > 0 {
-		time.Sleep(c.GetHTTPClientOptions().RequestDelay)
+	if delay := c.RequestDelay(); delay > 0 {
+		time.Sleep(delay)
 	}
 	// apply throttler if enabled
 	if c.throttler.IsThrottlerActive() {
@@ -404,7 +572,7 @@ func (c *HTTPClient) DoRequest(req *fasthttp.Request, resp *fasthttp.Response, b
 
 	// Initial request
 	start := time.Now()
-	err := c.client.Do(req, resp)
+	err := c.do(req, resp, bypassPayload)
 	requestTime := time.Since(start)
 
 	// Handle initial request result
@@ -421,6 +589,11 @@ func (c *HTTPClient) DoRequest(req *fasthttp.Request, resp *fasthttp.Response, b
 			return requestTime.Milliseconds(), nil
 		}
 
+		// A desync probe left waiting by the server is the signal, not a transient failure
+		if c.options.ReportTimeouts && IsResponseTimeoutError(err) {
+			return requestTime.Milliseconds(), fmt.Errorf("%w: %v", ErrReqTimedOut, err)
+		}
+
 		// Check if we should retry
 		retryDecision := IsRetryableError(err)
 		if !retryDecision.ShouldRetry {
@@ -449,6 +622,47 @@ func (c *HTTPClient) DoRequest(req *fasthttp.Request, resp *fasthttp.Response, b
 	// Handle successful response
 	if c.throttler.IsThrottableRespCode(resp.StatusCode()) {
 		c.throttler.EnableThrottler()
+		c.throttler.SetRetryAfter(parseRetryAfter(resp))
+	}
+
+	// Transient status codes (-retry-status) are retried, the last response is kept
+	if slices.Contains(c.options.RetryStatusCodes, resp.StatusCode()) {
+		return c.handleStatusRetries(req, resp, bypassPayload, requestTime)
+	}
+
+	return requestTime.Milliseconds(), nil
+}
+
+// handleStatusRetries resends a request answered with a RetryStatusCodes status, up to MaxRetries times.
+// Each retry waits for RetryDelay, or for the Retry-After of the response if longer (capped at MaxRetryAfter).
+func (c *HTTPClient) handleStatusRetries(req *fasthttp.Request, resp *fasthttp.Response, bypassPayload payload.BypassPayload, requestTime time.Duration) (int64, error) {
+	maxRetryAfter := c.options.MaxRetryAfter
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = DefaultThrottleConfig().MaxRetryAfter
+	}
+
+	for attempt := 1; attempt <= c.retryConfig.MaxRetries; attempt++ {
+		statusCode := resp.StatusCode()
+		delay := max(c.retryConfig.RetryDelay, min(parseRetryAfter(resp), maxRetryAfter))
+		GB403Logger.Debug().Msgf("Status %d for %s, retry %d/%d in %s\n",
+			statusCode, bypassPayload.PayloadToken, attempt, c.retryConfig.MaxRetries, delay)
+		time.Sleep(delay)
+
+		reqCopy := fasthttp.AcquireRequest()
+		ReqCopyToWithSettings(req, reqCopy)
+
+		resp.Reset()
+		start := time.Now()
+		err := c.do(reqCopy, resp, bypassPayload)
+		requestTime = time.Since(start)
+		fasthttp.ReleaseRequest(reqCopy)
+
+		if err != nil {
+			return requestTime.Milliseconds(), fmt.Errorf("retry %d after status %d failed: %w", attempt, statusCode, err)
+		}
+		if !slices.Contains(c.options.RetryStatusCodes, resp.StatusCode()) {
+			break
+		}
 	}
 
 	return requestTime.Milliseconds(), nil
@@ -500,6 +714,9 @@ func (c *HTTPClient) EnableStreamResponseBody() {
 // Close releases all idle connections
 func (c *HTTPClient) Close() {
 	c.client.CloseIdleConnections()
+	if c.h2 != nil {
+		c.h2.CloseIdleConnections()
+	}
 	c.throttler.ResetThrottler()
 }
 
@@ -552,16 +769,26 @@ func (c *HTTPClient) Close() {
 // 	return requestTime.Milliseconds(), nil
 // }
 
-// PreprocessCustomHeaders parses raw CLI header strings into optimized format
+// NextUserAgent returns the User-Agent of the next request, UserAgents are used round-robin
+func (opts *HTTPClientOptions) NextUserAgent() string {
+	if len(opts.UserAgents) == 0 {
+		return string(CustomUserAgent)
+	}
+	i := opts.userAgentIndex.Add(1) - 1
+	return opts.UserAgents[i%uint64(len(opts.UserAgents))]
+}
+
+// PreprocessCustomHeaders parses raw CLI header strings into optimized format.
+// The auth headers follow the -H headers, a -H header wins over an auth header of the same name
 func (opts *HTTPClientOptions) PreprocessCustomHeaders() {
-	if len(opts.CustomHTTPHeaders) == 0 {
+	if len(opts.CustomHTTPHeaders) == 0 && len(opts.AuthHeaders) == 0 {
 		return
 	}
 
-	opts.ParsedHeaders = make([]ParsedHeader, 0, len(opts.CustomHTTPHeaders))
-	opts.HeaderOverrides = make(map[string]bool, len(opts.CustomHTTPHeaders))
+	opts.ParsedHeaders = make([]ParsedHeader, 0, len(opts.CustomHTTPHeaders)+len(opts.AuthHeaders))
+	opts.HeaderOverrides = make(map[string]bool, len(opts.CustomHTTPHeaders)+len(opts.AuthHeaders))
 
-	for _, header := range opts.CustomHTTPHeaders {
+	for i, header := range slices.Concat(opts.CustomHTTPHeaders, opts.AuthHeaders) {
 		colonIdx := strings.Index(header, ":")
 		if colonIdx == -1 {
 			continue // Skip invalid headers
@@ -573,6 +800,9 @@ func (opts *HTTPClientOptions) PreprocessCustomHeaders() {
 		if name == "" {
 			continue // Skip empty header names
 		}
+		if i >= len(opts.CustomHTTPHeaders) && opts.HeaderOverrides[strings.ToLower(name)] {
+			continue // Auth header overridden with -H
+		}
 
 		opts.ParsedHeaders = append(opts.ParsedHeaders, ParsedHeader{
 			Name:  name,
@@ -0,0 +1,93 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+	"github.com/slicingmelon/go-bytesutil/bytesutil"
+	"github.com/valyala/fasthttp"
+)
+
+var respRawBodyBufPool bytesutil.ByteBufferPool
+
+// WriteResponseBody writes up to w.N bytes of the response body to w, decompressed according
+// to its Content-Encoding (gzip, deflate, br, zstd). At most rawLimit bytes of the received
+// body are read for decoding, a truncated body is decoded as far as it goes.
+// Bodies with an unsupported encoding, or that fail to decode, are written as received.
+func WriteResponseBody(resp *fasthttp.Response, w *LimitedWriter, rawLimit int64) error {
+	encoding := strings.ToLower(strings.TrimSpace(string(resp.Header.ContentEncoding())))
+	if encoding == "" || encoding == "identity" {
+		return resp.BodyWriteTo(w)
+	}
+
+	raw := respRawBodyBufPool.Get()
+	defer respRawBodyBufPool.Put(raw)
+
+	err := resp.BodyWriteTo(&LimitedWriter{W: raw, N: rawLimit})
+	if err != nil && err != io.EOF && !errors.Is(err, io.ErrShortWrite) {
+		return err
+	}
+
+	decoder, err := newContentDecoder(encoding, bytes.NewReader(raw.B))
+	if err == nil {
+		written := w.N
+		_, err = io.Copy(w, decoder)
+		decoder.Close()
+		written -= w.N
+
+		// Limit reached, or a truncated body decoded as far as it goes
+		if err == nil || written > 0 {
+			return nil
+		}
+	}
+
+	_, err = w.Write(raw.B)
+	if err == io.EOF {
+		err = nil
+	}
+	return err
+}
+
+// newContentDecoder returns a reader decoding r, compressed with the given Content-Encoding
+func newContentDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// "deflate" is zlib wrapped per RFC 9110, some servers send raw deflate data anyway
+		br := bufio.NewReader(r)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	case "br":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case "zstd":
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding: %s", encoding)
+	}
+}
+
+// isZlibHeader reports whether header (first 2 bytes) is a zlib stream header (CMF/FLG)
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
@@ -6,25 +6,37 @@ X: x.com/pedro_infosec
 package rawhttp
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpproxy"
+	"golang.org/x/net/proxy"
 )
 
 var (
 	clientSharedDialer *fasthttp.TCPDialer
 	onceClientDialer   sync.Once
+	clientDNSResolver  fasthttp.Resolver // nil = system resolver
 )
 
+// SetDNSResolver makes the HTTP client resolve target hosts with resolver (-resolvers)
+// instead of the system resolver. Must be called before the first request.
+func SetDNSResolver(resolver fasthttp.Resolver) {
+	clientDNSResolver = resolver
+}
+
 func GetHTTPClientSharedDialer() *fasthttp.TCPDialer {
 	onceClientDialer.Do(func() {
 		clientSharedDialer = &fasthttp.TCPDialer{
 			Concurrency:      2048,
 			DNSCacheDuration: 120 * time.Minute,
+			Resolver:         clientDNSResolver,
 		}
 	})
 	return clientSharedDialer
@@ -121,7 +133,14 @@ func GetHTTPClientSharedDialer() *fasthttp.TCPDialer {
 func CreateHTTPClientDialer(timeout time.Duration, proxyURL string) fasthttp.DialFunc {
 	dialer := GetHTTPClientSharedDialer()
 
+	// SOCKS5 proxies (socks5:// or socks5h://), hostnames are resolved by the proxy
+	if isSOCKSProxy(proxyURL) {
+		return createSOCKSDialer(timeout, proxyURL)
+	}
+
 	return func(addr string) (net.Conn, error) {
+		addr = decodeZoneID(addr)
+
 		// Handle proxy if configured
 		if proxyURL != "" {
 			proxyDialer := fasthttpproxy.FasthttpHTTPDialerTimeout(proxyURL, timeout)
@@ -140,3 +159,70 @@ func CreateHTTPClientDialer(timeout time.Duration, proxyURL string) fasthttp.Dia
 		return conn, nil
 	}
 }
+
+// decodeZoneID decodes the URL encoded zone id of an IPv6 literal address
+// ([fe80::1%25eth0]:80 -> [fe80::1%eth0]:80), the form net.Dial expects
+func decodeZoneID(addr string) string {
+	if !strings.HasPrefix(addr, "[") {
+		return addr
+	}
+	return strings.Replace(addr, "%25", "%", 1)
+}
+
+// tlsServerName returns the TLS ServerName for a dial address, IPv6 zone ids are dropped
+func tlsServerName(addr string) string {
+	host, _, err := net.SplitHostPort(decodeZoneID(addr))
+	if err != nil {
+		host = addr
+	}
+	if i := strings.IndexByte(host, '%'); i != -1 {
+		host = host[:i]
+	}
+	return host
+}
+
+// isSOCKSProxy reports whether the proxy URL uses a SOCKS5 scheme
+func isSOCKSProxy(proxyURL string) bool {
+	lower := strings.ToLower(proxyURL)
+	return strings.HasPrefix(lower, "socks5://") || strings.HasPrefix(lower, "socks5h://")
+}
+
+// createSOCKSDialer creates a DialFunc tunneling all connections through a SOCKS5 proxy.
+// TLS is handled by fasthttp on top of the tunneled connection.
+func createSOCKSDialer(timeout time.Duration, proxyURL string) fasthttp.DialFunc {
+	parsedProxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return func(addr string) (net.Conn, error) {
+			return nil, fmt.Errorf("[Client.socksDial] invalid proxy URL %s: %w", proxyURL, err)
+		}
+	}
+
+	// x/net/proxy only knows the socks5 scheme, which already lets the proxy resolve hostnames
+	if strings.EqualFold(parsedProxy.Scheme, "socks5h") {
+		parsedProxy.Scheme = "socks5"
+	}
+
+	socksDialer, err := proxy.FromURL(parsedProxy, &net.Dialer{Timeout: timeout})
+	if err != nil {
+		return func(addr string) (net.Conn, error) {
+			return nil, fmt.Errorf("[Client.socksDial] %s: %w", proxyURL, err)
+		}
+	}
+	contextDialer, _ := socksDialer.(proxy.ContextDialer)
+
+	return func(addr string) (net.Conn, error) {
+		var conn net.Conn
+		var err error
+		if contextDialer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			conn, err = contextDialer.DialContext(ctx, "tcp", addr)
+			cancel()
+		} else {
+			conn, err = socksDialer.Dial("tcp", addr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[Client.socksDial] %s: %w", addr, err)
+		}
+		return conn, nil
+	}
+}
@@ -0,0 +1,178 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHostRecoveryWindow is how long a degraded host stays backed off
+	DefaultHostRecoveryWindow = 30 * time.Second
+
+	hostLatencyAlpha      = 0.3 // Weight of the latest response time in the moving average
+	hostLatencyMinSamples = 5   // Responses needed before the average response time can back off a host
+)
+
+// HostHealth tracks the health of each host across the worker pools of all modules and target URLs
+// (-max-host-fails): its failed requests in a row (timeouts, resets, refused connections, after retries)
+// and an exponentially weighted moving average of its response times, compared to the request Timeout.
+// A host is degraded once it fails maxFails requests in a row, or once its average response time
+// reaches half of the Timeout. It is then backed off for the recovery window: its requests are sent
+// one at a time. Once the window is over, the host is back to normal, and backed off again at its next
+// failed or slow request if it is still degraded. Healthy hosts are never slowed down.
+type HostHealth struct {
+	mu             sync.Mutex
+	maxFails       int
+	timeout        time.Duration
+	recoveryWindow time.Duration
+	hosts          map[string]*hostHealthState
+}
+
+type hostHealthState struct {
+	consecutiveFails int
+	failedRequests   int
+	latencySamples   int
+	avgLatency       time.Duration // Moving average of the response times of the answered requests
+	backoffs         int
+	backoffUntil     time.Time
+	backoffSlot      chan struct{} // One request at a time while backed off
+}
+
+// BackedOffHost is a host backed off at least once by HostHealth
+type BackedOffHost struct {
+	Host           string
+	Backoffs       int           // Times the host was backed off
+	FailedRequests int           // Requests of the host that failed, over the whole scan
+	AvgLatency     time.Duration // Moving average of its response times, when it was last tracked
+}
+
+// NewHostHealth creates a HostHealth backing off a host for recoveryWindow after maxFails failed
+// requests in a row, or once its average response time reaches half of timeout.
+// Returns nil if maxFails <= 0 (no per-host threshold).
+func NewHostHealth(maxFails int, timeout, recoveryWindow time.Duration) *HostHealth {
+	if maxFails <= 0 {
+		return nil
+	}
+	return &HostHealth{
+		maxFails:       maxFails,
+		timeout:        timeout,
+		recoveryWindow: recoveryWindow,
+		hosts:          make(map[string]*hostHealthState),
+	}
+}
+
+func (hh *HostHealth) state(host string) *hostHealthState {
+	st, ok := hh.hosts[host]
+	if !ok {
+		st = &hostHealthState{backoffSlot: make(chan struct{}, 1)}
+		hh.hosts[host] = st
+	}
+	return st
+}
+
+// backOffIfDegraded starts the recovery window of a degraded host, unless it is already in one
+func (hh *HostHealth) backOffIfDegraded(st *hostHealthState) {
+	degraded := st.consecutiveFails >= hh.maxFails ||
+		(hh.timeout > 0 && st.latencySamples >= hostLatencyMinSamples && st.avgLatency >= hh.timeout/2)
+	now := time.Now()
+	if degraded && !now.Before(st.backoffUntil) {
+		st.backoffs++
+		st.backoffUntil = now.Add(hh.recoveryWindow)
+	}
+}
+
+// RecordSuccess records the response time of a request answered by host, resetting its failures in a row
+func (hh *HostHealth) RecordSuccess(host string, latency time.Duration) {
+	if hh == nil {
+		return
+	}
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+
+	st := hh.state(host)
+	st.consecutiveFails = 0
+	if st.latencySamples == 0 {
+		st.avgLatency = latency
+	} else {
+		st.avgLatency = time.Duration(hostLatencyAlpha*float64(latency) + (1-hostLatencyAlpha)*float64(st.avgLatency))
+	}
+	st.latencySamples++
+	hh.backOffIfDegraded(st)
+}
+
+// RecordFailure records a failed request of host
+func (hh *HostHealth) RecordFailure(host string) {
+	if hh == nil {
+		return
+	}
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+
+	st := hh.state(host)
+	st.failedRequests++
+	st.consecutiveFails++
+	hh.backOffIfDegraded(st)
+}
+
+// IsBackedOff reports whether host is in its recovery window. A nil HostHealth has no backed off host.
+func (hh *HostHealth) IsBackedOff(host string) bool {
+	if hh == nil {
+		return false
+	}
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+	st, ok := hh.hosts[host]
+	return ok && time.Now().Before(st.backoffUntil)
+}
+
+// Acquire lets a request to host through: right away for a healthy host, one at a time for a
+// backed off one. Returns the func releasing the request, and false if ctx is cancelled first.
+func (hh *HostHealth) Acquire(ctx context.Context, host string) (func(), bool) {
+	if !hh.IsBackedOff(host) {
+		return func() {}, true
+	}
+
+	hh.mu.Lock()
+	slot := hh.hosts[host].backoffSlot
+	hh.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// BackedOffHosts returns the hosts backed off so far, sorted by host
+func (hh *HostHealth) BackedOffHosts() []BackedOffHost {
+	if hh == nil {
+		return nil
+	}
+	hh.mu.Lock()
+	defer hh.mu.Unlock()
+
+	var hosts []BackedOffHost
+	for host, st := range hh.hosts {
+		if st.backoffs > 0 {
+			hosts = append(hosts, BackedOffHost{Host: host, Backoffs: st.backoffs, FailedRequests: st.failedRequests, AvgLatency: st.avgLatency})
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
+
+// RecoveryWindow returns how long a degraded host is backed off
+func (hh *HostHealth) RecoveryWindow() time.Duration {
+	if hh == nil {
+		return 0
+	}
+	return hh.recoveryWindow
+}
@@ -0,0 +1,62 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"context"
+	"sync"
+)
+
+// HostLimiter caps the number of in-flight requests per host (one semaphore per host)
+type HostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	sems  map[string]chan struct{}
+}
+
+// NewHostLimiter creates a HostLimiter allowing up to limit concurrent requests per host.
+// Returns nil if limit <= 0 (no per-host limit).
+func NewHostLimiter(limit int) *HostLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &HostLimiter{
+		limit: limit,
+		sems:  make(map[string]chan struct{}),
+	}
+}
+
+func (hl *HostLimiter) sem(host string) chan struct{} {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	s, ok := hl.sems[host]
+	if !ok {
+		s = make(chan struct{}, hl.limit)
+		hl.sems[host] = s
+	}
+	return s
+}
+
+// Acquire blocks until a slot for host is free, returns false if ctx is cancelled first
+func (hl *HostLimiter) Acquire(ctx context.Context, host string) bool {
+	select {
+	case hl.sem(host) <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release frees a slot previously acquired for host
+func (hl *HostLimiter) Release(host string) {
+	<-hl.sem(host)
+}
+
+// Limit returns the max number of concurrent requests per host
+func (hl *HostLimiter) Limit() int {
+	return hl.limit
+}
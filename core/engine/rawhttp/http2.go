@@ -0,0 +1,157 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
+)
+
+// ErrHTTP2NotNegotiated is returned when the server doesn't select h2 via ALPN
+var ErrHTTP2NotNegotiated = errors.New("server did not negotiate h2 via ALPN")
+
+var strHTTP2 = []byte("HTTP/2.0")
+
+// http2Client sends the requests of an HTTPClient over HTTP/2 (-http2).
+// Requests are built as usual (raw fasthttp request) and converted to h2 frames by x/net/http2,
+// the response is copied back into the fasthttp response so response processing stays the same.
+type http2Client struct {
+	transport   *http2.Transport
+	timeout     time.Duration
+	maxBodySize int
+	h1Hosts     sync.Map // hosts that didn't negotiate h2, served over HTTP/1.1 from then on
+}
+
+// newHTTP2Client creates the h2 transport of c, connections go through the client's dialer (direct or proxy)
+func newHTTP2Client(c *HTTPClient) *http2Client {
+	h := &http2Client{
+		timeout:     c.options.Timeout,
+		maxBodySize: max(c.options.MaxResponseBodySize, c.options.ResponseBodyPreviewSize),
+	}
+
+	h.transport = &http2.Transport{
+		DisableCompression: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			rawConn, err := c.dialer()(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			// The cloned config carries the -sni override, if any
+			config := c.client.TLSConfig.Clone()
+			if config.ServerName == "" {
+				config.ServerName = tlsServerName(addr)
+			}
+			config.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+
+			tlsConn := tls.Client(rawConn, config)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				rawConn.Close()
+				return nil, err
+			}
+			if tlsConn.ConnectionState().NegotiatedProtocol != http2.NextProtoTLS {
+				tlsConn.Close()
+				return nil, ErrHTTP2NotNegotiated
+			}
+			return tlsConn, nil
+		},
+	}
+	return h
+}
+
+// supports reports whether requests to host should be tried over h2
+func (h *http2Client) supports(bypassPayload payload.BypassPayload) bool {
+	if bypassPayload.Scheme != "https" {
+		return false
+	}
+	_, isH1 := h.h1Hosts.Load(bypassPayload.Host)
+	return !isH1
+}
+
+// Do sends req over HTTP/2 and fills resp. Returns ErrHTTP2NotNegotiated (and remembers the host)
+// if the server only speaks HTTP/1.1, the caller is expected to fall back to HTTP/1.1.
+func (h *http2Client) Do(req *fasthttp.Request, resp *fasthttp.Response, bypassPayload payload.BypassPayload) error {
+	hreq := &http.Request{
+		Method: string(req.Header.Method()),
+		URL: &url.URL{
+			Scheme: "https",
+			Host:   bypassPayload.Host,
+			Opaque: string(req.Header.RequestURI()), // sent as is in :path
+		},
+		Host:   string(req.Header.Host()), // :authority, may be spoofed by the payload
+		Header: make(http.Header),
+	}
+
+	// No User-Agent unless the payload has one (x/net/http2 would add its own)
+	hreq.Header["User-Agent"] = []string{""}
+	req.Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		switch strings.ToLower(name) {
+		case "host", "content-length", "connection", "keep-alive", "proxy-connection", "transfer-encoding", "upgrade":
+			// :authority and content-length are set by the transport, connection-specific headers are invalid in h2
+			return
+		case "user-agent":
+			hreq.Header["User-Agent"] = []string{string(value)}
+			return
+		}
+		hreq.Header[name] = append(hreq.Header[name], string(value))
+	})
+
+	if body := req.Body(); len(body) > 0 {
+		hreq.Body = io.NopCloser(bytes.NewReader(body))
+		hreq.ContentLength = int64(len(body))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	hresp, err := h.transport.RoundTrip(hreq.WithContext(ctx))
+	if err != nil {
+		if errors.Is(err, ErrHTTP2NotNegotiated) {
+			h.h1Hosts.Store(bypassPayload.Host, struct{}{})
+		}
+		return err
+	}
+	defer hresp.Body.Close()
+
+	resp.Reset()
+	resp.SetStatusCode(hresp.StatusCode)
+	resp.Header.SetProtocol(strHTTP2)
+	for name, values := range hresp.Header {
+		for _, value := range values {
+			resp.Header.Add(name, value)
+		}
+	}
+	if hresp.ContentLength < 0 {
+		resp.Header.SetContentLength(-2) // unknown length, -1 would add a Transfer-Encoding header
+	}
+
+	// The body is only needed for the preview, don't read past the max body size (or the preview size if larger)
+	body, err := io.ReadAll(io.LimitReader(hresp.Body, int64(h.maxBodySize)))
+	if err != nil {
+		return err
+	}
+	resp.SetBody(body)
+	return nil
+}
+
+// CloseIdleConnections closes the idle h2 connections
+func (h *http2Client) CloseIdleConnections() {
+	h.transport.CloseIdleConnections()
+}
@@ -0,0 +1,116 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+	"github.com/valyala/fasthttp"
+)
+
+// MaxProxyConsecutiveFailures is the number of failed dials in a row after which a proxy
+// is dropped from the rotation
+const MaxProxyConsecutiveFailures = 5
+
+var (
+	ErrNoProxyAvailable = errors.New("all proxies were dropped after consecutive failures")
+
+	proxyRotatorsMu sync.Mutex
+	proxyRotators   = make(map[string]*ProxyRotator) // keyed by the proxy list, shared by all clients
+)
+
+// rotatedProxy is a proxy of the rotation, with its own dialer
+type rotatedProxy struct {
+	url              string
+	dial             fasthttp.DialFunc
+	consecutiveFails atomic.Int32
+	dropped          atomic.Bool
+}
+
+// ProxyRotator dials every new connection through the next proxy of a list (-proxy-file),
+// round-robin. A proxy failing MaxProxyConsecutiveFailures dials in a row is dropped and
+// the dial is retried through the next one.
+type ProxyRotator struct {
+	proxies []*rotatedProxy
+	next    atomic.Uint64
+	alive   atomic.Int32
+}
+
+// GetProxyRotator returns the rotator of proxyURLs, created on first use and shared by all
+// HTTP clients (one per bypass module), so dropped proxies stay dropped for the whole scan
+func GetProxyRotator(proxyURLs []string, timeout time.Duration) *ProxyRotator {
+	key := strings.Join(proxyURLs, "\n")
+
+	proxyRotatorsMu.Lock()
+	defer proxyRotatorsMu.Unlock()
+
+	if r, ok := proxyRotators[key]; ok {
+		return r
+	}
+	r := NewProxyRotator(proxyURLs, timeout)
+	proxyRotators[key] = r
+	return r
+}
+
+// NewProxyRotator creates a rotator with one dialer per proxy URL (HTTP or SOCKS5)
+func NewProxyRotator(proxyURLs []string, timeout time.Duration) *ProxyRotator {
+	r := &ProxyRotator{}
+	for _, proxyURL := range proxyURLs {
+		r.proxies = append(r.proxies, &rotatedProxy{
+			url:  proxyURL,
+			dial: CreateHTTPClientDialer(timeout, proxyURL),
+		})
+	}
+	r.alive.Store(int32(len(r.proxies)))
+	return r
+}
+
+// Dial connects to addr through the next proxy still in the rotation
+func (r *ProxyRotator) Dial(addr string) (net.Conn, error) {
+	var lastErr error = ErrNoProxyAvailable
+
+	// Each proxy is tried at most once per dial
+	for range r.proxies {
+		if r.alive.Load() <= 0 {
+			break
+		}
+
+		p := r.proxies[(r.next.Add(1)-1)%uint64(len(r.proxies))]
+		if p.dropped.Load() {
+			continue
+		}
+
+		conn, err := p.dial(addr)
+		if err == nil {
+			p.consecutiveFails.Store(0)
+			return conn, nil
+		}
+		lastErr = err
+
+		if p.consecutiveFails.Add(1) >= MaxProxyConsecutiveFailures && p.dropped.CompareAndSwap(false, true) {
+			left := r.alive.Add(-1)
+			GB403Logger.Warning().Msgf("Proxy %s dropped after %d consecutive failures, %d proxies left: %v\n",
+				p.url, MaxProxyConsecutiveFailures, left, err)
+		}
+	}
+
+	if r.alive.Load() <= 0 {
+		return nil, fmt.Errorf("[Client.proxyRotationDial] %s: %w", addr, ErrNoProxyAvailable)
+	}
+	return nil, lastErr
+}
+
+// Alive returns the number of proxies still in the rotation
+func (r *ProxyRotator) Alive() int {
+	return int(r.alive.Load())
+}
@@ -0,0 +1,70 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket capping the requests per second of all workers sharing it.
+// The bucket holds a single token, so requests are evenly spaced instead of sent in bursts.
+type RateLimiter struct {
+	mu       sync.Mutex
+	rate     int
+	interval time.Duration // time to refill one token
+	next     time.Time     // when the next token is available
+}
+
+// NewRateLimiter creates a RateLimiter allowing up to rate requests per second.
+// Returns nil if rate <= 0 (no limit).
+func NewRateLimiter(rate int) *RateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		rate:     rate,
+		interval: time.Second / time.Duration(rate),
+	}
+}
+
+// reserve takes the next token and returns how long to wait before using it
+func (rl *RateLimiter) reserve() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	if rl.next.Before(now) {
+		// Bucket full, tokens don't accumulate past one
+		rl.next = now
+	}
+	wait := rl.next.Sub(now)
+	rl.next = rl.next.Add(rl.interval)
+	return wait
+}
+
+// Wait blocks until a token is available, returns false if ctx is cancelled first
+func (rl *RateLimiter) Wait(ctx context.Context) bool {
+	wait := rl.reserve()
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Rate returns the max number of requests per second
+func (rl *RateLimiter) Rate() int {
+	return rl.rate
+}
@@ -9,6 +9,7 @@ import (
 	"bufio"
 	"bytes"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/slicingmelon/gobypass403/core/engine/payload"
@@ -26,7 +27,9 @@ var (
 var (
 	strHost                = []byte("Host")
 	strHostColon           = []byte("Host: ")
+	strUserAgentColon      = []byte("User-Agent: ")
 	strAccept              = []byte("Accept: */*\r\n")
+	strAcceptEncodingColon = []byte("Accept-Encoding: ")
 	strColonSpace          = []byte(": ")
 	strCRLF                = []byte("\r\n")
 	strConnectionKeepAlive = []byte("Connection: keep-alive\r\n")
@@ -37,10 +40,14 @@ var (
 	strHostLower          = []byte("host")
 	strContentLengthLower = []byte("content-length")
 	strConnectionLower    = []byte("connection")
+	strAcceptLower        = []byte("accept")
+	strAcceptEncLower     = []byte("accept-encoding")
+	strCookieLower        = []byte("cookie")
 	//strUserAgentLower     = []byte("user-agent")
 	//bAcceptLower          = []byte("accept")
 	//bXGB403TokenLower     = []byte("x-gb403-token")
-	strHTTP11 = []byte("HTTP/1.1\r\n")
+	strHTTP11        = []byte("HTTP/1.1\r\n")
+	strNormalizeHost = []byte("localhost")
 )
 
 var (
@@ -80,12 +87,124 @@ For this to work, everything must be set in order, Raw Request line, headers, th
 then set req.UseHostHeader = true and then req.URI().SetScheme() and req.URI().SetHost()
 */
 func BuildRawHTTPRequest(httpclient *HTTPClient, req *fasthttp.Request, bypassPayload payload.BypassPayload) error {
+	return buildRawHTTPRequest(httpclient, req, bypassPayload, nil)
+}
+
+// buildRawHTTPRequest is BuildRawHTTPRequest, also copying the raw request bytes to rawRequest
+// when not nil (-dump-wire)
+func buildRawHTTPRequest(httpclient *HTTPClient, req *fasthttp.Request, bypassPayload payload.BypassPayload, rawRequest *[]byte) error {
 	// Build the raw HTTP request
-	bb, _ := BuildRawRequest(httpclient, bypassPayload)
+	bb, shouldCloseConn := BuildRawRequest(httpclient, bypassPayload)
 	defer requestBufferPool.Put(bb)
 
+	if rawRequest != nil {
+		*rawRequest = append((*rawRequest)[:0], bb.B...)
+	}
+
 	// Wrap the raw request into a FastHTTP request for other modules
-	return WrapRawFastHTTPRequest(req, bb, bypassPayload)
+	if err := WrapRawFastHTTPRequest(req, bb, bypassPayload); err != nil {
+		return err
+	}
+
+	// The raw Connection header is not parsed as special, flag the request so the client
+	// drops the connection after the response instead of putting it back in the pool
+	if shouldCloseConn {
+		req.SetConnectionClose()
+	}
+
+	restoreHeaderValueSpaces(req, httpclient.GetHTTPClientOptions(), bypassPayload)
+	return nil
+}
+
+// restoreHeaderValueSpaces puts back the leading/trailing spaces and tabs of payload header values
+// (e.g. "Host: example.com\t"), fasthttp trims them when parsing the raw request.
+// Headers overridden with -H were not written, so they are left alone.
+func restoreHeaderValueSpaces(req *fasthttp.Request, clientOpts *HTTPClientOptions, bypassPayload payload.BypassPayload) {
+	for _, h := range bypassPayload.Headers {
+		v := h.Value
+		if v == "" || !(isSpaceOrTab(v[0]) || isSpaceOrTab(v[len(v)-1])) {
+			continue
+		}
+		if clientOpts.HeaderOverrides != nil && clientOpts.HeaderOverrides[strings.ToLower(h.Header)] {
+			continue
+		}
+		req.Header.Set(h.Header, v)
+	}
+}
+
+func isSpaceOrTab(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// RequestHeaders returns the CLI (-H) and payload headers in the order BuildRawRequest writes them,
+// ahead of the default Host, User-Agent, Accept, Content-Length and Connection headers.
+// By default the CLI headers come first and payload headers with the same name are dropped.
+// With PreserveHeaderOrder the payload headers keep their slice order, a CLI header takes the
+// slot of the first payload header it overrides and the remaining CLI headers follow.
+func RequestHeaders(bypassPayload payload.BypassPayload, clientOpts *HTTPClientOptions) []payload.Headers {
+	if clientOpts == nil || len(clientOpts.ParsedHeaders) == 0 {
+		return bypassPayload.Headers
+	}
+
+	cliHeader := func(name string) int {
+		for i, h := range clientOpts.ParsedHeaders {
+			if strings.EqualFold(name, h.Name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	headers := make([]payload.Headers, 0, len(clientOpts.ParsedHeaders)+len(bypassPayload.Headers))
+	if !clientOpts.PreserveHeaderOrder {
+		for _, h := range clientOpts.ParsedHeaders {
+			headers = append(headers, payload.Headers{Header: h.Name, Value: cliHeaderValue(h, bypassPayload)})
+		}
+		for _, h := range bypassPayload.Headers {
+			if cliHeader(h.Header) == -1 {
+				headers = append(headers, h)
+			}
+		}
+		return headers
+	}
+
+	written := make([]bool, len(clientOpts.ParsedHeaders))
+	for _, h := range bypassPayload.Headers {
+		i := cliHeader(h.Header)
+		if i == -1 {
+			headers = append(headers, h)
+			continue
+		}
+		if !written[i] {
+			written[i] = true
+			headers = append(headers, payload.Headers{Header: clientOpts.ParsedHeaders[i].Name, Value: cliHeaderValue(clientOpts.ParsedHeaders[i], bypassPayload)})
+		}
+	}
+	for i, h := range clientOpts.ParsedHeaders {
+		if !written[i] {
+			headers = append(headers, payload.Headers{Header: h.Name, Value: h.Value})
+		}
+	}
+	return headers
+}
+
+// cliHeaderValue returns the value sent for a -H header. The payload cookies are appended to a
+// -H Cookie header instead of being dropped, so http_cookies composes with a session cookie
+func cliHeaderValue(h ParsedHeader, bypassPayload payload.BypassPayload) string {
+	if !isHeaderNameEqual(h.Name, strCookieLower) {
+		return h.Value
+	}
+
+	value := h.Value
+	for _, ph := range bypassPayload.Headers {
+		if isHeaderNameEqual(ph.Header, strCookieLower) && ph.Value != "" {
+			if value != "" {
+				value += "; "
+			}
+			value += ph.Value
+		}
+	}
+	return value
 }
 
 // BuildRawRequest builds a raw HTTP request from the bypass payload and returns the byte buffer
@@ -97,11 +216,7 @@ func BuildRawRequest(httpclient *HTTPClient, bypassPayload payload.BypassPayload
 	// Define shouldCloseConn based on general factors
 	shouldCloseConn := clientOpts.DisableKeepAlive ||
 		clientOpts.ProxyURL != "" ||
-		bypassPayload.BypassModule == "headers_scheme" ||
-		bypassPayload.BypassModule == "headers_ip" ||
-		bypassPayload.BypassModule == "headers_port" ||
-		bypassPayload.BypassModule == "headers_url" ||
-		bypassPayload.BypassModule == "headers_host"
+		payload.CloseConnectionModules[bypassPayload.BypassModule]
 
 	// Get ByteBuffer from pool
 	bb := requestBufferPool.Get()
@@ -109,7 +224,11 @@ func BuildRawRequest(httpclient *HTTPClient, bypassPayload payload.BypassPayload
 	// Build request line directly into byte buffer
 	bb.B = append(bb.B, bypassPayload.Method...)
 	bb.B = append(bb.B, strSpace...)
-	bb.B = append(bb.B, bypassPayload.RawURI...)
+	if clientOpts.DisablePathNormalizing {
+		bb.B = append(bb.B, bypassPayload.RawURI...)
+	} else {
+		bb.B = append(bb.B, NormalizeRequestURI(bypassPayload.RawURI)...)
+	}
 	bb.B = append(bb.B, strSpace...)
 	bb.B = append(bb.B, strHTTP11...)
 
@@ -118,6 +237,8 @@ func BuildRawRequest(httpclient *HTTPClient, bypassPayload payload.BypassPayload
 	hasHostHeader := false
 	hasContentLength := false
 	hasConnectionHeader := false
+	hasAcceptHeader := false
+	hasAcceptEncodingHeader := false
 
 	// Check if CLI headers override special headers
 	if clientOpts.HeaderOverrides != nil {
@@ -131,76 +252,115 @@ func BuildRawRequest(httpclient *HTTPClient, bypassPayload payload.BypassPayload
 		}
 	}
 
-	// PRIORITY 1: Add CLI custom headers first (highest priority)
-	for _, h := range clientOpts.ParsedHeaders {
-		// Use fast case-insensitive comparison with pre-computed byte slices
-		if isHeaderNameEqual(h.Name, strHostLower) {
-			hasHostHeader = true
-			shouldCloseConn = true
-		} else if isHeaderNameEqual(h.Name, strContentLengthLower) {
-			hasContentLength = true
-		} else if isHeaderNameEqual(h.Name, strConnectionLower) {
-			hasConnectionHeader = true
-			shouldCloseConn = true
-		}
-
-		// Add header with original case preserved
-		bb.B = append(bb.B, h.Name...)
-		bb.B = append(bb.B, strColonSpace...)
-		bb.B = append(bb.B, h.Value...)
-		bb.B = append(bb.B, strCRLF...)
-	}
-
-	// PRIORITY 2: Add payload headers (skip if already added by CLI)
 	// For certain modules, defer Content-Length headers to be added just before Connection
 	var deferredContentLengthHeaders []payload.Headers
 	shouldDeferContentLength := bypassPayload.BypassModule == "haproxy_bypasses"
 
-	for _, h := range bypassPayload.Headers {
-		// Use HeaderOverrides map to check if CLI already added this header
-		// Use fast case-insensitive comparison to avoid strings.ToLower() allocation
-		if clientOpts.HeaderOverrides != nil {
-			// Check against each CLI header using case-insensitive comparison
-			skipHeader := false
-			for _, cliHeader := range clientOpts.ParsedHeaders {
-				if bytes.EqualFold([]byte(h.Header), []byte(cliHeader.Name)) {
-					skipHeader = true
-					break
-				}
-			}
-			if skipHeader {
+	// PRIORITY 1+2 (-preserve-header-order): payload headers in slice order,
+	// a CLI header takes the slot of the payload header it overrides
+	if clientOpts.PreserveHeaderOrder {
+		for _, h := range RequestHeaders(bypassPayload, clientOpts) {
+			isContentLength := isHeaderNameEqual(h.Header, strContentLengthLower)
+
+			if shouldDeferContentLength && isContentLength && h.Header == "Content-Length" {
+				deferredContentLengthHeaders = append(deferredContentLengthHeaders, h)
+				hasContentLength = true
 				continue
 			}
-		}
 
-		// Use fast case-insensitive comparison for special headers
-		isHost := isHeaderNameEqual(h.Header, strHostLower)
-		isContentLength := isHeaderNameEqual(h.Header, strContentLengthLower)
-		isConnection := isHeaderNameEqual(h.Header, strConnectionLower)
+			if isHeaderNameEqual(h.Header, strHostLower) {
+				hasHostHeader = true
+				shouldCloseConn = true
+			} else if isContentLength {
+				hasContentLength = true
+			} else if isHeaderNameEqual(h.Header, strConnectionLower) {
+				hasConnectionHeader = true
+				shouldCloseConn = true
+			} else if isHeaderNameEqual(h.Header, strAcceptLower) {
+				hasAcceptHeader = true
+			} else if isHeaderNameEqual(h.Header, strAcceptEncLower) {
+				hasAcceptEncodingHeader = true
+			}
 
-		// For modules that need special Content-Length ordering, defer real Content-Length headers
-		if shouldDeferContentLength && isContentLength && h.Header == "Content-Length" {
-			deferredContentLengthHeaders = append(deferredContentLengthHeaders, h)
-			hasContentLength = true // Mark as having Content-Length to prevent auto-generation
-			continue
+			bb.B = append(bb.B, h.Header...)
+			bb.B = append(bb.B, strColonSpace...)
+			bb.B = append(bb.B, h.Value...)
+			bb.B = append(bb.B, strCRLF...)
 		}
+	} else {
+		// PRIORITY 1: Add CLI custom headers first (highest priority)
+		for _, h := range clientOpts.ParsedHeaders {
+			// Use fast case-insensitive comparison with pre-computed byte slices
+			if isHeaderNameEqual(h.Name, strHostLower) {
+				hasHostHeader = true
+				shouldCloseConn = true
+			} else if isHeaderNameEqual(h.Name, strContentLengthLower) {
+				hasContentLength = true
+			} else if isHeaderNameEqual(h.Name, strConnectionLower) {
+				hasConnectionHeader = true
+				shouldCloseConn = true
+			} else if isHeaderNameEqual(h.Name, strAcceptEncLower) {
+				hasAcceptEncodingHeader = true
+			}
 
-		// Set special header flags
-		if isHost {
-			hasHostHeader = true
-			shouldCloseConn = true
-		} else if isContentLength {
-			hasContentLength = true
-		} else if isConnection {
-			hasConnectionHeader = true
-			shouldCloseConn = true
+			// Add header with original case preserved
+			bb.B = append(bb.B, h.Name...)
+			bb.B = append(bb.B, strColonSpace...)
+			bb.B = append(bb.B, cliHeaderValue(h, bypassPayload)...)
+			bb.B = append(bb.B, strCRLF...)
 		}
 
-		// Add header with original case preserved
-		bb.B = append(bb.B, h.Header...)
-		bb.B = append(bb.B, strColonSpace...)
-		bb.B = append(bb.B, h.Value...)
-		bb.B = append(bb.B, strCRLF...)
+		// PRIORITY 2: Add payload headers (skip if already added by CLI)
+		for _, h := range bypassPayload.Headers {
+			// Use HeaderOverrides map to check if CLI already added this header
+			// Use fast case-insensitive comparison to avoid strings.ToLower() allocation
+			if clientOpts.HeaderOverrides != nil {
+				// Check against each CLI header using case-insensitive comparison
+				skipHeader := false
+				for _, cliHeader := range clientOpts.ParsedHeaders {
+					if bytes.EqualFold([]byte(h.Header), []byte(cliHeader.Name)) {
+						skipHeader = true
+						break
+					}
+				}
+				if skipHeader {
+					continue
+				}
+			}
+
+			// Use fast case-insensitive comparison for special headers
+			isHost := isHeaderNameEqual(h.Header, strHostLower)
+			isContentLength := isHeaderNameEqual(h.Header, strContentLengthLower)
+			isConnection := isHeaderNameEqual(h.Header, strConnectionLower)
+
+			// For modules that need special Content-Length ordering, defer real Content-Length headers
+			if shouldDeferContentLength && isContentLength && h.Header == "Content-Length" {
+				deferredContentLengthHeaders = append(deferredContentLengthHeaders, h)
+				hasContentLength = true // Mark as having Content-Length to prevent auto-generation
+				continue
+			}
+
+			// Set special header flags
+			if isHost {
+				hasHostHeader = true
+				shouldCloseConn = true
+			} else if isContentLength {
+				hasContentLength = true
+			} else if isConnection {
+				hasConnectionHeader = true
+				shouldCloseConn = true
+			} else if isHeaderNameEqual(h.Header, strAcceptLower) {
+				hasAcceptHeader = true
+			} else if isHeaderNameEqual(h.Header, strAcceptEncLower) {
+				hasAcceptEncodingHeader = true
+			}
+
+			// Add header with original case preserved
+			bb.B = append(bb.B, h.Header...)
+			bb.B = append(bb.B, strColonSpace...)
+			bb.B = append(bb.B, h.Value...)
+			bb.B = append(bb.B, strCRLF...)
+		}
 	}
 
 	// PRIORITY 3: Add default Host header if not provided
@@ -212,12 +372,24 @@ func BuildRawRequest(httpclient *HTTPClient, bypassPayload payload.BypassPayload
 
 	// PRIORITY 4: Add standard headers if not overridden by CLI
 	if clientOpts.HeaderOverrides == nil || !clientOpts.HeaderOverrides["user-agent"] {
-		bb.B = append(bb.B, strUserAgentHeader...)
+		if len(clientOpts.UserAgents) > 0 {
+			bb.B = append(bb.B, strUserAgentColon...)
+			bb.B = append(bb.B, clientOpts.NextUserAgent()...)
+		} else {
+			bb.B = append(bb.B, strUserAgentHeader...)
+		}
 		bb.B = append(bb.B, strCRLF...)
 	}
-	if clientOpts.HeaderOverrides == nil || !clientOpts.HeaderOverrides["accept"] {
+	// The default Accept is left out when a payload sends its own (http_headers_accept)
+	if !hasAcceptHeader && (clientOpts.HeaderOverrides == nil || !clientOpts.HeaderOverrides["accept"]) {
 		bb.B = append(bb.B, strAccept...)
 	}
+	// -accept-encoding, unless a payload or -H header sets its own
+	if clientOpts.AcceptEncoding != "" && !hasAcceptEncodingHeader {
+		bb.B = append(bb.B, strAcceptEncodingColon...)
+		bb.B = append(bb.B, clientOpts.AcceptEncoding...)
+		bb.B = append(bb.B, strCRLF...)
+	}
 
 	// Add Debug token if debug mode is enabled and not overridden
 	if GB403Logger.IsDebugEnabled() &&
@@ -297,6 +469,24 @@ func WrapRawFastHTTPRequest(req *fasthttp.Request, rawRequest *bytesutil.ByteBuf
 	return nil
 }
 
+// NormalizeRequestURI returns rawURI the way fasthttp normalizes a request path (-normalize-path):
+// percent-encoded bytes decoded, dot segments resolved and duplicate slashes removed, the path
+// encoded again. Only origin-form URIs are normalized, "*" and absolute URIs are returned as is
+func NormalizeRequestURI(rawURI string) string {
+	if !strings.HasPrefix(rawURI, "/") {
+		return rawURI
+	}
+
+	uri := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(uri)
+
+	// A host is passed so a leading "//" is parsed as part of the path, not as an authority
+	if err := uri.Parse(strNormalizeHost, bytesutil.ToUnsafeBytes(rawURI)); err != nil {
+		return rawURI
+	}
+	return string(uri.RequestURI())
+}
+
 func applyReqFlags(req *fasthttp.Request) {
 	req.URI().DisablePathNormalizing = true
 	req.Header.DisableNormalizing()
@@ -8,6 +8,7 @@ package rawhttp
 import (
 	"context"
 	"errors"
+	"io"
 	"sync/atomic"
 	"time"
 
@@ -27,7 +28,12 @@ type RequestWorkerPool struct {
 	// Request rate tracking
 	requestStartTime  atomic.Int64  // For elapsed time calculation
 	peakRequestRate   atomic.Uint64 // For tracking peak rate
+	sentRequests      atomic.Uint64 // Jobs actually sent, unlike CompletedTasks skipped jobs don't count
 	maxConcurrentReqs int
+	hostLimiter       *HostLimiter   // nil unless HostConcurrency is set
+	rateLimiter       *RateLimiter   // nil unless RequestRate is set, shared by all workers
+	requestBudget     *RequestBudget // nil unless a budget is set (-max-requests)
+	hostHealth        *HostHealth    // nil unless -max-host-fails is set, shared by all worker pools to back off degraded hosts
 }
 
 // Initializes a new RequestWorkerPool instance
@@ -41,12 +47,35 @@ func NewRequestWorkerPool(opts *HTTPClientOptions, maxConcurrentReqs int) *Reque
 		opts.RequestDelay = 100 * time.Millisecond
 	}
 
+	// Per-host limit, the global maxConcurrentReqs stays the upper bound.
+	// More in-flight requests than MaxConnsPerHost would only queue in fasthttp, so cap it there too.
+	hostConcurrency := opts.HostConcurrency
+	if hostConcurrency > 0 && opts.MaxConnsPerHost > 0 && hostConcurrency > opts.MaxConnsPerHost {
+		hostConcurrency = opts.MaxConnsPerHost
+	}
+	if hostConcurrency >= maxConcurrentReqs {
+		hostConcurrency = 0 // the global cap already enforces it
+	}
+
+	// Limiters shared with the other worker pools of the scan, standalone pools get their own
+	hostLimiter := opts.HostLimiter
+	if hostLimiter == nil {
+		hostLimiter = NewHostLimiter(hostConcurrency)
+	}
+	rateLimiter := opts.RateLimiter
+	if rateLimiter == nil {
+		rateLimiter = NewRateLimiter(opts.RequestRate)
+	}
+
 	wp := &RequestWorkerPool{
 		httpClient:        NewHTTPClient(opts),
 		ctx:               ctx,
 		cancel:            cancel,
 		pool:              pond.NewPool(maxConcurrentReqs),
 		maxConcurrentReqs: maxConcurrentReqs,
+		hostLimiter:       hostLimiter,
+		rateLimiter:       rateLimiter,
+		hostHealth:        opts.HostHealth,
 	}
 
 	// Initialize start time
@@ -79,6 +108,11 @@ func (wp *RequestWorkerPool) GetReqWPCompletedTasks() (completed uint64) {
 	return wp.pool.CompletedTasks()
 }
 
+// GetReqWPSentRequests returns the number of jobs sent so far, jobs skipped on cancellation excluded
+func (wp *RequestWorkerPool) GetReqWPSentRequests() uint64 {
+	return wp.sentRequests.Load()
+}
+
 // GetRequestRate returns the current requests per second
 func (wp *RequestWorkerPool) GetRequestRate() uint64 {
 	currentTime := time.Now().UnixNano()
@@ -132,25 +166,59 @@ func (wp *RequestWorkerPool) ResetPeakRate() {
 	wp.peakRequestRate.Store(0)
 }
 
-// ProcessRequests handles multiple payload jobs
-func (wp *RequestWorkerPool) ProcessRequests(bypassPayloads []payload.BypassPayload) <-chan *RawHTTPResponseDetails {
+// ProcessRequests handles multiple payload jobs.
+// Cancelling ctx (or the pool) stops pending jobs, requests already in flight still deliver their response.
+func (wp *RequestWorkerPool) ProcessRequests(ctx context.Context, bypassPayloads []payload.BypassPayload) <-chan *RawHTTPResponseDetails {
 	results := make(chan *RawHTTPResponseDetails, len(bypassPayloads))
 
+	// Jobs stop on either the caller's context or the pool's own (Cancel, max consecutive fails)
+	ctx, cancel := context.WithCancel(ctx)
+	stopAfter := context.AfterFunc(wp.ctx, cancel)
+
 	// Create task group with context for cancellation
-	group := wp.pool.NewGroupContext(wp.ctx)
+	group := wp.pool.NewGroupContext(ctx)
 
 	for _, bypassPayload := range bypassPayloads {
 		bypassPayload := bypassPayload
 		group.SubmitErr(func() error {
 			// Check for cancellation
-			if wp.ctx.Err() != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			// Backed off host (-max-host-fails), one request at a time until its recovery window is over
+			release, ok := wp.hostHealth.Acquire(ctx, bypassPayload.Host)
+			if !ok {
 				return nil
 			}
+			defer release()
 
+			// Wait for a free slot on this host (-host-concurrency)
+			if wp.hostLimiter != nil {
+				if !wp.hostLimiter.Acquire(ctx, bypassPayload.Host) {
+					return nil
+				}
+				defer wp.hostLimiter.Release(bypassPayload.Host)
+			}
+
+			// Wait for the pool wide rate limit (-rate)
+			if wp.rateLimiter != nil && !wp.rateLimiter.Wait(ctx) {
+				return nil
+			}
+
+			// Out of budget (-max-requests), skip this job and the pending ones
+			if !wp.requestBudget.Take() {
+				wp.cancel()
+				return ErrRequestBudgetExhausted
+			}
+			wp.sentRequests.Add(1)
+
+			start := time.Now()
 			resp, err := wp.ProcessRequestResponseJob(bypassPayload)
 
 			// Only propagate critical errors to pond, swallow the rest
 			if err != nil {
+				wp.hostHealth.RecordFailure(bypassPayload.Host)
 				if errors.Is(err, ErrReqFailedMaxConsecutiveFails) {
 					// Only return this specific error to pond
 					return ErrReqFailedMaxConsecutiveFails
@@ -159,9 +227,11 @@ func (wp *RequestWorkerPool) ProcessRequests(bypassPayloads []payload.BypassPayl
 				//GB403Logger.Debug().Msgf("Request error (handled): %v", err)
 				return nil
 			}
+			wp.hostHealth.RecordSuccess(bypassPayload.Host, time.Since(start))
 
-			// Only send valid responses
-			if resp != nil && wp.ctx.Err() == nil {
+			// Only send valid responses, requests already in flight when
+			// the pool is cancelled still deliver theirs (results is buffered)
+			if resp != nil {
 				results <- resp
 			}
 
@@ -172,6 +242,8 @@ func (wp *RequestWorkerPool) ProcessRequests(bypassPayloads []payload.BypassPayl
 	// Handle completion or error
 	go func() {
 		defer close(results)
+		defer cancel()
+		defer stopAfter()
 
 		err := group.Wait()
 
@@ -179,7 +251,7 @@ func (wp *RequestWorkerPool) ProcessRequests(bypassPayloads []payload.BypassPayl
 			if errors.Is(err, ErrReqFailedMaxConsecutiveFails) {
 				GB403Logger.Warning().Msgf("[!!!] Worker pool Wait() returned max consecutive failures for [%s]\n\n",
 					wp.httpClient.GetHTTPClientOptions().BypassModule)
-			} else if err != context.Canceled {
+			} else if !errors.Is(err, ErrRequestBudgetExhausted) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 				GB403Logger.Warning().Msgf("Worker pool for [%s] returned unexpected error: %v\n\n",
 					wp.httpClient.GetHTTPClientOptions().BypassModule, err)
 			}
@@ -192,6 +264,17 @@ func (wp *RequestWorkerPool) ProcessRequests(bypassPayloads []payload.BypassPayl
 	return results
 }
 
+// SetRequestBudget shares budget with the pool, jobs are dropped once it is spent.
+// Must be called before ProcessRequests.
+func (wp *RequestWorkerPool) SetRequestBudget(budget *RequestBudget) {
+	wp.requestBudget = budget
+}
+
+// Cancel stops submitting new requests; responses already queued are still delivered
+func (wp *RequestWorkerPool) Cancel() {
+	wp.cancel()
+}
+
 func (wp *RequestWorkerPool) Close() {
 	wp.pool.StopAndWait() // Ensure all workers are stopped
 	wp.ResetPeakRate()
@@ -209,12 +292,23 @@ func (wp *RequestWorkerPool) ProcessRequestResponseJob(bypassPayload payload.Byp
 		fasthttp.ReleaseResponse(resp)
 	}()
 
-	if err := BuildRawHTTPRequest(wp.httpClient, req, bypassPayload); err != nil {
+	// Keep the raw request bytes for -dump-wire
+	dumpWire := wp.httpClient.GetHTTPClientOptions().DumpWire
+	var rawRequest []byte
+	var rawRequestDest *[]byte
+	if dumpWire != "" {
+		rawRequestDest = &rawRequest
+	}
+
+	if err := buildRawHTTPRequest(wp.httpClient, req, bypassPayload, rawRequestDest); err != nil {
 		return nil, err
 	}
 
 	respTime, err := wp.httpClient.DoRequest(req, resp, bypassPayload)
 	if err != nil {
+		if dumpWire == WireDumpAll {
+			LogWireDump(bypassPayload.BypassModule, bypassPayload.PayloadToken, rawRequest, nil, err)
+		}
 		// Pass through the critical error for handling at higher level
 		if errors.Is(err, ErrReqFailedMaxConsecutiveFails) {
 			GB403Logger.Warning().Msgf("Max consecutive failures reached for %s: %d/%d -- Cancelling current bypass module\n\n",
@@ -222,6 +316,20 @@ func (wp *RequestWorkerPool) ProcessRequestResponseJob(bypassPayload payload.Byp
 			wp.cancel() // faster?
 			return nil, ErrReqFailedMaxConsecutiveFails
 		}
+		// Delivered as a response, the caller decides whether the timeout is a finding
+		if errors.Is(err, ErrReqTimedOut) {
+			result := AcquireResponseDetails()
+			result.URL = append(result.URL, bypassPayload.OriginalURL...)
+			result.BypassModule = append(result.BypassModule, bypassPayload.BypassModule...)
+			result.DebugToken = append(result.DebugToken, bypassPayload.PayloadToken...)
+			result.CurlCommand = BuildCurlCommandWithOpts(bypassPayload, wp.httpClient.GetHTTPClientOptions(), result.CurlCommand)
+			result.ResponseTime = respTime
+			result.TimedOut = true
+			if dumpWire == WireDumpMatched {
+				result.RawRequest = append(result.RawRequest, rawRequest...)
+			}
+			return result, nil
+		}
 		return nil, err
 	}
 
@@ -229,11 +337,54 @@ func (wp *RequestWorkerPool) ProcessRequestResponseJob(bypassPayload payload.Byp
 	result := ProcessHTTPResponse(wp.httpClient, resp, bypassPayload)
 	if result != nil {
 		result.ResponseTime = respTime
+
+		// The response head is taken from the parsed headers, the streamed body is left untouched
+		switch dumpWire {
+		case WireDumpAll:
+			LogWireDump(bypassPayload.BypassModule, bypassPayload.PayloadToken, rawRequest, result.ResponseHeaders, nil)
+		case WireDumpMatched:
+			result.RawRequest = append(result.RawRequest, rawRequest...)
+		}
 	}
 
 	return result, nil
 }
 
+// FetchResponseBody resends bypassPayload and writes up to maxSize bytes of the (decompressed) response body to w.
+// Used to save the full body of findings (-save-bodies), the worker pool only keeps a preview.
+// Returns the number of bytes written and whether the body was truncated at maxSize.
+func (wp *RequestWorkerPool) FetchResponseBody(ctx context.Context, bypassPayload payload.BypassPayload, w io.Writer, maxSize int64) (int64, bool, error) {
+	if wp.rateLimiter != nil && !wp.rateLimiter.Wait(ctx) {
+		return 0, false, ctx.Err()
+	}
+	if !wp.requestBudget.Take() {
+		return 0, false, ErrRequestBudgetExhausted
+	}
+	wp.sentRequests.Add(1)
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer func() {
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}()
+
+	if err := BuildRawHTTPRequest(wp.httpClient, req, bypassPayload); err != nil {
+		return 0, false, err
+	}
+	if _, err := wp.httpClient.DoRequest(req, resp, bypassPayload); err != nil {
+		return 0, false, err
+	}
+
+	// The limit is reached either with io.EOF/io.ErrShortWrite (streamed bodies) or silently (buffered bodies)
+	limitedWriter := &LimitedWriter{W: w, N: maxSize}
+	err := WriteResponseBody(resp, limitedWriter, maxSize)
+	if err == io.EOF || errors.Is(err, io.ErrShortWrite) {
+		err = nil
+	}
+	return maxSize - limitedWriter.N, limitedWriter.N <= 0, err
+}
+
 // buildRequest constructs the raw HTTP request
 func (wp *RequestWorkerPool) BuildRawRequestTask(req *fasthttp.Request, bypassPayload payload.BypassPayload) error {
 	if err := BuildRawHTTPRequest(wp.httpClient, req, bypassPayload); err != nil {
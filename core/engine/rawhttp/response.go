@@ -9,6 +9,8 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"net"
+	"net/url"
 	"runtime"
 	"slices"
 	"strings"
@@ -38,9 +40,16 @@ var (
 	respPreviewBufPool bytesutil.ByteBufferPool
 
 	// Pre-computed byte slices for static strings
-	curlFlags   = []byte("-skgi --path-as-is")
-	curlMethodX = []byte("-X")
-	curlHeaderH = []byte("-H")
+	curlFlags      = []byte("-skgi --path-as-is")
+	curlMethodX    = []byte("-X")
+	curlHeaderH    = []byte("-H")
+	curlHTTP2      = []byte("--http2")
+	curlCompressed = []byte("--compressed")
+
+	curlConnectToFlag = []byte("--connect-to")
+	curlRequestTarget = []byte("--request-target")
+	curlDataRaw       = []byte("--data-raw")
+	hexDigits         = "0123456789abcdef"
 	//strColon          = []byte(":")
 	strSingleQuote = []byte("'")
 	strSpace       = []byte(" ")
@@ -78,6 +87,9 @@ type RawHTTPResponseDetails struct {
 	Title           []byte
 	ResponseTime    int64 // in milliseconds
 	DebugToken      []byte
+	OpenRedirect    bool   // Location points off-host to a value injected by the payload
+	RawRequest      []byte // Raw request bytes, only kept with -dump-wire matched
+	TimedOut        bool   // No response before the timeout (HTTPClientOptions.ReportTimeouts), only the request fields are set
 }
 
 func AcquireResponseDetails() *RawHTTPResponseDetails {
@@ -96,16 +108,60 @@ func ReleaseResponseDetails(rd *RawHTTPResponseDetails) {
 	rd.RedirectURL = rd.RedirectURL[:0]
 	rd.Title = rd.Title[:0]
 	rd.DebugToken = rd.DebugToken[:0]
+	rd.RawRequest = rd.RawRequest[:0]
 
 	// Reset numeric fields
 	rd.StatusCode = 0
 	rd.ContentLength = 0
 	rd.ResponseBytes = 0
 	rd.ResponseTime = 0
+	rd.OpenRedirect = false
+	rd.TimedOut = false
 
 	responseDetailsPool.Put(rd)
 }
 
+// IsOpenRedirect reports whether a Location header points off-host to a value
+// injected by the payload (header value or request URI), i.e. a user-controllable redirect
+func IsOpenRedirect(location []byte, bypassPayload payload.BypassPayload) bool {
+	locURL, err := url.Parse(string(location))
+	if err != nil || locURL.Host == "" {
+		return false // relative redirects stay on the same host
+	}
+
+	locHost := strings.ToLower(locURL.Hostname())
+	if locHost == "" || locHost == payloadValueHost(bypassPayload.Host) {
+		return false
+	}
+
+	for _, h := range bypassPayload.Headers {
+		if payloadValueHost(h.Value) == locHost {
+			return true
+		}
+	}
+
+	// Host injected in the request URI (e.g. //evil.com/, ?next=http://evil.com)
+	if strings.ContainsAny(locHost, ".:") && strings.Contains(strings.ToLower(bypassPayload.RawURI), locHost) {
+		return true
+	}
+
+	return false
+}
+
+// payloadValueHost extracts the lowercase hostname from a host[:port] or URL value
+func payloadValueHost(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "://") {
+		if u, err := url.Parse(value); err == nil {
+			return strings.ToLower(u.Hostname())
+		}
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(strings.Trim(value, "[]"))
+}
+
 type LimitedWriter struct {
 	W io.Writer // Underlying writer
 	N int64     // Max bytes remaining
@@ -143,6 +199,7 @@ func ProcessHTTPResponse(httpclient *HTTPClient, resp *fasthttp.Response, bypass
 	if fasthttp.StatusCodeIsRedirect(result.StatusCode) {
 		if location := PeekResponseHeaderKeyCaseInsensitive(resp, strLocationHeader); len(location) > 0 {
 			result.RedirectURL = append(result.RedirectURL, location...)
+			result.OpenRedirect = IsOpenRedirect(location, bypassPayload)
 		}
 	}
 
@@ -159,8 +216,8 @@ func ProcessHTTPResponse(httpclient *HTTPClient, resp *fasthttp.Response, bypass
 			N: int64(previewSize),
 		}
 
-		// Attempt to write body to the limited writer
-		err := resp.BodyWriteTo(limitedWriter)
+		// Attempt to write the (decompressed) body to the limited writer
+		err := WriteResponseBody(resp, limitedWriter, int64(httpClientOpts.MaxResponseBodySize))
 
 		// Log only unexpected errors. Ignore nil (success), io.EOF (limit reached),
 		// and io.ErrShortWrite (expected when body > previewSize).
@@ -211,11 +268,27 @@ func BuildCurlCommandWithOpts(bypassPayload payload.BypassPayload, clientOpts *H
 	cmdBuf := curlCmdBuffPool.Get()
 	defer curlCmdBuffPool.Put(cmdBuf)
 
+	// -normalize-path, the PoC sends the path the scanner sent
+	if clientOpts != nil && !clientOpts.DisablePathNormalizing {
+		bypassPayload.RawURI = NormalizeRequestURI(bypassPayload.RawURI)
+	}
+
 	// Build command into buffer
 	cmdBuf.Write(curlCmd)
 	cmdBuf.Write(strSpace)
 	cmdBuf.Write(curlFlags)
 
+	if clientOpts != nil && clientOpts.EnableHTTP2 && bypassPayload.Scheme == "https" {
+		cmdBuf.Write(strSpace)
+		cmdBuf.Write(curlHTTP2)
+	}
+
+	// -accept-encoding, curl decodes the response like the scanner does
+	if clientOpts != nil && clientOpts.AcceptEncoding != "" {
+		cmdBuf.Write(strSpace)
+		cmdBuf.Write(curlCompressed)
+	}
+
 	if bypassPayload.Method != "GET" {
 		cmdBuf.Write(strSpace)
 		cmdBuf.Write(curlMethodX)
@@ -223,56 +296,236 @@ func BuildCurlCommandWithOpts(bypassPayload payload.BypassPayload, clientOpts *H
 		cmdBuf.Write(bytesutil.ToUnsafeBytes(bypassPayload.Method))
 	}
 
+	// Headers in the order they are sent (-preserve-header-order). The Host header stays a -H
+	// header here, curl would otherwise write it first
+	if clientOpts != nil && clientOpts.PreserveHeaderOrder {
+		for _, h := range RequestHeaders(bypassPayload, clientOpts) {
+			appendCurlHeader(cmdBuf, h.Header, h.Value)
+		}
+		appendCurlAcceptEncoding(cmdBuf, bypassPayload, clientOpts)
+		appendCurlBody(cmdBuf, bypassPayload)
+		return appendCurlURL(cmdBuf, bypassPayload, "", dest)
+	}
+
+	// A Host header bypass over plain http is sent to the host of the URL with --connect-to,
+	// so the URL shows the Host value under test
+	urlHost, connectTo := curlConnectTo(bypassPayload, clientOpts)
+	if connectTo != "" {
+		cmdBuf.Write(strSpace)
+		cmdBuf.Write(curlConnectToFlag)
+		cmdBuf.Write(strSpace)
+		appendCurlQuoted(cmdBuf, connectTo)
+	}
+
+	// Payload cookies are sent in the -H Cookie header
+	cliCookie := clientOpts != nil && clientOpts.HeaderOverrides["cookie"]
+
 	// Headers from bypassPayload
 	for _, h := range bypassPayload.Headers {
+		if connectTo != "" && h.Header == "Host" {
+			continue
+		}
+		if cliCookie && isHeaderNameEqual(h.Header, strCookieLower) {
+			continue
+		}
+		appendCurlHeader(cmdBuf, h.Header, h.Value)
+	}
+
+	// Add custom (-H) and auth headers from client options
+	if clientOpts != nil {
+		for _, h := range clientOpts.ParsedHeaders {
+			appendCurlHeader(cmdBuf, h.Name, cliHeaderValue(h, bypassPayload))
+		}
+	}
+	appendCurlAcceptEncoding(cmdBuf, bypassPayload, clientOpts)
+	appendCurlBody(cmdBuf, bypassPayload)
+
+	return appendCurlURL(cmdBuf, bypassPayload, urlHost, dest)
+}
+
+// appendCurlBody writes the request body of the payload as --data-raw, the method stays the one set with -X.
+// Chunked bodies (smuggling probes) are left out, curl would chunk them again
+func appendCurlBody(cmdBuf *bytesutil.ByteBuffer, bypassPayload payload.BypassPayload) {
+	if bypassPayload.Body == "" {
+		return
+	}
+	for _, h := range bypassPayload.Headers {
+		if strings.EqualFold(h.Header, "Transfer-Encoding") {
+			return
+		}
+	}
+	cmdBuf.Write(strSpace)
+	cmdBuf.Write(curlDataRaw)
+	cmdBuf.Write(strSpace)
+	appendCurlQuoted(cmdBuf, bypassPayload.Body)
+}
+
+// appendCurlAcceptEncoding writes the -accept-encoding header, unless a payload or -H header sets its own
+func appendCurlAcceptEncoding(cmdBuf *bytesutil.ByteBuffer, bypassPayload payload.BypassPayload, clientOpts *HTTPClientOptions) {
+	if clientOpts == nil || clientOpts.AcceptEncoding == "" {
+		return
+	}
+	for _, h := range RequestHeaders(bypassPayload, clientOpts) {
+		if isHeaderNameEqual(h.Header, strAcceptEncLower) {
+			return
+		}
+	}
+	appendCurlHeader(cmdBuf, "Accept-Encoding", clientOpts.AcceptEncoding)
+}
+
+// appendCurlHeader writes a quoted -H header to the curl command
+func appendCurlHeader(cmdBuf *bytesutil.ByteBuffer, name, value string) {
+	cmdBuf.Write(strSpace)
+	cmdBuf.Write(curlHeaderH)
+	cmdBuf.Write(strSpace)
+	appendCurlQuoted(cmdBuf, name+": "+value)
+}
+
+// appendCurlURL writes the quoted URL of the payload to the curl command and appends it to dest.
+// urlHost replaces the payload host when set (--connect-to). A RawURI curl can't send as is
+// (fragment, whitespace, non ASCII bytes, no leading slash) goes in --request-target
+func appendCurlURL(cmdBuf *bytesutil.ByteBuffer, bypassPayload payload.BypassPayload, urlHost string, dest []byte) []byte {
+	if urlHost == "" {
+		urlHost = bypassPayload.Host
+	}
+
+	rawURI := bypassPayload.RawURI
+	if curlNeedsRequestTarget(rawURI) {
 		cmdBuf.Write(strSpace)
-		cmdBuf.Write(curlHeaderH)
+		cmdBuf.Write(curlRequestTarget)
 		cmdBuf.Write(strSpace)
+		appendCurlQuoted(cmdBuf, rawURI)
+		rawURI = "/"
+	}
+
+	// URL construction
+	cmdBuf.Write(strSpace)
+	appendCurlQuoted(cmdBuf, bypassPayload.Scheme+string(strSchemeDelim)+urlHost+rawURI)
+
+	// Append to existing slice instead of creating new one
+	return append(dest[:0], cmdBuf.B...)
+}
+
+// appendCurlQuoted writes s single quoted for the shell. Values with a single quote or a non
+// printable byte (e.g. a trailing tab in the Host header) are written as an ANSI-C $'...'
+// string instead, the stored curl command is sanitized and would lose them otherwise
+func appendCurlQuoted(cmdBuf *bytesutil.ByteBuffer, s string) {
+	ansi := false
+	for i := 0; i < len(s); i++ {
+		if b := s[i]; b == '\'' || b < 0x20 || b >= 0x7f {
+			ansi = true
+			break
+		}
+	}
+
+	if !ansi {
 		cmdBuf.Write(strSingleQuote)
-		cmdBuf.Write(bytesutil.ToUnsafeBytes(h.Header))
-		cmdBuf.Write(strColonSpace)
-		cmdBuf.Write(bytesutil.ToUnsafeBytes(h.Value))
+		cmdBuf.B = append(cmdBuf.B, s...)
 		cmdBuf.Write(strSingleQuote)
+		return
 	}
 
-	// Add custom headers from client options
-	if clientOpts != nil && len(clientOpts.CustomHTTPHeaders) > 0 {
-		for _, header := range clientOpts.CustomHTTPHeaders {
-			colonIdx := strings.Index(header, ":")
-			if colonIdx != -1 {
-				headerName := strings.TrimSpace(header[:colonIdx])
-				headerValue := strings.TrimSpace(header[colonIdx+1:])
-
-				cmdBuf.Write(strSpace)
-				cmdBuf.Write(curlHeaderH)
-				cmdBuf.Write(strSpace)
-				cmdBuf.Write(strSingleQuote)
-				cmdBuf.Write(bytesutil.ToUnsafeBytes(headerName))
-				cmdBuf.Write(strColonSpace)
-				cmdBuf.Write(bytesutil.ToUnsafeBytes(headerValue))
-				cmdBuf.Write(strSingleQuote)
-			}
+	cmdBuf.B = append(cmdBuf.B, "$'"...)
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case b == '\\':
+			cmdBuf.B = append(cmdBuf.B, `\\`...)
+		case b == '\'':
+			cmdBuf.B = append(cmdBuf.B, `\'`...)
+		case b == '\t':
+			cmdBuf.B = append(cmdBuf.B, `\t`...)
+		case b == '\r':
+			cmdBuf.B = append(cmdBuf.B, `\r`...)
+		case b == '\n':
+			cmdBuf.B = append(cmdBuf.B, `\n`...)
+		case b < 0x20 || b >= 0x7f:
+			cmdBuf.B = append(cmdBuf.B, '\\', 'x', hexDigits[b>>4], hexDigits[b&0xf])
+		default:
+			cmdBuf.B = append(cmdBuf.B, b)
 		}
 	}
-
-	// URL construction
-	cmdBuf.Write(strSpace)
 	cmdBuf.Write(strSingleQuote)
+}
 
-	// Scheme
-	cmdBuf.Write(bytesutil.ToUnsafeBytes(bypassPayload.Scheme))
-	cmdBuf.Write(strSchemeDelim)
+// curlNeedsRequestTarget reports whether curl would alter rawURI in the URL: it drops the
+// fragment and encodes whitespace and non ASCII bytes, even with --path-as-is
+func curlNeedsRequestTarget(rawURI string) bool {
+	if !strings.HasPrefix(rawURI, "/") {
+		return rawURI != ""
+	}
+	for i := 0; i < len(rawURI); i++ {
+		if b := rawURI[i]; b == '#' || b <= 0x20 || b >= 0x7f {
+			return true
+		}
+	}
+	return false
+}
 
-	// Host
-	cmdBuf.Write(bytesutil.ToUnsafeBytes(bypassPayload.Host))
+// curlConnectTo returns the URL host and the --connect-to value reproducing a Host header
+// bypass, or empty strings to keep the -H Host header. Only plain http is rewritten: over
+// https curl would send the Host value as SNI, while the request is sent without it (IP) or
+// with the host of the URL. The Host value must survive curl unchanged, so values with
+// whitespace or the default :80 port (which curl strips) are kept as -H headers
+func curlConnectTo(bypassPayload payload.BypassPayload, clientOpts *HTTPClientOptions) (string, string) {
+	if bypassPayload.Scheme != "http" || strings.Contains(bypassPayload.Host, "%") {
+		return "", ""
+	}
 
-	// RawURI
-	cmdBuf.Write(bytesutil.ToUnsafeBytes(bypassPayload.RawURI))
+	hostValue := ""
+	hosts := 0
+	for _, h := range bypassPayload.Headers {
+		if strings.EqualFold(h.Header, "Host") {
+			hosts++
+			if h.Header == "Host" {
+				hostValue = h.Value
+			}
+		}
+	}
+	if clientOpts != nil {
+		for _, h := range clientOpts.ParsedHeaders {
+			if strings.EqualFold(h.Name, "Host") {
+				hosts++
+			}
+		}
+	}
+	if hosts != 1 || hostValue == bypassPayload.Host || !curlURLHost(hostValue) {
+		return "", ""
+	}
 
-	cmdBuf.Write(strSingleQuote)
+	addr := bypassPayload.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(strings.Trim(addr, "[]"), "80")
+	}
+	return hostValue, "::" + addr
+}
 
-	// Append to existing slice instead of creating new one
-	return append(dest[:0], cmdBuf.B...)
+// curlURLHost reports whether host is sent by curl as is in the Host header when used as the
+// host of a http URL: a hostname or a bracketed IPv6 address, with a port other than 80
+func curlURLHost(host string) bool {
+	name, port := host, ""
+	if strings.HasPrefix(host, "[") {
+		end := strings.IndexByte(host, ']')
+		if end == -1 {
+			return false
+		}
+		name, port = host[1:end], host[end+1:]
+		if name == "" || strings.Trim(name, "0123456789abcdefABCDEF:.") != "" {
+			return false
+		}
+	} else {
+		if i := strings.LastIndexByte(host, ':'); i != -1 {
+			name, port = host[:i], host[i:]
+		}
+		if name == "" || strings.Trim(name, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-_") != "" {
+			return false
+		}
+	}
+
+	if port == "" {
+		return true
+	}
+	port = strings.TrimPrefix(port, ":")
+	return port != "" && port != "80" && strings.Trim(port, "0123456789") == "" && len(port) <= 5
 }
 
 // GetResponseHeaders gets all HTTP headers including values from the response
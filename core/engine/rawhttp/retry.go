@@ -96,3 +96,16 @@ func IsRetryableError(err error) RetryDecision {
 
 	return RetryDecision{false, NoRetry}
 }
+
+// IsResponseTimeoutError reports whether err is a request sent but not answered within the timeout,
+// dial and TLS handshake timeouts (the server was never reached) don't count
+func IsResponseTimeoutError(err error) bool {
+	if err == nil || errors.Is(err, fasthttp.ErrDialTimeout) || errors.Is(err, fasthttp.ErrTLSHandshakeTimeout) {
+		return false
+	}
+	if errors.Is(err, fasthttp.ErrTimeout) {
+		return true
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "timeout") || strings.Contains(errStr, "timed out")
+}
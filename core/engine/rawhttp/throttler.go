@@ -8,17 +8,22 @@ package rawhttp
 import (
 	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/valyala/fasthttp"
 )
 
 type ThrottleConfig struct {
 	BaseRequestDelay        time.Duration
 	MaxRequestDelay         time.Duration
-	ExponentialRequestDelay float64 // Exponential request delay
-	RequestDelayJitter      int     // For random delay, percentage of variation (0-100)
-	ThrottleOnStatusCodes   []int   // Status codes that trigger throttling
+	ExponentialRequestDelay float64       // Exponential request delay
+	RequestDelayJitter      int           // For random delay, percentage of variation (0-100)
+	ThrottleOnStatusCodes   []int         // Status codes that trigger throttling
+	MaxRetryAfter           time.Duration // Cap for delays requested via Retry-After
 }
 
 // Throttler handles request rate limiting
@@ -27,6 +32,7 @@ type Throttler struct {
 	counter      atomic.Int32 // Counts consecutive throttled responses
 	lastDelay    atomic.Int64 // Last calculated delay in nanoseconds
 	isThrottling atomic.Bool  // Indicates if auto throttling is currently active
+	retryAfter   atomic.Int64 // Retry-After deadline in unix nanoseconds (0 = none)
 	mu           sync.RWMutex
 	randSource   *rand.Rand
 	randMu       sync.Mutex
@@ -40,6 +46,7 @@ func DefaultThrottleConfig() *ThrottleConfig {
 		RequestDelayJitter:      20,  // 20% of the base request delay
 		ExponentialRequestDelay: 2.0, // Each throttle doubles the delay
 		ThrottleOnStatusCodes:   []int{429, 503, 507},
+		MaxRetryAfter:           30 * time.Second,
 	}
 }
 
@@ -116,11 +123,64 @@ func (t *Throttler) ThrottleRequest() {
 
 	// Get delay under lock to ensure consistency
 	delay := t.GetCurrentThrottleRate()
+
+	// A pending Retry-After wins over the computed backoff if it's longer
+	if deadline := t.retryAfter.Load(); deadline > 0 {
+		if wait := time.Until(time.Unix(0, deadline)); wait > delay {
+			delay = wait
+		}
+	}
+
 	if delay > 0 {
 		time.Sleep(delay)
 	}
 }
 
+// SetRetryAfter makes throttled requests wait for the given delay (from a Retry-After header),
+// capped at MaxRetryAfter. A later deadline already set is kept.
+func (t *Throttler) SetRetryAfter(delay time.Duration) {
+	if t == nil || delay <= 0 {
+		return
+	}
+
+	if maxDelay := t.config.Load().MaxRetryAfter; maxDelay > 0 {
+		delay = min(delay, maxDelay)
+	}
+
+	deadline := time.Now().Add(delay).UnixNano()
+	for {
+		current := t.retryAfter.Load()
+		if current >= deadline || t.retryAfter.CompareAndSwap(current, deadline) {
+			return
+		}
+	}
+}
+
+// parseRetryAfter returns the delay requested by the Retry-After response header of a 429 or 503,
+// either delay-seconds or an HTTP-date. Returns 0 for other status codes, or if absent, invalid or in the past.
+func parseRetryAfter(resp *fasthttp.Response) time.Duration {
+	if statusCode := resp.StatusCode(); statusCode != fasthttp.StatusTooManyRequests && statusCode != fasthttp.StatusServiceUnavailable {
+		return 0
+	}
+
+	value := strings.TrimSpace(string(resp.Header.Peek("Retry-After")))
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := fasthttp.ParseHTTPDate([]byte(value)); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
+
 // UpdateThrottleConfig safely updates throttle configuration
 func (t *Throttler) UpdateThrottlerConfig(config *ThrottleConfig) {
 	t.config.Store(config)
@@ -158,4 +218,5 @@ func (t *Throttler) ResetThrottler() {
 	}
 	t.counter.Store(0)
 	t.lastDelay.Store(0)
+	t.retryAfter.Store(0)
 }
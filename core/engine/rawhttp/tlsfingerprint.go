@@ -0,0 +1,129 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	utls "github.com/refraction-networking/utls"
+	"github.com/valyala/fasthttp"
+)
+
+// TLSFingerprints lists the supported -tls-fingerprint values
+var TLSFingerprints = []string{"chrome", "firefox", "safari", "edge", "ios", "random"}
+
+// IsValidTLSFingerprint reports whether name is a supported TLS fingerprint
+func IsValidTLSFingerprint(name string) bool {
+	return slices.Contains(TLSFingerprints, strings.ToLower(name))
+}
+
+// tlsFingerprintHelloID maps a fingerprint name to the uTLS ClientHello it mimics
+func tlsFingerprintHelloID(name string) (utls.ClientHelloID, error) {
+	switch strings.ToLower(name) {
+	case "chrome":
+		return utls.HelloChrome_Auto, nil
+	case "firefox":
+		return utls.HelloFirefox_Auto, nil
+	case "safari":
+		return utls.HelloSafari_Auto, nil
+	case "edge":
+		return utls.HelloEdge_Auto, nil
+	case "ios":
+		return utls.HelloIOS_Auto, nil
+	case "random":
+		// No ALPN, the server can't pick h2
+		return utls.HelloRandomizedNoALPN, nil
+	}
+	return utls.ClientHelloID{}, fmt.Errorf("unsupported TLS fingerprint %q (supported: %s)", name, strings.Join(TLSFingerprints, ", "))
+}
+
+// helloSpecHTTP11 returns the ClientHello spec of a browser with ALPN restricted to http/1.1,
+// browsers offer h2 first but fasthttp only speaks HTTP/1.x
+func helloSpecHTTP11(helloID utls.ClientHelloID) (*utls.ClientHelloSpec, error) {
+	spec, err := utls.UTLSIdToSpec(helloID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ext := range spec.Extensions {
+		if alpn, ok := ext.(*utls.ALPNExtension); ok {
+			alpn.AlpnProtocols = []string{"http/1.1"}
+		}
+	}
+	return &spec, nil
+}
+
+// CreateTLSFingerprintDialer wraps dial (direct or proxy dialer) to perform a uTLS handshake
+// mimicking the given browser fingerprint. The returned conn is already TLS, meant for fasthttp.Client.DialTLS.
+// serverName is sent as SNI instead of the host of the dial address when not empty (-sni).
+func CreateTLSFingerprintDialer(dial fasthttp.DialFunc, fingerprint string, timeout time.Duration, clientCertFile, clientKeyFile, serverName string) (fasthttp.DialFunc, error) {
+	helloID, err := tlsFingerprintHelloID(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Browser fingerprints are applied as a custom spec (ALPN patched), randomized ones as is
+	customSpec := helloID != utls.HelloRandomizedNoALPN
+	if customSpec {
+		if _, err := helloSpecHTTP11(helloID); err != nil {
+			return nil, fmt.Errorf("failed to build %s ClientHello: %v", fingerprint, err)
+		}
+	}
+
+	var certificates []utls.Certificate
+	if clientCertFile != "" && clientKeyFile != "" {
+		cert, err := utls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		certificates = []utls.Certificate{cert}
+	}
+
+	return func(addr string) (net.Conn, error) {
+		rawConn, err := dial(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		sni := serverName
+		if sni == "" {
+			sni = tlsServerName(addr)
+		}
+
+		config := &utls.Config{
+			ServerName:         sni,
+			InsecureSkipVerify: true,
+			Certificates:       certificates,
+		}
+
+		var uconn *utls.UConn
+		if customSpec {
+			uconn = utls.UClient(rawConn, config, utls.HelloCustom)
+			// Extensions are stateful, so every conn gets a fresh spec
+			spec, _ := helloSpecHTTP11(helloID)
+			if err := uconn.ApplyPreset(spec); err != nil {
+				rawConn.Close()
+				return nil, fmt.Errorf("[Client.tlsFingerprintDial] failed to apply %s ClientHello: %v", fingerprint, err)
+			}
+		} else {
+			uconn = utls.UClient(rawConn, config, helloID)
+		}
+
+		if timeout > 0 {
+			uconn.SetDeadline(time.Now().Add(timeout))
+		}
+		if err := uconn.Handshake(); err != nil {
+			rawConn.Close()
+			return nil, fmt.Errorf("[Client.tlsFingerprintDial] TLS handshake with %s failed: %v", addr, err)
+		}
+		uconn.SetDeadline(time.Time{})
+
+		return uconn, nil
+	}, nil
+}
@@ -0,0 +1,39 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package rawhttp
+
+import (
+	"fmt"
+
+	"github.com/slicingmelon/gobypass403/core/utils/helpers"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// Wire dump modes (-dump-wire)
+const (
+	WireDumpAll     = "all"     // Every request sent, including the failed ones
+	WireDumpMatched = "matched" // Only the requests whose response passed the result filters
+)
+
+// WireDumpModes are the accepted -dump-wire values
+var WireDumpModes = []string{WireDumpAll, WireDumpMatched}
+
+// FormatWireDump returns the raw request bytes built by BuildRawRequest followed by the response
+// head (status line and headers) or the request error. Non-printable bytes are escaped (\x00),
+// CR and LF are kept so the request reads as it was sent.
+func FormatWireDump(rawRequest []byte, responseHead []byte, reqErr error) string {
+	dump := fmt.Sprintf(">>> Request (%d bytes)\n%s", len(rawRequest), helpers.SanitizeNonPrintableBytes(rawRequest))
+	if reqErr != nil {
+		return dump + fmt.Sprintf("\n<<< Error\n%v", reqErr)
+	}
+	return dump + "\n<<< Response head\n" + helpers.SanitizeNonPrintableBytes(responseHead)
+}
+
+// LogWireDump logs the wire dump of a request, with its module and debug token
+func LogWireDump(bypassModule string, debugToken string, rawRequest []byte, responseHead []byte, reqErr error) {
+	GB403Logger.Info().BypassModule(bypassModule).DebugToken(debugToken).Msgf("Wire dump\n%s",
+		FormatWireDump(rawRequest, responseHead, reqErr))
+}
@@ -10,11 +10,14 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"net/netip"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"fortio.org/progressbar"
 	"github.com/slicingmelon/go-rawurlparser"
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 	"github.com/valyala/fasthttp"
@@ -90,9 +93,28 @@ which maintains resolved addresses for 120 minutes and implements
 round-robin selection for multiple IPs.
 */
 type ReconService struct {
-	dialer     *fasthttp.TCPDialer
-	dnsServers []string
-	cache      *ReconCache
+	dialer      *fasthttp.TCPDialer
+	dnsServers  []string
+	cache       *ReconCache
+	opts        *ReconOptions
+	failedHosts map[string]string // host -> reason, filled by Run
+	failedMu    sync.Mutex
+}
+
+// ReconOptions contains configuration options for the ReconService
+type ReconOptions struct {
+	Concurrency        int           // Max hosts probed in parallel
+	Timeout            time.Duration // Overall timeout of a Run (0 means no timeout)
+	DisableProgressBar bool
+}
+
+// DefaultReconOptions returns the default recon options
+func DefaultReconOptions() *ReconOptions {
+	return &ReconOptions{
+		Concurrency:        50,
+		Timeout:            0,
+		DisableProgressBar: false,
+	}
 }
 
 type ReconResult struct {
@@ -103,19 +125,27 @@ type ReconResult struct {
 }
 
 func NewReconService() *ReconService {
+	return NewReconServiceWithOptions(DefaultReconOptions())
+}
+
+// NewReconServiceWithOptions creates a ReconService with custom probing options
+func NewReconServiceWithOptions(opts *ReconOptions) *ReconService {
+	if opts == nil {
+		opts = DefaultReconOptions()
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultReconOptions().Concurrency
+	}
+
 	// Get the shared dialer that already has our custom resolver
 	dialer := GetSharedDialer()
 
 	return &ReconService{
-		dialer: dialer,
-		dnsServers: []string{
-			"1.1.1.1:53",                // Cloudflare
-			"9.9.9.9:53",                // Quad9
-			"208.67.222.222:53",         // OpenDNS
-			"[2606:4700:4700::1111]:53", // Cloudflare IPv6
-			"[2620:fe::fe]:53",          // Quad9 IPv6
-		},
-		cache: NewReconCache(),
+		opts:        opts,
+		failedHosts: make(map[string]string),
+		dialer:      dialer,
+		dnsServers:  dnsServers,
+		cache:       NewReconCache(),
 	}
 }
 
@@ -144,8 +174,12 @@ func (r *ReconService) ProcessHost(input string) (*ReconResult, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex // To protect concurrent access to result
 
+	// IPv6 literals can carry a zone id (fe80::1%eth0), which net.ParseIP rejects
+	ipAddr, ipErr := netip.ParseAddr(host)
+	isIP := ipErr == nil
+
 	// Only do CNAME lookup if it's not an IP address
-	if net.ParseIP(host) == nil {
+	if !isIP {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -161,8 +195,8 @@ func (r *ReconService) ProcessHost(input string) (*ReconResult, error) {
 
 	// Continue with existing IP resolution code...
 	var ips []net.IP
-	if ip := net.ParseIP(host); ip != nil {
-		ips = []net.IP{ip}
+	if isIP {
+		ips = []net.IP{ipAddr.AsSlice()}
 	} else {
 		ips, err = r.ResolveDomain(host)
 		if err != nil {
@@ -187,6 +221,9 @@ func (r *ReconService) ProcessHost(input string) (*ReconResult, error) {
 	// Create a wait group for parallel port probing
 	for _, ip := range ips {
 		ipStr := ip.String()
+		if isIP && ipAddr.Zone() != "" {
+			ipStr += "%" + ipAddr.Zone()
+		}
 		services := result.IPv4Services
 		if ip.To4() == nil {
 			services = result.IPv6Services
@@ -226,9 +263,14 @@ func (r *ReconService) ProcessHost(input string) (*ReconResult, error) {
 }
 
 func (r *ReconService) Run(urls []string) error {
-	maxWorkers := 50
-	jobs := make(chan string, len(urls))
-	results := make(chan error, len(urls))
+	maxWorkers := r.opts.Concurrency
+
+	ctx := context.Background()
+	if r.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+	}
 
 	// Process unique hosts first to avoid duplicate work
 	uniqueHosts := make(map[string]bool)
@@ -238,6 +280,41 @@ func (r *ReconService) Run(urls []string) error {
 		}
 	}
 
+	totalHosts := len(uniqueHosts)
+	jobs := make(chan string, totalHosts)
+
+	var bar *progressbar.Bar
+	if !r.opts.DisableProgressBar && totalHosts > 0 {
+		cfg := progressbar.DefaultConfig()
+		cfg.Prefix = "[Recon] probing"
+		cfg.UseColors = GB403Logger.ColorEnabled()
+		cfg.ExtraLines = 1
+		cfg.Color = progressbar.GreenBar
+		bar = cfg.NewBar()
+	}
+
+	var processed, failed atomic.Int32
+
+	// Failures of this run only, Run can be called more than once (e.g. substitute hosts)
+	runFailed := make(map[string]string)
+	var runFailedMu sync.Mutex
+	addFailed := func(host, reason string) {
+		runFailedMu.Lock()
+		runFailed[host] = reason
+		runFailedMu.Unlock()
+		r.addFailedHost(host, reason)
+		failed.Add(1)
+	}
+	updateProgress := func() {
+		if bar == nil {
+			return
+		}
+		done := processed.Load()
+		bar.WriteAbove(fmt.Sprintf("Concurrency [%d] | Probed %d/%d | Failed %d    ",
+			maxWorkers, done, totalHosts, failed.Load()))
+		bar.Progress(float64(done) / float64(totalHosts) * 100.0)
+	}
+
 	// Start workers before feeding jobs
 	var wg sync.WaitGroup
 	for i := 0; i < maxWorkers; i++ {
@@ -245,19 +322,26 @@ func (r *ReconService) Run(urls []string) error {
 		go func() {
 			defer wg.Done()
 			for host := range jobs {
-				result, err := r.ProcessHost(host)
-				if err != nil {
-					select {
-					case results <- fmt.Errorf("host %s: %v", host, err):
-					default:
-					}
+				// Overall timeout reached, drain the remaining hosts without probing them
+				if ctx.Err() != nil {
+					addFailed(host, "recon timeout reached before the host was probed")
+					processed.Add(1)
+					updateProgress()
 					continue
 				}
 
-				// Cache the result after successful processing
-				if err := r.cache.Set(host, result); err != nil {
+				result, err := r.ProcessHost(host)
+				if err != nil {
+					addFailed(host, err.Error())
+				} else if len(result.IPv4Services) == 0 && len(result.IPv6Services) == 0 {
+					addFailed(host, "no HTTP/HTTPS service found")
+				} else if err := r.cache.Set(host, result); err != nil {
+					// Cache the result after successful processing
 					GB403Logger.Error().Msgf("Failed to cache %s: %v\n", host, err)
 				}
+
+				processed.Add(1)
+				updateProgress()
 			}
 		}()
 	}
@@ -268,21 +352,42 @@ func (r *ReconService) Run(urls []string) error {
 	}
 	close(jobs)
 
-	// Wait and process results
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+	wg.Wait()
+	if bar != nil {
+		bar.End()
+		fmt.Println()
+	}
 
-	for err := range results {
-		if err != nil {
-			GB403Logger.Error().Msgf("%v\n", err)
+	// Surface per-host failures so users know why some URLs get dropped before scanning
+	if n := failed.Load(); n > 0 {
+		GB403Logger.Warning().Msgf("%d/%d hosts failed recon and will be skipped:\n", n, totalHosts)
+		for host, reason := range runFailed {
+			GB403Logger.Warning().Msgf("  %s: %s\n", host, reason)
 		}
 	}
 
 	return nil
 }
 
+// addFailedHost records why a host failed recon
+func (r *ReconService) addFailedHost(host, reason string) {
+	r.failedMu.Lock()
+	r.failedHosts[host] = reason
+	r.failedMu.Unlock()
+}
+
+// GetFailedHosts returns a copy of the hosts that failed recon and the reason
+func (r *ReconService) GetFailedHosts() map[string]string {
+	r.failedMu.Lock()
+	defer r.failedMu.Unlock()
+
+	failed := make(map[string]string, len(r.failedHosts))
+	for host, reason := range r.failedHosts {
+		failed[host] = reason
+	}
+	return failed
+}
+
 // ProbePort probes a port on an IP address and returns the protocol (http or https)
 func (r *ReconService) ProbePort(ip string, port string, host string) (string, bool) {
 	addr := net.JoinHostPort(ip, port)
@@ -295,7 +400,7 @@ func (r *ReconService) ProbePort(ip string, port string, host string) (string, b
 	}
 
 	// Try HTTPS first
-	conn, err := ipProbeDialer.Dial(addr)
+	conn, err := ipProbeDialer.DialDualStack(addr)
 	if err != nil {
 		GB403Logger.Verbose().Msgf("TLS dial error for %s: %v", addr, err)
 	} else {
@@ -303,7 +408,7 @@ func (r *ReconService) ProbePort(ip string, port string, host string) (string, b
 		tlsConfig := &tls.Config{
 			InsecureSkipVerify: true,
 			MinVersion:         tls.VersionTLS10,
-			ServerName:         host,
+			ServerName:         stripZone(host),
 		}
 
 		tlsConn := tls.Client(conn, tlsConfig)
@@ -320,13 +425,19 @@ func (r *ReconService) ProbePort(ip string, port string, host string) (string, b
 	}
 
 	// Try HTTP
-	conn2, err := ipProbeDialer.Dial(addr)
+	conn2, err := ipProbeDialer.DialDualStack(addr)
 	if err != nil {
 		return "", false
 	}
 	defer conn2.Close()
 
-	_, err = fmt.Fprintf(conn2, "GET / HTTP/1.1\r\nHost: %s\r\nUser-Agent: Mozilla/5.0\r\nConnection: close\r\n\r\n", host)
+	// IPv6 literals must be bracketed in the Host header
+	hostHeader := stripZone(host)
+	if strings.Contains(hostHeader, ":") {
+		hostHeader = "[" + hostHeader + "]"
+	}
+
+	_, err = fmt.Fprintf(conn2, "GET / HTTP/1.1\r\nHost: %s\r\nUser-Agent: Mozilla/5.0\r\nConnection: close\r\n\r\n", hostHeader)
 	if err != nil {
 		return "", false // Port is open but not HTTP/HTTPS
 	}
@@ -399,7 +510,25 @@ func extractHostAndPort(input string) (host string, port string, err error) {
 	host, port, err = net.SplitHostPort(input)
 	if err != nil {
 		// No port specified, just return the host
-		return input, "", nil
+		return BareHost(input), "", nil
+	}
+	return BareHost(host), port, nil
+}
+
+// BareHost returns a URL host without the IPv6 brackets and with the URL encoded
+// zone id decoded, e.g. [fe80::1%25eth0] -> fe80::1%eth0. Recon results are cached
+// under this form.
+func BareHost(host string) string {
+	if !strings.HasPrefix(host, "[") || !strings.HasSuffix(host, "]") {
+		return host
+	}
+	return strings.Replace(host[1:len(host)-1], "%25", "%", 1)
+}
+
+// stripZone removes the zone id of an IPv6 literal (fe80::1%eth0 -> fe80::1)
+func stripZone(host string) string {
+	if i := strings.IndexByte(host, '%'); i != -1 {
+		return host[:i]
 	}
-	return host, port, nil
+	return host
 }
@@ -41,6 +41,14 @@ func (c *ReconCache) Set(hostname string, result *ReconResult) error {
 	return nil
 }
 
+// Purge drops all cached recon results and frees the cache memory
+func (c *ReconCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache.Reset()
+}
+
 func (c *ReconCache) Get(hostname string) (*ReconResult, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
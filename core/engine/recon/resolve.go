@@ -17,14 +17,27 @@ import (
 	"github.com/valyala/fasthttp"
 )
 
+// DefaultDNSServers are queried in parallel with the system resolver and DoH
+var DefaultDNSServers = []string{
+	"1.1.1.1:53",                // Cloudflare
+	"9.9.9.9:53",                // Quad9
+	"208.67.222.222:53",         // OpenDNS
+	"[2606:4700:4700::1111]:53", // Cloudflare IPv6
+	"[2620:fe::fe]:53",          // Quad9 IPv6
+}
+
 var (
 	sharedDialer *fasthttp.TCPDialer
 	onceDialer   sync.Once
+
+	dnsServers       = DefaultDNSServers
+	customDNSServers bool // -resolvers, only dnsServers are queried
 )
 
 type CustomResolver struct {
-	dohClient  *doh.DoH
-	dnsServers []string
+	dohClient   *doh.DoH
+	dnsServers  []string
+	serversOnly bool // Only query dnsServers, no system resolver nor DoH
 }
 
 type DNSResults struct {
@@ -49,31 +62,60 @@ func NewCustomResolver(dnsServers []string) *CustomResolver {
 	}
 }
 
+// SetDNSServers makes recon (and the HTTP client, see rawhttp.SetDNSResolver) query only these
+// DNS servers (-resolvers), e.g. an internal resolver for split-horizon DNS.
+// Must be called before the first resolution, an empty list keeps the defaults.
+func SetDNSServers(servers []string) {
+	if len(servers) == 0 {
+		return
+	}
+	dnsServers = servers
+	customDNSServers = true
+}
+
+// NewDNSResolver returns a resolver querying the default DNS servers, system resolver and DoH,
+// or only the servers set with SetDNSServers
+func NewDNSResolver() *CustomResolver {
+	r := NewCustomResolver(dnsServers)
+	r.serversOnly = customDNSServers
+	return r
+}
+
 // This gets the core dialer instance
 func GetSharedDialer() *fasthttp.TCPDialer {
 	onceDialer.Do(func() {
 		sharedDialer = &fasthttp.TCPDialer{
-			Concurrency:      2048,
-			DNSCacheDuration: 120 * time.Minute,
-			Resolver: NewCustomResolver([]string{
-				"1.1.1.1:53",                // Cloudflare
-				"9.9.9.9:53",                // Quad9
-				"208.67.222.222:53",         // OpenDNS
-				"[2606:4700:4700::1111]:53", // Cloudflare IPv6
-				"[2620:fe::fe]:53",          // Quad9 IPv6
-			}),
+			Concurrency:          2048,
+			DNSCacheDuration:     120 * time.Minute,
+			Resolver:             NewDNSResolver(),
 			DisableDNSResolution: false,
 		}
 	})
 	return sharedDialer
 }
 
+// serverResolver returns a Go resolver sending its queries to a single DNS server (ip:port)
+func serverResolver(server string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{Timeout: 2 * time.Second}
+			return d.DialContext(ctx, "udp", server)
+		},
+	}
+}
+
 // LookupIPAddr resolves a host and returns an array of IP addresses
 // This is the custom resolver that implements parallel DNS resolution strategy
 func (r *CustomResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	expectedResponses := len(r.dnsServers) // each DNS server
+	if !r.serversOnly {
+		expectedResponses += 2 // system + DoH
+	}
+
 	// Create new channels for this call
-	resolverChan := make(chan []net.IPAddr, 3)
-	errChan := make(chan error, 3)
+	resolverChan := make(chan []net.IPAddr, expectedResponses)
+	errChan := make(chan error, expectedResponses)
 
 	// Use a WaitGroup to track goroutines
 	var wg sync.WaitGroup
@@ -83,48 +125,42 @@ func (r *CustomResolver) LookupIPAddr(ctx context.Context, host string) ([]net.I
 		close(errChan)
 	}()
 
-	expectedResponses := len(r.dnsServers) + 2 // system + DoH + each DNS server
-
-	// 1. System resolver (parallel)
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		var systemIPs []net.IPAddr
-		if ips4, err := net.DefaultResolver.LookupIP(ctx, "ip4", host); err == nil {
-			for _, ip := range ips4 {
-				systemIPs = append(systemIPs, net.IPAddr{IP: ip})
-			}
-		}
-		if ips6, err := net.DefaultResolver.LookupIP(ctx, "ip6", host); err == nil {
-			for _, ip := range ips6 {
-				systemIPs = append(systemIPs, net.IPAddr{IP: ip})
+	// 1. System resolver (parallel), skipped with -resolvers
+	if !r.serversOnly {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var systemIPs []net.IPAddr
+			if ips4, err := net.DefaultResolver.LookupIP(ctx, "ip4", host); err == nil {
+				for _, ip := range ips4 {
+					systemIPs = append(systemIPs, net.IPAddr{IP: ip})
+				}
 			}
-		}
-		if len(systemIPs) > 0 {
-			select {
-			case resolverChan <- systemIPs:
-			case <-ctx.Done():
+			if ips6, err := net.DefaultResolver.LookupIP(ctx, "ip6", host); err == nil {
+				for _, ip := range ips6 {
+					systemIPs = append(systemIPs, net.IPAddr{IP: ip})
+				}
 			}
-		} else {
-			select {
-			case errChan <- fmt.Errorf("system resolver returned no IPs"):
-			case <-ctx.Done():
+			if len(systemIPs) > 0 {
+				select {
+				case resolverChan <- systemIPs:
+				case <-ctx.Done():
+				}
+			} else {
+				select {
+				case errChan <- fmt.Errorf("system resolver returned no IPs"):
+				case <-ctx.Done():
+				}
 			}
-		}
-	}()
+		}()
+	}
 
 	// 2. Custom DNS servers (parallel)
 	for _, server := range r.dnsServers {
 		wg.Add(1)
 		go func(server string) {
 			defer wg.Done()
-			resolver := &net.Resolver{
-				PreferGo: true,
-				Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-					d := net.Dialer{Timeout: 2 * time.Second}
-					return d.DialContext(ctx, "udp", server)
-				},
-			}
+			resolver := serverResolver(server)
 			var dnsIPs []net.IPAddr
 			if ips4, err := resolver.LookupIP(ctx, "ip4", host); err == nil {
 				for _, ip := range ips4 {
@@ -150,45 +186,47 @@ func (r *CustomResolver) LookupIPAddr(ctx context.Context, host string) ([]net.I
 		}(server)
 	}
 
-	// 3. DoH resolution (parallel)
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		var dohIPs []net.IPAddr
-		domain := dns.Domain(host)
-
-		// Query A records
-		rspA, err := r.dohClient.Query(ctx, domain, dns.TypeA)
-		if err == nil && rspA != nil && len(rspA.Answer) > 0 {
-			for _, answer := range rspA.Answer {
-				if ip := net.ParseIP(answer.Data); ip != nil {
-					dohIPs = append(dohIPs, net.IPAddr{IP: ip})
+	// 3. DoH resolution (parallel), skipped with -resolvers
+	if !r.serversOnly {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var dohIPs []net.IPAddr
+			domain := dns.Domain(host)
+
+			// Query A records
+			rspA, err := r.dohClient.Query(ctx, domain, dns.TypeA)
+			if err == nil && rspA != nil && len(rspA.Answer) > 0 {
+				for _, answer := range rspA.Answer {
+					if ip := net.ParseIP(answer.Data); ip != nil {
+						dohIPs = append(dohIPs, net.IPAddr{IP: ip})
+					}
 				}
 			}
-		}
 
-		// Query AAAA records
-		rspAAAA, err := r.dohClient.Query(ctx, domain, dns.TypeAAAA)
-		if err == nil && rspAAAA != nil && len(rspAAAA.Answer) > 0 {
-			for _, answer := range rspAAAA.Answer {
-				if ip := net.ParseIP(answer.Data); ip != nil {
-					dohIPs = append(dohIPs, net.IPAddr{IP: ip})
+			// Query AAAA records
+			rspAAAA, err := r.dohClient.Query(ctx, domain, dns.TypeAAAA)
+			if err == nil && rspAAAA != nil && len(rspAAAA.Answer) > 0 {
+				for _, answer := range rspAAAA.Answer {
+					if ip := net.ParseIP(answer.Data); ip != nil {
+						dohIPs = append(dohIPs, net.IPAddr{IP: ip})
+					}
 				}
 			}
-		}
 
-		if len(dohIPs) > 0 {
-			select {
-			case resolverChan <- dohIPs:
-			case <-ctx.Done():
-			}
-		} else {
-			select {
-			case errChan <- fmt.Errorf("DoH resolution returned no IPs"):
-			case <-ctx.Done():
+			if len(dohIPs) > 0 {
+				select {
+				case resolverChan <- dohIPs:
+				case <-ctx.Done():
+				}
+			} else {
+				select {
+				case errChan <- fmt.Errorf("DoH resolution returned no IPs"):
+				case <-ctx.Done():
+				}
 			}
-		}
-	}()
+		}()
+	}
 
 	// Collector to aggregate unique IPs
 	seen := make(map[string]struct{})
@@ -196,6 +234,7 @@ func (r *CustomResolver) LookupIPAddr(ctx context.Context, host string) ([]net.I
 	var ips []net.IPAddr
 
 	// Wait for results or timeout
+collect:
 	for {
 		select {
 		case resolvedIPs := <-resolverChan:
@@ -218,7 +257,7 @@ func (r *CustomResolver) LookupIPAddr(ctx context.Context, host string) ([]net.I
 
 		// Break when we have results or all resolvers have responded
 		if len(ips) > 0 || responses >= expectedResponses {
-			break
+			break collect
 		}
 	}
 
@@ -229,6 +268,17 @@ func (r *CustomResolver) LookupIPAddr(ctx context.Context, host string) ([]net.I
 }
 
 func (r *CustomResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	// -resolvers, ask the configured servers only
+	if r.serversOnly {
+		for _, server := range r.dnsServers {
+			cname, err := serverResolver(server).LookupCNAME(ctx, host)
+			if err == nil && cname != host+"." {
+				return cname, nil
+			}
+		}
+		return "", fmt.Errorf("no CNAME record")
+	}
+
 	domain := dns.Domain(host)
 
 	// Try DoH first
@@ -0,0 +1,176 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+	GB403ErrorHandler "github.com/slicingmelon/gobypass403/core/utils/error"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+// ScanResult holds all findings of a scanned target URL
+type ScanResult struct {
+	TargetURL string
+	Baseline  *scanner.BaselineResponse // dumb_check response, nil if it wasn't sent
+	Findings  []*scanner.Result
+	Errors    *GB403ErrorHandler.ErrorSummary // request errors of the target's host, nil if none
+}
+
+// findingView is a finding as rendered in the report, with its payload token decoded
+type findingView struct {
+	*scanner.Result
+	Index       int
+	Length      int64
+	StatusClass int // 2, 3, 4 or 5, used to color the status code
+	Payload     *payload.BypassPayload
+	PayloadErr  string
+}
+
+type scanView struct {
+	TargetURL string
+	Baseline  *scanner.BaselineResponse
+	Errors    *GB403ErrorHandler.ErrorSummary
+	Findings  []findingView
+}
+
+type reportView struct {
+	Version     string
+	GeneratedAt string
+	Total       int
+	Scans       []scanView
+}
+
+var tmpl = template.Must(template.New("report").Parse(reportTemplate))
+
+// renderHTMLReport renders all scans as a single self-contained HTML page.
+// All values go through html/template, so response content can't break the page.
+func renderHTMLReport(scans []ScanResult, version string) ([]byte, error) {
+	view := reportView{
+		Version:     version,
+		GeneratedAt: time.Now().Format(time.RFC1123),
+	}
+
+	index := 0
+	for _, scan := range scans {
+		sv := scanView{TargetURL: scan.TargetURL, Baseline: scan.Baseline, Errors: scan.Errors}
+		for _, res := range scan.Findings {
+			index++
+			fv := findingView{
+				Result:      res,
+				Index:       index,
+				Length:      res.ContentLength,
+				StatusClass: res.StatusCode / 100,
+			}
+			if fv.Length <= 0 {
+				fv.Length = int64(res.ResponseBodyBytes)
+			}
+
+			if res.DebugToken != "" {
+				decoded, err := payload.DecodePayloadToken(res.DebugToken)
+				if err != nil {
+					fv.PayloadErr = err.Error()
+				} else {
+					fv.Payload = &decoded
+				}
+			}
+			sv.Findings = append(sv.Findings, fv)
+		}
+		view.Total += len(sv.Findings)
+		view.Scans = append(view.Scans, sv)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("failed to render HTML report: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteHTMLReport renders the scans and writes the HTML report to path
+func WriteHTMLReport(path string, scans []ScanResult, version string) error {
+	data, err := renderHTMLReport(scans, version)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %v", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+type jsonScan struct {
+	TargetURL string                          `json:"target_url"`
+	Baseline  *scanner.BaselineResponse       `json:"baseline,omitempty"`
+	Errors    *GB403ErrorHandler.ErrorSummary `json:"errors,omitempty"`
+	Results   []scanner.WebhookFinding        `json:"results"`
+}
+
+type jsonReport struct {
+	SchemaVersion int        `json:"schema_version"`
+	Tool          string     `json:"tool"`
+	Version       string     `json:"version"`
+	GeneratedAt   string     `json:"generated_at"`
+	Total         int        `json:"total"`
+	Scans         []jsonScan `json:"scans"`
+}
+
+// WriteJSONReport writes the scans as a single JSON document to path, the findings in the
+// -jsonl format alongside the request errors of each target (timeouts, DNS failures, resets).
+// The findings use the layout of schemaVersion (0 means scanner.OutputSchemaVersion).
+func WriteJSONReport(path string, scans []ScanResult, version string, schemaVersion int) error {
+	if schemaVersion <= 0 {
+		schemaVersion = scanner.OutputSchemaVersion
+	}
+	doc := jsonReport{
+		SchemaVersion: schemaVersion,
+		Tool:          "gobypass403",
+		Version:       version,
+		GeneratedAt:   time.Now().UTC().Format(time.RFC3339),
+		Scans:         make([]jsonScan, 0, len(scans)),
+	}
+	for _, scan := range scans {
+		js := jsonScan{
+			TargetURL: scan.TargetURL,
+			Baseline:  scan.Baseline,
+			Errors:    scan.Errors,
+			Results:   make([]scanner.WebhookFinding, 0, len(scan.Findings)),
+		}
+		for _, res := range scan.Findings {
+			js.Results = append(js.Results, scanner.NewWebhookFinding(scan.TargetURL, version, schemaVersion, res))
+		}
+		doc.Total += len(js.Results)
+		doc.Scans = append(doc.Scans, js)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON report: %v", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %v", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,90 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"context"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// AuthBaseline is the status of the original request of a target URL with and without the
+// -auth credentials. Findings are only authorization bypasses when the authenticated request
+// is denied, a target already answering 200 with the credentials works because of them.
+type AuthBaseline struct {
+	AuthStatusCode   int `json:"auth_status_code"`    // 0 when the request failed
+	NoAuthStatusCode int `json:"no_auth_status_code"` // 0 when the request failed
+}
+
+// authBaseline returns the auth baseline of a target URL, nil if it wasn't checked
+func (s *Scanner) authBaseline(targetURL string) *AuthBaseline {
+	s.baselineMu.Lock()
+	defer s.baselineMu.Unlock()
+	return s.authBaselines[targetURL]
+}
+
+// checkAuthBaseline sends the original request of a target URL with and without the -auth
+// credentials, records both statuses and warns when they don't allow an authorization test
+func (s *Scanner) checkAuthBaseline(ctx context.Context, targetURL string) *AuthBaseline {
+	noAuthOpts := *s.scannerOpts
+	noAuthOpts.AuthHeaders = nil
+
+	ab := &AuthBaseline{
+		AuthStatusCode:   s.originalRequestStatus(ctx, targetURL, s.scannerOpts),
+		NoAuthStatusCode: s.originalRequestStatus(ctx, targetURL, &noAuthOpts),
+	}
+
+	s.baselineMu.Lock()
+	s.authBaselines[targetURL] = ab
+	s.baselineMu.Unlock()
+
+	GB403Logger.Info().Msgf("Original request of %s: %d with the -auth credentials, %d without\n",
+		targetURL, ab.AuthStatusCode, ab.NoAuthStatusCode)
+
+	switch {
+	case ab.AuthStatusCode == 0 || ab.NoAuthStatusCode == 0:
+		GB403Logger.Warning().Msgf("Could not compare the original request of %s with and without the -auth credentials\n", targetURL)
+	case ab.AuthStatusCode < 400:
+		GB403Logger.Warning().Msgf("%s already answers %d with the -auth credentials, its findings work because of them and are not authorization bypasses\n",
+			targetURL, ab.AuthStatusCode)
+	case ab.AuthStatusCode != 401 && ab.AuthStatusCode != 403:
+		GB403Logger.Warning().Msgf("%s answers %d with the -auth credentials instead of 401/403, there may be no authorization check to bypass\n",
+			targetURL, ab.AuthStatusCode)
+	case ab.AuthStatusCode == ab.NoAuthStatusCode:
+		GB403Logger.Warning().Msgf("%s answers %d with and without the -auth credentials, check they are valid\n",
+			targetURL, ab.AuthStatusCode)
+	}
+
+	return ab
+}
+
+// originalRequestStatus sends the unmodified request of a target URL with the given options
+// and returns its status code, 0 if it failed
+func (s *Scanner) originalRequestStatus(ctx context.Context, targetURL string, opts *ScannerOpts) int {
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "dumb_check",
+	})
+	jobs := pg.GenerateDumbCheckPayload(targetURL, "dumb_check")
+	if len(jobs) == 0 {
+		return 0
+	}
+
+	worker := NewBypassEngagement("dumb_check", targetURL, opts, len(jobs))
+	defer worker.Stop()
+
+	statusCode := 0
+	for response := range worker.requestPool.ProcessRequests(ctx, jobs) {
+		if response == nil {
+			continue
+		}
+		statusCode = response.StatusCode
+		rawhttp.ReleaseResponseDetails(response)
+	}
+	return statusCode
+}
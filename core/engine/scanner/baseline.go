@@ -0,0 +1,168 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultIgnorePatterns strip common dynamic content (dates, timestamps, nonces)
+// from response bodies before they are compared against the baseline
+var DefaultIgnorePatterns = []string{
+	// ISO 8601 / RFC 3339 (2024-05-01T10:20:30Z, 2024-05-01 10:20:30)
+	`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	// RFC 1123 (Wed, 01 May 2024 10:20:30 GMT)
+	`(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun), \d{2} (?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4} \d{2}:\d{2}:\d{2} GMT`,
+	// Unix timestamps (seconds or milliseconds)
+	`\b1\d{9}(?:\d{3})?\b`,
+	// Nonce/CSRF looking tokens (long hex/base64 blobs)
+	`[A-Za-z0-9_\-+/]{32,}={0,2}`,
+}
+
+var defaultIgnoreRegexps = compileDefaultIgnorePatterns()
+
+func compileDefaultIgnorePatterns() []*regexp.Regexp {
+	rxs := make([]*regexp.Regexp, 0, len(DefaultIgnorePatterns))
+	for _, p := range DefaultIgnorePatterns {
+		rxs = append(rxs, regexp.MustCompile(p))
+	}
+	return rxs
+}
+
+// CompileIgnorePatterns compiles the user supplied ignore patterns (-ignore-pattern), once at startup
+func CompileIgnorePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var rxs []*regexp.Regexp
+	for _, p := range patterns {
+		rx, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", p, err)
+		}
+		rxs = append(rxs, rx)
+	}
+	return rxs, nil
+}
+
+// responseBaseline holds the normalized fingerprint of the dumb_check response
+type responseBaseline struct {
+	statusCode int
+	bodyLen    int
+	bodyHash   uint64
+}
+
+// BaselineMatcher suppresses findings identical to the original (dumb_check) response
+type BaselineMatcher struct {
+	patterns  []*regexp.Regexp
+	mu        sync.RWMutex
+	baselines map[string]responseBaseline // keyed by target URL
+}
+
+// NewBaselineMatcher strips the default ignore patterns plus the user supplied ones (see CompileIgnorePatterns)
+func NewBaselineMatcher(ignorePatterns []*regexp.Regexp) *BaselineMatcher {
+	return &BaselineMatcher{
+		patterns:  append(append([]*regexp.Regexp{}, defaultIgnoreRegexps...), ignorePatterns...),
+		baselines: make(map[string]responseBaseline),
+	}
+}
+
+// Normalize strips all ignore patterns from the body
+func (bm *BaselineMatcher) Normalize(body []byte) []byte {
+	for _, rx := range bm.patterns {
+		body = rx.ReplaceAll(body, nil)
+	}
+	return body
+}
+
+func (bm *BaselineMatcher) fingerprint(statusCode int, body []byte) responseBaseline {
+	normalized := bm.Normalize(body)
+	h := fnv.New64a()
+	h.Write(normalized)
+	return responseBaseline{
+		statusCode: statusCode,
+		bodyLen:    len(normalized),
+		bodyHash:   h.Sum64(),
+	}
+}
+
+// SetBaseline records the baseline response for a target URL
+func (bm *BaselineMatcher) SetBaseline(targetURL string, statusCode int, body []byte) {
+	fp := bm.fingerprint(statusCode, body)
+
+	bm.mu.Lock()
+	bm.baselines[targetURL] = fp
+	bm.mu.Unlock()
+}
+
+// MatchesBaseline reports whether the response is the same as the baseline once normalized
+func (bm *BaselineMatcher) MatchesBaseline(targetURL string, statusCode int, body []byte) bool {
+	bm.mu.RLock()
+	baseline, ok := bm.baselines[targetURL]
+	bm.mu.RUnlock()
+	if !ok || baseline.statusCode != statusCode {
+		return false
+	}
+
+	return bm.fingerprint(statusCode, body) == baseline
+}
+
+// BaselineResponse is the status and length of the dumb_check response of a target URL,
+// findings report their length relative to it (Result.LengthDelta)
+type BaselineResponse struct {
+	StatusCode   int    `json:"status_code"`
+	Length       int64  `json:"length"`
+	ContentType  string `json:"-"` // Only used to score Result.Confidence
+	ResponseTime int64  `json:"-"` // In milliseconds, only used to spot slow request_smuggling_probe responses
+}
+
+// responseLength is the length of a response or finding everywhere it is shown or compared: its
+// Content-Length, or the body bytes read when there is none (0 or negative, e.g. chunked)
+func responseLength(contentLength int64, bodyBytes int) int64 {
+	if contentLength > 0 {
+		return contentLength
+	}
+	return int64(bodyBytes)
+}
+
+// setBaselineResponse records the dumb_check response of a target URL
+func (s *Scanner) setBaselineResponse(targetURL string, statusCode int, length int64, contentType string, responseTime int64) {
+	s.baselineMu.Lock()
+	s.baselineResponses[targetURL] = &BaselineResponse{StatusCode: statusCode, Length: length, ContentType: contentType, ResponseTime: responseTime}
+	s.baselineMu.Unlock()
+}
+
+// baselineResponse returns the dumb_check response of a target URL, nil if it wasn't sent
+func (s *Scanner) baselineResponse(targetURL string) *BaselineResponse {
+	s.baselineMu.Lock()
+	defer s.baselineMu.Unlock()
+	return s.baselineResponses[targetURL]
+}
+
+// deniedStatus returns the status code of the original request of a target URL when it was
+// denied (401 or 403), from the dumb_check response, the authenticated original request (-auth-header)
+// or else the calibration control request of the original URL. Returns 0 when unknown or not denied.
+func (s *Scanner) deniedStatus(targetURL string, calibration *CalibrationBaseline) int {
+	statusCode := 0
+	if baseline := s.baselineResponse(targetURL); baseline != nil {
+		statusCode = baseline.StatusCode
+	} else if authBaseline := s.authBaseline(targetURL); authBaseline != nil && authBaseline.AuthStatusCode > 0 {
+		statusCode = authBaseline.AuthStatusCode
+	} else if calibration != nil {
+		for _, sample := range calibration.Samples {
+			if !strings.Contains(sample.RawURI, "/"+calibrationBogusPrefix) {
+				statusCode = sample.StatusCode
+				break
+			}
+		}
+	}
+
+	if statusCode == 401 || statusCode == 403 {
+		return statusCode
+	}
+	return 0
+}
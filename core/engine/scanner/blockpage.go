@@ -0,0 +1,86 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"sync"
+
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+//go:embed blockpages.json
+var blockPagesJSON []byte
+
+// blockPageSignature identifies the block/challenge page of a WAF or CDN.
+// A response matches if it has any of the block headers, or any of the body markers
+// together with any of the vendor headers (body markers alone when there are none).
+type blockPageSignature struct {
+	Name         string   `json:"name"`
+	Headers      []string `json:"headers"`       // Vendor headers, "Name: value" or "Name"
+	Body         []string `json:"body"`          // Block page markers, searched in the body preview
+	BlockHeaders []string `json:"block_headers"` // Headers only set on block/challenge responses
+
+	headers      []HeaderMatcher
+	blockHeaders []HeaderMatcher
+}
+
+var (
+	blockPageSignatures     []blockPageSignature
+	blockPageSignaturesOnce sync.Once
+)
+
+// loadBlockPageSignatures parses the embedded signatures once
+func loadBlockPageSignatures() []blockPageSignature {
+	blockPageSignaturesOnce.Do(func() {
+		var signatures []blockPageSignature
+		if err := json.Unmarshal(blockPagesJSON, &signatures); err != nil {
+			GB403Logger.Error().Msgf("Failed to parse block page signatures: %v\n", err)
+			return
+		}
+
+		for i := range signatures {
+			sig := &signatures[i]
+			sig.headers = parseHeaderMatchers(sig.Headers)
+			sig.blockHeaders = parseHeaderMatchers(sig.BlockHeaders)
+		}
+		blockPageSignatures = signatures
+	})
+	return blockPageSignatures
+}
+
+// parseHeaderMatchers parses the header rules of a signature, invalid rules are skipped
+func parseHeaderMatchers(headers []string) []HeaderMatcher {
+	var matchers []HeaderMatcher
+	for _, h := range headers {
+		if m, err := ParseHeaderMatcher(h); err == nil {
+			matchers = append(matchers, m)
+		}
+	}
+	return matchers
+}
+
+// classifyBlockPage returns the WAF/CDN whose block page the response looks like
+// (e.g. "cloudflare", "akamai"), or "" if none. headers are the raw response headers
+// (status line first), body is the response body preview.
+func classifyBlockPage(headers, body []byte) string {
+	for _, sig := range loadBlockPageSignatures() {
+		if matchHeaders(headers, sig.blockHeaders) {
+			return sig.Name
+		}
+		if len(sig.headers) > 0 && !matchHeaders(headers, sig.headers) {
+			continue
+		}
+		for _, marker := range sig.Body {
+			if bytes.Contains(body, []byte(marker)) {
+				return sig.Name
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// Max bytes saved per response body (-save-bodies), larger bodies are truncated
+const maxSavedBodySize = 50 * 1024 * 1024
+
+// Debug tokens grow with the payload, longer ones are hashed to stay within filename limits
+const maxFindingFileNameLen = 200
+
+// findingFileName returns the file name of a finding file (saved body, .http request).
+// Tokens are base64url encoded, so they are already safe to use in paths.
+func findingFileName(debugToken, ext string) string {
+	if len(debugToken) > maxFindingFileNameLen {
+		sum := sha256.Sum256([]byte(debugToken))
+		debugToken = hex.EncodeToString(sum[:])
+	}
+	return debugToken + ext
+}
+
+// BodyFileName returns the file name of the saved body of the finding with debugToken.
+func BodyFileName(debugToken string) string {
+	return findingFileName(debugToken, ".bin")
+}
+
+// saveResponseBody resends the request of a finding and writes its complete response body
+// to OutDir/bodies/<debugToken>.bin (-save-bodies), or OutDir/<host>/bodies with -split-output.
+// res.BodyFilePath is set on success.
+// The worker pool only reads a preview of each body, so a follow-up request is needed.
+func (s *Scanner) saveResponseBody(worker *BypassEngagement, res *Result) {
+	if res.DebugToken == "" {
+		return
+	}
+
+	bypassPayload, err := payload.DecodePayloadToken(res.DebugToken)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to decode debug token to save the response body: %v\n", err)
+		return
+	}
+	bypassPayload.PayloadToken = res.DebugToken
+
+	bodiesDir := filepath.Join(s.targetOutDir(res.TargetURL), "bodies")
+	if err := os.MkdirAll(bodiesDir, 0o755); err != nil {
+		GB403Logger.Error().Msgf("Failed to create bodies directory: %v\n", err)
+		return
+	}
+
+	bodyFile := filepath.Join(bodiesDir, BodyFileName(res.DebugToken))
+	if err := s.writeResponseBody(worker, bypassPayload, bodyFile); err != nil {
+		GB403Logger.Error().Msgf("Failed to save response body of [%s] finding: %v\n", res.BypassModule, err)
+		os.Remove(bodyFile)
+		return
+	}
+
+	res.BodyFilePath = bodyFile
+}
+
+// writeResponseBody fetches the response body of bypassPayload into bodyFile
+func (s *Scanner) writeResponseBody(worker *BypassEngagement, bypassPayload payload.BypassPayload, bodyFile string) error {
+	f, err := os.Create(bodyFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	written, truncated, err := worker.requestPool.FetchResponseBody(s.ctx, bypassPayload, w, maxSavedBodySize)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %v", bodyFile, err)
+	}
+
+	if truncated {
+		GB403Logger.Verbose().Msgf("[%s] Saved response body truncated to %d bytes: %s\n", bypassPayload.BypassModule, written, bodyFile)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltResultsBucket holds one nested bucket per target URL, its findings keyed by a sequence
+// number so they are read back in discovery order
+var boltResultsBucket = []byte("scan_results")
+
+// boltStore saves the findings to a bbolt file as JSON documents (-db bolt)
+type boltStore struct {
+	path string
+	mu   sync.Mutex
+	db   *bolt.DB // nil once closed
+}
+
+// NewBoltResultStore opens (or creates) the bbolt findings file at path
+func NewBoltResultStore(path string) (ResultStore, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltResultsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltStore{path: path, db: db}, nil
+}
+
+func (b *boltStore) Append(results []*Result) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return fmt.Errorf("findings database is closed")
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(boltResultsBucket)
+		for _, res := range results {
+			bucket, err := root.CreateBucketIfNotExists([]byte(res.TargetURL))
+			if err != nil {
+				return fmt.Errorf("failed to create bucket: %v", err)
+			}
+			seq, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(res)
+			if err != nil {
+				return fmt.Errorf("failed to encode result: %v", err)
+			}
+			key := make([]byte, 8)
+			binary.BigEndian.PutUint64(key, seq)
+			if err := bucket.Put(key, data); err != nil {
+				return fmt.Errorf("failed to insert result: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Results reopens the file read-only once the store is closed
+func (b *boltStore) Results(targetURL string) ([]*Result, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db != nil {
+		return readBoltResults(b.db, targetURL)
+	}
+	return LoadResultsFromBolt(b.path, targetURL)
+}
+
+func (b *boltStore) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.db == nil {
+		return nil
+	}
+	err := b.db.Close()
+	b.db = nil
+	return err
+}
+
+// LoadResultsFromBolt returns the findings of a bbolt findings file (e.g. of a previous scan),
+// opened read-only, of targetURL or of all target URLs when it's empty
+func LoadResultsFromBolt(path string, targetURL string) ([]*Result, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("findings database not found: %v", err)
+	}
+	db, err := bolt.Open(path, 0o644, &bolt.Options{ReadOnly: true, Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read-only database: %v", err)
+	}
+	defer db.Close()
+	return readBoltResults(db, targetURL)
+}
+
+// readBoltResults reads the findings of targetURL (all target URLs when empty), in discovery order
+func readBoltResults(db *bolt.DB, targetURL string) ([]*Result, error) {
+	var results []*Result
+	err := db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(boltResultsBucket)
+		if root == nil {
+			return fmt.Errorf("not a findings database")
+		}
+		readBucket := func(bucket *bolt.Bucket) error {
+			return bucket.ForEach(func(_, data []byte) error {
+				res := &Result{}
+				if err := json.Unmarshal(data, res); err != nil {
+					return fmt.Errorf("failed to decode result: %v", err)
+				}
+				results = append(results, res)
+				return nil
+			})
+		}
+
+		if targetURL != "" {
+			if bucket := root.Bucket([]byte(targetURL)); bucket != nil {
+				return readBucket(bucket)
+			}
+			return nil
+		}
+		return root.ForEachBucket(func(name []byte) error {
+			return readBucket(root.Bucket(name))
+		})
+	})
+	return results, err
+}
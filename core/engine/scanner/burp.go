@@ -0,0 +1,199 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// burpTimeLayout is the date format of Burp Suite's saved items (Java's Date.toString)
+const burpTimeLayout = "Mon Jan 02 15:04:05 MST 2006"
+
+// BurpItems is the XML document of Burp Suite's "Save items", one item per finding
+type BurpItems struct {
+	XMLName     xml.Name   `xml:"items"`
+	BurpVersion string     `xml:"burpVersion,attr"`
+	ExportTime  string     `xml:"exportTime,attr"`
+	Items       []BurpItem `xml:"item"`
+}
+
+type BurpItem struct {
+	Time           string      `xml:"time"`
+	URL            BurpCDATA   `xml:"url"`
+	Host           BurpHost    `xml:"host"`
+	Port           string      `xml:"port"`
+	Protocol       string      `xml:"protocol"`
+	Method         BurpCDATA   `xml:"method"`
+	Path           BurpCDATA   `xml:"path"`
+	Extension      string      `xml:"extension"`
+	Request        BurpMessage `xml:"request"`
+	Status         int         `xml:"status"`
+	ResponseLength int         `xml:"responselength"`
+	MimeType       string      `xml:"mimetype"`
+	Response       BurpMessage `xml:"response"`
+	Comment        string      `xml:"comment"`
+}
+
+type BurpCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+type BurpHost struct {
+	IP   string `xml:"ip,attr"`
+	Name string `xml:",chardata"`
+}
+
+// BurpMessage is a raw HTTP request or response, base64 encoded
+type BurpMessage struct {
+	Base64 bool   `xml:"base64,attr"`
+	Data   string `xml:",cdata"`
+}
+
+// NewBurpItem maps a finding to a Burp item: the request rebuilt from bypassPayload and the
+// response from the captured headers and body preview
+func NewBurpItem(bypassPayload payload.BypassPayload, res *Result, comment string) BurpItem {
+	request := payload.BypassPayloadToRawRequest(bypassPayload)
+	response := res.ResponseHeaders + res.ResponseBodyPreview
+
+	hostname, port, err := net.SplitHostPort(bypassPayload.Host)
+	if err != nil {
+		hostname = bypassPayload.Host
+		port = "80"
+		if bypassPayload.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	return BurpItem{
+		Time:           time.Now().Format(burpTimeLayout),
+		URL:            BurpCDATA{payload.BypassPayloadToFullURL(bypassPayload)},
+		Host:           BurpHost{Name: hostname},
+		Port:           port,
+		Protocol:       bypassPayload.Scheme,
+		Method:         BurpCDATA{bypassPayload.Method},
+		Path:           BurpCDATA{bypassPayload.RawURI},
+		Extension:      burpExtension(bypassPayload.RawURI),
+		Request:        BurpMessage{Base64: true, Data: base64.StdEncoding.EncodeToString(request)},
+		Status:         res.StatusCode,
+		ResponseLength: len(response),
+		MimeType:       burpMimeType(res.ContentType),
+		Response:       BurpMessage{Base64: true, Data: base64.StdEncoding.EncodeToString([]byte(response))},
+		Comment:        comment,
+	}
+}
+
+// burpExtension returns the file extension of the path, "null" when there is none like Burp does
+func burpExtension(rawURI string) string {
+	path, _, _ := strings.Cut(rawURI, "?")
+	base := path[strings.LastIndex(path, "/")+1:]
+	if i := strings.LastIndex(base, "."); i >= 0 && i < len(base)-1 {
+		return base[i+1:]
+	}
+	return "null"
+}
+
+// burpMimeType maps a Content-Type to Burp's MIME type names
+func burpMimeType(contentType string) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "html"):
+		return "HTML"
+	case strings.Contains(contentType, "json"):
+		return "JSON"
+	case strings.Contains(contentType, "xml"):
+		return "XML"
+	case strings.Contains(contentType, "javascript"):
+		return "script"
+	case strings.Contains(contentType, "css"):
+		return "CSS"
+	case strings.HasPrefix(contentType, "image/"):
+		return "image"
+	case strings.HasPrefix(contentType, "text/"):
+		return "text"
+	}
+	return ""
+}
+
+// BurpWriter accumulates findings and writes them as Burp Suite saved items on Close (-burp)
+type BurpWriter struct {
+	path       string
+	version    string
+	clientOpts *rawhttp.HTTPClientOptions // -H headers added to the rebuilt requests
+	mu         sync.Mutex
+	items      []BurpItem
+	closeOnce  sync.Once
+}
+
+// NewBurpWriter creates a BurpWriter writing to path
+func NewBurpWriter(path, version string, clientOpts *rawhttp.HTTPClientOptions) *BurpWriter {
+	return &BurpWriter{
+		path:       path,
+		version:    version,
+		clientOpts: clientOpts,
+	}
+}
+
+// WriteResult adds a finding to the export, its request rebuilt from the debug token
+func (w *BurpWriter) WriteResult(targetURL string, res *Result) {
+	bypassPayload, err := findingBypassPayload(res, w.clientOpts)
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to decode debug token of [%s] finding for the Burp export: %v\n", res.BypassModule, err)
+		return
+	}
+	comment := fmt.Sprintf("gobypass403 [%s] %s", res.BypassModule, res.DebugToken)
+
+	w.mu.Lock()
+	w.items = append(w.items, NewBurpItem(bypassPayload, res, comment))
+	w.mu.Unlock()
+}
+
+// Close writes the Burp XML file
+func (w *BurpWriter) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if err := WriteBurpItems(w.path, w.version, w.items); err != nil {
+			GB403Logger.Error().Msgf("Failed to write Burp export: %v\n", err)
+			return
+		}
+		GB403Logger.Success().Msgf("Burp export saved to %s\n", w.path)
+	})
+}
+
+// WriteBurpItems writes items as a Burp Suite saved items XML file
+func WriteBurpItems(path, version string, items []BurpItem) error {
+	doc := BurpItems{
+		BurpVersion: "gobypass403 " + version,
+		ExportTime:  time.Now().Format(burpTimeLayout),
+		Items:       items,
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Burp items: %v", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create Burp output directory: %v", err)
+		}
+	}
+
+	return os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
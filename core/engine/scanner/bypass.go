@@ -7,8 +7,12 @@ package scanner
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,39 +25,57 @@ import (
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
-// Global map to track already seen RawURIs across all bypass modules
-var (
-	seenRawURIsMutex sync.RWMutex
-	seenRawURIs      = make(map[string]string) // map[rawURI]bypassModule
-)
+// payloadFilter tracks the payloads of a target URL already generated by its bypass modules
+type payloadFilter struct {
+	mu      sync.RWMutex
+	rawURIs map[string]string // map[rawURI]bypassModule
+
+	// Requests already sent by a module (-dedupe-payloads), keyed by payloadDedupeKey
+	payloads map[string]string // map[key]bypassModule
+}
+
+func newPayloadFilter() *payloadFilter {
+	return &payloadFilter{
+		rawURIs:  make(map[string]string),
+		payloads: make(map[string]string),
+	}
+}
+
+// Global filter of the exported helpers below, scans use one filter per target URL
+var globalPayloadFilter = newPayloadFilter()
 
 // FilterUniqueBypassPayloads removes payloads with RawURIs that have been seen before across modules
 func FilterUniqueBypassPayloads(payloads []payload.BypassPayload, bypassModule string) []payload.BypassPayload {
-	// Check if this module should be filtered
-	modulesToFilter := map[string]bool{
-		"case_substitution":          true,
-		"char_encode":                true,
-		"end_paths":                  true,
-		"mid_paths":                  true,
-		"nginx_bypasses":             true,
-		"path_prefix":                true,
-		"unicode_path_normalization": true,
-	}
-
-	if !modulesToFilter[bypassModule] {
+	return globalPayloadFilter.filterUnique(payloads, bypassModule)
+}
+
+// FilterDuplicatePayloads removes payloads sending the exact same request as a payload
+// of a previous module (or an earlier one of the same module). The first producer wins.
+func FilterDuplicatePayloads(payloads []payload.BypassPayload, bypassModule string) []payload.BypassPayload {
+	return globalPayloadFilter.filterDuplicates(payloads, bypassModule)
+}
+
+// ResetSeenRawURIs clears the global map of seen RawURIs
+func ResetSeenRawURIs() {
+	globalPayloadFilter.reset()
+}
+
+func (f *payloadFilter) filterUnique(payloads []payload.BypassPayload, bypassModule string) []payload.BypassPayload {
+	// Only path mutation modules are filtered
+	if !payload.PathMutationModules[bypassModule] {
 		return payloads
 	}
 
 	filtered := make([]payload.BypassPayload, 0, len(payloads))
 
-	seenRawURIsMutex.RLock()
-	initialSize := len(seenRawURIs)
-	seenRawURIsMutex.RUnlock()
+	f.mu.RLock()
+	initialSize := len(f.rawURIs)
+	f.mu.RUnlock()
 
 	for _, p := range payloads {
-		seenRawURIsMutex.RLock()
-		previousModule, seen := seenRawURIs[p.RawURI]
-		seenRawURIsMutex.RUnlock()
+		f.mu.RLock()
+		previousModule, seen := f.rawURIs[p.RawURI]
+		f.mu.RUnlock()
 
 		// Add payloads that are globally unique or belong to this module
 		if !seen || previousModule == bypassModule {
@@ -62,16 +84,16 @@ func FilterUniqueBypassPayloads(payloads []payload.BypassPayload, bypassModule s
 
 			// Update global map
 			if !seen {
-				seenRawURIsMutex.Lock()
-				seenRawURIs[p.RawURI] = bypassModule
-				seenRawURIsMutex.Unlock()
+				f.mu.Lock()
+				f.rawURIs[p.RawURI] = bypassModule
+				f.mu.Unlock()
 			}
 		}
 	}
 
-	seenRawURIsMutex.RLock()
-	newSize := len(seenRawURIs)
-	seenRawURIsMutex.RUnlock()
+	f.mu.RLock()
+	newSize := len(f.rawURIs)
+	f.mu.RUnlock()
 
 	// Calculate new unique RawURIs added
 	addedURIs := newSize - initialSize
@@ -82,6 +104,60 @@ func FilterUniqueBypassPayloads(payloads []payload.BypassPayload, bypassModule s
 	return filtered
 }
 
+func (f *payloadFilter) filterDuplicates(payloads []payload.BypassPayload, bypassModule string) []payload.BypassPayload {
+	filtered := make([]payload.BypassPayload, 0, len(payloads))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, p := range payloads {
+		key := payloadDedupeKey(p)
+		if previousModule, seen := f.payloads[key]; seen {
+			GB403Logger.Debug().BypassModule(bypassModule).Msgf("Dropping %s %s, already sent by [%s]\n", p.Method, p.RawURI, previousModule)
+			continue
+		}
+		f.payloads[key] = bypassModule
+		filtered = append(filtered, p)
+	}
+
+	GB403Logger.Verbose().Msgf("[%s] Deduplicated payloads: %d -> %d\n", bypassModule, len(payloads), len(filtered))
+	return filtered
+}
+
+func (f *payloadFilter) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Create a new map rather than clearing the existing one
+	// This is more efficient for large maps
+	f.rawURIs = make(map[string]string)
+	f.payloads = make(map[string]string)
+	GB403Logger.Verbose().Msgf("Reset global RawURI tracking map\n")
+}
+
+// payloadDedupeKey normalizes the parts of a payload that make up the request on the wire:
+// method, scheme and host are case-insensitive. Headers are sent as written and in order, so
+// their exact names and order are kept (header_case and header order variants are other requests).
+func payloadDedupeKey(p payload.BypassPayload) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToUpper(p.Method))
+	sb.WriteByte(0)
+	sb.WriteString(strings.ToLower(p.Scheme))
+	sb.WriteByte(0)
+	sb.WriteString(strings.ToLower(p.Host))
+	sb.WriteByte(0)
+	sb.WriteString(p.RawURI)
+	for _, h := range p.Headers {
+		sb.WriteByte(0)
+		sb.WriteString(h.Header)
+		sb.WriteString(": ")
+		sb.WriteString(h.Value)
+	}
+	sb.WriteByte(0)
+	sb.WriteString(p.Body)
+	return sb.String()
+}
+
 // IsValidBypassModule checks if a module is valid
 func IsValidBypassModule(moduleName string) bool {
 	return slices.Contains(payload.BypassModulesRegistry, moduleName)
@@ -102,18 +178,33 @@ func NewBypassEngagement(bypassmodule string, targetURL string, scannerOpts *Sca
 	httpClientOpts.BypassModule = bypassmodule
 	httpClientOpts.Timeout = time.Duration(scannerOpts.Timeout) * time.Millisecond
 
-	// Set response body preview size - buffer adjustments handled in NewHTTPClient
+	// Preview and max body size are independent, 0 picks the default in NewHTTPClient
 	httpClientOpts.ResponseBodyPreviewSize = scannerOpts.ResponseBodyPreviewSize
+	httpClientOpts.MaxResponseBodySize = scannerOpts.MaxResponseBodySize
 
 	// and proxy ofc
 	httpClientOpts.ProxyURL = scannerOpts.Proxy
 
+	// Rotated proxies (-proxy-file), each request opens a new connection through the next one
+	if len(scannerOpts.ProxyURLs) > 0 {
+		httpClientOpts.ProxyURLs = scannerOpts.ProxyURLs
+		httpClientOpts.DisableKeepAlive = len(scannerOpts.ProxyURLs) > 1
+	}
+
 	// Pass custom HTTP headers to client options
 	httpClientOpts.CustomHTTPHeaders = scannerOpts.CustomHTTPHeaders
-
-	// Apply a delay between requests
-	if scannerOpts.RequestDelay > 0 {
+	httpClientOpts.AuthHeaders = scannerOpts.AuthHeaders
+	httpClientOpts.UserAgents = scannerOpts.UserAgents
+	httpClientOpts.PreserveHeaderOrder = scannerOpts.PreserveHeaderOrder
+	httpClientOpts.AcceptEncoding = scannerOpts.AcceptEncoding
+	httpClientOpts.DisablePathNormalizing = !scannerOpts.NormalizePath
+
+	// Apply a rate limit, or a delay between requests (-rate takes precedence over -delay)
+	if scannerOpts.RequestRate > 0 {
+		httpClientOpts.RequestRate = scannerOpts.RequestRate
+	} else if scannerOpts.RequestDelay > 0 {
 		httpClientOpts.RequestDelay = time.Duration(scannerOpts.RequestDelay) * time.Millisecond
+		httpClientOpts.RequestDelayJitter = scannerOpts.RequestDelayJitter
 	}
 
 	httpClientOpts.MaxRetries = scannerOpts.MaxRetries
@@ -121,6 +212,14 @@ func NewBypassEngagement(bypassmodule string, targetURL string, scannerOpts *Sca
 	httpClientOpts.MaxConsecutiveFailedReqs = scannerOpts.MaxConsecutiveFailedReqs
 
 	httpClientOpts.AutoThrottle = scannerOpts.AutoThrottle
+	httpClientOpts.MaxRetryAfter = time.Duration(scannerOpts.MaxRetryAfter) * time.Second
+	httpClientOpts.RetryStatusCodes = scannerOpts.RetryStatusCodes
+	httpClientOpts.DisableTLSResumption = scannerOpts.DisableTLSResumption
+	httpClientOpts.ClientCertFile = scannerOpts.ClientCertFile
+	httpClientOpts.ClientKeyFile = scannerOpts.ClientKeyFile
+	httpClientOpts.TLSFingerprint = scannerOpts.TLSFingerprint
+	httpClientOpts.SNI = scannerOpts.SNI
+	httpClientOpts.EnableHTTP2 = scannerOpts.EnableHTTP2
 
 	// Disable streaming of response body if disabled via cli options
 	if scannerOpts.DisableStreamResponseBody {
@@ -134,6 +233,14 @@ func NewBypassEngagement(bypassmodule string, targetURL string, scannerOpts *Sca
 	if calculatedMaxConns > httpClientOpts.MaxConnsPerHost {
 		httpClientOpts.MaxConnsPerHost = calculatedMaxConns
 	}
+	httpClientOpts.HostConcurrency = scannerOpts.HostConcurrency
+	httpClientOpts.HostLimiter = scannerOpts.HostLimiter
+	httpClientOpts.RateLimiter = scannerOpts.RateLimiter
+	httpClientOpts.HostHealth = scannerOpts.HostHealth
+	httpClientOpts.DumpWire = scannerOpts.DumpWire
+
+	// A desync probe that gets no response is a finding, not a failed request
+	httpClientOpts.ReportTimeouts = bypassmodule == "request_smuggling_probe"
 
 	return &BypassEngagement{
 		bypassmodule: bypassmodule,
@@ -154,58 +261,119 @@ func (w *BypassEngagement) Stop() {
 	})
 }
 
-// ResetSeenRawURIs clears the global map of seen RawURIs
-func ResetSeenRawURIs() {
-	seenRawURIsMutex.Lock()
-	defer seenRawURIsMutex.Unlock()
-
-	// Create a new map rather than clearing the existing one
-	// This is more efficient for large maps
-	seenRawURIs = make(map[string]string)
-	GB403Logger.Verbose().Msgf("Reset global RawURI tracking map\n")
-}
-
 // Core Function
-func (s *Scanner) RunAllBypasses(targetURL string) int {
+// RunAllBypasses runs every selected bypass module against targetURL, until done or ctx is cancelled
+func (s *Scanner) RunAllBypasses(ctx context.Context, targetURL string) int {
 	totalFindings := 0
 
-	// Reset the global seen RawURIs map for this new target URL
-	ResetSeenRawURIs()
+	ctx, cancel := s.withRunContext(ctx)
+	defer cancel()
+
+	// Every target URL gets fresh seen payloads, -unique findings and request budget (-max-requests)
+	ts := s.startTarget(targetURL)
+	defer s.finishTarget(targetURL)
+
+	// Original request with and without the -auth credentials, before any bypass
+	if len(s.scannerOpts.AuthHeaders) > 0 {
+		s.checkAuthBaseline(ctx, targetURL)
+	}
 
 	modules := strings.Split(s.scannerOpts.BypassModule, ",")
+	modulesRun := 0
 	for _, module := range modules {
 		module = strings.TrimSpace(module)
 		if module == "" {
 			continue
 		}
 
+		// Whole run was cancelled (-stop-all-on-find, Ctrl-C)
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Already completed by a previous run (-resume)
+		if s.scannerOpts.Checkpoint != nil && s.scannerOpts.Checkpoint.IsCompleted(targetURL, module) {
+			GB403Logger.Info().Msgf("Skipping bypass module [%s] for %s, already completed (-resume)\n", module, targetURL)
+			continue
+		}
+
+		// Let the target cool down between modules (not before the first one)
+		if modulesRun > 0 && s.scannerOpts.ModuleDelay > 0 {
+			GB403Logger.Verbose().Msgf("Sleeping %ds before running bypass module [%s]\n", s.scannerOpts.ModuleDelay, module)
+			select {
+			case <-time.After(time.Duration(s.scannerOpts.ModuleDelay) * time.Second):
+			case <-ctx.Done():
+			}
+		}
+		modulesRun++
+
 		// Now RunBypassModule returns count instead of using channels
-		findings := s.RunBypassModule(module, targetURL)
+		findings := s.RunBypassModule(ctx, module, targetURL)
 		totalFindings += findings
+
+		// Budget spent, the rest of this module and the remaining ones are skipped
+		if ts.requestBudget.Exhausted() {
+			GB403Logger.Warning().Msgf("Request budget of %d requests reached for %s (-max-requests), skipping remaining payloads\n",
+				ts.requestBudget.Max(), targetURL)
+			break
+		}
+
+		// A cancelled module didn't complete, it will run again on -resume
+		if s.scannerOpts.Checkpoint != nil && ctx.Err() == nil {
+			if err := s.scannerOpts.Checkpoint.MarkCompleted(targetURL, module); err != nil {
+				GB403Logger.Error().Msgf("Failed to save checkpoint: %v\n", err)
+			}
+		}
 	}
 
 	return totalFindings
 }
 
+// generatePayloads generates the unique payloads of a bypass module for targetURL
+func (s *Scanner) generatePayloads(bypassModule string, targetURL string) []payload.BypassPayload {
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:      targetURL,
+		BypassModule:   bypassModule,
+		ReconCache:     s.scannerOpts.ReconCache,
+		SpoofHeader:    s.scannerOpts.SpoofHeader,
+		SpoofIP:        s.scannerOpts.SpoofIP,
+		URLHeaderLevel: s.scannerOpts.URLHeaderLevel,
+		BothForms:      s.scannerOpts.BothForms,
+		MutateQuery:    s.scannerOpts.MutateQuery,
+		Wordlists:      s.scannerOpts.CustomWordlists,
+		AppendPayloads: s.scannerOpts.AppendPayloads,
+		Passive:        s.scannerOpts.Passive,
+	})
+
+	allJobs := pg.Generate()
+
+	// Filter unique payloads based on RawURI
+	seen := s.target(targetURL).payloads
+	allJobs = seen.filterUnique(allJobs, bypassModule)
+
+	// Drop requests already sent by a previous module (-dedupe-payloads)
+	if s.scannerOpts.DedupePayloads {
+		allJobs = seen.filterDuplicates(allJobs, bypassModule)
+	}
+
+	// Cap the payloads of the module (-passive)
+	if maxPayloads := s.scannerOpts.MaxModulePayloads; maxPayloads > 0 && len(allJobs) > maxPayloads {
+		GB403Logger.Verbose().BypassModule(bypassModule).Msgf("Capping %d payloads to %d for %s", len(allJobs), maxPayloads, targetURL)
+		allJobs = allJobs[:maxPayloads]
+	}
+	return allJobs
+}
+
 // Run a specific Bypass Module and return the number of findings
-func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
+func (s *Scanner) RunBypassModule(ctx context.Context, bypassModule string, targetURL string) int {
 	if !IsValidBypassModule(bypassModule) {
 		GB403Logger.Error().Msgf("Invalid bypass module: %s\n", bypassModule)
 		return 0
 	}
 
-	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
-		TargetURL:    targetURL,
-		BypassModule: bypassModule,
-		ReconCache:   s.scannerOpts.ReconCache,
-		SpoofHeader:  s.scannerOpts.SpoofHeader,
-		SpoofIP:      s.scannerOpts.SpoofIP,
-	})
-
-	allJobs := pg.Generate()
+	start := time.Now()
 
-	// Filter unique payloads based on RawURI
-	allJobs = FilterUniqueBypassPayloads(allJobs, bypassModule)
+	allJobs := s.generatePayloads(bypassModule, targetURL)
 
 	totalJobs := len(allJobs)
 	if totalJobs == 0 {
@@ -213,6 +381,12 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 		return 0
 	}
 
+	// Control responses, computed once per target URL before the first module fires
+	var calibration *CalibrationBaseline
+	if s.scannerOpts.Calibrate {
+		calibration = s.getCalibration(ctx, targetURL)
+	}
+
 	GB403Logger.PrintBypassModuleInfo(bypassModule, totalJobs, targetURL)
 
 	maxModuleNameLength := 0
@@ -224,6 +398,8 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 
 	worker := NewBypassEngagement(bypassModule, targetURL, s.scannerOpts, totalJobs)
 	defer worker.Stop()
+	ts := s.target(targetURL)
+	worker.requestPool.SetRequestBudget(ts.requestBudget)
 
 	maxConcurrentReqs := s.scannerOpts.ConcurrentRequests
 
@@ -232,9 +408,39 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 	// Create new progress bar
 	bar := NewProgressBar(prefix, progressbar.RedBar, 1, &s.progressBarEnabled)
 
-	responses := worker.requestPool.ProcessRequests(allJobs)
-	var dbWg sync.WaitGroup
 	resultCount := atomic.Int32{}
+	live := &liveModule{
+		targetURL:    targetURL,
+		bypassModule: bypassModule,
+		payloads:     totalJobs,
+		pool:         worker.requestPool,
+		findings:     &resultCount,
+	}
+	s.startLiveModule(live)
+	defer s.stopLiveModule(live)
+
+	// Machine-readable progress on stderr (-progress-json)
+	s.progressEvents.emit("module_start", targetURL, bypassModule, totalJobs, worker.requestPool, &resultCount, start)
+	stopProgressEvents := s.progressEvents.emitPeriodic(targetURL, bypassModule, totalJobs, worker.requestPool, &resultCount, start)
+
+	// Probe variant of each request_smuggling_probe payload, by debug token
+	var probeVariants map[string]string
+	if bypassModule == "request_smuggling_probe" {
+		probeVariants = make(map[string]string, totalJobs)
+		for _, job := range allJobs {
+			probeVariants[job.PayloadToken] = payload.SmugglingProbeVariant(job)
+		}
+	}
+
+	responses := worker.requestPool.ProcessRequests(ctx, allJobs)
+	var dbWg sync.WaitGroup
+	var openRedirects []*Result
+	var desyncs []*Result
+	likelyFalsePositives := 0
+	uniqueSkipped := 0
+	deniedSkipped := 0
+	lowConfidenceSkipped := 0
+	var pendingResults []*Result
 
 	for response := range responses {
 		if response == nil {
@@ -252,6 +458,34 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 		)
 		bar.WriteAbove(msg)
 
+		// The dumb_check response is the baseline the other modules get compared against
+		if bypassModule == "dumb_check" {
+			s.setBaselineResponse(targetURL, response.StatusCode, responseLength(response.ContentLength, response.ResponseBytes), string(response.ContentType), response.ResponseTime)
+			if s.baseline != nil {
+				s.baseline.SetBaseline(targetURL, response.StatusCode, response.ResponsePreview)
+			}
+		}
+
+		// A CL.TE/TE.CL desync leaves the back-end waiting for the rest of the body: the probe times out
+		// or is answered far slower than dumb_check. Recorded whatever the response, the filters below target bypasses.
+		if probeVariants != nil && (response.TimedOut || s.isSlowResponse(targetURL, response.ResponseTime)) {
+			result := s.newDesyncResult(targetURL, response, probeVariants[string(response.DebugToken)])
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+
+			desyncs = append(desyncs, result)
+			if s.scannerOpts.ExportHTTPDir != "" {
+				s.exportHTTPRequest(result)
+			}
+			s.writeResult(&dbWg, &resultCount, targetURL, result)
+			continue
+		}
+		if response.TimedOut {
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
 		// Check status code - if no match, skip
 		if !matchStatusCodes(response.StatusCode, s.scannerOpts.MatchStatusCodes) {
 			rawhttp.ReleaseResponseDetails(response)
@@ -259,6 +493,14 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 			continue
 		}
 
+		// Same 401/403 as the original request, not a bypass (unless -show-denied)
+		if !s.scannerOpts.ShowDenied && response.StatusCode == s.deniedStatus(targetURL, calibration) {
+			deniedSkipped++
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
 		// Check content type if required
 		if len(s.scannerOpts.MatchContentTypeBytes) > 0 {
 			contentTypeMatched := false
@@ -275,15 +517,47 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 			}
 		}
 
-		// Check min content length
-		if s.scannerOpts.MinContentLength > 0 {
-			if response.ContentLength < 0 || response.ContentLength < int64(s.scannerOpts.MinContentLength) {
+		// Check response size filters (-fs / -ms), falling back to the bytes read when Content-Length is unknown
+		if len(s.scannerOpts.FilterSizes) > 0 || len(s.scannerOpts.MatchSizes) > 0 {
+			size := responseLength(response.ContentLength, response.ResponseBytes)
+			if matchSizes(size, s.scannerOpts.FilterSizes) ||
+				(len(s.scannerOpts.MatchSizes) > 0 && !matchSizes(size, s.scannerOpts.MatchSizes)) {
 				rawhttp.ReleaseResponseDetails(response)
 				bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
 				continue
 			}
 		}
 
+		// Check regex matchers against the response headers and body preview
+		if s.scannerOpts.MatchRegex != nil && !matchRegex(s.scannerOpts.MatchRegex, response) ||
+			s.scannerOpts.FilterRegex != nil && matchRegex(s.scannerOpts.FilterRegex, response) {
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
+		// Check response headers (-match-header / -filter-header)
+		if len(s.scannerOpts.MatchHeaders) > 0 && !matchHeaders(response.ResponseHeaders, s.scannerOpts.MatchHeaders) ||
+			matchHeaders(response.ResponseHeaders, s.scannerOpts.FilterHeaders) {
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
+		// Check magic bytes (file signature) of the response body
+		if len(s.scannerOpts.MatchMagicBytes) > 0 && !bytes.HasPrefix(response.ResponsePreview, s.scannerOpts.MatchMagicBytes) {
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
+		// Check min content length, chunked responses are measured by the body bytes read
+		if s.scannerOpts.MinContentLength > 0 && belowMinLength(response, s.scannerOpts.MinContentLength, s.scannerOpts.ResponseBodyPreviewSize) {
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
 		// Check max content length
 		if s.scannerOpts.MaxContentLength > 0 && response.ContentLength >= 0 {
 			if response.ContentLength > int64(s.scannerOpts.MaxContentLength) {
@@ -293,22 +567,100 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 			}
 		}
 
+		// Skip responses identical to the baseline once dynamic content is stripped
+		if s.baseline != nil && bypassModule != "dumb_check" &&
+			s.baseline.MatchesBaseline(targetURL, response.StatusCode, response.ResponsePreview) {
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
 		// Process valid result
 		result := &Result{
-			TargetURL:           string(response.URL),
-			BypassModule:        string(response.BypassModule),
-			StatusCode:          response.StatusCode,
-			ResponseHeaders:     helpers.SanitizeNonPrintableBytes(response.ResponseHeaders),
-			CurlCMD:             helpers.SanitizeNonPrintableBytes(response.CurlCommand),
-			ResponseBodyPreview: string(response.ResponsePreview),
-			ContentType:         string(response.ContentType),
-			ContentLength:       response.ContentLength,
-			ResponseBodyBytes:   response.ResponseBytes,
-			Title:               string(response.Title),
-			ServerInfo:          string(response.ServerInfo),
-			RedirectURL:         helpers.SanitizeNonPrintableBytes(response.RedirectURL),
-			ResponseTime:        response.ResponseTime,
-			DebugToken:          string(response.DebugToken),
+			TargetURL:      string(response.URL),
+			BypassModule:   string(response.BypassModule),
+			IsLikelyBypass: true,
+		}
+
+		// Same status and body (or length, within tolerance) as a control request, most likely still denied
+		if calibration != nil {
+			result.Calibration = calibration
+			result.IsLikelyBypass = !calibration.Matches(s.newCalibrationSample(response, ""))
+		}
+
+		// WAF/CDN block page served with a matching status (e.g. a 200 challenge page)
+		result.BlockedBy = classifyBlockPage(response.ResponseHeaders, response.ResponsePreview)
+
+		// Combined score of the signals above and the dumb_check baseline (-min-confidence)
+		result.Confidence = ScoreConfidence(ConfidenceSignals{
+			StatusCode:     response.StatusCode,
+			Length:         responseLength(response.ContentLength, response.ResponseBytes),
+			ContentType:    string(response.ContentType),
+			Baseline:       s.baselineResponse(targetURL),
+			BlockedBy:      result.BlockedBy,
+			Calibrated:     calibration != nil,
+			IsLikelyBypass: result.IsLikelyBypass,
+		})
+		if result.Confidence < s.scannerOpts.MinConfidence {
+			lowConfidenceSkipped++
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+
+		// Keep the first finding per status, length and title of the target URL, across modules (-unique),
+		// claimed once all the drop filters passed
+		if s.scannerOpts.Unique &&
+			!ts.firstUniqueFinding(response.StatusCode, responseLength(response.ContentLength, response.ResponseBytes), string(response.Title)) {
+			uniqueSkipped++
+			rawhttp.ReleaseResponseDetails(response)
+			bar.Progress((float64(completed) / float64(totalJobs)) * 100.0)
+			continue
+		}
+		if !result.IsLikelyBypass {
+			likelyFalsePositives++
+		}
+
+		// Raw request and response head of the matched result (-dump-wire matched)
+		if s.scannerOpts.DumpWire == rawhttp.WireDumpMatched {
+			rawhttp.LogWireDump(string(response.BypassModule), string(response.DebugToken), response.RawRequest, response.ResponseHeaders, nil)
+		}
+
+		// Dedupe, -unique, the outputs and replay rely on these, they are always captured
+		result.StatusCode = response.StatusCode
+		result.ContentLength = response.ContentLength
+		result.ResponseBodyBytes = response.ResponseBytes
+		result.ContentType = string(response.ContentType)
+		result.OpenRedirect = response.OpenRedirect
+		result.ResponseTime = response.ResponseTime
+		result.CurlCMD = helpers.SanitizeNonPrintableBytes(response.CurlCommand)
+		result.DebugToken = string(response.DebugToken)
+
+		// Length relative to the original request, a different content is a strong signal
+		if baseline := s.baselineResponse(targetURL); baseline != nil {
+			result.Baseline = baseline
+			result.LengthDelta = responseLength(response.ContentLength, response.ResponseBytes) - baseline.Length
+		}
+
+		// Only populate the optional fields selected with -capture
+		capture := s.scannerOpts.CaptureFields
+		if capture == 0 {
+			capture = CaptureAll
+		}
+		if capture&CaptureHeaders != 0 {
+			result.ResponseHeaders = helpers.SanitizeNonPrintableBytes(response.ResponseHeaders)
+		}
+		if capture&CapturePreview != 0 {
+			result.ResponseBodyPreview = string(response.ResponsePreview)
+		}
+		if capture&CaptureTitle != 0 {
+			result.Title = string(response.Title)
+		}
+		if capture&CaptureServer != 0 {
+			result.ServerInfo = string(response.ServerInfo)
+		}
+		if capture&CaptureRedirect != 0 {
+			result.RedirectURL = helpers.SanitizeNonPrintableBytes(response.RedirectURL)
 		}
 
 		rawhttp.ReleaseResponseDetails(response)
@@ -316,26 +668,165 @@ func (s *Scanner) RunBypassModule(bypassModule string, targetURL string) int {
 		progressPercent = min(progressPercent, 100.0)
 		bar.Progress(progressPercent)
 
-		dbWg.Add(1)
-		go func(res *Result) {
-			defer dbWg.Done()
-			if err := AppendResultsToDB([]*Result{res}); err != nil {
-				GB403Logger.Error().Msgf("Failed to write result to DB: %v\n\n", err)
-			} else {
-				resultCount.Add(1)
-			}
-		}(result)
+		// First finding cancels everything, responses already queued are still drained and saved
+		if s.scannerOpts.StopAllOnFind {
+			s.stopAll()
+			worker.requestPool.Cancel()
+		}
+
+		// Bonus finding, reported once the progress bar is done
+		if result.OpenRedirect {
+			openRedirects = append(openRedirects, result)
+		}
 
+		// Near-identical findings are clustered once all responses are in (-dedupe-responses)
+		if s.scannerOpts.DedupeResponses {
+			pendingResults = append(pendingResults, result)
+			continue
+		}
+
+		if s.scannerOpts.SaveBodies {
+			s.saveResponseBody(worker, result)
+		}
+		if s.scannerOpts.ExportHTTPDir != "" {
+			s.exportHTTPRequest(result)
+		}
+		s.writeResult(&dbWg, &resultCount, targetURL, result)
 	}
 
 	bar.End()
 	fmt.Println()
 
+	for _, res := range openRedirects {
+		GB403Logger.Warning().Msgf("Possible open redirect [%s] -> %s\n%s\n", res.BypassModule, res.RedirectURL, res.CurlCMD)
+	}
+	for _, res := range desyncs {
+		GB403Logger.Warning().Msgf("Possible request smuggling [%s] %s (%d ms)\n%s\n", res.BypassModule, res.Desync, res.ResponseTime, res.CurlCMD)
+	}
+
+	if likelyFalsePositives > 0 {
+		GB403Logger.Verbose().Msgf("[%s] %d findings match the calibration responses (is_likely_bypass=0)\n", bypassModule, likelyFalsePositives)
+	}
+	if deniedSkipped > 0 {
+		GB403Logger.Verbose().Msgf("[%s] %d responses skipped, same denied status as the original request (-show-denied to keep them)\n", bypassModule, deniedSkipped)
+	}
+	if lowConfidenceSkipped > 0 {
+		GB403Logger.Verbose().Msgf("[%s] %d findings skipped, confidence below %.2f (-min-confidence)\n", bypassModule, lowConfidenceSkipped, s.scannerOpts.MinConfidence)
+	}
+	if uniqueSkipped > 0 {
+		GB403Logger.Verbose().Msgf("[%s] %d findings skipped, same status, length and title as a previous finding (-unique)\n", bypassModule, uniqueSkipped)
+	}
+
 	dbWg.Wait()
 
+	if len(pendingResults) > 0 {
+		uniqueResults := DedupeResults(pendingResults)
+		GB403Logger.Verbose().Msgf("[%s] %d findings clustered into %d unique responses\n", bypassModule, len(pendingResults), len(uniqueResults))
+
+		if s.scannerOpts.SaveBodies {
+			for _, res := range uniqueResults {
+				s.saveResponseBody(worker, res)
+			}
+		}
+		if s.scannerOpts.ExportHTTPDir != "" {
+			for _, res := range uniqueResults {
+				s.exportHTTPRequest(res)
+			}
+		}
+
+		if err := AppendResultsToDB(uniqueResults); err != nil {
+			GB403Logger.Error().Msgf("Failed to write results to DB: %v\n\n", err)
+		} else {
+			resultCount.Add(int32(len(uniqueResults)))
+		}
+
+		for _, w := range s.resultWriters {
+			for _, res := range uniqueResults {
+				w.WriteResult(targetURL, res)
+			}
+		}
+	}
+
+	stopProgressEvents()
+	s.progressEvents.emit("module_end", targetURL, bypassModule, totalJobs, worker.requestPool, &resultCount, start)
+
+	s.recordModuleStats(ModuleStats{
+		TargetURL:    targetURL,
+		BypassModule: bypassModule,
+		Payloads:     totalJobs,
+		Requests:     worker.requestPool.GetReqWPSentRequests(),
+		Findings:     int(resultCount.Load()),
+		AvgRate:      worker.requestPool.GetAverageRequestRate(),
+		Duration:     time.Since(start),
+	}, live)
+
 	return int(resultCount.Load())
 }
 
+// request_smuggling_probe responses this much slower than the dumb_check response hint at a desync
+const (
+	desyncSlowFactor = 5    // times the dumb_check response time
+	desyncMinDelayMs = 5000 // and at least this many milliseconds more
+)
+
+// isSlowResponse reports whether a response took far longer than the dumb_check response of targetURL,
+// false when dumb_check wasn't sent
+func (s *Scanner) isSlowResponse(targetURL string, responseTime int64) bool {
+	baseline := s.baselineResponse(targetURL)
+	if baseline == nil {
+		return false
+	}
+	return responseTime >= desyncSlowFactor*baseline.ResponseTime && responseTime-baseline.ResponseTime >= desyncMinDelayMs
+}
+
+// newDesyncResult builds the finding of a request_smuggling_probe that timed out (status code 0)
+// or was answered far slower than dumb_check, tagged with its probe variant
+func (s *Scanner) newDesyncResult(targetURL string, response *rawhttp.RawHTTPResponseDetails, variant string) *Result {
+	result := &Result{
+		TargetURL:           string(response.URL),
+		BypassModule:        string(response.BypassModule),
+		StatusCode:          response.StatusCode,
+		ResponseHeaders:     helpers.SanitizeNonPrintableBytes(response.ResponseHeaders),
+		ResponseBodyPreview: string(response.ResponsePreview),
+		ContentType:         string(response.ContentType),
+		ContentLength:       response.ContentLength,
+		ResponseBodyBytes:   response.ResponseBytes,
+		Title:               string(response.Title),
+		ServerInfo:          string(response.ServerInfo),
+		ResponseTime:        response.ResponseTime,
+		CurlCMD:             helpers.SanitizeNonPrintableBytes(response.CurlCommand),
+		DebugToken:          string(response.DebugToken),
+		IsLikelyBypass:      true,
+		Desync:              variant,
+	}
+	if baseline := s.baselineResponse(targetURL); baseline != nil && !response.TimedOut {
+		result.Baseline = baseline
+		result.LengthDelta = responseLength(response.ContentLength, response.ResponseBytes) - baseline.Length
+	}
+	if s.scannerOpts.DumpWire == rawhttp.WireDumpMatched {
+		rawhttp.LogWireDump(string(response.BypassModule), string(response.DebugToken), response.RawRequest, response.ResponseHeaders, nil)
+	}
+	return result
+}
+
+// writeResult saves a finding to the findings DB in the background (counted in resultCount once
+// written) and streams it to the result writers (-webhook, -csv, -sarif)
+func (s *Scanner) writeResult(dbWg *sync.WaitGroup, resultCount *atomic.Int32, targetURL string, result *Result) {
+	dbWg.Add(1)
+	go func(res *Result) {
+		defer dbWg.Done()
+		if err := AppendResultsToDB([]*Result{res}); err != nil {
+			GB403Logger.Error().Msgf("Failed to write result to DB: %v\n\n", err)
+		} else {
+			resultCount.Add(1)
+		}
+	}(result)
+
+	for _, w := range s.resultWriters {
+		w.WriteResult(targetURL, result)
+	}
+}
+
 // ResendRequestFromToken
 // Resend a request from a payload token (debug token)
 func (s *Scanner) ResendRequestFromToken(debugToken string, resendCount int) ([]*Result, error) {
@@ -352,6 +843,7 @@ func (s *Scanner) ResendRequestFromToken(debugToken string, resendCount int) ([]
 		Host:         tokenData.Host,
 		RawURI:       tokenData.RawURI,
 		Headers:      tokenData.Headers,
+		Body:         tokenData.Body,
 		BypassModule: tokenData.BypassModule,
 	}
 
@@ -378,7 +870,7 @@ func (s *Scanner) ResendRequestFromToken(debugToken string, resendCount int) ([]
 	bar := NewProgressBar(prefix, progressbar.BlueBar, 1, &s.progressBarEnabled)
 	bar.Progress(0)
 
-	responses := worker.requestPool.ProcessRequests(jobs)
+	responses := worker.requestPool.ProcessRequests(s.ctx, jobs)
 	var results []*Result
 
 	for response := range responses {
@@ -393,6 +885,10 @@ func (s *Scanner) ResendRequestFromToken(debugToken string, resendCount int) ([]
 
 		// Process Valid Response
 		if matchStatusCodes(response.StatusCode, s.scannerOpts.MatchStatusCodes) {
+			if s.scannerOpts.DumpWire == rawhttp.WireDumpMatched {
+				rawhttp.LogWireDump(string(response.BypassModule), string(response.DebugToken), response.RawRequest, response.ResponseHeaders, nil)
+			}
+
 			result := &Result{
 				TargetURL:           targetURL,
 				BypassModule:        string(response.BypassModule),
@@ -408,7 +904,17 @@ func (s *Scanner) ResendRequestFromToken(debugToken string, resendCount int) ([]
 				RedirectURL:         helpers.SanitizeNonPrintableBytes(response.RedirectURL),
 				ResponseTime:        response.ResponseTime,
 				DebugToken:          string(response.DebugToken),
+				IsLikelyBypass:      true,
+				BlockedBy:           classifyBlockPage(response.ResponseHeaders, response.ResponsePreview),
 			}
+			result.Confidence = ScoreConfidence(ConfidenceSignals{
+				StatusCode:     response.StatusCode,
+				Length:         responseLength(response.ContentLength, response.ResponseBytes),
+				ContentType:    result.ContentType,
+				Baseline:       s.baselineResponse(targetURL),
+				BlockedBy:      result.BlockedBy,
+				IsLikelyBypass: true,
+			})
 			results = append(results, result)
 		}
 
@@ -442,6 +948,105 @@ func (s *Scanner) ResendRequestFromToken(debugToken string, resendCount int) ([]
 	return results, nil
 }
 
+// PrintReplay prints the request of the debug token as sent (-H headers included),
+// followed by the headers and body preview of each replayed response (-replay)
+func (s *Scanner) PrintReplay(w io.Writer, debugToken string, results []*Result) error {
+	bypassPayload, err := payload.DecodePayloadToken(debugToken)
+	if err != nil {
+		return fmt.Errorf("failed to decode debug token: %w", err)
+	}
+
+	clientOpts := &rawhttp.HTTPClientOptions{
+		CustomHTTPHeaders:   s.scannerOpts.CustomHTTPHeaders,
+		AuthHeaders:         s.scannerOpts.AuthHeaders,
+		PreserveHeaderOrder: s.scannerOpts.PreserveHeaderOrder,
+	}
+	clientOpts.PreprocessCustomHeaders()
+	bypassPayload.Headers = rawhttp.RequestHeaders(bypassPayload, clientOpts)
+
+	fmt.Fprintln(w, "=== Request ===")
+	w.Write(payload.BypassPayloadToRawHTTPFile(bypassPayload))
+
+	for i, res := range results {
+		fmt.Fprintf(w, "\n=== Response %d/%d (%d ms) ===\n", i+1, len(results), res.ResponseTime)
+		fmt.Fprint(w, strings.ReplaceAll(res.ResponseHeaders, "\r\n", "\n"))
+		if res.ResponseBodyPreview != "" {
+			fmt.Fprintln(w, res.ResponseBodyPreview)
+		}
+	}
+	return nil
+}
+
+// ReplayFindingsThroughProxy re-sends the request of every finding saved for targetURL
+// through proxyURL, so the findings land in the proxy history (e.g. Burp) for manual review
+func (s *Scanner) ReplayFindingsThroughProxy(ctx context.Context, targetURL string, proxyURL string) error {
+	tokens, err := GetDebugTokensFromDB(targetURL)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) == 0 {
+		GB403Logger.Verbose().Msgf("No findings to replay for %s\n", targetURL)
+		return nil
+	}
+
+	jobs := make([]payload.BypassPayload, 0, len(tokens))
+	for _, token := range tokens {
+		tokenData, err := payload.DecodePayloadToken(token)
+		if err != nil {
+			GB403Logger.Error().Msgf("Failed to decode debug token %s: %v\n", token, err)
+			continue
+		}
+
+		jobs = append(jobs, payload.BypassPayload{
+			OriginalURL:  targetURL,
+			Method:       tokenData.Method,
+			Scheme:       tokenData.Scheme,
+			Host:         tokenData.Host,
+			RawURI:       tokenData.RawURI,
+			Headers:      tokenData.Headers,
+			Body:         tokenData.Body,
+			BypassModule: tokenData.BypassModule,
+			PayloadToken: token,
+		})
+	}
+
+	totalJobs := len(jobs)
+	if totalJobs == 0 {
+		return nil
+	}
+
+	GB403Logger.Info().Msgf("Replaying %d findings for %s through proxy %s\n", totalJobs, targetURL, proxyURL)
+
+	// Same settings as the scan, only routed through the replay proxy
+	replayOpts := *s.scannerOpts
+	replayOpts.Proxy = proxyURL
+	replayOpts.ProxyURLs = nil
+
+	worker := NewBypassEngagement("replay_findings", targetURL, &replayOpts, totalJobs)
+	defer worker.Stop()
+
+	bar := NewProgressBar("[Replay] findings", progressbar.BlueBar, 1, &s.progressBarEnabled)
+	bar.Progress(0)
+
+	responses := worker.requestPool.ProcessRequests(ctx, jobs)
+	for response := range responses {
+		if response != nil {
+			rawhttp.ReleaseResponseDetails(response)
+		}
+
+		completed := worker.requestPool.GetReqWPCompletedTasks()
+		progressPercent := (float64(completed) / float64(totalJobs)) * 100.0
+		bar.Progress(min(progressPercent, 100.0))
+	}
+
+	bar.Progress(100.0)
+	bar.End()
+	fmt.Println()
+
+	return nil
+}
+
 // match HTTP status code in list
 // if codes is nil, match all status codes
 func matchStatusCodes(code int, codes []int) bool {
@@ -450,3 +1055,113 @@ func matchStatusCodes(code int, codes []int) bool {
 	}
 	return slices.Contains(codes, code)
 }
+
+// match regex against the response headers or body preview
+func matchRegex(rx *regexp.Regexp, response *rawhttp.RawHTTPResponseDetails) bool {
+	return rx.Match(response.ResponseHeaders) || rx.Match(response.ResponsePreview)
+}
+
+// SizeRange is an inclusive response size range, Min == Max for exact sizes
+type SizeRange struct {
+	Min int64
+	Max int64
+}
+
+// ParseSizeRanges parses a comma separated list of sizes and ranges (example: 1234,100-200)
+func ParseSizeRanges(sizes string) ([]SizeRange, error) {
+	var ranges []SizeRange
+	for _, part := range strings.Split(sizes, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		minStr, maxStr, isRange := strings.Cut(part, "-")
+		if !isRange {
+			maxStr = minStr
+		}
+		minSize, err := strconv.ParseInt(strings.TrimSpace(minStr), 10, 64)
+		if err != nil || minSize < 0 {
+			return nil, fmt.Errorf("invalid size: %s", part)
+		}
+		maxSize, err := strconv.ParseInt(strings.TrimSpace(maxStr), 10, 64)
+		if err != nil || maxSize < minSize {
+			return nil, fmt.Errorf("invalid size range: %s", part)
+		}
+		ranges = append(ranges, SizeRange{Min: minSize, Max: maxSize})
+	}
+	return ranges, nil
+}
+
+// belowMinLength reports whether a response is shorter than minLength, from its Content-Length or,
+// when unknown (-1, chunked), the body bytes read. A full preview may hide a longer body, it is kept
+func belowMinLength(response *rawhttp.RawHTTPResponseDetails, minLength, previewSize int) bool {
+	if response.ContentLength >= 0 {
+		return response.ContentLength < int64(minLength)
+	}
+	if previewSize <= 0 {
+		previewSize = rawhttp.DefaultResponseBodyPreviewSize
+	}
+	if response.ResponseBytes >= previewSize {
+		return false
+	}
+	return response.ResponseBytes < minLength
+}
+
+// match response size in any of the ranges
+func matchSizes(size int64, ranges []SizeRange) bool {
+	for _, r := range ranges {
+		if size >= r.Min && size <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// HeaderMatcher matches a response header by name (case-insensitive) and, if set,
+// a case-insensitive substring of its value
+type HeaderMatcher struct {
+	Name  []byte // Lowercased header name
+	Value []byte // Lowercased value substring, empty matches any value
+}
+
+// ParseHeaderMatcher parses a header matcher, "Name: value" or "Name" (example: "Server: nginx")
+func ParseHeaderMatcher(header string) (HeaderMatcher, error) {
+	name, value, _ := strings.Cut(header, ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return HeaderMatcher{}, fmt.Errorf("invalid header: %q", header)
+	}
+	return HeaderMatcher{
+		Name:  []byte(strings.ToLower(name)),
+		Value: []byte(strings.ToLower(value)),
+	}, nil
+}
+
+// Matches reports whether the raw response headers (status line first) contain the header
+func (m HeaderMatcher) Matches(headers []byte) bool {
+	_, rest, _ := bytes.Cut(headers, []byte("\r\n"))
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\r\n"))
+
+		name, value, ok := bytes.Cut(line, []byte(":"))
+		if !ok || !bytes.EqualFold(bytes.TrimSpace(name), m.Name) {
+			continue
+		}
+		if len(m.Value) == 0 || bytes.Contains(bytes.ToLower(value), m.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// match response headers against any of the header matchers
+func matchHeaders(headers []byte, matchers []HeaderMatcher) bool {
+	for _, m := range matchers {
+		if m.Matches(headers) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"sync"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+const (
+	// Length tolerance used when comparing against the calibration samples,
+	// whichever is bigger: a fixed number of bytes or a percentage of the sample length
+	calibrationLengthToleranceBytes   = 32
+	calibrationLengthTolerancePercent = 2
+
+	// Last segment prefix of the bogus control paths
+	calibrationBogusPrefix = "gb403-"
+)
+
+// CalibrationSample is the fingerprint of one control response
+type CalibrationSample struct {
+	RawURI        string `json:"raw_uri"`
+	StatusCode    int    `json:"status_code"`
+	ContentLength int64  `json:"content_length"` // -1 when unknown (e.g. chunked)
+	BodyBytes     int    `json:"body_bytes"`     // bytes read from the body preview
+	BodyHash      string `json:"body_hash"`      // hash of the normalized body preview
+}
+
+// CalibrationBaseline holds the "denied" control responses of a target URL
+type CalibrationBaseline struct {
+	Samples []CalibrationSample `json:"samples"`
+}
+
+// lengthsClose reports whether candLen is within the length tolerance of baseLen
+func lengthsClose(baseLen, candLen int64) bool {
+	tolerance := max(int64(calibrationLengthToleranceBytes), baseLen*calibrationLengthTolerancePercent/100)
+	diff := candLen - baseLen
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
+
+// Matches reports whether a response looks like one of the denied control responses
+func (cb *CalibrationBaseline) Matches(sample CalibrationSample) bool {
+	if cb == nil {
+		return false
+	}
+
+	for _, s := range cb.Samples {
+		if s.StatusCode != sample.StatusCode {
+			continue
+		}
+		if s.BodyHash == sample.BodyHash {
+			return true
+		}
+
+		// Some targets return a slightly different length per request, use a tolerance window
+		if lengthsClose(responseLength(s.ContentLength, s.BodyBytes), responseLength(sample.ContentLength, sample.BodyBytes)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newCalibrationSample fingerprints a response, stripping dynamic content before hashing
+func (s *Scanner) newCalibrationSample(response *rawhttp.RawHTTPResponseDetails, rawURI string) CalibrationSample {
+	body := response.ResponsePreview
+	if s.calibrationNormalizer != nil {
+		body = s.calibrationNormalizer.Normalize(body)
+	}
+
+	h := fnv.New64a()
+	h.Write(body)
+
+	return CalibrationSample{
+		RawURI:        rawURI,
+		StatusCode:    response.StatusCode,
+		ContentLength: response.ContentLength,
+		BodyBytes:     response.ResponseBytes,
+		BodyHash:      fmt.Sprintf("%016x", h.Sum64()),
+	}
+}
+
+// calibrationEntry is the calibration baseline of a target URL, its lock is held while the
+// control requests are sent so only the modules of that URL wait for them
+type calibrationEntry struct {
+	mu       sync.Mutex
+	baseline *CalibrationBaseline
+}
+
+// getCalibration returns the calibration baseline of a target URL, sending the control requests once.
+// A failed (or interrupted) calibration isn't cached, the next module of the URL retries it.
+func (s *Scanner) getCalibration(ctx context.Context, targetURL string) *CalibrationBaseline {
+	s.calibrationMu.Lock()
+	entry, ok := s.calibrations[targetURL]
+	if !ok {
+		entry = &calibrationEntry{}
+		s.calibrations[targetURL] = entry
+	}
+	s.calibrationMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.baseline == nil {
+		entry.baseline = s.calibrate(ctx, targetURL)
+	}
+	return entry.baseline
+}
+
+// calibrate sends the control requests (the original request plus a couple of bogus paths)
+// and records their fingerprints
+func (s *Scanner) calibrate(ctx context.Context, targetURL string) *CalibrationBaseline {
+	parsedURL, err := rawurlparser.RawURLParse(targetURL)
+	if err != nil {
+		GB403Logger.Error().Msgf("Calibration failed, cannot parse %s: %v\n", targetURL, err)
+		return nil
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "dumb_check",
+	})
+	jobs := pg.GenerateDumbCheckPayload(targetURL, "dumb_check")
+
+	// Obviously bogus paths, at the root and next to the target path
+	path := strings.TrimRight(parsedURL.Path, "/")
+	for _, rawURI := range []string{
+		fmt.Sprintf("/%s%x", calibrationBogusPrefix, rand.Uint64()),
+		fmt.Sprintf("%s/%s%x", path, calibrationBogusPrefix, rand.Uint64()),
+	} {
+		job := payload.BypassPayload{
+			OriginalURL:  targetURL,
+			Method:       "GET",
+			Scheme:       parsedURL.Scheme,
+			Host:         parsedURL.Host,
+			RawURI:       rawURI,
+			BypassModule: "dumb_check",
+		}
+		job.PayloadToken = payload.GeneratePayloadToken(job)
+		jobs = append(jobs, job)
+	}
+
+	worker := NewBypassEngagement("dumb_check", targetURL, s.scannerOpts, len(jobs))
+	defer worker.Stop()
+
+	// Map the responses back to the request URI through their debug token
+	rawURIs := make(map[string]string, len(jobs))
+	for _, job := range jobs {
+		rawURIs[job.PayloadToken] = job.RawURI
+	}
+
+	cb := &CalibrationBaseline{}
+	for response := range worker.requestPool.ProcessRequests(ctx, jobs) {
+		if response == nil {
+			continue
+		}
+		cb.Samples = append(cb.Samples, s.newCalibrationSample(response, rawURIs[string(response.DebugToken)]))
+		rawhttp.ReleaseResponseDetails(response)
+	}
+
+	if len(cb.Samples) == 0 {
+		GB403Logger.Warning().Msgf("Calibration failed for %s: no control responses\n", targetURL)
+		return nil
+	}
+
+	for _, sample := range cb.Samples {
+		GB403Logger.Verbose().Msgf("Calibration [%s] %s -> status %d, length %d, hash %s\n",
+			targetURL, sample.RawURI, sample.StatusCode, responseLength(sample.ContentLength, sample.BodyBytes), sample.BodyHash)
+	}
+
+	return cb
+}
@@ -0,0 +1,134 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Checkpoint tracks the (target URL, bypass module) pairs completed by a scan, used by -resume
+type Checkpoint struct {
+	Modules   string              `json:"modules"`
+	URLsHash  string              `json:"urls_hash"`
+	URLCount  int                 `json:"url_count"`
+	Completed map[string][]string `json:"completed"` // target URL -> completed bypass modules
+	UpdatedAt string              `json:"updated_at"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// hashURLs fingerprints the URL set of a scan, regardless of order
+func hashURLs(urls []string) string {
+	sorted := slices.Clone(urls)
+	slices.Sort(sorted)
+
+	h := sha256.New()
+	for _, u := range sorted {
+		h.Write([]byte(u))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewCheckpoint creates an empty checkpoint for the given URL set and bypass modules
+func NewCheckpoint(path string, urls []string, modules string) *Checkpoint {
+	return &Checkpoint{
+		Modules:   modules,
+		URLsHash:  hashURLs(urls),
+		URLCount:  len(urls),
+		Completed: make(map[string][]string),
+		path:      path,
+	}
+}
+
+// LoadCheckpoint reads a checkpoint written by a previous run
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %v", path, err)
+	}
+	if cp.Completed == nil {
+		cp.Completed = make(map[string][]string)
+	}
+	cp.path = path
+	return cp, nil
+}
+
+// Validate checks the checkpoint was written by a scan of the same URLs and bypass modules
+func (cp *Checkpoint) Validate(urls []string, modules string) error {
+	if cp.Modules != modules {
+		return fmt.Errorf("checkpoint was created for modules %q, current modules are %q", cp.Modules, modules)
+	}
+	if cp.URLsHash != hashURLs(urls) {
+		return fmt.Errorf("checkpoint was created for a different URL set (%d URLs, current scan has %d)", cp.URLCount, len(urls))
+	}
+	return nil
+}
+
+// IsCompleted reports whether bypassModule already ran to completion against targetURL
+func (cp *Checkpoint) IsCompleted(targetURL, bypassModule string) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return slices.Contains(cp.Completed[targetURL], bypassModule)
+}
+
+// MarkCompleted records a completed (target URL, bypass module) pair and persists the checkpoint
+func (cp *Checkpoint) MarkCompleted(targetURL, bypassModule string) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if !slices.Contains(cp.Completed[targetURL], bypassModule) {
+		cp.Completed[targetURL] = append(cp.Completed[targetURL], bypassModule)
+	}
+	return cp.save()
+}
+
+// save writes the checkpoint atomically (temp file + rename), so an interrupted write never corrupts it
+func (cp *Checkpoint) save() error {
+	cp.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cp.path), ".checkpoint-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync checkpoint: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), cp.path); err != nil {
+		return fmt.Errorf("failed to rename checkpoint: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"math"
+	"strings"
+)
+
+// Weights of the signals combined into Result.Confidence, in points out of 100. A signal that
+// can't be evaluated (no dumb_check baseline, no -calibrate control responses) counts half its
+// weight, so a finding is neither rewarded nor penalized for a check that didn't run. Points keep
+// the half weights exact, the same signals always round to the same score.
+const (
+	// The status differs from the baseline status: full weight for a success or redirect
+	// (e.g. 403 -> 200), half for another error (e.g. 403 -> 404)
+	confidenceWeightStatus float64 = 35
+	// The length differs from the baseline length, scaled by the relative delta up to
+	// confidenceFullLengthDelta (a delta of half the baseline length or more gets full weight)
+	confidenceWeightLength float64 = 20
+	// The media type differs from the baseline one (e.g. text/html -> application/json)
+	confidenceWeightContentType float64 = 10
+	// The response is not a known WAF/CDN block page (Result.BlockedBy)
+	confidenceWeightNotBlocked float64 = 15
+	// The response differs from the -calibrate control responses (Result.IsLikelyBypass)
+	confidenceWeightCalibration float64 = 20
+
+	confidenceFullLengthDelta = 0.5
+)
+
+// ConfidenceSignals are the inputs of ScoreConfidence, taken from the response of a finding
+type ConfidenceSignals struct {
+	StatusCode     int
+	Length         int64             // Content-Length, or the body bytes read without one
+	ContentType    string            // Content-Type header of the response
+	Baseline       *BaselineResponse // dumb_check response of the target URL, nil if it wasn't sent
+	BlockedBy      string            // WAF/CDN block page detected in the response
+	Calibrated     bool              // The finding was compared against -calibrate control responses
+	IsLikelyBypass bool              // Result of that comparison
+}
+
+// ScoreConfidence combines the signals of a finding into a score from 0 (most likely still denied)
+// to 1 (most likely a bypass), rounded to 2 decimals. The score only depends on the signals.
+func ScoreConfidence(sig ConfidenceSignals) float64 {
+	score := 0.0
+
+	if sig.Baseline == nil {
+		score += (confidenceWeightStatus + confidenceWeightLength + confidenceWeightContentType) / 2
+	} else {
+		if sig.StatusCode != sig.Baseline.StatusCode {
+			if sig.StatusCode < 400 {
+				score += confidenceWeightStatus
+			} else {
+				score += confidenceWeightStatus / 2
+			}
+		}
+
+		delta := math.Abs(float64(sig.Length - sig.Baseline.Length))
+		if sig.Baseline.Length > 0 {
+			score += confidenceWeightLength * min(delta/float64(sig.Baseline.Length)/confidenceFullLengthDelta, 1)
+		} else if delta > 0 {
+			score += confidenceWeightLength
+		}
+
+		if mediaType(sig.ContentType) != mediaType(sig.Baseline.ContentType) {
+			score += confidenceWeightContentType
+		}
+	}
+
+	if sig.BlockedBy == "" {
+		score += confidenceWeightNotBlocked
+	}
+
+	switch {
+	case !sig.Calibrated:
+		score += confidenceWeightCalibration / 2
+	case sig.IsLikelyBypass:
+		score += confidenceWeightCalibration
+	}
+
+	return math.Round(min(max(score, 0), 100)) / 100
+}
+
+// mediaType returns the lower case media type of a Content-Type, without its parameters
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i != -1 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
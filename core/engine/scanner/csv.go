@@ -0,0 +1,106 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// CSVHeader is the header row of the -csv output
+var CSVHeader = []string{
+	"target_url",
+	"bypass_module",
+	"status_code",
+	"content_length",
+	"content_type",
+	"title",
+	"server",
+	"redirect_url",
+	"debug_token",
+	"curl_cmd",
+}
+
+// CSVWriter streams findings as CSV rows, flushing after every row so partial results survive a crash
+type CSVWriter struct {
+	mu        sync.Mutex
+	file      *os.File
+	writer    *csv.Writer
+	closeOnce sync.Once
+}
+
+// NewCSVWriter creates (truncates) the CSV file and writes the header row
+func NewCSVWriter(path string) (*CSVWriter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create CSV output directory: %v", err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CSV file: %v", err)
+	}
+
+	w := &CSVWriter{
+		file:   file,
+		writer: csv.NewWriter(file),
+	}
+	if err := w.writeRow(CSVHeader); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *CSVWriter) writeRow(row []string) error {
+	if err := w.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write CSV row: %v", err)
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// WriteResult appends a finding as a CSV row
+func (w *CSVWriter) WriteResult(targetURL string, res *Result) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.writeRow([]string{
+		res.TargetURL,
+		res.BypassModule,
+		strconv.Itoa(res.StatusCode),
+		strconv.FormatInt(res.ContentLength, 10),
+		res.ContentType,
+		res.Title,
+		res.ServerInfo,
+		res.RedirectURL,
+		res.DebugToken,
+		res.CurlCMD,
+	})
+	if err != nil {
+		GB403Logger.Error().Msgf("%v\n", err)
+	}
+}
+
+// Close flushes and closes the CSV file
+func (w *CSVWriter) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.writer.Flush()
+		if err := w.file.Close(); err != nil {
+			GB403Logger.Error().Msgf("Failed to close CSV file: %v\n", err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// decodedToken is a decoded debug token as printed by -decode-token
+type decodedToken struct {
+	BypassModule string         `json:"bypass_module"`
+	URL          string         `json:"url"`
+	Method       string         `json:"method"`
+	Scheme       string         `json:"scheme"`
+	Host         string         `json:"host"`
+	RawURI       string         `json:"raw_uri"`
+	Headers      []dryRunHeader `json:"headers,omitempty"`
+	Body         string         `json:"body,omitempty"`
+	DebugToken   string         `json:"debug_token"`
+}
+
+// PrintDecodedToken decodes a debug token and prints the request it stands for, as fields, as a
+// .http request and as JSON (-decode-token). Nothing is sent. A token missing the scheme, host or
+// method (truncated, or from another version) is printed as far as it decodes, with a warning.
+func PrintDecodedToken(w io.Writer, token string) error {
+	token = payload.NormalizePayloadToken(token)
+	bp, err := payload.DecodePayloadToken(token)
+	if err != nil {
+		return fmt.Errorf("invalid debug token: %w", err)
+	}
+	if bp.Scheme == "" || bp.Host == "" || bp.Method == "" {
+		GB403Logger.Warning().Msgf("The token has no scheme, host or method, it may be truncated or come from another version\n")
+	}
+
+	decoded := decodedToken{
+		BypassModule: bp.BypassModule,
+		Method:       bp.Method,
+		Scheme:       bp.Scheme,
+		Host:         bp.Host,
+		RawURI:       bp.RawURI,
+		Body:         bp.Body,
+		DebugToken:   token,
+	}
+	if bp.Scheme != "" && bp.Host != "" {
+		decoded.URL = payload.BypassPayloadToFullURL(bp)
+	}
+	for _, h := range bp.Headers {
+		decoded.Headers = append(decoded.Headers, dryRunHeader{Name: h.Header, Value: h.Value})
+	}
+
+	orDash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	fmt.Fprintln(w, "=== Decoded Token ===")
+	fmt.Fprintf(w, "Bypass module: %s\n", orDash(decoded.BypassModule))
+	fmt.Fprintf(w, "URL:           %s\n", orDash(decoded.URL))
+	fmt.Fprintf(w, "Method:        %s\n", orDash(decoded.Method))
+	fmt.Fprintf(w, "Scheme:        %s\n", orDash(decoded.Scheme))
+	fmt.Fprintf(w, "Host:          %s\n", orDash(decoded.Host))
+	fmt.Fprintf(w, "Raw URI:       %s\n", orDash(decoded.RawURI))
+	fmt.Fprintf(w, "Headers:       %d\n", len(decoded.Headers))
+	for _, h := range decoded.Headers {
+		fmt.Fprintf(w, "  %s: %s\n", h.Name, h.Value)
+	}
+	fmt.Fprintf(w, "Body:          %d bytes\n", len(decoded.Body))
+
+	fmt.Fprintln(w, "\n=== Request ===")
+	w.Write(payload.BypassPayloadToRawHTTPFile(bp))
+
+	fmt.Fprintln(w, "\n=== JSON ===")
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(decoded)
+}
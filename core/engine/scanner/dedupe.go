@@ -0,0 +1,126 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math/bits"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Max number of differing simhash bits for two bodies to be considered the same page.
+// Body previews are short, so a single reflected word flips more bits than on full pages;
+// unrelated bodies differ by ~32 bits.
+const dedupeSimhashDistance = 10
+
+// resultCluster groups near-identical findings behind a representative
+type resultCluster struct {
+	representative *Result
+	simhash        uint64
+	length         int64
+}
+
+// responseSimhash computes a 64-bit simhash over the words of a response body.
+// Bodies differing only by a few words (e.g. a reflected path) get close hashes.
+func responseSimhash(body []byte) uint64 {
+	var weights [64]int
+	words := bytes.FieldsFunc(body, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	for _, word := range words {
+		// Skip single chars, they carry no signal
+		if utf8.RuneCount(word) < 2 {
+			continue
+		}
+		h.Reset()
+		h.Write(bytes.ToLower(word))
+		sum := h.Sum64()
+		for i := range 64 {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var simhash uint64
+	for i, w := range weights {
+		if w > 0 {
+			simhash |= 1 << i
+		}
+	}
+	return simhash
+}
+
+// DedupeResults clusters near-identical findings (same status code and title, similar length and body)
+// and returns one representative per cluster, in order, with DuplicateCount set to the number of
+// findings merged into it
+func DedupeResults(results []*Result) []*Result {
+	var clusters []*resultCluster
+
+	for _, res := range results {
+		simhash := responseSimhash([]byte(res.ResponseBodyPreview))
+		// Same length as the results table
+		length := responseLength(res.ContentLength, res.ResponseBodyBytes)
+
+		merged := false
+		for _, c := range clusters {
+			rep := c.representative
+			if rep.StatusCode != res.StatusCode || rep.Title != res.Title {
+				continue
+			}
+			if !lengthsClose(c.length, length) || bits.OnesCount64(c.simhash^simhash) > dedupeSimhashDistance {
+				continue
+			}
+			rep.DuplicateCount++
+			merged = true
+			break
+		}
+
+		if !merged {
+			clusters = append(clusters, &resultCluster{
+				representative: res,
+				simhash:        simhash,
+				length:         length,
+			})
+		}
+	}
+
+	representatives := make([]*Result, 0, len(clusters))
+	for _, c := range clusters {
+		representatives = append(representatives, c.representative)
+	}
+	return representatives
+}
+
+// uniqueFindingKey identifies a finding for -unique, an exact match unlike DedupeResults
+type uniqueFindingKey struct {
+	statusCode int
+	length     int64
+	title      string
+}
+
+// firstUniqueFinding reports whether no finding of the target URL had this status code,
+// length and title yet, and records it
+func (ts *targetState) firstUniqueFinding(statusCode int, length int64, title string) bool {
+	key := uniqueFindingKey{statusCode: statusCode, length: length, title: title}
+
+	ts.uniqueMu.Lock()
+	defer ts.uniqueMu.Unlock()
+	if _, ok := ts.uniqueFindings[key]; ok {
+		return false
+	}
+	ts.uniqueFindings[key] = struct{}{}
+	return true
+}
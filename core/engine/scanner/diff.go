@@ -0,0 +1,281 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+// FindingKey identifies the same finding across two scans.
+// Debug tokens carry a random nonce, so the request is keyed on the decoded payload instead.
+type FindingKey struct {
+	TargetURL    string
+	BypassModule string
+	StatusCode   int
+	Request      string
+}
+
+// FindingChange is a finding present in both scans with a different response
+type FindingChange struct {
+	Old     *Result
+	New     *Result
+	Changes []string // e.g. "length: 120 -> 340"
+}
+
+// FindingsDiff holds the findings added, removed and changed between two scans
+type FindingsDiff struct {
+	Added   []*Result
+	Removed []*Result
+	Changed []FindingChange
+}
+
+// LoadFindings reads the findings of a previous scan, either a results DB (.db, or .bolt
+// with -db bolt) or a -jsonl findings file
+func LoadFindings(path string) ([]*Result, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return LoadResultsFromDB(path)
+	case ".bolt":
+		return LoadResultsFromBolt(path, "")
+	default:
+		return ReadJSONLFindings(path)
+	}
+}
+
+// findingRequest returns the request of a finding: method, raw URI, headers and body decoded
+// from its debug token, or the curl command when the token is missing (-capture)
+func findingRequest(res *Result) string {
+	if res.DebugToken == "" {
+		return res.CurlCMD
+	}
+
+	p, err := payload.DecodePayloadToken(res.DebugToken)
+	if err != nil {
+		return res.DebugToken
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.Method)
+	sb.WriteByte(' ')
+	sb.WriteString(p.RawURI)
+	for _, h := range p.Headers {
+		sb.WriteString(" | ")
+		sb.WriteString(h.Header)
+		sb.WriteString(": ")
+		sb.WriteString(h.Value)
+	}
+	if p.Body != "" {
+		sb.WriteString(" | ")
+		sb.WriteString(p.Body)
+	}
+	return sb.String()
+}
+
+// NewFindingKey returns the key of a finding
+func NewFindingKey(res *Result) FindingKey {
+	return FindingKey{
+		TargetURL:    res.TargetURL,
+		BypassModule: res.BypassModule,
+		StatusCode:   res.StatusCode,
+		Request:      findingRequest(res),
+	}
+}
+
+func (k FindingKey) less(o FindingKey) bool {
+	if k.TargetURL != o.TargetURL {
+		return k.TargetURL < o.TargetURL
+	}
+	if k.BypassModule != o.BypassModule {
+		return k.BypassModule < o.BypassModule
+	}
+	if k.StatusCode != o.StatusCode {
+		return k.StatusCode < o.StatusCode
+	}
+	return k.Request < o.Request
+}
+
+// compareFindings lists the response fields that differ between two findings with the same key
+func compareFindings(oldRes, newRes *Result) []string {
+	var changes []string
+	oldLen := responseLength(oldRes.ContentLength, oldRes.ResponseBodyBytes)
+	newLen := responseLength(newRes.ContentLength, newRes.ResponseBodyBytes)
+	if oldLen != newLen {
+		changes = append(changes, fmt.Sprintf("length: %d -> %d", oldLen, newLen))
+	}
+	if oldRes.ContentType != newRes.ContentType {
+		changes = append(changes, fmt.Sprintf("content-type: %q -> %q", oldRes.ContentType, newRes.ContentType))
+	}
+	if oldRes.Title != newRes.Title {
+		changes = append(changes, fmt.Sprintf("title: %q -> %q", oldRes.Title, newRes.Title))
+	}
+	if oldRes.RedirectURL != newRes.RedirectURL {
+		changes = append(changes, fmt.Sprintf("redirect: %q -> %q", oldRes.RedirectURL, newRes.RedirectURL))
+	}
+	return changes
+}
+
+// DiffFindings compares the findings of two scans.
+// Findings sharing a key within the same scan are only counted once (first one wins).
+// Results are sorted by target URL, module, status code and request.
+func DiffFindings(oldResults, newResults []*Result) *FindingsDiff {
+	index := func(results []*Result) (map[FindingKey]*Result, []FindingKey) {
+		byKey := make(map[FindingKey]*Result, len(results))
+		var keys []FindingKey
+		for _, res := range results {
+			key := NewFindingKey(res)
+			if _, ok := byKey[key]; ok {
+				continue
+			}
+			byKey[key] = res
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+		return byKey, keys
+	}
+
+	oldByKey, oldKeys := index(oldResults)
+	newByKey, newKeys := index(newResults)
+
+	diff := &FindingsDiff{}
+	for _, key := range oldKeys {
+		newRes, ok := newByKey[key]
+		if !ok {
+			diff.Removed = append(diff.Removed, oldByKey[key])
+			continue
+		}
+		if changes := compareFindings(oldByKey[key], newRes); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, FindingChange{Old: oldByKey[key], New: newRes, Changes: changes})
+		}
+	}
+	for _, key := range newKeys {
+		if _, ok := oldByKey[key]; !ok {
+			diff.Added = append(diff.Added, newByKey[key])
+		}
+	}
+
+	return diff
+}
+
+// diffFinding is a finding in the -diff-json output
+type diffFinding struct {
+	URL           string `json:"url"`
+	BypassModule  string `json:"bypass_module"`
+	StatusCode    int    `json:"status_code"`
+	ContentType   string `json:"content_type"`
+	ContentLength int64  `json:"content_length"`
+	Title         string `json:"title"`
+	RedirectURL   string `json:"redirect_url"`
+	Request       string `json:"request"`
+	CurlCMD       string `json:"curl_cmd"`
+	DebugToken    string `json:"debug_token"`
+}
+
+type diffChange struct {
+	Old     diffFinding `json:"old"`
+	New     diffFinding `json:"new"`
+	Changes []string    `json:"changes"`
+}
+
+type diffDocument struct {
+	Added   []diffFinding `json:"added"`
+	Removed []diffFinding `json:"removed"`
+	Changed []diffChange  `json:"changed"`
+}
+
+func newDiffFinding(res *Result) diffFinding {
+	return diffFinding{
+		URL:           res.TargetURL,
+		BypassModule:  res.BypassModule,
+		StatusCode:    res.StatusCode,
+		ContentType:   res.ContentType,
+		ContentLength: responseLength(res.ContentLength, res.ResponseBodyBytes),
+		Title:         res.Title,
+		RedirectURL:   res.RedirectURL,
+		Request:       findingRequest(res),
+		CurlCMD:       res.CurlCMD,
+		DebugToken:    res.DebugToken,
+	}
+}
+
+// WriteJSON writes the diff as a single JSON document (-diff-json)
+func (d *FindingsDiff) WriteJSON(w io.Writer) error {
+	doc := diffDocument{
+		Added:   make([]diffFinding, 0, len(d.Added)),
+		Removed: make([]diffFinding, 0, len(d.Removed)),
+		Changed: make([]diffChange, 0, len(d.Changed)),
+	}
+	for _, res := range d.Added {
+		doc.Added = append(doc.Added, newDiffFinding(res))
+	}
+	for _, res := range d.Removed {
+		doc.Removed = append(doc.Removed, newDiffFinding(res))
+	}
+	for _, c := range d.Changed {
+		doc.Changed = append(doc.Changed, diffChange{Old: newDiffFinding(c.Old), New: newDiffFinding(c.New), Changes: c.Changes})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// PrintFindingsDiff prints the added (+), removed (-) and changed (~) findings as a table
+func PrintFindingsDiff(d *FindingsDiff, oldPath, newPath string) error {
+	pterm.DefaultHeader.WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
+		Printf("Findings diff: %s -> %s", oldPath, newPath)
+
+	if len(d.Added)+len(d.Removed)+len(d.Changed) == 0 {
+		fmt.Println("No differences")
+		return nil
+	}
+
+	row := func(color pterm.Color, mark string, res *Result, details string) []string {
+		return []string{
+			color.Sprint(mark),
+			color.Sprint(res.BypassModule),
+			color.Sprint(strconv.Itoa(res.StatusCode)),
+			LimitStringWithSuffix(res.TargetURL, 50),
+			LimitStringWithSuffix(findingRequest(res), 80),
+			details,
+		}
+	}
+
+	tableData := pterm.TableData{{"", "Module", "Status", "Target", "Request", "Details"}}
+	for _, res := range d.Added {
+		tableData = append(tableData, row(pterm.FgGreen, "+", res, "length: "+strconv.FormatInt(responseLength(res.ContentLength, res.ResponseBodyBytes), 10)))
+	}
+	for _, res := range d.Removed {
+		tableData = append(tableData, row(pterm.FgRed, "-", res, "length: "+strconv.FormatInt(responseLength(res.ContentLength, res.ResponseBodyBytes), 10)))
+	}
+	for _, c := range d.Changed {
+		tableData = append(tableData, row(pterm.FgYellow, "~", c.New, strings.Join(c.Changes, ", ")))
+	}
+
+	tableStr, err := pterm.DefaultTable.
+		WithHasHeader().
+		WithBoxed().
+		WithData(tableData).
+		Srender()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %v", err)
+	}
+
+	fmt.Println(tableStr)
+	fmt.Printf("%s, %s, %s\n",
+		pterm.FgGreen.Sprintf("%d added", len(d.Added)),
+		pterm.FgRed.Sprintf("%d removed", len(d.Removed)),
+		pterm.FgYellow.Sprintf("%d changed", len(d.Changed)))
+	return nil
+}
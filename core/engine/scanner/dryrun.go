@@ -0,0 +1,122 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// dryRunPayload is a generated payload as printed by -dry-run-json
+type dryRunPayload struct {
+	TargetURL    string         `json:"target_url"`
+	BypassModule string         `json:"bypass_module"`
+	Method       string         `json:"method"`
+	Scheme       string         `json:"scheme"`
+	Host         string         `json:"host"`
+	RawURI       string         `json:"raw_uri"`
+	Headers      []dryRunHeader `json:"headers,omitempty"`
+	Body         string         `json:"body,omitempty"`
+	DebugToken   string         `json:"debug_token"`
+}
+
+type dryRunHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DryRun generates the payloads of every selected bypass module for every URL and writes them to w,
+// without sending any request (-dry-run). Payloads are written as request line + headers, or as JSON lines if asJSON.
+func (s *Scanner) DryRun(w io.Writer, asJSON bool) error {
+	defer s.Close()
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	total := 0
+	for _, targetURL := range s.urls {
+		// Same per URL dedupe as a real scan
+		s.startTarget(targetURL)
+
+		for _, module := range strings.Split(s.scannerOpts.BypassModule, ",") {
+			module = strings.TrimSpace(module)
+			if module == "" {
+				continue
+			}
+			if !IsValidBypassModule(module) {
+				GB403Logger.Error().Msgf("Invalid bypass module: %s\n", module)
+				continue
+			}
+
+			payloads := s.generatePayloads(module, targetURL)
+			GB403Logger.Info().Msgf("[dry-run] %s: %d payloads for %s\n", module, len(payloads), targetURL)
+			total += len(payloads)
+
+			for _, p := range payloads {
+				if asJSON {
+					headers := make([]dryRunHeader, 0, len(p.Headers))
+					for _, h := range p.Headers {
+						headers = append(headers, dryRunHeader{Name: h.Header, Value: h.Value})
+					}
+					if err := enc.Encode(dryRunPayload{
+						TargetURL:    targetURL,
+						BypassModule: module,
+						Method:       p.Method,
+						Scheme:       p.Scheme,
+						Host:         p.Host,
+						RawURI:       p.RawURI,
+						Headers:      headers,
+						Body:         p.Body,
+						DebugToken:   p.PayloadToken,
+					}); err != nil {
+						return fmt.Errorf("failed to write dry-run payload: %v", err)
+					}
+					continue
+				}
+				writeDryRunRequest(bw, p)
+			}
+			if err := bw.Flush(); err != nil {
+				return fmt.Errorf("failed to write dry-run payloads: %v", err)
+			}
+		}
+		s.finishTarget(targetURL)
+	}
+
+	GB403Logger.Success().Msgf("[dry-run] %d payloads generated for %d URLs, no request sent\n", total, len(s.urls))
+	return nil
+}
+
+// writeDryRunRequest writes a payload the way it goes on the wire (before client defaults like User-Agent),
+// preceded by its module and debug token
+func writeDryRunRequest(w *bufio.Writer, p payload.BypassPayload) {
+	fmt.Fprintf(w, "# [%s] %s://%s token=%s\n", p.BypassModule, p.Scheme, p.Host, p.PayloadToken)
+	fmt.Fprintf(w, "%s %s HTTP/1.1\n", p.Method, p.RawURI)
+
+	hasHost := false
+	for _, h := range p.Headers {
+		if strings.EqualFold(h.Header, "host") {
+			hasHost = true
+			break
+		}
+	}
+	if !hasHost {
+		fmt.Fprintf(w, "Host: %s\n", p.Host)
+	}
+	for _, h := range p.Headers {
+		fmt.Fprintf(w, "%s: %s\n", h.Header, h.Value)
+	}
+	if p.Body != "" {
+		fmt.Fprintf(w, "\n%s\n", p.Body)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,67 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// HTTPFileName returns the file name of the .http request file of the finding with debugToken.
+func HTTPFileName(debugToken string) string {
+	return findingFileName(debugToken, ".http")
+}
+
+// exportHTTPRequest writes the request of a finding to <ExportHTTPDir>/<debugToken>.http (-export-http)
+func (s *Scanner) exportHTTPRequest(res *Result) {
+	if res.DebugToken == "" {
+		return
+	}
+
+	bypassPayload, err := findingBypassPayload(res, s.requestHeaderOpts())
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to decode debug token to export the .http request: %v\n", err)
+		return
+	}
+
+	if err := os.MkdirAll(s.scannerOpts.ExportHTTPDir, 0o755); err != nil {
+		GB403Logger.Error().Msgf("Failed to create .http export directory: %v\n", err)
+		return
+	}
+
+	httpFile := filepath.Join(s.scannerOpts.ExportHTTPDir, HTTPFileName(res.DebugToken))
+	if err := os.WriteFile(httpFile, payload.BypassPayloadToRawHTTPFile(bypassPayload), 0o644); err != nil {
+		GB403Logger.Error().Msgf("Failed to export .http request of [%s] finding: %v\n", res.BypassModule, err)
+	}
+}
+
+// requestHeaderOpts returns the client options findingBypassPayload needs to add the -H and auth headers
+func (s *Scanner) requestHeaderOpts() *rawhttp.HTTPClientOptions {
+	clientOpts := &rawhttp.HTTPClientOptions{
+		CustomHTTPHeaders:   s.scannerOpts.CustomHTTPHeaders,
+		AuthHeaders:         s.scannerOpts.AuthHeaders,
+		PreserveHeaderOrder: s.scannerOpts.PreserveHeaderOrder,
+	}
+	clientOpts.PreprocessCustomHeaders()
+	return clientOpts
+}
+
+// findingBypassPayload rebuilds the request of a finding from its debug token, with the -H headers
+// in the order BuildRawHTTPRequest sends them (-export-http, -burp)
+func findingBypassPayload(res *Result, clientOpts *rawhttp.HTTPClientOptions) (payload.BypassPayload, error) {
+	bypassPayload, err := payload.DecodePayloadToken(res.DebugToken)
+	if err != nil {
+		return bypassPayload, err
+	}
+	// Debug tokens don't carry the original URL
+	bypassPayload.OriginalURL = res.TargetURL
+	bypassPayload.Headers = rawhttp.RequestHeaders(bypassPayload, clientOpts)
+	return bypassPayload, nil
+}
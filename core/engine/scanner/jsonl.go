@@ -0,0 +1,107 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// JSONLWriter appends one compact JSON document per finding to a .jsonl file.
+// The file is opened once in append mode, so every line is complete and the file is tailable.
+type JSONLWriter struct {
+	mu            sync.Mutex
+	file          *os.File
+	version       string
+	schemaVersion int
+	closeOnce     sync.Once
+}
+
+// NewJSONLWriter opens (or creates) the JSONL file for appending,
+// findings are written in the layout of schemaVersion (0 means OutputSchemaVersion)
+func NewJSONLWriter(path, version string, schemaVersion int) (*JSONLWriter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create JSONL output directory: %v", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSONL file: %v", err)
+	}
+
+	return &JSONLWriter{
+		file:          file,
+		version:       version,
+		schemaVersion: schemaVersion,
+	}, nil
+}
+
+// WriteResult appends a finding as a single JSON line
+func (w *JSONLWriter) WriteResult(targetURL string, res *Result) {
+	line, err := json.Marshal(NewWebhookFinding(targetURL, w.version, w.schemaVersion, res))
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to marshal JSONL finding: %v\n", err)
+		return
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.file.Write(line); err != nil {
+		GB403Logger.Error().Msgf("Failed to write JSONL finding: %v\n", err)
+	}
+}
+
+// Close closes the JSONL file
+func (w *JSONLWriter) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if err := w.file.Close(); err != nil {
+			GB403Logger.Error().Msgf("Failed to close JSONL file: %v\n", err)
+		}
+	})
+}
+
+// ReadJSONLFindings reads back the findings of a -jsonl file, blank lines are skipped
+func ReadJSONLFindings(path string) ([]*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var results []*Result
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // curl commands of big payloads make long lines
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var finding WebhookFinding
+		if err := json.Unmarshal(line, &finding); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid finding: %v", path, lineNum, err)
+		}
+		results = append(results, finding.toResult())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	return results, nil
+}
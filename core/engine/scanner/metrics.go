@@ -0,0 +1,146 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// liveModule is the bypass module currently running, reported on /stats
+type liveModule struct {
+	targetURL    string
+	bypassModule string
+	payloads     int
+	pool         *rawhttp.RequestWorkerPool
+	findings     *atomic.Int32
+}
+
+// LiveStats is the live state of the scan, served as JSON on /stats (-metrics-addr)
+type LiveStats struct {
+	Uptime          string `json:"uptime"`
+	URLsTotal       int    `json:"urls_total"`
+	URLsScanned     int    `json:"urls_scanned"`
+	URLsActive      int    `json:"urls_active"`
+	TargetURL       string `json:"target_url,omitempty"`
+	BypassModule    string `json:"bypass_module,omitempty"`
+	ModulePayloads  int    `json:"module_payloads"`
+	ModuleCompleted uint64 `json:"module_completed"`
+	ActiveWorkers   int64  `json:"active_workers"`
+	WaitingTasks    uint64 `json:"waiting_tasks"`
+	RequestRate     uint64 `json:"request_rate"`
+	AvgRequestRate  uint64 `json:"avg_request_rate"`
+	RequestsSent    uint64 `json:"requests_sent"`
+	Findings        int    `json:"findings"`
+	Goroutines      int    `json:"goroutines"`
+}
+
+// startLiveModule adds m to the modules currently running
+func (s *Scanner) startLiveModule(m *liveModule) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if s.live == nil {
+		s.live = make(map[*liveModule]struct{})
+	}
+	s.live[m] = struct{}{}
+}
+
+// stopLiveModule removes m from the modules currently running
+func (s *Scanner) stopLiveModule(m *liveModule) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	delete(s.live, m)
+}
+
+// LiveStats returns the live state of the scan: the modules currently running (one per target
+// URL scanned concurrently), their worker pool metrics and the totals of all module runs so far.
+// The target URL and module are only set when a single module is running.
+func (s *Scanner) LiveStats() LiveStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+
+	stats := LiveStats{
+		URLsTotal:   len(s.urls),
+		URLsScanned: s.scannedURLs,
+		Goroutines:  runtime.NumGoroutine(),
+	}
+	if !s.started.IsZero() {
+		stats.Uptime = time.Since(s.started).Round(time.Second).String()
+	}
+
+	for _, st := range s.moduleStats {
+		stats.RequestsSent += st.Requests
+		stats.Findings += st.Findings
+	}
+
+	stats.URLsActive = len(s.live)
+	for m := range s.live {
+		if len(s.live) == 1 {
+			stats.TargetURL = m.targetURL
+			stats.BypassModule = m.bypassModule
+		}
+		stats.ModulePayloads += m.payloads
+		stats.ModuleCompleted += m.pool.GetReqWPCompletedTasks()
+		stats.ActiveWorkers += m.pool.GetReqWPActiveWorkers()
+		stats.WaitingTasks += m.pool.GetReqWPWaitingTasks()
+		stats.RequestRate += m.pool.GetRequestRate()
+		stats.AvgRequestRate += m.pool.GetAverageRequestRate()
+		stats.RequestsSent += m.pool.GetReqWPSentRequests()
+		stats.Findings += int(m.findings.Load())
+	}
+
+	return stats
+}
+
+// MetricsHandler serves the pprof endpoints under /debug/pprof/ and the live scan stats on /stats
+func (s *Scanner) MetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.LiveStats())
+	})
+	return mux
+}
+
+// startMetricsServer starts the -metrics-addr HTTP server, the returned func shuts it down
+func (s *Scanner) startMetricsServer(addr string) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{
+		Handler:           s.MetricsHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			GB403Logger.Error().Msgf("Metrics server stopped: %v\n", err)
+		}
+	}()
+	GB403Logger.Info().Msgf("Metrics server listening on http://%s (/stats, /debug/pprof/)\n", ln.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}, nil
+}
@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 
 	"fortio.org/progressbar"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
 )
 
 // ProgressBar wraps the fortio progressbar to respect an enabled flag
@@ -23,7 +24,7 @@ func NewProgressBar(prefix string, color string, extraLines int, enabledFlag *at
 	if enabledFlag.Load() {
 		cfg := progressbar.DefaultConfig()
 		cfg.Prefix = prefix
-		cfg.UseColors = true
+		cfg.UseColors = GB403Logger.ColorEnabled()
 		cfg.ExtraLines = extraLines
 		cfg.Color = color
 		bar = cfg.NewBar()
@@ -0,0 +1,111 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+// progressEventInterval is the period of the module_progress events (-progress-json)
+const progressEventInterval = time.Second
+
+// ProgressEvent is a -progress-json event, written as one JSON line.
+// Event is module_start, module_progress or module_end.
+type ProgressEvent struct {
+	Event        string `json:"event"`
+	Timestamp    string `json:"timestamp"`
+	TargetURL    string `json:"target_url"`
+	BypassModule string `json:"module"`
+	Total        int    `json:"total"`
+	Completed    uint64 `json:"completed"`
+	Rate         uint64 `json:"rate"`
+	AvgRate      uint64 `json:"avg_rate"`
+	Findings     int    `json:"findings"`
+	DurationMs   int64  `json:"duration_ms,omitempty"` // module_end only
+}
+
+// progressEmitter writes the progress events of a scan, safe for concurrent use
+type progressEmitter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// SetProgressOutput writes the -progress-json events to w instead of stderr, nil disables them
+func (s *Scanner) SetProgressOutput(w io.Writer) {
+	if w == nil {
+		s.progressEvents = nil
+		return
+	}
+	s.progressEvents = &progressEmitter{w: w}
+}
+
+// emit writes a progress event, the counters are read from the module worker pool
+func (e *progressEmitter) emit(event string, targetURL string, bypassModule string, total int,
+	pool *rawhttp.RequestWorkerPool, findings *atomic.Int32, start time.Time) {
+	if e == nil {
+		return
+	}
+
+	ev := ProgressEvent{
+		Event:        event,
+		Timestamp:    time.Now().UTC().Format(time.RFC3339Nano),
+		TargetURL:    targetURL,
+		BypassModule: bypassModule,
+		Total:        total,
+		Completed:    pool.GetReqWPCompletedTasks(),
+		Rate:         pool.GetRequestRate(),
+		AvgRate:      pool.GetAverageRequestRate(),
+		Findings:     int(findings.Load()),
+	}
+	if event == "module_end" {
+		ev.DurationMs = time.Since(start).Milliseconds()
+	}
+
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.w.Write(append(line, '\n'))
+}
+
+// emitPeriodic emits module_progress events until the returned func is called
+func (e *progressEmitter) emitPeriodic(targetURL string, bypassModule string, total int,
+	pool *rawhttp.RequestWorkerPool, findings *atomic.Int32, start time.Time) (stop func()) {
+	if e == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressEventInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				e.emit("module_progress", targetURL, bypassModule, total, pool, findings, start)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -6,8 +6,12 @@ X: x.com/pedro_infosec
 package scanner
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -66,6 +70,17 @@ func InitDB(dbFilePath string, workers int) error {
                 curl_cmd TEXT,
                 debug_token TEXT,
                 response_time INTEGER,
+                open_redirect INTEGER DEFAULT 0,
+                is_likely_bypass INTEGER DEFAULT 1,
+                calibration TEXT,
+                duplicate_count INTEGER DEFAULT 0,
+                body_file_path TEXT,
+                blocked_by TEXT,
+                baseline_status INTEGER,
+                baseline_length INTEGER,
+                length_delta INTEGER,
+                confidence REAL,
+                desync TEXT,
                 scan_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
             );
 
@@ -77,6 +92,27 @@ func InitDB(dbFilePath string, workers int) error {
 			return
 		}
 
+		// Results DBs created by older versions lack the newer columns
+		for _, column := range []string{
+			"open_redirect INTEGER DEFAULT 0",
+			"is_likely_bypass INTEGER DEFAULT 1",
+			"calibration TEXT",
+			"duplicate_count INTEGER DEFAULT 0",
+			"body_file_path TEXT",
+			"blocked_by TEXT",
+			"baseline_status INTEGER",
+			"baseline_length INTEGER",
+			"length_delta INTEGER",
+			"confidence REAL",
+			"desync TEXT",
+		} {
+			if _, err := db.Exec(`ALTER TABLE scan_results ADD COLUMN ` + column); err != nil &&
+				!strings.Contains(err.Error(), "duplicate column name") {
+				initErr = fmt.Errorf("failed to migrate results table: %v", err)
+				return
+			}
+		}
+
 		// Initialize statement pool
 		stmtPool = make(chan *sql.Stmt, 1) // Only need one prepared statement since we're using a single connection
 
@@ -86,18 +122,75 @@ func InitDB(dbFilePath string, workers int) error {
                 target_url, bypass_module, status_code, content_length, content_type,
                 response_headers, response_body_preview, response_body_bytes,
                 title, server_info, redirect_url, curl_cmd, debug_token, 
-                response_time
-            ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+                response_time, open_redirect, is_likely_bypass, calibration, duplicate_count,
+                body_file_path, blocked_by, baseline_status, baseline_length, length_delta, confidence, desync
+            ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
         `)
 		if err != nil {
 			initErr = fmt.Errorf("failed to prepare statement: %v", err)
 			return
 		}
 		stmtPool <- stmt
+		resultStore = sqliteStore{}
 	})
 	return initErr
 }
 
+// Result fields that can be selected with -capture. Only headers, preview, title, server and redirect
+// are optional, the URL, module, status, length, type, time, curl command and token are always captured
+// (dedupe, -unique, the outputs and replay rely on them), their flags are kept so older -capture lists still parse.
+const (
+	CaptureStatus = 1 << iota
+	CaptureLength
+	CaptureType
+	CaptureHeaders
+	CapturePreview
+	CaptureTitle
+	CaptureServer
+	CaptureRedirect
+	CaptureTime
+	CaptureCurl
+	CaptureToken
+
+	CaptureAll = 1<<iota - 1
+)
+
+// CaptureFieldNames maps -capture field names to their flags
+var CaptureFieldNames = map[string]int{
+	"status":   CaptureStatus,
+	"length":   CaptureLength,
+	"type":     CaptureType,
+	"headers":  CaptureHeaders,
+	"preview":  CapturePreview,
+	"title":    CaptureTitle,
+	"server":   CaptureServer,
+	"redirect": CaptureRedirect,
+	"time":     CaptureTime,
+	"curl":     CaptureCurl,
+	"token":    CaptureToken,
+	"all":      CaptureAll,
+}
+
+// ParseCaptureFields parses a comma separated -capture field list
+func ParseCaptureFields(fields string) (int, error) {
+	capture := 0
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		flag, ok := CaptureFieldNames[field]
+		if !ok {
+			return 0, fmt.Errorf("unknown capture field: %s", field)
+		}
+		capture |= flag
+	}
+	if capture == 0 {
+		return 0, fmt.Errorf("no capture fields specified")
+	}
+	return capture, nil
+}
+
 type Result struct {
 	TargetURL           string
 	BypassModule        string
@@ -113,6 +206,36 @@ type Result struct {
 	RedirectURL         string
 	ResponseTime        int64
 	DebugToken          string
+	OpenRedirect        bool                 // Location reflects a value injected by the payload, off-host
+	IsLikelyBypass      bool                 // false when the response matches the -calibrate control responses
+	Calibration         *CalibrationBaseline // control responses the finding was compared against (-calibrate)
+	DuplicateCount      int                  // near-identical findings merged into this one (-dedupe-responses)
+	BodyFilePath        string               // full response body saved to OutDir/bodies (-save-bodies)
+	BlockedBy           string               // WAF/CDN whose block page the response looks like (e.g. cloudflare)
+	Baseline            *BaselineResponse    // dumb_check response of the target URL, nil if it wasn't sent
+	LengthDelta         int64                // Length minus the Baseline length
+	Confidence          float64              // 0 (most likely still denied) to 1 (most likely a bypass), see ScoreConfidence
+	Desync              string               // request_smuggling_probe variant that timed out or was answered far slower than dumb_check
+}
+
+// SortByValues are the finding orders of -sort-by, the default order groups findings by
+// status code, module and length. Response time, length and confidence put the outliers first.
+var SortByValues = []string{"time", "length", "status", "confidence"}
+
+// SortResults sorts results in place in the -sort-by order, a no-op for an empty sortBy
+func SortResults(results []*Result, sortBy string) {
+	length := func(r *Result) int64 { return responseLength(r.ContentLength, r.ResponseBodyBytes) }
+
+	switch sortBy {
+	case "time":
+		slices.SortStableFunc(results, func(a, b *Result) int { return cmp.Compare(b.ResponseTime, a.ResponseTime) })
+	case "length":
+		slices.SortStableFunc(results, func(a, b *Result) int { return cmp.Compare(length(b), length(a)) })
+	case "status":
+		slices.SortStableFunc(results, func(a, b *Result) int { return cmp.Compare(a.StatusCode, b.StatusCode) })
+	case "confidence":
+		slices.SortStableFunc(results, func(a, b *Result) int { return cmp.Compare(b.Confidence, a.Confidence) })
+	}
 }
 
 // getTableHeader returns the header row for the results table
@@ -122,59 +245,42 @@ func getTableHeader() []string {
 		"Curl CMD",
 		"Status",
 		"Length",
+		"Delta",
+		"Conf",
+		"Time",
 		"Type",
 		"Title",
 		"Server",
+		"Blocked",
+		"Dups",
 	}
 }
 
-func PrintResultsTableFromDB(targetURL, bypassModule string) error {
-	// Extract dbPath from existing connection
-	roDb, err := sql.Open("sqlite3", "file:"+dbPath+"?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=10000&cache=shared&mode=ro")
+// PrintResultsTableFromDB prints the findings of targetURL for the given modules, grouped by status code,
+// module and length, or in the -sort-by order (time, length, status or confidence) when sortBy is set
+func PrintResultsTableFromDB(targetURL, bypassModule, sortBy string) error {
+	findings, err := GetResultsFromDB(targetURL)
 	if err != nil {
-		return fmt.Errorf("failed to open read-only database: %v", err)
+		return err
 	}
-	defer roDb.Close()
-
-	// Configure read-only connection for optimal performance
-	roDb.SetMaxOpenConns(10)
-	roDb.SetMaxIdleConns(5)
 
 	queryModules := strings.Split(bypassModule, ",")
-	placeholders := strings.Repeat("?,", len(queryModules))
-	placeholders = placeholders[:len(placeholders)-1] // Remove trailing comma
-
-	query := fmt.Sprintf(`
-        SELECT 
-            bypass_module, curl_cmd, status_code, 
-            response_body_bytes, content_length, content_type, title, server_info,
-            response_body_preview
-        FROM scan_results
-        WHERE target_url = ? AND bypass_module IN (%s)
-        ORDER BY status_code ASC, bypass_module ASC, 
-                 CASE WHEN content_length > 0 THEN content_length ELSE response_body_bytes END ASC
-    `, placeholders)
-
-	// Prepare query arguments
-	args := make([]any, len(queryModules)+1)
-	args[0] = targetURL
-	for i, module := range queryModules {
-		args[i+1] = module
-	}
-
-	// Prepare the statement with the actual query
-	stmt, err := roDb.Prepare(query)
-	if err != nil {
-		return fmt.Errorf("failed to prepare query: %v", err)
-	}
-	defer stmt.Close()
+	findings = slices.DeleteFunc(findings, func(res *Result) bool {
+		return !slices.Contains(queryModules, res.BypassModule)
+	})
 
-	// Execute the query
-	rows, err := stmt.Query(args...)
-	if err != nil {
-		return fmt.Errorf("database query error: %v", err)
+	if slices.Contains(SortByValues, sortBy) {
+		SortResults(findings, sortBy)
+	} else {
+		sortBy = ""
+		slices.SortStableFunc(findings, func(a, b *Result) int {
+			return cmp.Or(
+				cmp.Compare(a.StatusCode, b.StatusCode),
+				cmp.Compare(a.BypassModule, b.BypassModule),
+				cmp.Compare(responseLength(a.ContentLength, a.ResponseBodyBytes), responseLength(b.ContentLength, b.ResponseBodyBytes)),
+			)
+		})
 	}
-	defer rows.Close()
 
 	// New code: Group results by module -> status code -> content length
 	type ResultGroup struct {
@@ -188,31 +294,46 @@ func PrintResultsTableFromDB(targetURL, bypassModule string) error {
 	var currentModule, currentStatus string
 	var currentLength int64 = -9999 // Reverted: Identifier for the current sub-group (content/body length)
 	var currentGroup ResultGroup
+	hasDuplicates, hasBlocked, hasBaseline := false, false, false
+	sortedCounts := make(map[string]int) // -sort-by: rows per (module, status, length), capped like the groups
 
-	for rows.Next() {
-		var module, curlCmd, contentType, title, serverInfo string
-		var responseBodyPreview string // Still needed for potential future logic, but not primary grouper now
-		var statusCode, responseBodyBytes int
-		var contentLength sql.NullInt64
+	for _, res := range findings {
+		module := res.BypassModule
+		lengthToDisplay := responseLength(res.ContentLength, res.ResponseBodyBytes)
+		lengthDelta := sql.NullInt64{Int64: res.LengthDelta, Valid: res.Baseline != nil}
 
-		err := rows.Scan(&module, &curlCmd, &statusCode, &responseBodyBytes,
-			&contentLength, &contentType, &title, &serverInfo,
-			&responseBodyPreview)
-		if err != nil {
-			return fmt.Errorf("failed to scan row: %v", err)
+		statusStr := bytesutil.Itoa(res.StatusCode)
+		lengthStr := formatBytes(lengthToDisplay)
+		row := []string{
+			module,
+			LimitStringWithSuffix(res.CurlCMD, 115),
+			statusStr,
+			lengthStr, // Reverted: Use the original length string for display
+			formatLengthDelta(lengthDelta),
+			strconv.FormatFloat(res.Confidence, 'f', 2, 64),
+			formatResponseTime(res.ResponseTime),
+			formatContentType(res.ContentType),
+			LimitStringWithSuffix(formatValue(res.Title), 14),
+			LimitStringWithSuffix(formatValue(res.ServerInfo), 14),
+			formatValue(res.BlockedBy),
+			formatDuplicateCount(res.DuplicateCount),
 		}
 
-		// Determine effective content length (lengthToDisplay)
-		var lengthToDisplay int64
-		if contentLength.Valid && contentLength.Int64 > 0 {
-			lengthToDisplay = contentLength.Int64
-		} else {
-			lengthToDisplay = int64(responseBodyBytes)
+		// Sorted (-sort-by): rows in that order, no grouping
+		if sortBy != "" {
+			key := module + "|" + statusStr + "|" + lengthStr
+			if sortedCounts[key] >= 5 {
+				continue
+			}
+			sortedCounts[key]++
+			tableData = append(tableData, row)
+			hasDuplicates = hasDuplicates || res.DuplicateCount > 0
+			hasBlocked = hasBlocked || res.BlockedBy != ""
+			hasBaseline = hasBaseline || lengthDelta.Valid
+			rowCount++
+			continue
 		}
 
-		statusStr := bytesutil.Itoa(statusCode)
-		lengthStr := formatBytes(lengthToDisplay)
-
 		// Check if we need to start a new group (major: module/status, or minor: lengthToDisplay)
 		if module != currentModule || statusStr != currentStatus || lengthToDisplay != currentLength {
 			// If it's a major group change (module or status differs)
@@ -250,15 +371,16 @@ func PrintResultsTableFromDB(targetURL, bypassModule string) error {
 		}
 
 		// Add to current group
-		currentGroup.rows = append(currentGroup.rows, []string{
-			module,
-			LimitStringWithSuffix(curlCmd, 115),
-			statusStr,
-			lengthStr, // Reverted: Use the original length string for display
-			formatContentType(contentType),
-			LimitStringWithSuffix(formatValue(title), 14),
-			LimitStringWithSuffix(formatValue(serverInfo), 14),
-		})
+		currentGroup.rows = append(currentGroup.rows, row)
+		if res.DuplicateCount > 0 {
+			hasDuplicates = true
+		}
+		if res.BlockedBy != "" {
+			hasBlocked = true
+		}
+		if lengthDelta.Valid {
+			hasBaseline = true
+		}
 		currentGroup.size++
 		rowCount++
 	}
@@ -268,14 +390,25 @@ func PrintResultsTableFromDB(targetURL, bypassModule string) error {
 		tableData = append(tableData, currentGroup.rows...)
 	}
 
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("row iteration error: %v", err)
-	}
-
 	if rowCount == 0 {
 		return fmt.Errorf("no results found for %s (modules: %s)", targetURL, bypassModule)
 	}
 
+	// The Dups column is only relevant for -dedupe-responses scans, Blocked when a block page was detected
+	if !hasDuplicates {
+		tableData = removeTableColumn(tableData, len(tableData[0])-1)
+	}
+	if !hasBlocked {
+		tableData = removeTableColumn(tableData, slices.Index(getTableHeader(), "Blocked"))
+	}
+	// Time is only shown when sorting by it
+	if sortBy != "time" {
+		tableData = removeTableColumn(tableData, slices.Index(getTableHeader(), "Time"))
+	}
+	if !hasBaseline {
+		tableData = removeTableColumn(tableData, slices.Index(getTableHeader(), "Delta"))
+	}
+
 	// Display header directly to avoid an allocation
 	pterm.DefaultHeader.WithBackgroundStyle(pterm.NewStyle(pterm.BgGreen)).
 		Println("Results summary for " + targetURL)
@@ -298,9 +431,12 @@ func PrintResultsTableFromDB(targetURL, bypassModule string) error {
 	return nil
 }
 
-func AppendResultsToDB(results []*Result) error {
-	if len(results) == 0 {
-		return nil
+// sqliteStore saves the findings to the results DB opened by InitDB (-db sqlite)
+type sqliteStore struct{}
+
+func (sqliteStore) Append(results []*Result) error {
+	if db == nil {
+		return fmt.Errorf("findings database is not initialized")
 	}
 
 	// Get prepared statement from pool
@@ -322,6 +458,20 @@ func AppendResultsToDB(results []*Result) error {
 
 	// Batch insert all results in a single transaction
 	for _, result := range results {
+		var calibration sql.NullString
+		if result.Calibration != nil {
+			if data, err := json.Marshal(result.Calibration); err == nil {
+				calibration = sql.NullString{String: string(data), Valid: true}
+			}
+		}
+
+		var baselineStatus, baselineLength, lengthDelta sql.NullInt64
+		if result.Baseline != nil {
+			baselineStatus = sql.NullInt64{Int64: int64(result.Baseline.StatusCode), Valid: true}
+			baselineLength = sql.NullInt64{Int64: result.Baseline.Length, Valid: true}
+			lengthDelta = sql.NullInt64{Int64: result.LengthDelta, Valid: true}
+		}
+
 		_, err := txStmt.Exec(
 			result.TargetURL,
 			result.BypassModule,
@@ -337,6 +487,17 @@ func AppendResultsToDB(results []*Result) error {
 			result.CurlCMD,
 			result.DebugToken,
 			result.ResponseTime,
+			result.OpenRedirect,
+			result.IsLikelyBypass,
+			calibration,
+			result.DuplicateCount,
+			result.BodyFilePath,
+			result.BlockedBy,
+			baselineStatus,
+			baselineLength,
+			lengthDelta,
+			result.Confidence,
+			result.Desync,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert result: %v", err)
@@ -346,7 +507,13 @@ func AppendResultsToDB(results []*Result) error {
 	return tx.Commit()
 }
 
-func CleanupFindingsDB() {
+// Results uses its own read-only connection, so it also works once the store is closed
+func (sqliteStore) Results(targetURL string) ([]*Result, error) {
+	return queryResultsDB(dbPath, `WHERE target_url = ?`, targetURL)
+}
+
+// Close closes the findings DB, safe to call more than once
+func (sqliteStore) Close() error {
 	if db != nil {
 		// Drain and close all prepared statements in the pool
 		close(stmtPool)
@@ -354,7 +521,84 @@ func CleanupFindingsDB() {
 			stmt.Close()
 		}
 		db.Close()
+		db = nil
+	}
+	return nil
+}
+
+// LoadResultsFromDB returns all findings of the results DB at path (e.g. of a previous scan), opened read-only
+func LoadResultsFromDB(path string) ([]*Result, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return queryResultsDB(path, ``)
+}
+
+// queryResultsDB reads the findings of the results DB at path matching the where clause, in insertion order
+func queryResultsDB(path string, where string, args ...any) ([]*Result, error) {
+	roDb, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=10000&mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read-only database: %v", err)
+	}
+	defer roDb.Close()
+
+	rows, err := roDb.Query(`
+        SELECT
+            target_url, bypass_module, status_code, content_length, content_type,
+            response_headers, response_body_preview, response_body_bytes,
+            title, server_info, redirect_url, curl_cmd, debug_token,
+            response_time, open_redirect, is_likely_bypass, calibration, duplicate_count,
+            body_file_path, blocked_by, baseline_status, baseline_length, length_delta, confidence, desync
+        FROM scan_results
+        `+where+`
+        ORDER BY id ASC
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database query error: %v", err)
+	}
+	defer rows.Close()
+
+	var results []*Result
+	for rows.Next() {
+		res := &Result{}
+		var contentLength sql.NullInt64
+		var calibration sql.NullString
+		var bodyFilePath, blockedBy, desync sql.NullString
+		var baselineStatus, baselineLength, lengthDelta sql.NullInt64
+		var confidence sql.NullFloat64
+
+		err := rows.Scan(&res.TargetURL, &res.BypassModule, &res.StatusCode, &contentLength, &res.ContentType,
+			&res.ResponseHeaders, &res.ResponseBodyPreview, &res.ResponseBodyBytes,
+			&res.Title, &res.ServerInfo, &res.RedirectURL, &res.CurlCMD, &res.DebugToken,
+			&res.ResponseTime, &res.OpenRedirect, &res.IsLikelyBypass, &calibration, &res.DuplicateCount, &bodyFilePath,
+			&blockedBy, &baselineStatus, &baselineLength, &lengthDelta, &confidence, &desync)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+
+		res.ContentLength = contentLength.Int64
+		res.BodyFilePath = bodyFilePath.String
+		res.BlockedBy = blockedBy.String
+		res.Confidence = confidence.Float64
+		res.Desync = desync.String
+		if baselineStatus.Valid {
+			res.Baseline = &BaselineResponse{StatusCode: int(baselineStatus.Int64), Length: baselineLength.Int64}
+			res.LengthDelta = lengthDelta.Int64
+		}
+		if calibration.Valid {
+			res.Calibration = &CalibrationBaseline{}
+			if err := json.Unmarshal([]byte(calibration.String), res.Calibration); err != nil {
+				res.Calibration = nil
+			}
+		}
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %v", err)
 	}
+
+	return results, nil
 }
 
 // Helper functions
@@ -365,6 +609,38 @@ func formatValue(val string) string {
 	return val
 }
 
+// removeTableColumn removes column i from all the rows of the table
+func removeTableColumn(tableData pterm.TableData, i int) pterm.TableData {
+	for r, row := range tableData {
+		tableData[r] = slices.Delete(row, i, i+1)
+	}
+	return tableData
+}
+
+func formatDuplicateCount(count int) string {
+	if count == 0 {
+		return "[-]"
+	}
+	return "+" + bytesutil.Itoa(count)
+}
+
+// formatResponseTime formats the response time of a finding, in milliseconds
+func formatResponseTime(ms int64) string {
+	return strconv.FormatInt(ms, 10) + "ms"
+}
+
+// formatLengthDelta formats the length of a finding relative to the dumb_check response
+func formatLengthDelta(delta sql.NullInt64) string {
+	switch {
+	case !delta.Valid:
+		return "[-]"
+	case delta.Int64 > 0:
+		return "+" + strconv.FormatInt(delta.Int64, 10)
+	default:
+		return strconv.FormatInt(delta.Int64, 10)
+	}
+}
+
 func formatContentType(contentType string) string {
 	if contentType == "" {
 		return "[-]"
@@ -0,0 +1,210 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/slicingmelon/gobypass403"
+)
+
+// SARIF 2.1.0 document, only the parts GitHub code scanning needs
+type SarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool    SarifTool     `json:"tool"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifTool struct {
+	Driver SarifDriver `json:"driver"`
+}
+
+type SarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	ID               string         `json:"id"`
+	Name             string         `json:"name"`
+	ShortDescription SarifMessage   `json:"shortDescription"`
+	HelpURI          string         `json:"helpUri"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    SarifMessage    `json:"message"`
+	Locations  []SarifLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type SarifPhysicalLocation struct {
+	ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+}
+
+type SarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func newSarifRule(module string) SarifRule {
+	return SarifRule{
+		ID:               module,
+		Name:             module,
+		ShortDescription: SarifMessage{Text: fmt.Sprintf("403/401 bypass via the %s module", module)},
+		HelpURI:          sarifToolURI + "#bypass-modules",
+		Properties:       map[string]any{"tags": []string{"security", "access-control"}},
+	}
+}
+
+// NewSarifReport creates an empty report with one rule per bypass module
+func NewSarifReport(version string) *SarifReport {
+	rules := make([]SarifRule, 0, len(payload.BypassModulesRegistry))
+	for _, module := range payload.BypassModulesRegistry {
+		rules = append(rules, newSarifRule(module))
+	}
+
+	return &SarifReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []SarifRun{{
+			Tool: SarifTool{Driver: SarifDriver{
+				Name:           "gobypass403",
+				Version:        version,
+				InformationURI: sarifToolURI,
+				Rules:          rules,
+			}},
+			Results: []SarifResult{},
+		}},
+	}
+}
+
+// AddResult maps a finding to a SARIF result of its bypass module rule
+func (r *SarifReport) AddResult(targetURL string, res *Result) {
+	run := &r.Runs[0]
+
+	ruleIndex := -1
+	for i, rule := range run.Tool.Driver.Rules {
+		if rule.ID == res.BypassModule {
+			ruleIndex = i
+			break
+		}
+	}
+	if ruleIndex == -1 {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSarifRule(res.BypassModule))
+		ruleIndex = len(run.Tool.Driver.Rules) - 1
+	}
+
+	props := map[string]any{
+		"url":           res.TargetURL,
+		"statusCode":    res.StatusCode,
+		"contentLength": res.ContentLength,
+		"curlCmd":       res.CurlCMD,
+		"debugToken":    res.DebugToken,
+		"confidence":    res.Confidence,
+	}
+	if res.Baseline != nil {
+		props["baselineStatusCode"] = res.Baseline.StatusCode
+		props["baselineLength"] = res.Baseline.Length
+		props["lengthDelta"] = res.LengthDelta
+	}
+
+	run.Results = append(run.Results, SarifResult{
+		RuleID:    res.BypassModule,
+		RuleIndex: ruleIndex,
+		Level:     "warning",
+		Message: SarifMessage{
+			Text: fmt.Sprintf("Possible bypass of %s [%s] with status %d.\nPoC: %s", targetURL, res.BypassModule, res.StatusCode, res.CurlCMD),
+		},
+		Locations: []SarifLocation{{
+			PhysicalLocation: SarifPhysicalLocation{
+				ArtifactLocation: SarifArtifactLocation{URI: targetURL},
+			},
+		}},
+		Properties: props,
+	})
+}
+
+// WriteFile writes the report as indented JSON
+func (r *SarifReport) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF report: %v", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create SARIF output directory: %v", err)
+		}
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// SarifWriter accumulates findings and writes them as a SARIF report on Close
+type SarifWriter struct {
+	path      string
+	mu        sync.Mutex
+	report    *SarifReport
+	closeOnce sync.Once
+}
+
+// NewSarifWriter creates a SarifWriter writing to path
+func NewSarifWriter(path, version string) *SarifWriter {
+	return &SarifWriter{
+		path:   path,
+		report: NewSarifReport(version),
+	}
+}
+
+// WriteResult adds a finding to the report
+func (w *SarifWriter) WriteResult(targetURL string, res *Result) {
+	w.mu.Lock()
+	w.report.AddResult(targetURL, res)
+	w.mu.Unlock()
+}
+
+// Close writes the SARIF report
+func (w *SarifWriter) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if err := w.report.WriteFile(w.path); err != nil {
+			GB403Logger.Error().Msgf("Failed to write SARIF report: %v\n", err)
+			return
+		}
+		GB403Logger.Success().Msgf("SARIF report saved to %s\n", w.path)
+	})
+}
@@ -6,10 +6,16 @@ X: x.com/pedro_infosec
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/slicingmelon/go-rawurlparser"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
 	"github.com/slicingmelon/gobypass403/core/engine/recon"
 	GB403ErrorHandler "github.com/slicingmelon/gobypass403/core/utils/error"
 	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
@@ -18,31 +24,96 @@ import (
 type ScannerOpts struct {
 	Timeout                   int
 	ConcurrentRequests        int
+	HostConcurrency           int // max in-flight requests per host, 0 = only ConcurrentRequests applies
 	MatchStatusCodes          []int
 	MatchContentTypeBytes     [][]byte
+	MatchMagicBytes           []byte          // Response body must start with these bytes
+	FilterSizes               []SizeRange     // Hide responses of these sizes
+	MatchSizes                []SizeRange     // Only keep responses of these sizes
+	MatchRegex                *regexp.Regexp  // Headers or body preview must match (compiled once by the cli)
+	FilterRegex               *regexp.Regexp  // Hide responses whose headers or body preview match
+	MatchHeaders              []HeaderMatcher // Only keep responses with any of these headers
+	FilterHeaders             []HeaderMatcher // Hide responses with any of these headers
 	MinContentLength          int
 	MaxContentLength          int
+	MinConfidence             float64 // Drop findings scoring below this, see ScoreConfidence (-min-confidence)
 	Debug                     bool
 	Verbose                   bool
 	BypassModule              string
 	OutDir                    string
-	ResultsDBFile             string
+	ResultsDBFile             string // Results DB of the sqlite store, empty with -db none
 	RequestDelay              int
+	RequestRate               int // Max requests per second per module worker pool, takes precedence over RequestDelay
+	RequestDelayJitter        int // RequestDelay randomized by up to ± this percentage per request
+	ModuleDelay               int // in seconds, pause between bypass modules
 	MaxRetries                int
 	RetryDelay                int
 	MaxConsecutiveFailedReqs  int
 	AutoThrottle              bool
+	MaxRetryAfter             int   // in seconds, cap for Retry-After delays honored by auto-throttle and -retry-status
+	RetryStatusCodes          []int // Response status codes retried as transient (-retry-status)
 	Proxy                     string
+	ProxyURLs                 []string // Proxies rotated per request (-proxy-file), takes precedence over Proxy
 	EnableHTTP2               bool
+	DisableTLSResumption      bool
+	ClientCertFile            string // mTLS client certificate (PEM)
+	ClientKeyFile             string // mTLS client key (PEM)
+	TLSFingerprint            string // Browser TLS ClientHello to mimic (rawhttp.TLSFingerprints)
+	SNI                       string // TLS ServerName sent instead of the target host, empty = target host
 	SpoofHeader               string
 	SpoofIP                   string
-	CustomHTTPHeaders         []string // Custom HTTP headers in "Name: Value" format
+	URLHeaderLevel            int                 // headers_url variation level (1-3)
+	BothForms                 bool                // Path modules also use the percent-decoded path
+	MutateQuery               bool                // case_substitution and char_encode also mutate the query string
+	CustomWordlists           map[string]string   // Custom wordlists (-w), bypass module -> file
+	AppendPayloads            map[string][]string // Extra end_paths/mid_paths payloads, bypass module -> payloads
+	CustomHTTPHeaders         []string            // Custom HTTP headers in "Name: Value" format
+	AuthHeaders               []string            // Credentials sent with every request (-auth-header, -auth-file), "Name: Value" format
+	UserAgents                []string            // User-Agents rotated per request, empty = default User-Agent
+	PreserveHeaderOrder       bool                // Send payload headers in slice order, -H headers in the slot they override
+	AcceptEncoding            string              // Accept-Encoding sent with every request, empty = none
+	NormalizePath             bool                // Normalize request paths (dot segments, encoded bytes) instead of sending them verbatim
 	FollowRedirects           bool
 	ResponseBodyPreviewSize   int
+	MaxResponseBodySize       int // 0 = computed from ResponseBodyPreviewSize
+	CaptureFields             int // Capture* flags, 0 means CaptureAll
 	DisableStreamResponseBody bool
 	DisableProgressBar        bool
 	ResendRequest             string
+	ReplayFindingsProxy       string
+	StopAllOnFind             bool
+	MaxDuration               time.Duration // Overall scan deadline (-max-duration), 0 = no limit
+	MetricsAddr               string        // Serve pprof and live stats on this address during the scan (-metrics-addr)
+	ProgressJSON              bool          // Emit module start/progress/end events as JSON lines on stderr
+	Webhook                   string        // POST each finding as JSON to this URL
+	SarifFile                 string        // Write findings as a SARIF 2.1.0 report to this file
+	BurpFile                  string        // Write findings as Burp Suite saved items (XML) to this file
+	CSVFile                   string        // Stream findings as CSV rows to this file
+	JSONLFile                 string        // Append findings as JSON lines to this file
+	OutputVersion             int           // Schema version of the JSON finding documents, 0 = OutputSchemaVersion
+	SortBy                    string        // Results table order (SortByValues), empty = grouped by status code and module
+	ToolVersion               string
+	SuppressBaseline          bool             // Drop findings identical to the dumb_check response
+	IgnorePatterns            []*regexp.Regexp // Extra regexes stripped from bodies before baseline comparison (compiled once by the cli)
+	Calibrate                 bool             // Send control requests first and flag findings matching them
+	DedupeResponses           bool             // Keep one finding per cluster of near-identical responses
+	Unique                    bool             // Keep the first finding per status code, length and title of a target URL
+	ShowDenied                bool             // Keep findings with the same 401/403 status as the original request
+	DedupePayloads            bool             // Drop payloads already sent by a previous module for the same URL
+	MaxRequests               int              // Max requests sent per target URL across all modules, 0 = no limit
+	Passive                   bool             // Only GET requests without a body, no double/triple encodings (-passive)
+	MaxModulePayloads         int              // Max payloads sent per bypass module and URL, 0 = no limit
+	SaveBodies                bool             // Save the full response body of each finding to OutDir/bodies
+	SplitOutput               bool             // Write findings and bodies per target host, under OutDir/<host>
+	ExportHTTPDir             string           // Write each finding's request as a .http file to this directory
+	URLConcurrency            int              // Target URLs scanned concurrently, each with its own worker pool (-url-concurrency)
 	ReconCache                *recon.ReconCache
+	Checkpoint                *Checkpoint          // Completed (URL, module) pairs, persisted after each module
+	HostLimiter               *rawhttp.HostLimiter // Per-host cap shared by the worker pools of all modules and URLs, set by NewScanner
+	RateLimiter               *rawhttp.RateLimiter // -rate shared by the worker pools of all URLs, set by NewScanner
+	MaxHostFails              int                  // Failed requests in a row after which a host is backed off, 0 = off
+	HostHealth                *rawhttp.HostHealth  // -max-host-fails back-off shared by the worker pools of all URLs, set by NewScanner
+	DumpWire                  string               // Log raw request bytes and response heads: all requests or only matched ones (rawhttp.WireDumpModes)
 }
 
 // Scanner represents the main scanner structure, perhaps the highest level in the hierarchy of the tool
@@ -50,25 +121,190 @@ type Scanner struct {
 	scannerOpts        *ScannerOpts
 	urls               []string
 	progressBarEnabled atomic.Bool
+	ctx                context.Context    // Shared by all modules and URLs of this run
+	cancel             context.CancelFunc // Cancels the whole run (-stop-all-on-find)
+	baseline           *BaselineMatcher   // nil unless -suppress-baseline is set
+	resultWriters      []ResultWriter     // -webhook, -sarif, -burp, -csv, -jsonl, -split-output
+	scannedURLs        int                // URLs scanned so far (fully or until interrupted)
+	totalFindings      int                // Findings saved so far, across all URLs
+	outputMu           sync.Mutex         // Keeps the results tables of concurrent URLs apart
+
+	targetsMu sync.Mutex
+	targets   map[string]*targetState // Target URLs being scanned
+
+	calibrationMu         sync.Mutex
+	calibrations          map[string]*calibrationEntry // keyed by target URL (-calibrate)
+	calibrationNormalizer *BaselineMatcher
+
+	baselineMu        sync.Mutex
+	baselineResponses map[string]*BaselineResponse // dumb_check responses, keyed by target URL
+	authBaselines     map[string]*AuthBaseline     // original request with and without -auth credentials, keyed by target URL
+
+	statsMu     sync.Mutex
+	moduleStats []ModuleStats            // One entry per (target URL, bypass module) run
+	live        map[*liveModule]struct{} // Modules currently running, one per target URL (-metrics-addr)
+	started     time.Time                // Start of Run
+
+	progressEvents *progressEmitter // nil unless -progress-json is set
+
+	errorSummaries map[string]*GB403ErrorHandler.ErrorSummary // keyed by target URL, taken at the end of Run
 }
 
 // NewScanner creates a new Scanner instance
 func NewScanner(opts *ScannerOpts, urls []string) *Scanner {
+	ctx, cancel := context.WithCancel(context.Background())
 	s := &Scanner{
-		scannerOpts: opts,
-		urls:        urls,
+		scannerOpts:  opts,
+		urls:         urls,
+		ctx:          ctx,
+		cancel:       cancel,
+		calibrations: make(map[string]*calibrationEntry),
+
+		baselineResponses: make(map[string]*BaselineResponse),
+		authBaselines:     make(map[string]*AuthBaseline),
 	}
 	s.progressBarEnabled.Store(!opts.DisableProgressBar)
+
+	// One per-host cap (-host-concurrency) shared by the worker pools of all modules and URLs,
+	// URLs scanned at once (-url-concurrency) are capped at -c per host when it isn't set
+	hostConcurrency := opts.HostConcurrency
+	if hostConcurrency <= 0 && opts.URLConcurrency > 1 {
+		hostConcurrency = opts.ConcurrentRequests
+	}
+	opts.HostLimiter = rawhttp.NewHostLimiter(hostConcurrency)
+
+	// Several URLs at once (-url-concurrency): their worker pools share one request rate,
+	// and the progress bars of concurrent modules would overwrite each other
+	if opts.URLConcurrency > 1 {
+		if opts.RequestRate > 0 {
+			opts.RateLimiter = rawhttp.NewRateLimiter(opts.RequestRate)
+		}
+
+		if s.progressBarEnabled.Load() {
+			GB403Logger.Info().Msgf("Progress bars disabled, scanning %d URLs concurrently (-url-concurrency)\n", opts.URLConcurrency)
+			s.progressBarEnabled.Store(false)
+		}
+	}
+
+	// Hosts failing -max-host-fails requests in a row, or answering slowly, are backed off for a while, across modules and URLs
+	opts.HostHealth = rawhttp.NewHostHealth(opts.MaxHostFails, time.Duration(opts.Timeout)*time.Millisecond, rawhttp.DefaultHostRecoveryWindow)
+
+	if opts.Webhook != "" {
+		s.resultWriters = append(s.resultWriters, NewWebhookWriter(opts.Webhook, opts.ToolVersion, opts.OutputVersion))
+	}
+
+	if opts.SarifFile != "" {
+		s.resultWriters = append(s.resultWriters, NewSarifWriter(opts.SarifFile, opts.ToolVersion))
+	}
+
+	if opts.BurpFile != "" {
+		s.resultWriters = append(s.resultWriters, NewBurpWriter(opts.BurpFile, opts.ToolVersion, s.requestHeaderOpts()))
+	}
+
+	if opts.CSVFile != "" {
+		csvWriter, err := NewCSVWriter(opts.CSVFile)
+		if err != nil {
+			GB403Logger.Error().Msgf("CSV output disabled: %v\n", err)
+		} else {
+			s.resultWriters = append(s.resultWriters, csvWriter)
+		}
+	}
+
+	if opts.JSONLFile != "" {
+		jsonlWriter, err := NewJSONLWriter(opts.JSONLFile, opts.ToolVersion, opts.OutputVersion)
+		if err != nil {
+			GB403Logger.Error().Msgf("JSONL output disabled: %v\n", err)
+		} else {
+			s.resultWriters = append(s.resultWriters, jsonlWriter)
+		}
+	}
+
+	if opts.SplitOutput {
+		s.resultWriters = append(s.resultWriters, NewSplitJSONLWriter(opts.OutDir, opts.ToolVersion, opts.OutputVersion))
+	}
+
+	if opts.ProgressJSON {
+		s.SetProgressOutput(os.Stderr)
+	}
+
+	if opts.SuppressBaseline {
+		s.baseline = NewBaselineMatcher(opts.IgnorePatterns)
+	}
+
+	if opts.Calibrate {
+		s.calibrationNormalizer = NewBaselineMatcher(opts.IgnorePatterns)
+	}
 	return s
 }
 
-// Run runs the scanner..
-func (s *Scanner) Run() error {
+// errMaxDurationReached is the cancel cause of a run stopped by -max-duration,
+// it wraps context.DeadlineExceeded so the worker pool treats it as a regular cancellation
+var errMaxDurationReached = fmt.Errorf("max scan duration reached: %w", context.DeadlineExceeded)
+
+// withRunContext returns a context cancelled when either ctx or the scanner's own context
+// (-stop-all-on-find, Close) is done
+func (s *Scanner) withRunContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Run runs the scanner until all URLs are scanned or ctx is cancelled (e.g. Ctrl-C)
+func (s *Scanner) Run(ctx context.Context) error {
 	defer s.Close()
 
+	ctx, cancel := s.withRunContext(ctx)
+	defer cancel()
+
+	s.statsMu.Lock()
+	s.started = time.Now()
+	s.statsMu.Unlock()
+
+	// Live stats and pprof over HTTP, shut down once the scan is done
+	if s.scannerOpts.MetricsAddr != "" {
+		shutdown, err := s.startMetricsServer(s.scannerOpts.MetricsAddr)
+		if err != nil {
+			GB403Logger.Error().Msgf("Failed to start the metrics server on %s: %v\n", s.scannerOpts.MetricsAddr, err)
+		} else {
+			defer shutdown()
+		}
+	}
+
+	// Overall deadline (-max-duration), pending jobs are dropped once it's reached
+	if s.scannerOpts.MaxDuration > 0 {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithTimeoutCause(ctx, s.scannerOpts.MaxDuration, errMaxDurationReached)
+		defer cancelDeadline()
+		GB403Logger.Info().Msgf("Scan deadline set to %s (-max-duration %s)\n",
+			time.Now().Add(s.scannerOpts.MaxDuration).Format(time.DateTime), s.scannerOpts.MaxDuration)
+	}
+
 	GB403Logger.Info().Msgf("Initializing scanner with %d URLs", len(s.urls))
 
+	// One URL at a time unless -url-concurrency is set, each URL runs its modules in order
+	sem := make(chan struct{}, max(s.scannerOpts.URLConcurrency, 1))
+	var wg sync.WaitGroup
+
 	for _, url := range s.urls {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			if s.ctx.Err() != nil {
+				GB403Logger.Info().Msgf("Scan stopped on first finding, skipping remaining URLs\n")
+			} else if context.Cause(ctx) == errMaxDurationReached {
+				GB403Logger.Warning().Msgf("Max scan duration of %s reached, skipping remaining URLs\n", s.scannerOpts.MaxDuration)
+			} else {
+				GB403Logger.Warning().Msgf("Scan interrupted, skipping remaining URLs\n")
+			}
+			break
+		}
+
 		parsedURL, err := rawurlparser.RawURLParse(url)
 		if err != nil {
 			// Keep one error handling as reference example
@@ -77,41 +313,126 @@ func (s *Scanner) Run() error {
 				ErrorSource:  "Scanner.Run.URLParse",
 				BypassModule: s.scannerOpts.BypassModule,
 			})
+			<-sem
 			continue
 		}
 
-		// Just scan and continue on error - no need for nested error handling
-		_ = s.scanURL(url)
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Just scan and continue on error - no need for nested error handling
+			_ = s.scanURL(ctx, url)
+		}(url)
+	}
+	wg.Wait()
+
+	// Replay all findings through the given proxy (e.g. Burp) for manual review
+	if s.scannerOpts.ReplayFindingsProxy != "" && ctx.Err() == nil {
+		for _, url := range s.urls {
+			if err := s.ReplayFindingsThroughProxy(ctx, url, s.scannerOpts.ReplayFindingsProxy); err != nil {
+				GB403Logger.Error().Msgf("Failed to replay findings for %s: %v\n", url, err)
+			}
+		}
 	}
 
 	fmt.Println()
-	GB403Logger.Success().Msgf("Findings saved to %s\n\n",
-		s.scannerOpts.ResultsDBFile)
+	if err := PrintModuleStatsTable(s.ModuleStats()); err != nil {
+		GB403Logger.Error().Msgf("Failed to display module summary: %v\n", err)
+	}
+	for _, h := range s.scannerOpts.HostHealth.BackedOffHosts() {
+		GB403Logger.Warning().Msgf("Host %s backed off %d time(s) for %s (-max-host-fails): %d failed requests, average response time %s\n",
+			h.Host, h.Backoffs, s.scannerOpts.HostHealth.RecoveryWindow(), h.FailedRequests, h.AvgLatency.Round(time.Millisecond))
+	}
+
+	// Interrupted (Ctrl-C, -max-duration): the findings of the interrupted module were still drained and saved
+	if ctx.Err() != nil && s.ctx.Err() == nil {
+		reason := "Scan interrupted"
+		if context.Cause(ctx) == errMaxDurationReached {
+			reason = fmt.Sprintf("Max scan duration of %s reached", s.scannerOpts.MaxDuration)
+		}
+		s.statsMu.Lock()
+		scannedURLs, totalFindings := s.scannedURLs, s.totalFindings
+		s.statsMu.Unlock()
+		GB403Logger.Warning().Msgf("%s after %d/%d URLs, %d findings collected so far\n",
+			reason, scannedURLs, len(s.urls), totalFindings)
+	}
+	if s.scannerOpts.ResultsDBFile != "" {
+		GB403Logger.Success().Msgf("Findings saved to %s\n\n",
+			s.scannerOpts.ResultsDBFile)
+	}
+
+	// Kept for the reports, the error handler is reset by Close
+	s.snapshotErrorSummaries()
 	GB403ErrorHandler.GetErrorHandler().PrintErrorStats()
 	return nil
 }
 
-func (s *Scanner) scanURL(url string) error {
-	resultCount := s.RunAllBypasses(url)
+// snapshotErrorSummaries records the request errors of each target URL's host
+func (s *Scanner) snapshotErrorSummaries() {
+	errHandler := GB403ErrorHandler.GetErrorHandler()
+	s.errorSummaries = make(map[string]*GB403ErrorHandler.ErrorSummary, len(s.urls))
+	for _, url := range s.urls {
+		if parsedURL, err := rawurlparser.RawURLParse(url); err == nil {
+			s.errorSummaries[url] = errHandler.Summary(parsedURL.BaseURL())
+		}
+	}
+}
+
+// ErrorSummary returns the request errors of the host of targetURL recorded during Run,
+// nil if none
+func (s *Scanner) ErrorSummary(targetURL string) *GB403ErrorHandler.ErrorSummary {
+	return s.errorSummaries[targetURL]
+}
+
+func (s *Scanner) scanURL(ctx context.Context, url string) error {
+	resultCount := s.RunAllBypasses(ctx, url)
+	s.statsMu.Lock()
+	s.scannedURLs++
+	s.totalFindings += resultCount
+	s.statsMu.Unlock()
 
 	if resultCount > 0 {
+		// Print the table of each URL in one piece when several URLs are scanned concurrently
+		s.outputMu.Lock()
+		defer s.outputMu.Unlock()
+
 		resultsFile := s.scannerOpts.ResultsDBFile
 
 		fmt.Println()
-		if err := PrintResultsTableFromDB(url, s.scannerOpts.BypassModule); err != nil {
+		if err := PrintResultsTableFromDB(url, s.scannerOpts.BypassModule, s.scannerOpts.SortBy); err != nil {
 			GB403Logger.Error().Msgf("Failed to display results: %v\n", err)
-		} else {
+		} else if resultsFile != "" {
 			fmt.Println()
 			GB403Logger.Success().Msgf("%d findings saved to %s\n\n",
 				resultCount, resultsFile)
+		} else {
+			fmt.Println()
+			GB403Logger.Success().Msgf("%d findings (-db none, not saved)\n\n", resultCount)
 		}
 	}
 
 	return nil
 }
 
+// stopAll cancels the whole run, used by -stop-all-on-find
+func (s *Scanner) stopAll() {
+	if s.ctx.Err() == nil {
+		GB403Logger.Success().Msgf("Finding detected, stopping all scans (-stop-all-on-find)\n")
+	}
+	s.cancel()
+}
+
 // Close the scanner instance
 func (s *Scanner) Close() {
+	s.cancel()
+
+	// Flush findings still queued for the webhook, write the SARIF report
+	for _, w := range s.resultWriters {
+		w.Close()
+	}
+
 	// Reset error handler instance (this will also close ristretto caches)
 	GB403ErrorHandler.ResetInstance()
 
@@ -0,0 +1,99 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/slicingmelon/go-rawurlparser"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+// TargetDirName returns the directory name of a target URL's output (-split-output): its host
+// and port, lower case, with every byte other than letters, digits, '.' and '-' replaced by '_'
+// (example.com:8443 -> example.com_8443, [::1]:80 -> __1_80)
+func TargetDirName(targetURL string) string {
+	host := ""
+	if parsedURL, err := rawurlparser.RawURLParse(targetURL); err == nil {
+		host = parsedURL.Host
+	}
+	host = strings.Trim(strings.ToLower(host), "[]")
+	host = strings.ReplaceAll(host, "]:", ":")
+
+	dirName := []byte(host)
+	for i, c := range dirName {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-') {
+			dirName[i] = '_'
+		}
+	}
+	// "." and ".." would point outside OutDir
+	if name := strings.Trim(string(dirName), "."); name != "" {
+		return string(dirName)
+	}
+	return "unknown_host"
+}
+
+// targetOutDir returns the directory of a target URL's output files, OutDir/<host> with
+// -split-output, OutDir otherwise
+func (s *Scanner) targetOutDir(targetURL string) string {
+	if !s.scannerOpts.SplitOutput {
+		return s.scannerOpts.OutDir
+	}
+	return filepath.Join(s.scannerOpts.OutDir, TargetDirName(targetURL))
+}
+
+// SplitJSONLWriter appends each finding as a JSON line to the findings.jsonl of its target host,
+// OutDir/<host>/findings.jsonl (-split-output). Files are opened on the first finding of a host.
+type SplitJSONLWriter struct {
+	mu            sync.Mutex
+	outDir        string
+	version       string
+	schemaVersion int
+	writers       map[string]*JSONLWriter // keyed by target dir name
+}
+
+// NewSplitJSONLWriter creates a writer of per-host findings.jsonl files under outDir
+func NewSplitJSONLWriter(outDir, version string, schemaVersion int) *SplitJSONLWriter {
+	return &SplitJSONLWriter{
+		outDir:        outDir,
+		version:       version,
+		schemaVersion: schemaVersion,
+		writers:       make(map[string]*JSONLWriter),
+	}
+}
+
+// WriteResult appends a finding to the findings.jsonl of its target host
+func (w *SplitJSONLWriter) WriteResult(targetURL string, res *Result) {
+	dirName := TargetDirName(targetURL)
+
+	w.mu.Lock()
+	writer, ok := w.writers[dirName]
+	if !ok {
+		var err error
+		writer, err = NewJSONLWriter(filepath.Join(w.outDir, dirName, "findings.jsonl"), w.version, w.schemaVersion)
+		if err != nil {
+			w.mu.Unlock()
+			GB403Logger.Error().Msgf("Failed to write finding of %s: %v\n", targetURL, err)
+			return
+		}
+		w.writers[dirName] = writer
+	}
+	w.mu.Unlock()
+
+	writer.WriteResult(targetURL, res)
+}
+
+// Close closes the findings.jsonl files of all hosts
+func (w *SplitJSONLWriter) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, writer := range w.writers {
+		writer.Close()
+	}
+}
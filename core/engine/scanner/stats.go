@@ -0,0 +1,119 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// ModuleStats holds the counters of a bypass module run against a target URL
+type ModuleStats struct {
+	TargetURL    string
+	BypassModule string
+	Payloads     int           // Unique payloads generated
+	Requests     uint64        // Requests sent by the worker pool
+	Findings     int           // Findings saved to the DB
+	AvgRate      uint64        // Average requests per second
+	Duration     time.Duration // Wall-clock time of the module, payload generation included
+}
+
+// recordModuleStats stores the stats of a finished (or interrupted) module run
+func (s *Scanner) recordModuleStats(stats ModuleStats, live *liveModule) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.moduleStats = append(s.moduleStats, stats)
+	delete(s.live, live) // counted in moduleStats from now on
+}
+
+// ModuleStats returns the stats of all module runs so far, one entry per (target URL, module)
+func (s *Scanner) ModuleStats() []ModuleStats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return append([]ModuleStats(nil), s.moduleStats...)
+}
+
+// SummarizeModuleStats merges the stats of all target URLs per bypass module, in order of first run.
+// The average rate is recomputed from the merged requests and duration.
+func SummarizeModuleStats(stats []ModuleStats) []ModuleStats {
+	var summary []ModuleStats
+	index := make(map[string]int)
+
+	for _, st := range stats {
+		i, ok := index[st.BypassModule]
+		if !ok {
+			index[st.BypassModule] = len(summary)
+			summary = append(summary, ModuleStats{BypassModule: st.BypassModule})
+			i = len(summary) - 1
+		}
+		summary[i].Payloads += st.Payloads
+		summary[i].Requests += st.Requests
+		summary[i].Findings += st.Findings
+		summary[i].Duration += st.Duration
+	}
+
+	for i := range summary {
+		summary[i].AvgRate = requestRate(summary[i].Requests, summary[i].Duration)
+	}
+	return summary
+}
+
+// requestRate returns requests per second over d, 0 for runs too short to measure
+func requestRate(requests uint64, d time.Duration) uint64 {
+	if d < 100*time.Millisecond {
+		return 0
+	}
+	return uint64(float64(requests) / d.Seconds())
+}
+
+// PrintModuleStatsTable prints the per module summary of the scan
+func PrintModuleStatsTable(stats []ModuleStats) error {
+	summary := SummarizeModuleStats(stats)
+	if len(summary) == 0 {
+		return nil
+	}
+
+	tableData := pterm.TableData{{"Module", "Payloads", "Requests", "Findings", "Avg Rate", "Time"}}
+	var total ModuleStats
+	for _, st := range summary {
+		tableData = append(tableData, moduleStatsRow(st.BypassModule, st))
+		total.Payloads += st.Payloads
+		total.Requests += st.Requests
+		total.Findings += st.Findings
+		total.Duration += st.Duration
+	}
+	total.AvgRate = requestRate(total.Requests, total.Duration)
+	tableData = append(tableData, moduleStatsRow("TOTAL", total))
+
+	pterm.DefaultHeader.WithBackgroundStyle(pterm.NewStyle(pterm.BgBlue)).
+		Println("Bypass modules summary")
+
+	tableStr, err := pterm.DefaultTable.
+		WithHasHeader().
+		WithBoxed().
+		WithData(tableData).
+		Srender()
+	if err != nil {
+		return fmt.Errorf("failed to render table: %v", err)
+	}
+
+	fmt.Println(tableStr)
+	return nil
+}
+
+func moduleStatsRow(name string, st ModuleStats) []string {
+	return []string{
+		name,
+		strconv.Itoa(st.Payloads),
+		strconv.FormatUint(st.Requests, 10),
+		strconv.Itoa(st.Findings),
+		strconv.FormatUint(st.AvgRate, 10) + " req/s",
+		st.Duration.Round(time.Millisecond).String(),
+	}
+}
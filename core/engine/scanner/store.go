@@ -0,0 +1,146 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Backends of the findings store (-db)
+const (
+	DBBackendSQLite = "sqlite" // results.db in the output directory (or -db-path), can be queried with SQL afterward
+	DBBackendBolt   = "bolt"   // results.bolt in the output directory (or -db-path), a bbolt key/value file of JSON findings
+	DBBackendNone   = "none"   // Kept in memory for the results tables and reports of the run, nothing written to disk
+)
+
+// DBBackends are the values of -db
+var DBBackends = []string{DBBackendSQLite, DBBackendBolt, DBBackendNone}
+
+// DBFileName is the name of the findings file of backend in the output directory
+func DBFileName(backend string) string {
+	if backend == DBBackendBolt {
+		return "results.bolt"
+	}
+	return "results.db"
+}
+
+// ResultStore saves the findings of a scan, the results tables, reports, -fail-on and
+// -replay-proxy read them back per target URL
+type ResultStore interface {
+	// Append saves findings, called concurrently by the bypass modules
+	Append(results []*Result) error
+	// Results returns the findings saved for targetURL, in discovery order
+	Results(targetURL string) ([]*Result, error)
+	// Close releases the store once the scan is done, Results still works afterward
+	Close() error
+}
+
+// resultStore is the store of the scan, set by OpenResultStore before scanning
+var resultStore ResultStore
+
+// OpenResultStore opens the findings store of backend (-db), path is the results DB file of sqlite
+// and bolt. A results DB that can't be created is an error, rather than a scan losing all its findings.
+func OpenResultStore(backend string, path string, workers int) error {
+	switch backend {
+	case DBBackendSQLite, DBBackendBolt:
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create results DB directory: %v", err)
+		}
+		if backend == DBBackendBolt {
+			store, err := NewBoltResultStore(path)
+			if err != nil {
+				return fmt.Errorf("failed to open results DB %s: %v", path, err)
+			}
+			resultStore = store
+			return nil
+		}
+		if err := InitDB(path, workers); err != nil {
+			return fmt.Errorf("failed to open results DB %s: %v", path, err)
+		}
+		return nil
+	case DBBackendNone:
+		resultStore = NewMemoryResultStore()
+		return nil
+	}
+	return fmt.Errorf("unknown results DB backend %q, expected one of %s", backend, strings.Join(DBBackends, ", "))
+}
+
+// AppendResultsToDB saves findings to the store of the scan
+func AppendResultsToDB(results []*Result) error {
+	if len(results) == 0 {
+		return nil
+	}
+	if resultStore == nil {
+		return fmt.Errorf("findings database is not initialized")
+	}
+	return resultStore.Append(results)
+}
+
+// GetResultsFromDB returns all findings saved for targetURL, also once the scanner is closed
+func GetResultsFromDB(targetURL string) ([]*Result, error) {
+	if resultStore == nil {
+		return nil, fmt.Errorf("findings database is not initialized")
+	}
+	return resultStore.Results(targetURL)
+}
+
+// GetDebugTokensFromDB returns the debug tokens of all findings saved for targetURL
+func GetDebugTokensFromDB(targetURL string) ([]string, error) {
+	results, err := GetResultsFromDB(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []string
+	for _, res := range results {
+		if res.DebugToken != "" {
+			tokens = append(tokens, res.DebugToken)
+		}
+	}
+	return tokens, nil
+}
+
+// CleanupFindingsDB closes the findings store, safe to call more than once
+func CleanupFindingsDB() {
+	if resultStore != nil {
+		resultStore.Close()
+	}
+}
+
+// memoryStore keeps the findings of the run in memory (-db none)
+type memoryStore struct {
+	mu      sync.Mutex
+	results []*Result
+}
+
+// NewMemoryResultStore returns a store keeping the findings in memory, lost when the process exits
+func NewMemoryResultStore() ResultStore {
+	return &memoryStore{}
+}
+
+func (m *memoryStore) Append(results []*Result) error {
+	m.mu.Lock()
+	m.results = append(m.results, results...)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memoryStore) Results(targetURL string) ([]*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.DeleteFunc(slices.Clone(m.results), func(res *Result) bool {
+		return res.TargetURL != targetURL
+	}), nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"sync"
+
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+// targetState is the state of a target URL scan shared by its bypass modules, one per target
+// URL so that several of them can be scanned concurrently (-url-concurrency)
+type targetState struct {
+	payloads      *payloadFilter         // Payloads already generated by the previous modules
+	requestBudget *rawhttp.RequestBudget // Requests left (-max-requests), nil = no limit
+
+	uniqueMu       sync.Mutex
+	uniqueFindings map[uniqueFindingKey]struct{} // Findings kept so far (-unique)
+}
+
+// startTarget gives targetURL a fresh state, before running its first module
+func (s *Scanner) startTarget(targetURL string) *targetState {
+	ts := &targetState{
+		payloads:       newPayloadFilter(),
+		requestBudget:  rawhttp.NewRequestBudget(s.scannerOpts.MaxRequests),
+		uniqueFindings: make(map[uniqueFindingKey]struct{}),
+	}
+
+	s.targetsMu.Lock()
+	defer s.targetsMu.Unlock()
+	if s.targets == nil {
+		s.targets = make(map[string]*targetState)
+	}
+	s.targets[targetURL] = ts
+	return ts
+}
+
+// target returns the state of targetURL, started on first use
+func (s *Scanner) target(targetURL string) *targetState {
+	s.targetsMu.Lock()
+	ts, ok := s.targets[targetURL]
+	s.targetsMu.Unlock()
+
+	if ok {
+		return ts
+	}
+	return s.startTarget(targetURL)
+}
+
+// finishTarget drops the state of targetURL once all its modules ran
+func (s *Scanner) finishTarget(targetURL string) {
+	s.targetsMu.Lock()
+	defer s.targetsMu.Unlock()
+	delete(s.targets, targetURL)
+}
@@ -0,0 +1,376 @@
+/*
+GoByPASS403
+Author: slicingmelon <github.com/slicingmelon>
+X: x.com/pedro_infosec
+*/
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	webhookQueueSize    = 256
+	webhookMaxRetries   = 3
+	webhookRetryDelay   = 500 * time.Millisecond
+	webhookTimeout      = 10 * time.Second
+	webhookCloseTimeout = 30 * time.Second
+)
+
+// ResultWriter receives findings in real time, as they are discovered
+type ResultWriter interface {
+	WriteResult(targetURL string, res *Result)
+	Close()
+}
+
+// Schema versions of the finding documents (-webhook, -jsonl, -json). Bump OutputSchemaVersion
+// whenever a field is added, renamed or removed, and keep the previous layouts selectable
+// through -output-version.
+const (
+	OutputSchemaVersion    = 3 // Current layout, all the fields of WebhookFinding
+	MinOutputSchemaVersion = 1 // Original -webhook layout, without the analysis fields
+)
+
+// WebhookFinding is the JSON document POSTed to the webhook for each finding
+type WebhookFinding struct {
+	SchemaVersion  int                  `json:"schema_version"`
+	Tool           string               `json:"tool"`
+	Version        string               `json:"version"`
+	Target         string               `json:"target"`
+	Timestamp      string               `json:"timestamp"`
+	URL            string               `json:"url"`
+	BypassModule   string               `json:"bypass_module"`
+	StatusCode     int                  `json:"status_code"`
+	ContentType    string               `json:"content_type"`
+	ContentLength  int64                `json:"content_length"`
+	Title          string               `json:"title"`
+	ServerInfo     string               `json:"server"`
+	RedirectURL    string               `json:"redirect_url"`
+	OpenRedirect   bool                 `json:"open_redirect"`
+	IsLikelyBypass bool                 `json:"is_likely_bypass"`
+	Calibration    *CalibrationBaseline `json:"calibration,omitempty"`
+	DuplicateCount int                  `json:"duplicate_count"`
+	BodyFilePath   string               `json:"body_file_path,omitempty"`
+	BlockedBy      string               `json:"blocked_by,omitempty"`
+	Baseline       *BaselineResponse    `json:"baseline,omitempty"`
+	LengthDelta    *int64               `json:"length_delta,omitempty"`
+	Confidence     float64              `json:"confidence"`
+	Desync         string               `json:"desync,omitempty"`
+	ResponseTime   int64                `json:"response_time_ms"`
+	CurlCMD        string               `json:"curl_cmd"`
+	DebugToken     string               `json:"debug_token"`
+}
+
+// webhookFindingV2 is the layout of schema version 2, without the confidence
+type webhookFindingV2 struct {
+	SchemaVersion  int                  `json:"schema_version"`
+	Tool           string               `json:"tool"`
+	Version        string               `json:"version"`
+	Target         string               `json:"target"`
+	Timestamp      string               `json:"timestamp"`
+	URL            string               `json:"url"`
+	BypassModule   string               `json:"bypass_module"`
+	StatusCode     int                  `json:"status_code"`
+	ContentType    string               `json:"content_type"`
+	ContentLength  int64                `json:"content_length"`
+	Title          string               `json:"title"`
+	ServerInfo     string               `json:"server"`
+	RedirectURL    string               `json:"redirect_url"`
+	OpenRedirect   bool                 `json:"open_redirect"`
+	IsLikelyBypass bool                 `json:"is_likely_bypass"`
+	Calibration    *CalibrationBaseline `json:"calibration,omitempty"`
+	DuplicateCount int                  `json:"duplicate_count"`
+	BodyFilePath   string               `json:"body_file_path,omitempty"`
+	BlockedBy      string               `json:"blocked_by,omitempty"`
+	Baseline       *BaselineResponse    `json:"baseline,omitempty"`
+	LengthDelta    *int64               `json:"length_delta,omitempty"`
+	ResponseTime   int64                `json:"response_time_ms"`
+	CurlCMD        string               `json:"curl_cmd"`
+	DebugToken     string               `json:"debug_token"`
+}
+
+// webhookFindingV1 is the layout of schema version 1
+type webhookFindingV1 struct {
+	SchemaVersion int    `json:"schema_version"`
+	Tool          string `json:"tool"`
+	Version       string `json:"version"`
+	Target        string `json:"target"`
+	Timestamp     string `json:"timestamp"`
+	URL           string `json:"url"`
+	BypassModule  string `json:"bypass_module"`
+	StatusCode    int    `json:"status_code"`
+	ContentType   string `json:"content_type"`
+	ContentLength int64  `json:"content_length"`
+	Title         string `json:"title"`
+	ServerInfo    string `json:"server"`
+	RedirectURL   string `json:"redirect_url"`
+	ResponseTime  int64  `json:"response_time_ms"`
+	CurlCMD       string `json:"curl_cmd"`
+	DebugToken    string `json:"debug_token"`
+}
+
+// MarshalJSON writes the finding in the layout of its SchemaVersion
+func (f WebhookFinding) MarshalJSON() ([]byte, error) {
+	if f.SchemaVersion == 1 {
+		return json.Marshal(webhookFindingV1{
+			SchemaVersion: f.SchemaVersion,
+			Tool:          f.Tool,
+			Version:       f.Version,
+			Target:        f.Target,
+			Timestamp:     f.Timestamp,
+			URL:           f.URL,
+			BypassModule:  f.BypassModule,
+			StatusCode:    f.StatusCode,
+			ContentType:   f.ContentType,
+			ContentLength: f.ContentLength,
+			Title:         f.Title,
+			ServerInfo:    f.ServerInfo,
+			RedirectURL:   f.RedirectURL,
+			ResponseTime:  f.ResponseTime,
+			CurlCMD:       f.CurlCMD,
+			DebugToken:    f.DebugToken,
+		})
+	}
+	if f.SchemaVersion == 2 {
+		return json.Marshal(webhookFindingV2{
+			SchemaVersion:  f.SchemaVersion,
+			Tool:           f.Tool,
+			Version:        f.Version,
+			Target:         f.Target,
+			Timestamp:      f.Timestamp,
+			URL:            f.URL,
+			BypassModule:   f.BypassModule,
+			StatusCode:     f.StatusCode,
+			ContentType:    f.ContentType,
+			ContentLength:  f.ContentLength,
+			Title:          f.Title,
+			ServerInfo:     f.ServerInfo,
+			RedirectURL:    f.RedirectURL,
+			OpenRedirect:   f.OpenRedirect,
+			IsLikelyBypass: f.IsLikelyBypass,
+			Calibration:    f.Calibration,
+			DuplicateCount: f.DuplicateCount,
+			BodyFilePath:   f.BodyFilePath,
+			BlockedBy:      f.BlockedBy,
+			Baseline:       f.Baseline,
+			LengthDelta:    f.LengthDelta,
+			ResponseTime:   f.ResponseTime,
+			CurlCMD:        f.CurlCMD,
+			DebugToken:     f.DebugToken,
+		})
+	}
+
+	type webhookFinding WebhookFinding // without the MarshalJSON method
+	return json.Marshal(webhookFinding(f))
+}
+
+// ValidateOutputSchemaVersion checks that schemaVersion is a layout the writers can emit
+func ValidateOutputSchemaVersion(schemaVersion int) error {
+	if schemaVersion < MinOutputSchemaVersion || schemaVersion > OutputSchemaVersion {
+		return fmt.Errorf("unsupported output schema version %d, expected %d to %d",
+			schemaVersion, MinOutputSchemaVersion, OutputSchemaVersion)
+	}
+	return nil
+}
+
+// NewWebhookFinding converts a finding to its JSON document (-webhook, -jsonl, -json),
+// in the layout of schemaVersion (0 means OutputSchemaVersion)
+func NewWebhookFinding(targetURL, version string, schemaVersion int, res *Result) WebhookFinding {
+	var lengthDelta *int64
+	if res.Baseline != nil {
+		lengthDelta = &res.LengthDelta
+	}
+	if schemaVersion <= 0 {
+		schemaVersion = OutputSchemaVersion
+	}
+	return WebhookFinding{
+		SchemaVersion:  schemaVersion,
+		Tool:           "gobypass403",
+		Version:        version,
+		Target:         targetURL,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+		URL:            res.TargetURL,
+		BypassModule:   res.BypassModule,
+		StatusCode:     res.StatusCode,
+		ContentType:    res.ContentType,
+		ContentLength:  res.ContentLength,
+		Title:          res.Title,
+		ServerInfo:     res.ServerInfo,
+		RedirectURL:    res.RedirectURL,
+		OpenRedirect:   res.OpenRedirect,
+		IsLikelyBypass: res.IsLikelyBypass,
+		Calibration:    res.Calibration,
+		DuplicateCount: res.DuplicateCount,
+		BodyFilePath:   res.BodyFilePath,
+		BlockedBy:      res.BlockedBy,
+		Baseline:       res.Baseline,
+		LengthDelta:    lengthDelta,
+		Confidence:     res.Confidence,
+		Desync:         res.Desync,
+		ResponseTime:   res.ResponseTime,
+		CurlCMD:        res.CurlCMD,
+		DebugToken:     res.DebugToken,
+	}
+}
+
+// toResult converts a finding document back to a Result (-diff)
+func (f WebhookFinding) toResult() *Result {
+	var lengthDelta int64
+	if f.LengthDelta != nil {
+		lengthDelta = *f.LengthDelta
+	}
+	return &Result{
+		TargetURL:      f.URL,
+		BypassModule:   f.BypassModule,
+		StatusCode:     f.StatusCode,
+		ContentType:    f.ContentType,
+		ContentLength:  f.ContentLength,
+		Title:          f.Title,
+		ServerInfo:     f.ServerInfo,
+		RedirectURL:    f.RedirectURL,
+		OpenRedirect:   f.OpenRedirect,
+		IsLikelyBypass: f.IsLikelyBypass,
+		Calibration:    f.Calibration,
+		DuplicateCount: f.DuplicateCount,
+		BodyFilePath:   f.BodyFilePath,
+		BlockedBy:      f.BlockedBy,
+		Baseline:       f.Baseline,
+		LengthDelta:    lengthDelta,
+		Confidence:     f.Confidence,
+		Desync:         f.Desync,
+		ResponseTime:   f.ResponseTime,
+		CurlCMD:        f.CurlCMD,
+		DebugToken:     f.DebugToken,
+	}
+}
+
+// WebhookWriter POSTs each finding as JSON to a webhook.
+// Findings are queued and sent by a background worker using its own HTTP client,
+// so a slow webhook never stalls the scan. When the queue is full findings are dropped.
+type WebhookWriter struct {
+	webhookURL    string
+	version       string
+	schemaVersion int
+	client        *fasthttp.Client
+	queue         chan []byte
+	wg            sync.WaitGroup
+	closeOnce     sync.Once
+	dropped       atomic.Int32
+	failed        atomic.Int32
+}
+
+// NewWebhookWriter creates a WebhookWriter and starts its background worker,
+// findings are sent in the layout of schemaVersion (0 means OutputSchemaVersion)
+func NewWebhookWriter(webhookURL, version string, schemaVersion int) *WebhookWriter {
+	w := &WebhookWriter{
+		webhookURL:    webhookURL,
+		version:       version,
+		schemaVersion: schemaVersion,
+		client: &fasthttp.Client{
+			Name:            "gobypass403-webhook",
+			ReadTimeout:     webhookTimeout,
+			WriteTimeout:    webhookTimeout,
+			MaxConnsPerHost: 2,
+		},
+		queue: make(chan []byte, webhookQueueSize),
+	}
+
+	w.wg.Add(1)
+	go w.worker()
+	return w
+}
+
+// WriteResult queues a finding, it never blocks
+func (w *WebhookWriter) WriteResult(targetURL string, res *Result) {
+	body, err := json.Marshal(NewWebhookFinding(targetURL, w.version, w.schemaVersion, res))
+	if err != nil {
+		GB403Logger.Error().Msgf("Failed to marshal webhook finding: %v\n", err)
+		return
+	}
+
+	select {
+	case w.queue <- body:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+func (w *WebhookWriter) worker() {
+	defer w.wg.Done()
+	for body := range w.queue {
+		if err := w.send(body); err != nil {
+			w.failed.Add(1)
+			GB403Logger.Verbose().Msgf("Webhook delivery failed: %v\n", err)
+		}
+	}
+}
+
+// send POSTs a single finding, retrying with exponential backoff
+func (w *WebhookWriter) send(body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= webhookMaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(webhookRetryDelay * time.Duration(1<<(attempt-1)))
+		}
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		req.SetRequestURI(w.webhookURL)
+		req.Header.SetMethod(fasthttp.MethodPost)
+		req.Header.SetContentType("application/json")
+		req.SetBody(body)
+
+		err := w.client.DoTimeout(req, resp, webhookTimeout)
+		statusCode := resp.StatusCode()
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if statusCode >= 200 && statusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook returned status %d", statusCode)
+
+		// Client errors won't get better by retrying (except rate limiting)
+		if statusCode >= 400 && statusCode < 500 && statusCode != fasthttp.StatusTooManyRequests {
+			break
+		}
+	}
+	return lastErr
+}
+
+// Close flushes the queued findings (bounded by webhookCloseTimeout) and stops the worker
+func (w *WebhookWriter) Close() {
+	w.closeOnce.Do(func() {
+		close(w.queue)
+
+		done := make(chan struct{})
+		go func() {
+			w.wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(webhookCloseTimeout):
+			GB403Logger.Warning().Msgf("Timed out flushing webhook queue, %d findings not delivered\n", len(w.queue))
+		}
+
+		if n := w.dropped.Load(); n > 0 {
+			GB403Logger.Warning().Msgf("Webhook queue was full, %d findings were not sent\n", n)
+		}
+		if n := w.failed.Load(); n > 0 {
+			GB403Logger.Warning().Msgf("Failed to deliver %d findings to the webhook\n", n)
+		}
+	})
+}
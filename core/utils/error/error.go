@@ -290,6 +290,7 @@ func (e *ErrorHandler) HandleError(err error, ctx ErrorContext) error {
 	} else {
 		hostStats = make(map[string]*ErrorStats)
 		e.cache.Set(host, hostStats, 1)
+		e.cache.Wait() // visible to the next error of this host
 	}
 
 	// Get or create error stats
@@ -396,6 +397,80 @@ func (e *ErrorHandler) PrintErrorStats() {
 	fmt.Print(buf.String())
 }
 
+// ErrorSummary counts the errors of a scan by error class and by host
+type ErrorSummary struct {
+	Total   int64            `json:"total"`
+	ByClass map[string]int64 `json:"by_class"`
+	ByHost  map[string]int64 `json:"by_host"`
+}
+
+// Summary returns the error counts of the given hosts (base URLs, as in ErrorContext.Host),
+// or of all hosts if none is given. It returns nil if no error was recorded for them.
+func (e *ErrorHandler) Summary(hosts ...string) *ErrorSummary {
+	keys := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		keys = append(keys, "host:"+host)
+	}
+	if len(keys) == 0 {
+		keys = e.getHosts()
+	}
+
+	e.cache.Wait()
+	e.statsLock.RLock()
+	defer e.statsLock.RUnlock()
+
+	summary := &ErrorSummary{
+		ByClass: make(map[string]int64),
+		ByHost:  make(map[string]int64),
+	}
+	for _, host := range keys {
+		hostStats, found := e.cache.Get(host)
+		if !found {
+			continue
+		}
+		for errMsg, stats := range hostStats {
+			count := stats.Count.Load()
+			summary.Total += count
+			summary.ByClass[ClassifyError(errMsg)] += count
+			summary.ByHost[strings.TrimPrefix(host, "host:")] += count
+		}
+	}
+
+	if summary.Total == 0 {
+		return nil
+	}
+	return summary
+}
+
+// ClassifyError maps a (stripped) error message to a coarse error class:
+// dns, timeout, connection_refused, connection_reset, connection_closed, tls, proxy or other
+func ClassifyError(errMsg string) string {
+	msg := strings.ToLower(errMsg)
+	switch {
+	case strings.Contains(msg, "no such host") || strings.Contains(msg, "lookup ") ||
+		strings.Contains(msg, "dns") || strings.Contains(msg, "server misbehaving"):
+		return "dns"
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out") ||
+		strings.Contains(msg, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(msg, "proxy") || strings.Contains(msg, "socks"):
+		return "proxy"
+	case strings.Contains(msg, "connection refused"):
+		return "connection_refused"
+	case strings.Contains(msg, "connection reset") || strings.Contains(msg, "forcibly closed") ||
+		strings.Contains(msg, "broken pipe"):
+		return "connection_reset"
+	case strings.Contains(msg, "tls") || strings.Contains(msg, "x509") || strings.Contains(msg, "certificate") ||
+		strings.Contains(msg, "handshake"):
+		return "tls"
+	case strings.Contains(msg, "eof") || strings.Contains(msg, "server closed connection") ||
+		strings.Contains(msg, "closed connection"):
+		return "connection_closed"
+	default:
+		return "other"
+	}
+}
+
 func (e *ErrorHandler) getHosts() []string {
 	var hosts []string
 	e.hostSet.Range(func(key, _ any) bool {
@@ -7,9 +7,14 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pterm/pterm"
 )
@@ -18,16 +23,26 @@ type Logger struct {
 	mu      sync.Mutex
 	verbose bool
 	debug   bool
+	json    io.Writer // -log-json, newline-delimited JSON records instead of the pretty console output
 }
 
 var DefaultLogger *Logger
 
+// colorEnabled decides whether ANSI colors are emitted, by the logger, the pterm tables and the progress bars
+var colorEnabled atomic.Bool
+
 func init() {
 	DefaultLogger = &Logger{
 		verbose: false,
 		debug:   false,
 	}
 
+	// No colors when stdout is redirected (file, pipe) or NO_COLOR is set (https://no-color.org)
+	colorEnabled.Store(true)
+	if os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+		DisableColor()
+	}
+
 	// stupid pterm
 	pterm.EnableDebugMessages()
 
@@ -71,6 +86,7 @@ func init() {
 type Event struct {
 	logger       *Logger
 	printer      pterm.PrefixPrinter
+	level        string
 	bypassModule string
 	debugToken   string
 	metadata     map[string]string
@@ -100,43 +116,61 @@ func (sw *SafeWriter) Write(p []byte) (n int, err error) {
 	return sw.w.Write(newP)
 }
 
-func (l *Logger) newEvent(printer pterm.PrefixPrinter) *Event {
+// isTerminal reports whether f is a terminal (character device)
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// DisableColor turns off ANSI colors for all output (-no-color, NO_COLOR, stdout not a terminal)
+func DisableColor() {
+	colorEnabled.Store(false)
+	pterm.DisableColor()
+}
+
+// ColorEnabled reports whether output may contain ANSI colors, the progress bars check it too
+func ColorEnabled() bool {
+	return colorEnabled.Load()
+}
+
+func (l *Logger) newEvent(printer pterm.PrefixPrinter, level string) *Event {
 	return &Event{
 		logger:   l,
 		printer:  printer,
+		level:    level,
 		metadata: make(map[string]string),
 	}
 }
 
 // Core logging methods
 func Info() *Event {
-	return DefaultLogger.newEvent(pterm.Info)
+	return DefaultLogger.newEvent(pterm.Info, "info")
 }
 
 func Success() *Event {
-	return DefaultLogger.newEvent(pterm.Success)
+	return DefaultLogger.newEvent(pterm.Success, "success")
 }
 
 func Error() *Event {
-	return DefaultLogger.newEvent(pterm.Error)
+	return DefaultLogger.newEvent(pterm.Error, "error")
 }
 
 func Warning() *Event {
-	return DefaultLogger.newEvent(pterm.Warning)
+	return DefaultLogger.newEvent(pterm.Warning, "warning")
 }
 
 func Debug() *Event {
 	if !DefaultLogger.IsDebugEnabled() {
 		return nil
 	}
-	return DefaultLogger.newEvent(pterm.Debug)
+	return DefaultLogger.newEvent(pterm.Debug, "debug")
 }
 
 func Verbose() *Event {
 	if !DefaultLogger.verbose {
 		return nil
 	}
-	return DefaultLogger.newEvent(pterm.Info)
+	return DefaultLogger.newEvent(pterm.Info, "verbose")
 }
 
 func (e *Event) Msgf(format string, args ...any) {
@@ -147,6 +181,11 @@ func (e *Event) Msgf(format string, args ...any) {
 	e.logger.mu.Lock()
 	defer e.logger.mu.Unlock()
 
+	if e.logger.json != nil {
+		e.logger.writeJSON(e.level, e.bypassModule, e.debugToken, e.metadata, fmt.Sprintf(format, args...))
+		return
+	}
+
 	// Build metadata string
 	var meta string
 	for k, v := range e.metadata {
@@ -195,7 +234,50 @@ func (e *Event) Metadata(key, value string) *Event {
 	return e
 }
 
+// writeJSON writes a log record as a JSON line, metadata keys are added as top level fields.
+// Callers must hold l.mu.
+func (l *Logger) writeJSON(level, module, debugToken string, metadata map[string]string, message string) {
+	record := make(map[string]string, len(metadata)+5)
+	for k, v := range metadata {
+		record[k] = v
+	}
+	record["level"] = level
+	record["timestamp"] = time.Now().UTC().Format(time.RFC3339Nano)
+	record["message"] = strings.TrimSpace(message)
+	if module != "" {
+		record["module"] = module
+	}
+	if debugToken != "" {
+		record["debug_token"] = debugToken
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	l.json.Write(append(line, '\n'))
+}
+
+// printJSON writes a message of the Print* helpers as an info record, reports whether JSON output is on.
+// Callers must hold l.mu.
+func (l *Logger) printJSON(format string, args ...any) bool {
+	if l.json == nil {
+		return false
+	}
+	l.writeJSON("info", "", "", nil, fmt.Sprintf(format, args...))
+	return true
+}
+
 // Logger control methods
+
+// EnableJSON switches the logger to newline-delimited JSON records written to w (-log-json).
+// Each record has level, timestamp, message and, when set, module, debug_token and metadata fields.
+func (l *Logger) EnableJSON(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.json = w
+}
+
 func (l *Logger) EnableDebug() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -232,36 +314,54 @@ func IsVerboseEnabled() bool {
 func PrintGreenLn(format string, args ...any) {
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
+	if DefaultLogger.printJSON(format, args...) {
+		return
+	}
 	pterm.FgGreen.Printfln(format, args...)
 }
 
 func PrintGreen(format string, args ...any) {
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
+	if DefaultLogger.printJSON(format, args...) {
+		return
+	}
 	pterm.FgGreen.Printf(format, args...)
 }
 
 func PrintYellowLn(format string, args ...any) {
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
+	if DefaultLogger.printJSON(format, args...) {
+		return
+	}
 	pterm.FgYellow.Printfln(format, args...)
 }
 
 func PrintYellow(format string, args ...any) {
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
+	if DefaultLogger.printJSON(format, args...) {
+		return
+	}
 	pterm.FgYellow.Printf(format, args...)
 }
 
 func PrintCyanLn(format string, args ...any) {
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
+	if DefaultLogger.printJSON(format, args...) {
+		return
+	}
 	pterm.FgCyan.Printfln(format, args...)
 }
 
 func PrintCyan(format string, args ...any) {
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
+	if DefaultLogger.printJSON(format, args...) {
+		return
+	}
 	pterm.FgCyan.Printf(format, args...)
 }
 
@@ -271,6 +371,14 @@ func PrintBypassModuleInfo(bypassModule string, payloadCount int, targetURL stri
 	DefaultLogger.mu.Lock()
 	defer DefaultLogger.mu.Unlock()
 
+	if DefaultLogger.json != nil {
+		DefaultLogger.writeJSON("info", bypassModule, "", map[string]string{
+			"payloads":   fmt.Sprint(payloadCount),
+			"target_url": targetURL,
+		}, "Scanning "+targetURL)
+		return
+	}
+
 	moduleText := pterm.NewStyle(pterm.BgCyan, pterm.FgBlack).Sprintf(" %s ", bypassModule)
 
 	payloadText := pterm.NewStyle(pterm.BgCyan, pterm.FgBlack).Sprintf(" %d PAYLOADS ", payloadCount)
@@ -197,6 +197,13 @@ type Client struct {
 	// If not set, DialTimeout is used.
 	Dial DialFunc
 
+	// PATCH gobypass403
+	// Callback for establishing new TLS connections to hosts.
+	//
+	// It must return a connection with the TLS handshake already done
+	// (e.g. a uTLS connection). Used instead of Dial/DialTimeout for https.
+	DialTLS DialFunc
+
 	// TLS config for https connections.
 	//
 	// Default TLS config is used if not set.
@@ -540,6 +547,7 @@ func (c *Client) Do(req *Request, resp *Response) error {
 				NoDefaultUserAgentHeader:      c.NoDefaultUserAgentHeader,
 				Dial:                          c.Dial,
 				DialTimeout:                   c.DialTimeout,
+				DialTLS:                       c.DialTLS, // PATCH gobypass403
 				DialDualStack:                 c.DialDualStack,
 				IsTLS:                         isTLS,
 				TLSConfig:                     c.TLSConfig,
@@ -741,6 +749,13 @@ type HostClient struct {
 	// If not set, DialTimeout is used.
 	Dial DialFunc
 
+	// PATCH gobypass403
+	// Callback for establishing new TLS connections to hosts.
+	//
+	// It must return a connection with the TLS handshake already done
+	// (e.g. a uTLS connection). Used instead of Dial/DialTimeout for https.
+	DialTLS DialFunc
+
 	// Optional TLS config.
 	TLSConfig *tls.Config
 
@@ -1977,7 +1992,12 @@ func (c *HostClient) dialHostHard(dialTimeout time.Duration) (conn net.Conn, err
 	for n > 0 {
 		addr := c.nextAddr()
 		tlsConfig := c.cachedTLSConfig(addr)
-		conn, err = dialAddr(addr, c.Dial, c.DialTimeout, c.DialDualStack, c.IsTLS, tlsConfig, dialTimeout, c.WriteTimeout)
+		// PATCH gobypass403
+		if c.IsTLS && c.DialTLS != nil {
+			conn, err = dialAddr(addr, c.DialTLS, nil, c.DialDualStack, c.IsTLS, tlsConfig, dialTimeout, c.WriteTimeout)
+		} else {
+			conn, err = dialAddr(addr, c.Dial, c.DialTimeout, c.DialDualStack, c.IsTLS, tlsConfig, dialTimeout, c.WriteTimeout)
+		}
 		if err == nil {
 			return conn, nil
 		}
@@ -294,6 +294,17 @@ func (h *RequestHeader) realContentLength() int {
 // Negative content-length sets 'Transfer-Encoding: chunked' header.
 func (h *RequestHeader) SetContentLength(contentLength int) {
 	h.contentLength = contentLength
+	// PATCH gobypass403
+	// With special headers disabled the raw headers are left untouched, so a
+	// Transfer-Encoding header sent on purpose is neither dropped nor reordered.
+	if h.disableSpecialHeader {
+		if contentLength >= 0 {
+			h.contentLengthBytes = AppendUint(h.contentLengthBytes[:0], contentLength)
+		} else {
+			h.contentLengthBytes = h.contentLengthBytes[:0]
+		}
+		return
+	}
 	if contentLength >= 0 {
 		h.contentLengthBytes = AppendUint(h.contentLengthBytes[:0], contentLength)
 		h.h = delAllArgs(h.h, HeaderTransferEncoding)
@@ -1334,6 +1345,12 @@ func (h *RequestHeader) del(key []byte) {
 	case HeaderTrailer:
 		h.trailer = h.trailer[:0]
 	}
+	// PATCH gobypass403
+	// Keep the order of the remaining raw headers
+	if h.disableSpecialHeader {
+		h.h = delAllArgsStable(h.h, b2s(key))
+		return
+	}
 	h.h = delAllArgs(h.h, b2s(key))
 }
 
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"net"
 	"testing"
 
@@ -37,7 +38,7 @@ func BenchmarkProcessRequests(b *testing.B) {
 	b.ResetTimer()
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
-			pool.ProcessRequests([]payload.BypassPayload{job})
+			pool.ProcessRequests(context.Background(), []payload.BypassPayload{job})
 		}
 	})
 }
@@ -63,7 +64,7 @@ func BenchmarkProcessRequests2(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
 			count := 0
-			for range pool.ProcessRequests(jobs) {
+			for range pool.ProcessRequests(context.Background(), jobs) {
 				count++
 			}
 			// Optionally verify count if needed
@@ -119,7 +120,7 @@ func BenchmarkProcessRequests3(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
 		for pb.Next() {
 			count := 0
-			for result := range pool.ProcessRequests(jobs) {
+			for result := range pool.ProcessRequests(context.Background(), jobs) {
 				if result != nil {
 					count++
 				}
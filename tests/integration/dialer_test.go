@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bufio"
+	"context"
 	"io"
 	"net"
 	"strings"
@@ -59,7 +60,7 @@ func TestDialerInMemory(t *testing.T) {
 	}
 
 	// Process request and get results
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 
 	// Read result
 	var result *rawhttp.RawHTTPResponseDetails
@@ -132,7 +133,7 @@ func TestDialerWithProxy(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Process request and get results
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 
 	var result *rawhttp.RawHTTPResponseDetails
 	select {
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"net"
 	"sync"
 	"testing"
@@ -56,7 +57,7 @@ func TestClient301RedirectInmemory(t *testing.T) {
 	}
 
 	// Process request and get results channel
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 
 	// Read result
 	var result *rawhttp.RawHTTPResponseDetails
@@ -146,7 +147,7 @@ func TestClient302RedirectCaseInsensitive(t *testing.T) {
 	}
 
 	// Process request and get results
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 
 	// Read result
 	var result *rawhttp.RawHTTPResponseDetails
@@ -240,7 +241,7 @@ func TestRequestDelay(t *testing.T) {
 	}
 
 	// Process requests
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 
 	// Collect results
 	var results []*rawhttp.RawHTTPResponseDetails
@@ -328,7 +329,7 @@ func TestRequestDelayWithMultipleWorkers(t *testing.T) {
 
 	// Process requests and collect results
 	start := time.Now()
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 	var results []*rawhttp.RawHTTPResponseDetails
 	for result := range resultsChan {
 		results = append(results, result)
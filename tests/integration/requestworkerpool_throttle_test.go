@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"net"
 	"testing"
 	"time"
@@ -88,7 +89,7 @@ func TestRequestWorkerPoolThrottle(t *testing.T) {
 	}
 
 	start := time.Now()
-	resultsChan := pool.ProcessRequests(jobs)
+	resultsChan := pool.ProcessRequests(context.Background(), jobs)
 	var results []*rawhttp.RawHTTPResponseDetails
 
 	// Monitor pool stats during processing
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/cli"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scan.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFileRejectsUnknownKeys(t *testing.T) {
+	path := writeConfigFile(t, "cr: 5\nno-such-flag: true\n")
+	_, err := cli.ParseArgs([]string{"-u", "https://example.com/admin", "-o", t.TempDir(), "-config", path})
+	if err == nil || !strings.Contains(err.Error(), `unknown key "no-such-flag"`) {
+		t.Fatalf("Expected the unknown key to be rejected, got %v", err)
+	}
+}
+
+func TestConfigFileCLIOverrides(t *testing.T) {
+	path := writeConfigFile(t, "cr: 5\nT: 3000\n")
+	opts, err := cli.ParseArgs([]string{"-u", "https://example.com/admin", "-o", t.TempDir(), "-config", path, "-cr", "9"})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	if opts.ConcurrentRequests != 9 {
+		t.Errorf("Expected -cr 9 from the command line to override the file, got %d", opts.ConcurrentRequests)
+	}
+	if opts.Timeout != 3000 {
+		t.Errorf("Expected -T 3000 from the config file, got %d", opts.Timeout)
+	}
+}
+
+func TestConfigFileListValues(t *testing.T) {
+	path := writeConfigFile(t, "header:\n  - \"X-One: 1\"\n  - \"X-Two: a, b\"\nmc: [200, 302]\n")
+	opts, err := cli.ParseArgs([]string{"-u", "https://example.com/admin", "-o", t.TempDir(), "-config", path})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	// Repeatable flags take one value per item, commas included
+	if want := []string{"X-One: 1", "X-Two: a, b"}; !slices.Equal(opts.CustomHTTPHeaders, want) {
+		t.Errorf("Expected headers %q, got %q", want, opts.CustomHTTPHeaders)
+	}
+	// The other flags a comma separated list
+	if want := []int{200, 302}; !slices.Equal(opts.MatchStatusCodes, want) {
+		t.Errorf("Expected -mc %v, got %v", want, opts.MatchStatusCodes)
+	}
+}
+
+func TestConfigFileDiff(t *testing.T) {
+	path := writeConfigFile(t, "diff: old/results.db,new/results.db\n")
+	opts, err := cli.ParseArgs([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	if want := []string{"old/results.db", "new/results.db"}; !slices.Equal(opts.DiffFiles, want) {
+		t.Errorf("Expected -diff files %v from the config file, got %v", want, opts.DiffFiles)
+	}
+
+	opts, err = cli.ParseArgs([]string{"-diff", "a.db", "b.db"})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	if want := []string{"a.db", "b.db"}; !slices.Equal(opts.DiffFiles, want) {
+		t.Errorf("Expected -diff files %v, got %v", want, opts.DiffFiles)
+	}
+}
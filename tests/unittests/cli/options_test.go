@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/cli"
+)
+
+func TestInvalidRegexRejectedAtStartup(t *testing.T) {
+	for flagName, want := range map[string]string{
+		"-ignore-pattern": "-ignore-pattern",
+		"-mre":            "-match-regex",
+		"-fre":            "-filter-regex",
+	} {
+		_, err := cli.ParseArgs([]string{"-u", "https://example.com/admin", "-o", t.TempDir(), flagName, "(unclosed"})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %s (unclosed to be rejected, got %v", flagName, err)
+		}
+	}
+}
+
+func TestIgnorePatternCompiledOnce(t *testing.T) {
+	opts, err := cli.ParseArgs([]string{"-u", "https://example.com/admin", "-o", t.TempDir(), "-ignore-pattern", `csrf=[a-f0-9]+`})
+	if err != nil {
+		t.Fatalf("ParseArgs failed: %v", err)
+	}
+	if len(opts.IgnoreRegexps) != 1 || !opts.IgnoreRegexps[0].MatchString("csrf=deadbeef") {
+		t.Errorf("Expected the compiled -ignore-pattern to be kept on the options, got %v", opts.IgnoreRegexps)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestAPIGatewayPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/api/v2/admin?id=1"
+	moduleName := "api_gateway"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateAPIGatewayPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	var getURIs []string
+	preflights := make(map[string]bool)
+	forwardedIPs := make(map[string]bool)
+	for _, p := range generatedPayloads {
+		if p.BypassModule != moduleName {
+			t.Errorf("Expected the %s module label, got %q", moduleName, p.BypassModule)
+		}
+		if p.Host != "www.example.com" || p.Scheme != "https" {
+			t.Errorf("Target must be preserved, got %s://%s", p.Scheme, p.Host)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %s %s", p.Method, p.RawURI)
+		}
+
+		headers := make(map[string]string)
+		for _, h := range p.Headers {
+			headers[h.Header] = h.Value
+		}
+
+		switch p.Method {
+		case "OPTIONS":
+			if p.RawURI != "/api/v2/admin?id=1" || headers["Origin"] != "https://www.example.com" {
+				t.Errorf("Expected a preflight to the original URI from its own origin, got %s with Origin %q", p.RawURI, headers["Origin"])
+			}
+			preflights[headers["Access-Control-Request-Method"]] = true
+		case "GET":
+			if ip, ok := headers["X-Forwarded-For"]; ok {
+				forwardedIPs[ip] = true
+				continue
+			}
+			getURIs = append(getURIs, p.RawURI)
+		default:
+			t.Errorf("Unexpected method %s", p.Method)
+		}
+	}
+
+	if !preflights["GET"] || !preflights["POST"] {
+		t.Errorf("Expected GET and POST preflights, got %v", preflights)
+	}
+	if !forwardedIPs["10.0.0.1"] || !forwardedIPs["172.17.0.1"] {
+		t.Errorf("Expected X-Forwarded-For with internal gateway IPs, got %v", forwardedIPs)
+	}
+
+	for _, want := range []string{
+		"/api/v2/admin?id=1&_method=GET", // method override parameter
+		"/v1/api/v2/admin?id=1",          // version prefix
+		"/api/v1/admin?id=1",             // version swaps
+		"/api/v3/admin?id=1",
+	} {
+		if !slices.Contains(getURIs, want) {
+			t.Errorf("Expected payload %s, got %v", want, getURIs)
+		}
+	}
+	for _, notWant := range []string{"/api/v2/admin?id=1", "/api/api/v2/admin?id=1"} {
+		if slices.Contains(getURIs, notWant) {
+			t.Errorf("Unexpected payload %s", notWant)
+		}
+	}
+}
+
+func TestAPIGatewayPayloadsWithoutQuery(t *testing.T) {
+	targetURL := "https://www.example.com/admin"
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "api_gateway",
+	})
+
+	var rawURIs []string
+	for _, p := range pg.GenerateAPIGatewayPayloads(targetURL, "api_gateway") {
+		rawURIs = append(rawURIs, p.RawURI)
+	}
+	for _, want := range []string{"/admin?_method=GET", "/v1/admin", "/api/v1/admin"} {
+		if !slices.Contains(rawURIs, want) {
+			t.Errorf("Expected payload %s, got %v", want, rawURIs)
+		}
+	}
+}
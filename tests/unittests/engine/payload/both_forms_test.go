@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestBothFormsPayloads(t *testing.T) {
+	targetURL := "http://localhost/%61dmin/users"
+	moduleName := "separator"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	rawOnly := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	}).Generate()
+
+	bothForms := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+		BothForms:    true,
+	}).Generate()
+
+	if len(bothForms) <= len(rawOnly) {
+		t.Fatalf("Expected -both-forms to add payloads: raw=%d both=%d", len(rawOnly), len(bothForms))
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range bothForms {
+		if _, ok := seen[p.RawURI]; ok {
+			t.Errorf("Duplicate RawURI generated: %q", p.RawURI)
+		}
+		seen[p.RawURI] = struct{}{}
+
+		if p.OriginalURL != targetURL {
+			t.Errorf("Expected OriginalURL %q, got %q", targetURL, p.OriginalURL)
+		}
+	}
+
+	for _, uri := range []string{"/%61dmin\\users", "/admin\\users"} {
+		if _, ok := seen[uri]; !ok {
+			t.Errorf("Expected RawURI %q was not generated", uri)
+		}
+	}
+
+	// Nothing to decode, so both forms must be identical to the raw form
+	plainURL := "http://localhost/admin/users"
+	plain := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    plainURL,
+		BypassModule: moduleName,
+		BothForms:    true,
+	}).Generate()
+	if len(plain) != len(rawOnly) {
+		t.Errorf("Expected %d payloads for an unencoded path, got %d", len(rawOnly), len(plain))
+	}
+}
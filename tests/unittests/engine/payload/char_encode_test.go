@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net" // Required for net.Listener
@@ -101,7 +102,7 @@ func TestCharEncodePayloads(t *testing.T) {
 	wp := rawhttp.NewRequestWorkerPool(clientOpts, 20)
 	defer wp.Close()
 
-	resultsChan := wp.ProcessRequests(generatedPayloads)
+	resultsChan := wp.ProcessRequests(context.Background(), generatedPayloads)
 
 	// Drain the results channel
 	responseCount := 0
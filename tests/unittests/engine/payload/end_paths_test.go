@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net" // Required for net.Listener
@@ -92,7 +93,7 @@ func TestEndPathsPayloads(t *testing.T) {
 	wp := rawhttp.NewRequestWorkerPool(clientOpts, 30) // Number of client workers
 	defer wp.Close()                                   // Ensure worker pool is closed
 
-	resultsChan := wp.ProcessRequests(generatedPayloads)
+	resultsChan := wp.ProcessRequests(context.Background(), generatedPayloads)
 
 	// 9. Drain the client results channel
 	responseCount := 0
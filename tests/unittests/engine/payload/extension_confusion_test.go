@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestExtensionConfusionPayloads(t *testing.T) {
+	tests := []struct {
+		targetURL string
+		want      []string
+		notWant   []string
+	}{
+		{
+			targetURL: "https://www.example.com/admin",
+			want:      []string{"/admin%00", "/admin%00.json", "/admin.json%00", "/admin%09", "/admin%20", "/admin%2e%2e"},
+			notWant:   []string{"/admin"},
+		},
+		{
+			targetURL: "https://www.example.com/admin/?id=1",
+			want:      []string{"/admin%00?id=1", "/admin%00.json?id=1", "/admin.json%00?id=1"},
+			notWant:   []string{"/admin/%00?id=1"},
+		},
+		{
+			targetURL: "https://www.example.com/admin/config.php",
+			want:      []string{"/admin/config.php%00", "/admin/config%00.php", "/admin/config%2ephp"},
+		},
+		{
+			targetURL: "https://www.example.com/",
+			want:      []string{"/%00", "/%00.json", "/.json%00"},
+		},
+	}
+
+	for _, tt := range tests {
+		pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+			TargetURL:    tt.targetURL,
+			BypassModule: "extension_confusion",
+		})
+		generatedPayloads := pg.GenerateExtensionConfusionPayloads(tt.targetURL, "extension_confusion")
+
+		rawURIs := make(map[string]int)
+		for _, p := range generatedPayloads {
+			rawURIs[p.RawURI]++
+			if p.Host != "www.example.com" || p.Scheme != "https" || p.Method != "GET" {
+				t.Errorf("%s: target must be preserved, got %s %s://%s", tt.targetURL, p.Method, p.Scheme, p.Host)
+			}
+			if p.PayloadToken == "" {
+				t.Errorf("%s: missing payload token for %s", tt.targetURL, p.RawURI)
+			}
+			if strings.Contains(p.RawURI, "\x00") {
+				t.Errorf("%s: expected encoded bytes, got a raw NUL in %q", tt.targetURL, p.RawURI)
+			}
+		}
+
+		for rawURI, n := range rawURIs {
+			if n > 1 {
+				t.Errorf("%s: %s generated %d times", tt.targetURL, rawURI, n)
+			}
+		}
+		for _, want := range tt.want {
+			if rawURIs[want] == 0 {
+				t.Errorf("%s: expected payload %s", tt.targetURL, want)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if rawURIs[notWant] > 0 {
+				t.Errorf("%s: unexpected payload %s", tt.targetURL, notWant)
+			}
+		}
+	}
+}
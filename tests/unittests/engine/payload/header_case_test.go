@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestHeaderNameCases(t *testing.T) {
+	got := payload.HeaderNameCases("X-Original-URL")
+	want := []string{"x-original-url", "X-ORIGINAL-URL", "X-oRiGiNaL-uRl", "x-OrIgInAl-UrL"}
+	if !slices.Equal(got, want) {
+		t.Errorf("HeaderNameCases(X-Original-URL) = %v, want %v", got, want)
+	}
+
+	// Variations equal to the name itself are left out
+	if got := payload.HeaderNameCases("x-real-ip"); slices.Contains(got, "x-real-ip") {
+		t.Errorf("Expected the original name left out, got %v", got)
+	}
+}
+
+func TestHeaderCasePayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "header_case"
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateHeaderCasePayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range generatedPayloads {
+		if p.Host != "www.example.com" || p.Scheme != "https" || p.Method != "GET" {
+			t.Errorf("Target must be preserved, got %s %s://%s", p.Method, p.Scheme, p.Host)
+		}
+		if len(p.Headers) != 1 {
+			t.Fatalf("Expected a single header, got %v", p.Headers)
+		}
+		h := p.Headers[0]
+		names[h.Header] = true
+
+		switch strings.ToLower(h.Header) {
+		case "x-original-url":
+			if p.RawURI != "/" || h.Value != "/admin?id=1" {
+				t.Errorf("%s: expected the original path on /, got %s with %q", h.Header, p.RawURI, h.Value)
+			}
+		case "x-forwarded-for":
+			if p.RawURI != "/admin?id=1" || h.Value != "127.0.0.1" {
+				t.Errorf("%s: expected 127.0.0.1 on the original path, got %s with %q", h.Header, p.RawURI, h.Value)
+			}
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %s", h.Header)
+		}
+	}
+
+	for _, want := range []string{"x-original-url", "X-ORIGINAL-URL", "X-oRiGiNaL-uRl", "x-forwarded-for"} {
+		if !names[want] {
+			t.Errorf("Expected a payload with the %s header", want)
+		}
+	}
+	if names["X-Original-URL"] {
+		t.Errorf("The canonical header case is already sent by headers_url, it should be left out")
+	}
+}
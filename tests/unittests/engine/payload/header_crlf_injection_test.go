@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestHeaderCRLFInjectionPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "header_crlf_injection"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+		SpoofHeader:  "X-Custom-IP,X-Forwarded-For",
+	})
+	generatedPayloads := pg.GenerateHeaderCRLFInjectionPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[payload.Headers]struct{})
+	for _, p := range generatedPayloads {
+		if p.Method != "GET" || p.Host != "www.example.com" || p.RawURI != "/admin?id=1" {
+			t.Errorf("Request must go to the original URL, got %s %s%s", p.Method, p.Host, p.RawURI)
+		}
+		if len(p.Headers) != 1 {
+			t.Fatalf("Expected a single header, got %v", p.Headers)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %v", p.Headers[0])
+		}
+		if _, ok := seen[p.Headers[0]]; ok {
+			t.Errorf("Duplicate header generated: %v", p.Headers[0])
+		}
+		seen[p.Headers[0]] = struct{}{}
+	}
+
+	for _, want := range []payload.Headers{
+		{Header: "X-Forwarded-For", Value: "127.0.0.1%0d%0aX-Original-URL: /admin"},
+		{Header: "X-Custom-IP", Value: "127.0.0.1%0aX-Original-URL: /admin"},
+		{Header: "X-Original-URL", Value: "/admin%0dX-Forwarded-For: 127.0.0.1"},
+		{Header: "X-Original-URL", Value: "/admin%E5%98%8A%E5%98%8DX-Forwarded-For: 127.0.0.1"},
+	} {
+		if _, ok := seen[want]; !ok {
+			t.Errorf("Expected header %s: %s was not generated", want.Header, want.Value)
+		}
+	}
+}
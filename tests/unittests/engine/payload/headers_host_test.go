@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/recon"
+)
+
+func TestHeadersHostPayloadsIPv6(t *testing.T) {
+	moduleName := "headers_host"
+
+	tests := []struct {
+		targetURL string
+		cacheKey  string
+		ip        string
+		port      string
+		wantHost  string
+	}{
+		{"http://[::1]:8080/admin", "::1", "::1", "8080", "[::1]:8080"},
+		{"http://[::1]/admin", "::1", "::1", "80", "[::1]"},
+		{"http://[fe80::1%25eth0]:8080/admin", "fe80::1%eth0", "fe80::1%eth0", "8080", "[fe80::1%25eth0]:8080"},
+	}
+
+	for _, tt := range tests {
+		cache := recon.NewReconCache()
+		if err := cache.Set(tt.cacheKey, &recon.ReconResult{
+			Hostname:     tt.cacheKey,
+			IPv4Services: map[string]map[string][]string{},
+			IPv6Services: map[string]map[string][]string{"http": {tt.ip: {tt.port}}},
+		}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+
+		pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+			TargetURL:    tt.targetURL,
+			BypassModule: moduleName,
+			ReconCache:   cache,
+		})
+		generatedPayloads := pg.GenerateHeadersHostPayloads(tt.targetURL, moduleName)
+		if len(generatedPayloads) != 2 {
+			t.Fatalf("%s: expected 2 payloads, got %d", tt.targetURL, len(generatedPayloads))
+		}
+
+		// IPv6 service as the URL host, and as the Host header value
+		if p := generatedPayloads[0]; p.Host != tt.wantHost || p.RawURI != "/admin" {
+			t.Errorf("%s: expected URL host %s, got %s%s", tt.targetURL, tt.wantHost, p.Host, p.RawURI)
+		}
+		if p := generatedPayloads[1]; p.Headers[0].Value != tt.wantHost {
+			t.Errorf("%s: expected Host header %s, got %s", tt.targetURL, tt.wantHost, p.Headers[0].Value)
+		}
+	}
+}
+
+func TestHeadersHostPayloadsWithoutRecon(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "headers_host"
+
+	// Empty cache (-no-probe) and no cache at all
+	for _, cache := range []*recon.ReconCache{recon.NewReconCache(), nil} {
+		pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+			TargetURL:    targetURL,
+			BypassModule: moduleName,
+			ReconCache:   cache,
+		})
+		generatedPayloads := pg.GenerateHeadersHostPayloads(targetURL, moduleName)
+		if len(generatedPayloads) == 0 {
+			t.Fatalf("Expected Host header mutations when there are no recon results")
+		}
+
+		seen := make(map[string]struct{})
+		for _, p := range generatedPayloads {
+			if p.Host != "www.example.com" || p.RawURI != "/admin?id=1" || p.BypassModule != moduleName {
+				t.Errorf("Fallback payload must go to the original URL, got %s%s (%s)", p.Host, p.RawURI, p.BypassModule)
+			}
+			if len(p.Headers) != 1 || p.Headers[0].Header != "Host" {
+				t.Fatalf("Expected a single Host header, got %v", p.Headers)
+			}
+			seen[p.Headers[0].Value] = struct{}{}
+		}
+		for _, value := range []string{"www.example.com.", "www.example.com:443", "WWW.EXAMPLE.COM"} {
+			if _, ok := seen[value]; !ok {
+				t.Errorf("Expected Host value %q was not generated", value)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bufio"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net"
@@ -110,7 +111,7 @@ func TestHeadersURLPayloads(t *testing.T) {
 	wp := rawhttp.NewRequestWorkerPool(clientOpts, 20)
 	defer wp.Close()
 
-	resultsChan := wp.ProcessRequests(generatedPayloads)
+	resultsChan := wp.ProcessRequests(context.Background(), generatedPayloads)
 
 	// Drain the results channel
 	responseCount := 0
@@ -228,6 +229,47 @@ func TestHeadersURLPayloads(t *testing.T) {
 	t.Logf("TestHeadersURLPayloads finished. Total time: %s", time.Since(startTime))
 }
 
+func TestHeadersURLPayloadsNoDuplicateHeaderValues(t *testing.T) {
+	moduleName := "headers_url"
+
+	// Short paths used to emit the same (header, value) job more than once
+	for _, targetURL := range []string{
+		"http://localhost/",
+		"http://localhost/admin",
+		"http://localhost/admin/login?next=/",
+	} {
+		var prevCount int
+		for level := 1; level <= payload.MaxURLHeaderLevel; level++ {
+			pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+				TargetURL:      targetURL,
+				BypassModule:   moduleName,
+				URLHeaderLevel: level,
+			})
+			generatedPayloads := pg.GenerateHeadersURLPayloads(targetURL, moduleName)
+			if len(generatedPayloads) == 0 {
+				t.Fatalf("No payloads were generated for %s (level %d)", targetURL, level)
+			}
+
+			seen := make(map[payload.Headers]struct{})
+			for _, p := range generatedPayloads {
+				for _, h := range p.Headers {
+					if _, ok := seen[h]; ok {
+						t.Errorf("Duplicate (header, value) for %s (level %d): %s: %s", targetURL, level, h.Header, h.Value)
+					}
+					seen[h] = struct{}{}
+				}
+			}
+
+			if len(generatedPayloads) < prevCount {
+				t.Errorf("Level %d generated fewer payloads (%d) than level %d (%d) for %s",
+					level, len(generatedPayloads), level-1, prevCount, targetURL)
+			}
+			prevCount = len(generatedPayloads)
+			t.Logf("%s level %d: %d payloads", targetURL, level, len(generatedPayloads))
+		}
+	}
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestHTTPAbsoluteURIPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "http_absolute_uri"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateHTTPAbsoluteURIPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if p.Host != "www.example.com" || p.Scheme != "https" || p.Method != "GET" {
+			t.Errorf("Request must go to the original host, got %s %s://%s", p.Method, p.Scheme, p.Host)
+		}
+		if !strings.HasSuffix(p.RawURI, "/admin?id=1") {
+			t.Errorf("Path and query must be preserved, got %q", p.RawURI)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %q", p.RawURI)
+		}
+
+		key := p.RawURI
+		for _, h := range p.Headers {
+			key += " | " + h.Header + ": " + h.Value
+		}
+		if _, ok := seen[key]; ok {
+			t.Errorf("Duplicate payload generated: %q", key)
+		}
+		seen[key] = struct{}{}
+	}
+
+	expected := []string{
+		"https://www.example.com/admin?id=1",
+		"http://www.example.com/admin?id=1",
+		"https://www.example.com:443/admin?id=1",
+		"HTTPS://WWW.EXAMPLE.COM/admin?id=1",
+		"https://www.example.com/admin?id=1 | Host: localhost",
+		"https://www.example.com/admin?id=1 | Host: 127.0.0.1",
+		"/admin?id=1 | X-Forwarded-Host: localhost",
+		"/admin?id=1 | X-Forwarded-Host: www.example.com:443",
+		"https://www.example.com/admin?id=1 | X-Forwarded-Host: localhost",
+	}
+	for _, key := range expected {
+		if _, ok := seen[key]; !ok {
+			t.Errorf("Expected payload %q was not generated", key)
+		}
+	}
+}
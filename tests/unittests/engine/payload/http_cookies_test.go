@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestHTTPCookiesPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "http_cookies"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateHTTPCookiesPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if p.Method != "GET" || p.Host != "www.example.com" || p.RawURI != "/admin?id=1" {
+			t.Errorf("Request must go to the original URL, got %s %s%s", p.Method, p.Host, p.RawURI)
+		}
+		if len(p.Headers) != 1 || p.Headers[0].Header != "Cookie" {
+			t.Fatalf("Expected a single Cookie header, got %v", p.Headers)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %s", p.Headers[0].Value)
+		}
+		if _, ok := seen[p.Headers[0].Value]; ok {
+			t.Errorf("Duplicate cookie generated: %s", p.Headers[0].Value)
+		}
+		seen[p.Headers[0].Value] = struct{}{}
+	}
+
+	for _, cookie := range []string{"isadmin=1", "role=admin", "debug=true", "X-Forwarded-For=127.0.0.1"} {
+		if _, ok := seen[cookie]; !ok {
+			t.Errorf("Expected cookie %s was not generated", cookie)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestBypassPayloadToRawHTTPFile(t *testing.T) {
+	p := payload.BypassPayload{
+		OriginalURL:  "https://example.com/admin",
+		Method:       "POST",
+		Scheme:       "https",
+		Host:         "example.com",
+		RawURI:       "/admin;/?id=1",
+		BypassModule: "end_paths",
+		Headers: []payload.Headers{
+			{Header: "X-Forwarded-For", Value: "127.0.0.1"},
+			{Header: "X-Original-URL", Value: "/admin"},
+		},
+		Body: "a=1",
+	}
+
+	want := "# end_paths: https://example.com/admin\n" +
+		"POST https://example.com/admin;/?id=1 HTTP/1.1\n" +
+		"X-Forwarded-For: 127.0.0.1\n" +
+		"X-Original-URL: /admin\n" +
+		"Host: example.com\n" +
+		"Content-Length: 3\n" +
+		"\n" +
+		"a=1\n"
+	if got := string(payload.BypassPayloadToRawHTTPFile(p)); got != want {
+		t.Errorf("Unexpected .http file:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A payload Host header replaces the default one, in place
+	p.Headers = []payload.Headers{{Header: "Host", Value: "localhost"}, {Header: "X-Test", Value: "1"}}
+	p.Body = ""
+	got := string(payload.BypassPayloadToRawHTTPFile(p))
+	if strings.Count(got, "Host:") != 1 || !strings.Contains(got, "HTTP/1.1\nHost: localhost\nX-Test: 1\n") {
+		t.Errorf("Unexpected headers:\n%s", got)
+	}
+	if strings.Contains(got, "Content-Length") || strings.HasSuffix(got, "\n\n") {
+		t.Errorf("No body expected:\n%s", got)
+	}
+}
+
+func TestBypassPayloadToRawHTTPFileDeferredContentLength(t *testing.T) {
+	p := payload.BypassPayload{
+		Method:       "GET",
+		Scheme:       "http",
+		Host:         "example.com",
+		RawURI:       "/",
+		BypassModule: "haproxy_bypasses",
+		Headers: []payload.Headers{
+			{Header: "Content-Length", Value: "0"},
+			{Header: "Content-Length0aaaa", Value: "x"},
+		},
+		Body: "GET /admin HTTP/1.1\r\n\r\n",
+	}
+
+	lines := strings.Split(string(payload.BypassPayloadToRawHTTPFile(p)), "\n")
+	if lines[2] != "Content-Length0aaaa: x" || lines[3] != "Host: example.com" || lines[4] != "Content-Length: 0" {
+		t.Errorf("Content-Length must be written last for haproxy_bypasses, got %q", lines[1:5])
+	}
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestHTTPHeadersAcceptPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/api/users?id=1"
+	moduleName := "http_headers_accept"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateHTTPHeadersAcceptPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[payload.Headers]struct{})
+	for _, p := range generatedPayloads {
+		if p.Method != "GET" || p.Host != "www.example.com" || p.RawURI != "/api/users?id=1" {
+			t.Errorf("Request must go to the original URL, got %s %s%s", p.Method, p.Host, p.RawURI)
+		}
+		if len(p.Headers) != 1 {
+			t.Fatalf("Expected a single header, got %v", p.Headers)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %v", p.Headers[0])
+		}
+		if _, ok := seen[p.Headers[0]]; ok {
+			t.Errorf("Duplicate header generated: %v", p.Headers[0])
+		}
+		seen[p.Headers[0]] = struct{}{}
+	}
+
+	expected := []payload.Headers{
+		{Header: "Accept", Value: "application/json"},
+		{Header: "Accept", Value: "application/json, text/javascript, */*; q=0.01"},
+		{Header: "Content-Type", Value: "application/json"},
+		{Header: "X-Requested-With", Value: "XMLHttpRequest"},
+	}
+	for _, h := range expected {
+		if _, ok := seen[h]; !ok {
+			t.Errorf("Expected header %s: %s was not generated", h.Header, h.Value)
+		}
+	}
+}
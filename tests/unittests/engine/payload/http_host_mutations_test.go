@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestHTTPHostMutationsPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "http_host_mutations"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateHTTPHostMutationsPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if p.Host != "www.example.com" || p.Scheme != "https" || p.RawURI != "/admin?id=1" {
+			t.Errorf("Request must go to the original URL, got %s://%s%s", p.Scheme, p.Host, p.RawURI)
+		}
+		if len(p.Headers) != 1 || p.Headers[0].Header != "Host" {
+			t.Fatalf("Expected a single Host header, got %v", p.Headers)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for Host %q", p.Headers[0].Value)
+		}
+
+		value := p.Headers[0].Value
+		if _, ok := seen[value]; ok {
+			t.Errorf("Duplicate Host value generated: %q", value)
+		}
+		seen[value] = struct{}{}
+	}
+
+	if _, ok := seen["www.example.com"]; ok {
+		t.Errorf("The original Host value must not be generated")
+	}
+
+	expected := []string{
+		"www.example.com.",
+		"www.example.com:443",
+		"www.example.com:80",
+		"WWW.EXAMPLE.COM",
+		"Www.example.com",
+		"www.example.com ",
+		"www.example.com\t",
+		"www.example.com.:443",
+	}
+	for _, value := range expected {
+		if _, ok := seen[value]; !ok {
+			t.Errorf("Expected Host value %q was not generated", value)
+		}
+	}
+}
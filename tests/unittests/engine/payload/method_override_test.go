@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestMethodOverridePayloads(t *testing.T) {
+	targetURL := "http://localhost/admin?id=1"
+	moduleName := "method_override"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	headers, err := payload.ReadPayloadsFromFile("header_http_methods.lst")
+	if err != nil {
+		t.Fatalf("Failed to read header_http_methods.lst: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateMethodOverridePayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[payload.Headers]struct{})
+	perHeader := make(map[string]int)
+	for _, p := range generatedPayloads {
+		if p.Method != "GET" || p.RawURI != "/admin?id=1" {
+			t.Errorf("Expected GET /admin?id=1 on the request line, got %s %s", p.Method, p.RawURI)
+		}
+		if len(p.Headers) != 1 {
+			t.Fatalf("Expected exactly one override header, got %v", p.Headers)
+		}
+		if p.Headers[0].Value == "GET" {
+			t.Errorf("GET override should be skipped")
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %v", p.Headers[0])
+		}
+		if _, ok := seen[p.Headers[0]]; ok {
+			t.Errorf("Duplicate header/method combination: %v", p.Headers[0])
+		}
+		seen[p.Headers[0]] = struct{}{}
+		perHeader[p.Headers[0].Header]++
+	}
+
+	for _, h := range headers {
+		if perHeader[h] == 0 {
+			t.Errorf("No payloads generated for header %s", h)
+		}
+		if perHeader[h] != perHeader[headers[0]] {
+			t.Errorf("Expected the same methods for every header, %s has %d, %s has %d", h, perHeader[h], headers[0], perHeader[headers[0]])
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net"
@@ -94,7 +95,7 @@ func TestMidPathsPayloads(t *testing.T) {
 	wp := rawhttp.NewRequestWorkerPool(clientOpts, 100)
 	defer wp.Close()
 
-	resultsChan := wp.ProcessRequests(generatedPayloads)
+	resultsChan := wp.ProcessRequests(context.Background(), generatedPayloads)
 
 	// Drain the results channel
 	responseCount := 0
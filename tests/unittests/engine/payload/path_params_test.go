@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestPathParamsPayloads(t *testing.T) {
+	targetURL := "http://localhost/admin/users?id=1"
+	moduleName := "path_params"
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GeneratePathParamsPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if _, ok := seen[p.RawURI]; ok {
+			t.Errorf("Duplicate RawURI generated: %q", p.RawURI)
+		}
+		seen[p.RawURI] = struct{}{}
+
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %q", p.RawURI)
+		}
+	}
+
+	expected := []string{
+		"/admin;jsessionid=x/users?id=1",
+		"/admin;/users?id=1",
+		"/admin/..;/admin/users?id=1",
+		"/admin/%2e/users?id=1",
+		"/;/admin/users?id=1",
+		"/admin/users;jsessionid=x?id=1",
+		"/admin/users;/?id=1",
+		"/admin/users/..;/users?id=1",
+		"/admin/users/%2e/?id=1",
+		"/admin/;/users?id=1",
+	}
+	for _, rawURI := range expected {
+		if _, ok := seen[rawURI]; !ok {
+			t.Errorf("Expected payload not generated: %q", rawURI)
+		}
+	}
+}
+
+func TestPathParamsPayloadsRootPath(t *testing.T) {
+	targetURL := "http://localhost/"
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "path_params",
+	})
+
+	generatedPayloads := pg.GeneratePathParamsPayloads(targetURL, "path_params")
+	if len(generatedPayloads) != 4 {
+		t.Errorf("Expected 4 payloads for the root path, got %d", len(generatedPayloads))
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net"
@@ -92,7 +93,7 @@ func TestPathPrefixPayloads(t *testing.T) {
 	wp := rawhttp.NewRequestWorkerPool(clientOpts, 100)
 	defer wp.Close()
 
-	resultsChan := wp.ProcessRequests(generatedPayloads)
+	resultsChan := wp.ProcessRequests(context.Background(), generatedPayloads)
 
 	// Drain the results channel
 	responseCount := 0
@@ -239,7 +240,7 @@ func TestPathPrefixPayloadsWithProxy(t *testing.T) {
 	t.Logf("(WithProxy) Client configured to use proxy: %s, Timeout: %s, RequestDelay: %s", clientOpts.ProxyURL, clientOpts.Timeout, clientOpts.RequestDelay)
 
 	wp := rawhttp.NewRequestWorkerPool(clientOpts, 150)
-	resultsChan := wp.ProcessRequests(generatedPayloads)
+	resultsChan := wp.ProcessRequests(context.Background(), generatedPayloads)
 
 	// 6. Drain client results
 	responseCount := 0      // This counts actual responses received via the proxy
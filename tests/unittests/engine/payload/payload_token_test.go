@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestDeterministicPayloadTokens(t *testing.T) {
+	job := payload.BypassPayload{
+		OriginalURL:  "https://example.com/admin",
+		Method:       "POST",
+		Scheme:       "https",
+		Host:         "example.com",
+		RawURI:       "/admin/..;/",
+		Headers:      []payload.Headers{{Header: "X-Forwarded-For", Value: "127.0.0.1"}},
+		Body:         "a=b",
+		BypassModule: "mid_paths",
+	}
+
+	// Random nonces by default
+	if payload.GeneratePayloadToken(job) == payload.GeneratePayloadToken(job) {
+		t.Errorf("Expected different tokens for the same payload with random nonces")
+	}
+
+	payload.SetDeterministicTokens(true)
+	defer payload.SetDeterministicTokens(false)
+
+	token := payload.GeneratePayloadToken(job)
+	if again := payload.GeneratePayloadToken(job); again != token {
+		t.Errorf("Expected the same token for the same payload, got %s and %s", token, again)
+	}
+
+	other := job
+	other.RawURI = "/admin/..;/x"
+	if payload.GeneratePayloadToken(other) == token {
+		t.Errorf("Expected different tokens for different payloads")
+	}
+
+	// Deterministic tokens decode like random ones
+	decoded, err := payload.DecodePayloadToken(token)
+	if err != nil {
+		t.Fatalf("Failed to decode deterministic token: %v", err)
+	}
+	if decoded.Method != job.Method || decoded.Scheme != job.Scheme || decoded.Host != job.Host ||
+		decoded.RawURI != job.RawURI || decoded.Body != job.Body || decoded.BypassModule != job.BypassModule {
+		t.Errorf("Decoded payload %+v does not match %+v", decoded, job)
+	}
+	if len(decoded.Headers) != 1 || decoded.Headers[0] != job.Headers[0] {
+		t.Errorf("Decoded headers %+v do not match %+v", decoded.Headers, job.Headers)
+	}
+}
+
+func TestNormalizePayloadToken(t *testing.T) {
+	job := payload.BypassPayload{
+		Method:       "GET",
+		Scheme:       "https",
+		Host:         "example.com",
+		RawURI:       "/admin/..;/",
+		BypassModule: "mid_paths",
+	}
+	token := payload.GeneratePayloadToken(job)
+	stdAlphabet := strings.NewReplacer("-", "+", "_", "/").Replace(token)
+
+	for _, pasted := range []string{
+		token,
+		" " + token + "\n",
+		`"` + token + `"`,
+		"'" + token + "'",
+		"token=" + token,
+		token + "==",
+		stdAlphabet,
+	} {
+		normalized := payload.NormalizePayloadToken(pasted)
+		if normalized != token {
+			t.Errorf("NormalizePayloadToken(%q) = %q, want %q", pasted, normalized, token)
+			continue
+		}
+		decoded, err := payload.DecodePayloadToken(pasted)
+		if err != nil {
+			t.Errorf("Failed to decode %q: %v", pasted, err)
+			continue
+		}
+		if decoded.RawURI != job.RawURI || decoded.BypassModule != job.BypassModule {
+			t.Errorf("Decoded payload %+v does not match %+v", decoded, job)
+		}
+	}
+
+	for _, bad := range []string{"", "not a token!", token[:len(token)/2]} {
+		if _, err := payload.DecodePayloadToken(bad); err == nil {
+			t.Errorf("Expected an error decoding %q", bad)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestProxyPathRewritePayloads(t *testing.T) {
+	targetURL := "http://localhost/admin/users?id=1"
+	moduleName := "proxy_path_rewrite"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateProxyPathRewritePayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	type variant struct {
+		rawURI string
+		header string
+		value  string
+	}
+	got := make(map[variant]bool)
+	for _, p := range generatedPayloads {
+		if len(p.Headers) != 1 {
+			t.Fatalf("Expected exactly one rewrite header, got %v", p.Headers)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %s %v", p.RawURI, p.Headers[0])
+		}
+		v := variant{p.RawURI, p.Headers[0].Header, p.Headers[0].Value}
+		if got[v] {
+			t.Errorf("Duplicate payload: %v", v)
+		}
+		got[v] = true
+	}
+
+	expected := []variant{
+		// Root request, full path in header
+		{"/", "X-Original-URL", "/admin/users?id=1"},
+		// Parent path request, full path in header
+		{"/admin", "X-Original-URL", "/admin/users?id=1"},
+		{"/admin", "X-Forwarded-Prefix", "/admin/users?id=1"},
+		// Prefix split
+		{"/users?id=1", "X-Forwarded-Prefix", "/admin"},
+		{"/?id=1", "X-Forwarded-Prefix", "/admin/users"},
+	}
+	for _, e := range expected {
+		if !got[e] {
+			t.Errorf("Missing payload: GET %s with %s: %s", e.rawURI, e.header, e.value)
+		}
+	}
+
+	// Prefix split only applies to prefix headers
+	if got[variant{"/users?id=1", "X-Original-URL", "/admin"}] {
+		t.Errorf("Prefix split should not be generated for X-Original-URL")
+	}
+}
+
+func TestProxyPathRewritePayloadsRootPath(t *testing.T) {
+	targetURL := "http://localhost/"
+	moduleName := "proxy_path_rewrite"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	if generated := pg.GenerateProxyPathRewritePayloads(targetURL, moduleName); len(generated) != 0 {
+		t.Errorf("Expected no payloads for the root path, got %d", len(generated))
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func generateRawURIs(t *testing.T, module string, targetURL string, mutateQuery bool) map[string]string {
+	t.Helper()
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: module,
+		MutateQuery:  mutateQuery,
+	})
+
+	rawURIs := make(map[string]string)
+	for _, job := range pg.Generate() {
+		if job.PayloadToken == "" {
+			t.Errorf("Missing payload token for %s", job.RawURI)
+		}
+		rawURIs[job.RawURI] = job.BypassModule
+	}
+	return rawURIs
+}
+
+func TestCaseSubstitutionMutateQuery(t *testing.T) {
+	const targetURL = "http://example.com/admin?debug=true&x"
+
+	for rawURI := range generateRawURIs(t, "case_substitution", targetURL, false) {
+		if !strings.HasSuffix(rawURI, "?debug=true&x") {
+			t.Errorf("Query must be left untouched by default, got %s", rawURI)
+		}
+	}
+
+	rawURIs := generateRawURIs(t, "case_substitution", targetURL, true)
+	for _, want := range []string{
+		"/admin?DEBUG=TRUE&X",
+		"/admin?DEBUG=true&x",
+		"/admin?debug=TRUE&x",
+		"/admin?debug=true&X",
+		"/Admin?debug=true&x", // path payloads still there
+	} {
+		if _, ok := rawURIs[want]; !ok {
+			t.Errorf("Expected payload %s", want)
+		}
+	}
+}
+
+func TestCharEncodeMutateQuery(t *testing.T) {
+	const targetURL = "http://example.com/admin?id=1"
+
+	if _, ok := generateRawURIs(t, "char_encode", targetURL, false)["/admin?%69d=1"]; ok {
+		t.Errorf("Query must be left untouched by default")
+	}
+
+	rawURIs := generateRawURIs(t, "char_encode", targetURL, true)
+	for want, module := range map[string]string{
+		"/admin?%69d=1":   "char_encode",
+		"/admin?i%64=1":   "char_encode",
+		"/admin?id=%31":   "char_encode",
+		"/admin?%2569d=1": "char_encode_double",
+		"/admin?id=%2531": "char_encode_double",
+		"/%61dmin?id=1":   "char_encode",
+	} {
+		if got, ok := rawURIs[want]; !ok {
+			t.Errorf("Expected payload %s", want)
+		} else if got != module {
+			t.Errorf("Expected %s to belong to %s, got %s", want, module, got)
+		}
+	}
+}
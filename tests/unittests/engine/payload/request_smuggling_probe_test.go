@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestRequestSmugglingProbePayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin?id=1"
+	moduleName := "request_smuggling_probe"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateRequestSmugglingProbePayloads(targetURL, moduleName)
+	if len(generatedPayloads) != 12 {
+		t.Fatalf("Expected 12 payloads (2 probes x 6 Transfer-Encoding variants), got %d", len(generatedPayloads))
+	}
+
+	var clte, tecl int
+	variants := make(map[string]bool)
+	for _, p := range generatedPayloads {
+		variants[payload.SmugglingProbeVariant(p)] = true
+		if p.Method != "POST" || p.Host != "www.example.com" || p.RawURI != "/admin?id=1" {
+			t.Errorf("Probe must POST to the original URL, got %s %s%s", p.Method, p.Host, p.RawURI)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token")
+		}
+
+		var contentLength, transferEncoding, connection int
+		var cl string
+		for _, h := range p.Headers {
+			switch name := strings.ToLower(strings.TrimSpace(h.Header)); name {
+			case "content-length":
+				contentLength++
+				cl = h.Value
+			case "transfer-encoding":
+				transferEncoding++
+			case "connection":
+				connection++
+				if h.Value != "close" {
+					t.Errorf("Probe must close the connection, got Connection: %s", h.Value)
+				}
+			}
+		}
+		if contentLength != 1 || transferEncoding == 0 || connection != 1 {
+			t.Errorf("Probe must carry conflicting Content-Length/Transfer-Encoding headers, got %v", p.Headers)
+		}
+
+		// CL.TE: Content-Length cuts the chunked body short, TE.CL: the terminating chunk is shorter than Content-Length
+		n, _ := strconv.Atoi(cl)
+		switch p.Body {
+		case "1\r\nZ\r\nQ":
+			clte++
+			if n != 4 {
+				t.Errorf("CL.TE probe must have Content-Length: 4, got %s", cl)
+			}
+		case "0\r\n\r\nX":
+			tecl++
+			if n != 6 {
+				t.Errorf("TE.CL probe must have Content-Length: 6, got %s", cl)
+			}
+		default:
+			t.Errorf("Unexpected probe body %q", p.Body)
+		}
+	}
+
+	if clte != 6 || tecl != 6 {
+		t.Errorf("Expected 6 CL.TE and 6 TE.CL probes, got %d and %d", clte, tecl)
+	}
+	if len(variants) != 12 {
+		t.Errorf("Expected a distinct variant name per probe, got %v", variants)
+	}
+	if !variants[`CL.TE "Transfer-Encoding: \tchunked"`] || !variants[`TE.CL "Transfer-Encoding : chunked"`] {
+		t.Errorf("Unexpected variant names: %v", variants)
+	}
+	if variant := payload.SmugglingProbeVariant(payload.BypassPayload{Method: "GET", RawURI: "/admin"}); variant != "" {
+		t.Errorf("Expected no variant for a non probe payload, got %q", variant)
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestSeparatorPayloads(t *testing.T) {
+	targetURL := "http://localhost/admin/users/list?id=1"
+	moduleName := "separator"
+
+	// Make sure separators.lst is present in the local payloads dir
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateSeparatorPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	seen := make(map[string]struct{})
+	for _, p := range generatedPayloads {
+		if _, ok := seen[p.RawURI]; ok {
+			t.Errorf("Duplicate RawURI generated: %q", p.RawURI)
+		}
+		seen[p.RawURI] = struct{}{}
+	}
+
+	expected := []string{
+		"/admin\\users/list?id=1",
+		"/admin/users\\list?id=1",
+		"/admin\\users\\list?id=1",
+		"/admin%5cusers%5clist?id=1",
+		"/admin%2fusers/list?id=1",
+		"/admin/users%c0%aflist?id=1",
+	}
+	for _, uri := range expected {
+		if _, ok := seen[uri]; !ok {
+			t.Errorf("Expected RawURI %q was not generated", uri)
+		}
+	}
+
+	t.Logf("Generated %d unique payloads for %s", len(generatedPayloads), moduleName)
+}
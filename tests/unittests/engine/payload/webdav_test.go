@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestWebDAVPayloads(t *testing.T) {
+	targetURL := "https://www.example.com/admin&files?id=1"
+	moduleName := "webdav"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: moduleName,
+	})
+	generatedPayloads := pg.GenerateWebDAVPayloads(targetURL, moduleName)
+	if len(generatedPayloads) == 0 {
+		t.Fatalf("No payloads were generated for %s", moduleName)
+	}
+
+	methods := make(map[string]int)
+	var depths, destinations int
+	for _, p := range generatedPayloads {
+		methods[p.Method]++
+		if p.Host != "www.example.com" || p.Scheme != "https" || p.RawURI != "/admin&files?id=1" {
+			t.Errorf("Target must be preserved, got %s://%s%s", p.Scheme, p.Host, p.RawURI)
+		}
+		if !strings.HasPrefix(p.Body, "<?xml") || !strings.Contains(p.Body, `xmlns:D="DAV:"`) {
+			t.Errorf("%s: expected an XML body, got %q", p.Method, p.Body)
+		}
+		if p.PayloadToken == "" {
+			t.Errorf("Missing payload token for %s", p.Method)
+		}
+
+		headers := make(map[string]string)
+		for _, h := range p.Headers {
+			headers[h.Header] = h.Value
+		}
+		if !strings.Contains(headers["Content-Type"], "xml") {
+			t.Errorf("%s: expected an XML Content-Type, got %q", p.Method, headers["Content-Type"])
+		}
+		if headers["Content-Length"] != strconv.Itoa(len(p.Body)) {
+			t.Errorf("%s: Content-Length %q doesn't match the %d bytes body", p.Method, headers["Content-Length"], len(p.Body))
+		}
+		if _, ok := headers["Depth"]; ok {
+			depths++
+		}
+		if dest, ok := headers["Destination"]; ok {
+			destinations++
+			if dest != "https://www.example.com/admin&files?id=1" {
+				t.Errorf("Expected the target URL as Destination, got %q", dest)
+			}
+		}
+
+		// The SEARCH scope is the XML escaped target path, without the query
+		if p.Method == "SEARCH" && !strings.Contains(p.Body, "<D:href>/admin&amp;files</D:href>") {
+			t.Errorf("Expected the escaped path as SEARCH scope, got %q", p.Body)
+		}
+	}
+
+	if methods["PROPFIND"] == 0 || methods["SEARCH"] == 0 {
+		t.Errorf("Expected PROPFIND and SEARCH payloads, got %v", methods)
+	}
+	if depths != len(generatedPayloads) {
+		t.Errorf("Expected a Depth header on every payload, got %d of %d", depths, len(generatedPayloads))
+	}
+	if destinations == 0 {
+		t.Errorf("Expected payloads with a Destination header")
+	}
+}
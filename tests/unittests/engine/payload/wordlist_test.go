@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+)
+
+func TestCustomWordlistOverridesModuleList(t *testing.T) {
+	targetURL := "http://localhost/admin/users"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	wordlist := filepath.Join(t.TempDir(), "midpaths.txt")
+	if err := os.WriteFile(wordlist, []byte("gb403-custom-a\r\n\n  gb403-custom-b  \n"), 0644); err != nil {
+		t.Fatalf("Failed to write wordlist: %v", err)
+	}
+	wordlists := map[string]string{"mid_paths": wordlist}
+
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "mid_paths",
+		Wordlists:    wordlists,
+	})
+	custom := pg.GenerateMidPathsPayloads(targetURL, "mid_paths")
+	if len(custom) == 0 {
+		t.Fatalf("No payloads generated from the custom wordlist")
+	}
+	foundA, foundB := false, false
+	for _, p := range custom {
+		foundA = foundA || strings.Contains(p.RawURI, "gb403-custom-a")
+		foundB = foundB || strings.Contains(p.RawURI, "gb403-custom-b")
+	}
+	if !foundA || !foundB {
+		t.Errorf("Expected payloads from both custom entries (a=%v, b=%v)", foundA, foundB)
+	}
+
+	builtin := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "mid_paths",
+	}).GenerateMidPathsPayloads(targetURL, "mid_paths")
+	if len(custom) >= len(builtin) {
+		t.Errorf("Expected the 2-entry wordlist to replace the built-in list (%d custom vs %d built-in payloads)", len(custom), len(builtin))
+	}
+
+	// A wordlist keyed on another module must not affect end_paths
+	pgEnd := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "end_paths",
+		Wordlists:    wordlists,
+	})
+	for _, p := range pgEnd.GenerateEndPathsPayloads(targetURL, "end_paths") {
+		if strings.Contains(p.RawURI, "gb403-custom") {
+			t.Fatalf("end_paths used the mid_paths wordlist: %s", p.RawURI)
+		}
+	}
+}
+
+func TestReadPayloadsFromFileWithOverride(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	builtin, err := payload.ReadPayloadsFromFileWithOverride("separators.lst", "")
+	if err != nil || len(builtin) == 0 {
+		t.Fatalf("Expected the built-in list without override, got %v (err: %v)", builtin, err)
+	}
+
+	if _, err := payload.ReadPayloadsFromFileWithOverride("separators.lst", filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("Expected an error for a missing custom wordlist")
+	}
+
+	for module, file := range payload.ModuleWordlists {
+		if _, err := payload.ReadPayloadsFromFile(file); err != nil {
+			t.Errorf("ModuleWordlists entry %s -> %s doesn't exist: %v", module, file, err)
+		}
+	}
+}
+
+func TestAppendPayloadsMergedWithModuleList(t *testing.T) {
+	targetURL := "http://localhost/admin"
+
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads dir: %v", err)
+	}
+
+	builtin := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "end_paths",
+	}).GenerateEndPathsPayloads(targetURL, "end_paths")
+
+	// .json and %00 are already in internal_endpaths.lst
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:      targetURL,
+		BypassModule:   "end_paths",
+		AppendPayloads: map[string][]string{"end_paths": {".json", "%00", ".gb403-extra", ".gb403-extra"}},
+	})
+	appended := pg.GenerateEndPathsPayloads(targetURL, "end_paths")
+
+	seen := make(map[string]struct{})
+	for _, p := range appended {
+		seen[p.RawURI] = struct{}{}
+	}
+	for _, rawURI := range []string{"/admin/.gb403-extra", "/admin/.gb403-extra/", "/admin.gb403-extra", "/admin.json"} {
+		if _, ok := seen[rawURI]; !ok {
+			t.Errorf("Expected payload not generated: %q", rawURI)
+		}
+	}
+	// Only the 4 variants of the new suffix are added
+	if len(appended) != len(builtin)+4 {
+		t.Errorf("Expected %d payloads (built-in + 4), got %d", len(builtin)+4, len(appended))
+	}
+
+	merged := payload.MergePayloads([]string{"a", "b"}, []string{"b", "c", "c"})
+	if strings.Join(merged, ",") != "a,b,c" {
+		t.Errorf("Unexpected merged payloads: %v", merged)
+	}
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNewHTTPClientResponseSizeDefaults(t *testing.T) {
+	opts := &rawhttp.HTTPClientOptions{ResponseBodyPreviewSize: 4096}
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	got := client.GetHTTPClientOptions()
+	if want := rawhttp.DefaultHeadersBuffSize + 4096 + rawhttp.DefaultBufferPadding; got.MaxResponseBodySize != want {
+		t.Errorf("Expected MaxResponseBodySize %d computed from the preview, got %d", want, got.MaxResponseBodySize)
+	}
+	if got.ReadBufferSize != rawhttp.DefaultReadWriteBufferSize || got.WriteBufferSize != rawhttp.DefaultReadWriteBufferSize {
+		t.Errorf("Expected %d bytes read/write buffers, got %d/%d", rawhttp.DefaultReadWriteBufferSize, got.ReadBufferSize, got.WriteBufferSize)
+	}
+}
+
+func TestNewHTTPClientHonorsSmallMaxBodyWithLargePreview(t *testing.T) {
+	body := bytes.Repeat([]byte(`{"id":1,"role":"admin"},`), 8*1024)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	const maxBody, preview = 512, 64 * 1024
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.MaxResponseBodySize = maxBody
+	opts.ResponseBodyPreviewSize = preview
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	got := client.GetHTTPClientOptions()
+	if got.MaxResponseBodySize != maxBody || got.ResponseBodyPreviewSize != preview {
+		t.Fatalf("Expected max body %d and preview %d kept, got %d and %d",
+			maxBody, preview, got.MaxResponseBodySize, got.ResponseBodyPreviewSize)
+	}
+	if got.ReadBufferSize != rawhttp.DefaultReadWriteBufferSize {
+		t.Errorf("Expected the read buffer left at %d, got %d", rawhttp.DefaultReadWriteBufferSize, got.ReadBufferSize)
+	}
+
+	u, _ := url.Parse(server.URL)
+	bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: u.Host, RawURI: "/api/users"}
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+		t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+	}
+	if _, err := client.DoRequest(req, resp, bypassPayload); err != nil {
+		t.Fatalf("DoRequest failed: %v", err)
+	}
+	result := rawhttp.ProcessHTTPResponse(client, resp, bypassPayload)
+
+	// Bodies past the max body size are streamed, the preview still gets its full size
+	if !bytes.Equal(result.ResponsePreview, body[:preview]) {
+		t.Errorf("Expected a %d bytes preview, got %d bytes", preview, len(result.ResponsePreview))
+	}
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestRequestBudget(t *testing.T) {
+	if b := rawhttp.NewRequestBudget(0); b != nil || !b.Take() || b.Exhausted() {
+		t.Fatalf("Expected a nil budget without limit")
+	}
+
+	b := rawhttp.NewRequestBudget(2)
+	if !b.Take() || !b.Take() {
+		t.Fatalf("Expected 2 requests to fit in the budget")
+	}
+	if b.Exhausted() {
+		t.Errorf("Budget must not be exhausted before a request is refused")
+	}
+	if b.Take() {
+		t.Errorf("Expected the third request to be refused")
+	}
+	if !b.Exhausted() || b.Used() != 2 {
+		t.Errorf("Expected an exhausted budget with 2 requests used, got exhausted=%v used=%d", b.Exhausted(), b.Used())
+	}
+}
+
+func TestRequestWorkerPoolRequestBudget(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+
+	var jobs []payload.BypassPayload
+	for i := 0; i < 30; i++ {
+		jobs = append(jobs, payload.BypassPayload{Method: "GET", Scheme: "http", Host: u.Host, RawURI: "/admin"})
+	}
+
+	// The budget is shared by the pools of successive modules
+	budget := rawhttp.NewRequestBudget(25)
+	responses := 0
+	var sent uint64
+	for i := 0; i < 2; i++ {
+		pool := rawhttp.NewRequestWorkerPool(rawhttp.DefaultHTTPClientOptions(), 5)
+		pool.SetRequestBudget(budget)
+		for range pool.ProcessRequests(context.Background(), jobs[:15]) {
+			responses++
+		}
+		sent += pool.GetReqWPSentRequests()
+		pool.Close()
+	}
+
+	if got := hits.Load(); got != 25 {
+		t.Errorf("Expected 25 requests to reach the server, got %d", got)
+	}
+	if responses != 25 {
+		t.Errorf("Expected 25 responses, got %d", responses)
+	}
+	if sent != 25 {
+		t.Errorf("Expected the pools to report 25 sent requests, got %d", sent)
+	}
+	if !budget.Exhausted() {
+		t.Errorf("Expected the budget to be exhausted")
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestProcessRequestsContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	pool := rawhttp.NewRequestWorkerPool(opts, 2)
+	defer pool.Close()
+
+	var jobs []payload.BypassPayload
+	for i := 0; i < 200; i++ {
+		jobs = append(jobs, payload.BypassPayload{
+			Method: "GET",
+			Scheme: "http",
+			Host:   u.Host,
+			RawURI: "/admin",
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := pool.ProcessRequests(ctx, jobs)
+
+	time.AfterFunc(120*time.Millisecond, cancel)
+
+	start := time.Now()
+	count := 0
+	for range results {
+		count++
+	}
+	elapsed := time.Since(start)
+
+	// 200 jobs on 2 workers would take ~5s, cancellation must close the results channel well before
+	if elapsed > 2*time.Second {
+		t.Errorf("Results channel closed %v after start, expected prompt close on cancel", elapsed)
+	}
+	if count == 0 || count >= len(jobs) {
+		t.Errorf("Expected some but not all of %d responses, got %d", len(jobs), count)
+	}
+}
+
+func TestProcessRequestsContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+
+	pool := rawhttp.NewRequestWorkerPool(rawhttp.DefaultHTTPClientOptions(), 2)
+	defer pool.Close()
+
+	// Already expired context, no job should run
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	jobs := []payload.BypassPayload{{Method: "GET", Scheme: "http", Host: u.Host, RawURI: "/admin"}}
+	count := 0
+	for range pool.ProcessRequests(ctx, jobs) {
+		count++
+	}
+	if count != 0 {
+		t.Errorf("Expected no responses with an expired context, got %d", count)
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"bufio"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+)
+
+// captureRequestHead accepts one connection on ln and returns its request line and Host header line
+func captureRequestHead(ln net.Listener) <-chan string {
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		br := bufio.NewReader(conn)
+		head, _ := br.ReadString('\n')
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+			if name, _, _ := strings.Cut(line, ":"); strings.EqualFold(name, "Host") {
+				head += line
+			}
+		}
+		received <- head
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+	}()
+	return received
+}
+
+func waitRequestHead(t *testing.T, received <-chan string) string {
+	t.Helper()
+	select {
+	case head := <-received:
+		return head
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not receive the request")
+		return ""
+	}
+}
+
+func TestCurlCommandPocQuoting(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload payload.BypassPayload
+		want    string
+	}{
+		{
+			name:    "Host header bypass over http",
+			payload: payload.BypassPayload{Method: "GET", Scheme: "http", Host: "10.0.0.1", RawURI: "/admin", Headers: []payload.Headers{{Header: "Host", Value: "admin.internal"}}},
+			want:    "curl -skgi --path-as-is --connect-to '::10.0.0.1:80' 'http://admin.internal/admin'",
+		},
+		{
+			name:    "Host header bypass over https",
+			payload: payload.BypassPayload{Method: "GET", Scheme: "https", Host: "10.0.0.1", RawURI: "/admin", Headers: []payload.Headers{{Header: "Host", Value: "admin.internal"}}},
+			want:    "curl -skgi --path-as-is -H 'Host: admin.internal' 'https://10.0.0.1/admin'",
+		},
+		{
+			name:    "Host header with the default port",
+			payload: payload.BypassPayload{Method: "GET", Scheme: "http", Host: "example.com", RawURI: "/admin", Headers: []payload.Headers{{Header: "Host", Value: "example.com:80"}}},
+			want:    "curl -skgi --path-as-is -H 'Host: example.com:80' 'http://example.com/admin'",
+		},
+		{
+			name:    "Host header with a trailing tab",
+			payload: payload.BypassPayload{Method: "GET", Scheme: "http", Host: "example.com", RawURI: "/admin", Headers: []payload.Headers{{Header: "Host", Value: "example.com\t"}}},
+			want:    "curl -skgi --path-as-is -H $'Host: example.com\\t' 'http://example.com/admin'",
+		},
+		{
+			name:    "Fragment in the path",
+			payload: payload.BypassPayload{Method: "GET", Scheme: "https", Host: "example.com", RawURI: "/admin#x'"},
+			want:    "curl -skgi --path-as-is --request-target $'/admin#x\\'' 'https://example.com/'",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := string(rawhttp.BuildCurlCommandPoc(tt.payload, nil)); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCurlCommandPocReproducesRequest(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer client.Close()
+
+	payloads := []payload.BypassPayload{
+		{Method: "GET", Scheme: "http", Host: ln.Addr().String(), RawURI: "/admin", Headers: []payload.Headers{{Header: "Host", Value: "ADMIN.internal.:8080"}}},
+		{Method: "GET", Scheme: "http", Host: ln.Addr().String(), RawURI: "/admin", Headers: []payload.Headers{{Header: "Host", Value: "admin.internal\t"}}},
+		{Method: "GET", Scheme: "http", Host: ln.Addr().String(), RawURI: "/%2e/admin/..;/#frag"},
+	}
+
+	for _, bypassPayload := range payloads {
+		// Request sent by the scanner
+		received := captureRequestHead(ln)
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+		client.DoRequest(req, resp, bypassPayload)
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+		want := waitRequestHead(t, received)
+
+		// Request sent by the curl PoC
+		cmd := string(rawhttp.BuildCurlCommandPoc(bypassPayload, nil))
+		received = captureRequestHead(ln)
+		if out, err := exec.Command(bash, "-c", cmd).CombinedOutput(); err != nil {
+			t.Fatalf("%s failed: %v\n%s", cmd, err, out)
+		}
+		if got := waitRequestHead(t, received); got != want {
+			t.Errorf("%s sent %q, the scanner sent %q", cmd, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+)
+
+func compressBody(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch encoding {
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+	case "deflate":
+		w := zlib.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+	case "raw-deflate":
+		w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		w.Write(body)
+		w.Close()
+	case "br":
+		w := brotli.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+	case "zstd":
+		w, _ := zstd.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+	default:
+		buf.Write(body)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessHTTPResponseDecompressesBody(t *testing.T) {
+	body := []byte("<html><head><title>Admin Panel</title></head><body>" + strings.Repeat("secret ", 2000) + "</body></html>")
+
+	var acceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		encoding := strings.TrimPrefix(r.URL.Path, "/")
+		w.Header().Set("Content-Type", "text/html")
+		switch encoding {
+		case "identity":
+		case "raw-deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+		case "unknown":
+			w.Header().Set("Content-Encoding", "compress")
+		default:
+			w.Header().Set("Content-Encoding", encoding)
+		}
+		w.Write(compressBody(t, encoding, body))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.AcceptEncoding = "gzip, deflate, br, zstd"
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	preview := opts.ResponseBodyPreviewSize
+	for _, encoding := range []string{"identity", "gzip", "deflate", "raw-deflate", "br", "zstd", "unknown"} {
+		bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: u.Host, RawURI: "/" + encoding}
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+		if _, err := client.DoRequest(req, resp, bypassPayload); err != nil {
+			t.Fatalf("%s: DoRequest failed: %v", encoding, err)
+		}
+		result := rawhttp.ProcessHTTPResponse(client, resp, bypassPayload)
+
+		if acceptEncoding != opts.AcceptEncoding {
+			t.Errorf("%s: expected Accept-Encoding %q, got %q", encoding, opts.AcceptEncoding, acceptEncoding)
+		}
+		if encoding == "unknown" {
+			// Written as received
+			if !bytes.Equal(result.ResponsePreview, body[:preview]) {
+				t.Errorf("%s: expected the body as received, got %q", encoding, result.ResponsePreview)
+			}
+		} else {
+			if !bytes.Equal(result.ResponsePreview, body[:preview]) {
+				t.Errorf("%s: expected the decompressed preview, got %q", encoding, result.ResponsePreview)
+			}
+			if string(result.Title) != "Admin Panel" {
+				t.Errorf("%s: expected title %q, got %q", encoding, "Admin Panel", result.Title)
+			}
+		}
+		if !strings.Contains(string(result.CurlCommand), "--compressed") {
+			t.Errorf("%s: expected --compressed in the curl PoC, got %s", encoding, result.CurlCommand)
+		}
+
+		rawhttp.ReleaseResponseDetails(result)
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+}
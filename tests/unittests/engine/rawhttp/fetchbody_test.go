@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestFetchResponseBodyFull(t *testing.T) {
+	// Well past the default MaxResponseBodySize and preview size
+	body := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: u.Host, RawURI: "/admin"}
+
+	pool := rawhttp.NewRequestWorkerPool(rawhttp.DefaultHTTPClientOptions(), 1)
+	defer pool.Close()
+
+	var buf bytes.Buffer
+	written, truncated, err := pool.FetchResponseBody(context.Background(), bypassPayload, &buf, int64(len(body))+1)
+	if err != nil {
+		t.Fatalf("FetchResponseBody failed: %v", err)
+	}
+	if truncated {
+		t.Errorf("Expected the body not to be truncated")
+	}
+	if written != int64(len(body)) || !bytes.Equal(buf.Bytes(), body) {
+		t.Errorf("Expected the full %d bytes body, got %d bytes", len(body), written)
+	}
+
+	// Capped at maxSize
+	buf.Reset()
+	written, truncated, err = pool.FetchResponseBody(context.Background(), bypassPayload, &buf, 4096)
+	if err != nil {
+		t.Fatalf("FetchResponseBody failed: %v", err)
+	}
+	if !truncated || written != 4096 || !bytes.Equal(buf.Bytes(), body[:4096]) {
+		t.Errorf("Expected the body truncated to 4096 bytes, got %d bytes (truncated=%v)", written, truncated)
+	}
+
+	if sent := pool.GetReqWPSentRequests(); sent != 2 {
+		t.Errorf("Expected 2 requests sent, got %d", sent)
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// wireHeaderNames sends bypassPayload and returns the header names as read on the wire by a raw echo server
+func wireHeaderNames(t *testing.T, opts *rawhttp.HTTPClientOptions, bypassPayload payload.BypassPayload) []string {
+	t.Helper()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		br := bufio.NewReader(conn)
+		br.ReadString('\n') // request line
+		var names []string
+		for {
+			line, err := br.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+			name, _, _ := strings.Cut(line, ":")
+			names = append(names, name)
+		}
+		received <- names
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+	}()
+
+	opts.Dialer = func(addr string) (net.Conn, error) { return ln.Dial() }
+	opts.MaxRetries = 0
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+		t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+	}
+	client.DoRequest(req, resp, bypassPayload)
+
+	select {
+	case names := <-received:
+		return names
+	case <-time.After(5 * time.Second):
+		t.Fatal("Echo server did not receive the request")
+		return nil
+	}
+}
+
+func headerOrderPayload() payload.BypassPayload {
+	return payload.BypassPayload{
+		Method:       "POST",
+		Scheme:       "http",
+		Host:         "example.com",
+		RawURI:       "/admin",
+		BypassModule: "nginx_bypasses",
+		Headers: []payload.Headers{
+			{Header: "X-B", Value: "1"},
+			{Header: "Host", Value: "localhost"},
+			{Header: "X-Original-URL", Value: "/admin"},
+			{Header: "X-A", Value: "1"},
+			{Header: "Transfer-Encoding", Value: "chunked"},
+			{Header: "Content-Length", Value: "5"},
+		},
+		Body: "0\r\n\r\n",
+	}
+}
+
+func TestHeaderOrderOnWire(t *testing.T) {
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.CustomHTTPHeaders = []string{"X-Original-URL: /cli", "X-Cli: 1"}
+
+	got := strings.Join(wireHeaderNames(t, opts, headerOrderPayload()), ",")
+	want := "X-Original-URL,X-Cli,X-B,Host,X-A,Transfer-Encoding,Content-Length"
+	if !strings.HasPrefix(got, want+",") {
+		t.Errorf("Unexpected header order on the wire:\n got: %s\nwant: %s,...", got, want)
+	}
+}
+
+func TestPreserveHeaderOrderOnWire(t *testing.T) {
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.CustomHTTPHeaders = []string{"X-Original-URL: /cli", "X-Cli: 1"}
+	opts.PreserveHeaderOrder = true
+
+	got := strings.Join(wireHeaderNames(t, opts, headerOrderPayload()), ",")
+	want := "X-B,Host,X-Original-URL,X-A,Transfer-Encoding,Content-Length,X-Cli"
+	if !strings.HasPrefix(got, want+",") {
+		t.Errorf("Unexpected header order on the wire:\n got: %s\nwant: %s,...", got, want)
+	}
+
+	// The curl PoC lists the headers in the same order
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+	curl := string(rawhttp.BuildCurlCommandWithOpts(headerOrderPayload(), client.GetHTTPClientOptions(), nil))
+	if !strings.Contains(curl, "-H 'X-B: 1' -H 'Host: localhost' -H 'X-Original-URL: /cli' -H 'X-A: 1'") ||
+		strings.Count(curl, "X-Original-URL") != 1 || !strings.Contains(curl, "-H 'X-Cli: 1' 'http://") {
+		t.Errorf("Unexpected curl header order: %s", curl)
+	}
+}
+
+func TestAuthHeadersFollowCLIHeaders(t *testing.T) {
+	opts := &rawhttp.HTTPClientOptions{
+		CustomHTTPHeaders: []string{"X-Cli: 1", "Authorization: Basic cli"},
+		AuthHeaders:       []string{"Authorization: Bearer token", "Cookie: session=abc"},
+	}
+	opts.PreprocessCustomHeaders()
+
+	bypassPayload := payload.BypassPayload{
+		Method: "GET", Scheme: "http", Host: "example.com", RawURI: "/admin",
+		Headers: []payload.Headers{{Header: "Cookie", Value: "admin=1"}},
+	}
+
+	var got []string
+	for _, h := range rawhttp.RequestHeaders(bypassPayload, opts) {
+		got = append(got, h.Header+": "+h.Value)
+	}
+
+	// -H wins over the auth header of the same name, the payload cookie joins the session cookie
+	want := "X-Cli: 1,Authorization: Basic cli,Cookie: session=abc; admin=1"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Unexpected request headers:\n got: %s\nwant: %s", strings.Join(got, ","), want)
+	}
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestHostHealth(t *testing.T) {
+	if hh := rawhttp.NewHostHealth(0, time.Second, time.Second); hh != nil || hh.IsBackedOff("a.example.com") {
+		t.Fatalf("Expected a nil HostHealth without threshold")
+	}
+
+	hh := rawhttp.NewHostHealth(3, time.Second, 50*time.Millisecond)
+	hh.RecordFailure("a.example.com")
+	hh.RecordFailure("a.example.com")
+	hh.RecordSuccess("a.example.com", 10*time.Millisecond) // an answer resets the failures in a row
+	hh.RecordFailure("a.example.com")
+	hh.RecordFailure("a.example.com")
+	if hh.IsBackedOff("a.example.com") {
+		t.Fatalf("Expected a.example.com healthy, its failures were not in a row")
+	}
+	hh.RecordFailure("a.example.com")
+	if !hh.IsBackedOff("a.example.com") {
+		t.Fatalf("Expected a.example.com backed off after 3 failures in a row")
+	}
+
+	// Fast and slow hosts, the slow one is backed off once its average reaches half of the timeout
+	for i := 0; i < 5; i++ {
+		hh.RecordSuccess("fast.example.com", 20*time.Millisecond)
+		hh.RecordSuccess("slow.example.com", 700*time.Millisecond)
+	}
+	if hh.IsBackedOff("fast.example.com") {
+		t.Errorf("Expected fast.example.com unaffected")
+	}
+	if !hh.IsBackedOff("slow.example.com") {
+		t.Errorf("Expected slow.example.com backed off, its average response time is above half of the timeout")
+	}
+
+	// Back to normal once the recovery window is over
+	time.Sleep(80 * time.Millisecond)
+	if hh.IsBackedOff("a.example.com") || hh.IsBackedOff("slow.example.com") {
+		t.Fatalf("Expected the hosts recovered after the recovery window")
+	}
+
+	hosts := hh.BackedOffHosts()
+	if len(hosts) != 2 || hosts[0].Host != "a.example.com" || hosts[0].FailedRequests != 5 || hosts[0].Backoffs != 1 ||
+		hosts[1].Host != "slow.example.com" || hosts[1].FailedRequests != 0 {
+		t.Errorf("Unexpected backed off hosts: %+v", hosts)
+	}
+}
+
+func TestHostHealthAcquire(t *testing.T) {
+	hh := rawhttp.NewHostHealth(1, time.Second, time.Minute)
+	hh.RecordFailure("a.example.com")
+
+	// A backed off host gets one request at a time
+	release, ok := hh.Acquire(context.Background(), "a.example.com")
+	if !ok {
+		t.Fatalf("Expected the first request to the backed off host let through")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, ok := hh.Acquire(ctx, "a.example.com"); ok {
+		t.Errorf("Expected a second concurrent request to the backed off host to wait")
+	}
+	release()
+	if release, ok := hh.Acquire(context.Background(), "a.example.com"); !ok {
+		t.Errorf("Expected the next request let through once the first one is done")
+	} else {
+		release()
+	}
+
+	// Healthy hosts are never held back
+	for i := 0; i < 3; i++ {
+		if _, ok := hh.Acquire(ctx, "b.example.com"); !ok {
+			t.Errorf("Expected requests to a healthy host let through concurrently")
+		}
+	}
+}
+
+func TestRequestWorkerPoolHostHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	// A closed port, every connection is refused
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	deadHost := ln.Addr().String()
+	ln.Close()
+
+	jobs := func(host string) []payload.BypassPayload {
+		var jobs []payload.BypassPayload
+		for i := 0; i < 30; i++ {
+			jobs = append(jobs, payload.BypassPayload{Method: "GET", Scheme: "http", Host: host, RawURI: "/admin"})
+		}
+		return jobs
+	}
+
+	hostHealth := rawhttp.NewHostHealth(5, time.Second, time.Minute)
+	run := func(host string) (responses int) {
+		opts := rawhttp.DefaultHTTPClientOptions()
+		opts.MaxRetries = 0
+		opts.RetryDelay = time.Millisecond
+		opts.HostHealth = hostHealth
+		pool := rawhttp.NewRequestWorkerPool(opts, 2)
+		defer pool.Close()
+		for range pool.ProcessRequests(context.Background(), jobs(host)) {
+			responses++
+		}
+		return responses
+	}
+
+	run(deadHost)
+	if !hostHealth.IsBackedOff(deadHost) {
+		t.Fatalf("Expected %s backed off", deadHost)
+	}
+
+	// Healthy hosts are unaffected by the backed off one
+	if responses := run(u.Host); responses != 30 {
+		t.Errorf("Expected 30 responses from the healthy host, got %d", responses)
+	}
+	if hostHealth.IsBackedOff(u.Host) {
+		t.Errorf("Expected %s not backed off", u.Host)
+	}
+	if hosts := hostHealth.BackedOffHosts(); len(hosts) != 1 || hosts[0].Host != deadHost {
+		t.Errorf("Unexpected backed off hosts: %+v", hosts)
+	}
+}
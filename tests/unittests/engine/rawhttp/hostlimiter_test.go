@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestHostLimiterCapsConcurrencyPerHost(t *testing.T) {
+	hl := rawhttp.NewHostLimiter(2)
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !hl.Acquire(context.Background(), "a.example.com") {
+				t.Errorf("Acquire failed without cancellation")
+				return
+			}
+			defer hl.Release("a.example.com")
+
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("Expected peak of 2 in-flight requests, got %d", got)
+	}
+}
+
+func TestHostLimiterHostsAreIndependent(t *testing.T) {
+	hl := rawhttp.NewHostLimiter(1)
+
+	if !hl.Acquire(context.Background(), "a.example.com") {
+		t.Fatalf("Acquire failed for a.example.com")
+	}
+	defer hl.Release("a.example.com")
+
+	// A busy host must not block another one
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if !hl.Acquire(ctx, "b.example.com") {
+		t.Fatalf("b.example.com was blocked by a.example.com")
+	}
+	hl.Release("b.example.com")
+
+	// The busy host itself only gives up on cancellation
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	if hl.Acquire(ctx2, "a.example.com") {
+		t.Errorf("Expected Acquire to fail on a full host once the context is done")
+	}
+}
+
+func TestHostLimiterDisabled(t *testing.T) {
+	if hl := rawhttp.NewHostLimiter(0); hl != nil {
+		t.Errorf("Expected nil HostLimiter for limit 0")
+	}
+}
+
+func TestRequestWorkerPoolHostConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.HostConcurrency = 3
+	pool := rawhttp.NewRequestWorkerPool(opts, 20)
+	defer pool.Close()
+
+	var jobs []payload.BypassPayload
+	for i := 0; i < 30; i++ {
+		jobs = append(jobs, payload.BypassPayload{
+			Method: "GET",
+			Scheme: "http",
+			Host:   u.Host,
+			RawURI: "/admin",
+		})
+	}
+
+	count := 0
+	for range pool.ProcessRequests(context.Background(), jobs) {
+		count++
+	}
+
+	if count != len(jobs) {
+		t.Errorf("Expected %d responses, got %d", len(jobs), count)
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("Expected at most 3 concurrent requests to the host, got %d", got)
+	}
+}
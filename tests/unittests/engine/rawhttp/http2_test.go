@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+// newProtoServer answers with the protocol and raw request URI the server saw
+func newProtoServer(t *testing.T, enableHTTP2 bool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		w.Header().Set("X-Authority", r.Host)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("<html><title>Denied</title>" + r.RequestURI + "</html>"))
+	}))
+	server.EnableHTTP2 = enableHTTP2
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sendOne(t *testing.T, opts *rawhttp.HTTPClientOptions, job payload.BypassPayload) *rawhttp.RawHTTPResponseDetails {
+	pool := rawhttp.NewRequestWorkerPool(opts, 2)
+	defer pool.Close()
+
+	var result *rawhttp.RawHTTPResponseDetails
+	for resp := range pool.ProcessRequests(context.Background(), []payload.BypassPayload{job}) {
+		result = resp
+	}
+	if result == nil {
+		t.Fatalf("No response for %s", job.RawURI)
+	}
+	return result
+}
+
+func TestHTTP2Request(t *testing.T) {
+	server := newProtoServer(t, true)
+	u, _ := url.Parse(server.URL)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.EnableHTTP2 = true
+
+	result := sendOne(t, opts, payload.BypassPayload{
+		OriginalURL:  server.URL + "/admin",
+		Method:       "GET",
+		Scheme:       "https",
+		Host:         u.Host,
+		RawURI:       "/admin/..;/",
+		BypassModule: "http2_test",
+		Headers:      []payload.Headers{{Header: "Host", Value: "spoofed.example.com"}},
+	})
+
+	headers := string(result.ResponseHeaders)
+	if result.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", result.StatusCode)
+	}
+	if !strings.HasPrefix(headers, "HTTP/2.0 403") {
+		t.Errorf("Expected an HTTP/2.0 status line, got:\n%s", headers)
+	}
+	if !strings.Contains(headers, "X-Proto: HTTP/2.0") {
+		t.Errorf("Expected the server to see HTTP/2.0, got:\n%s", headers)
+	}
+	if !strings.Contains(headers, "X-Authority: spoofed.example.com") {
+		t.Errorf("Expected the Host header to be sent as :authority, got:\n%s", headers)
+	}
+	if !strings.Contains(string(result.ResponsePreview), "/admin/..;/") {
+		t.Errorf("Expected the raw :path to be preserved, got preview %q", result.ResponsePreview)
+	}
+	if string(result.Title) != "Denied" {
+		t.Errorf("Expected title Denied, got %q", result.Title)
+	}
+	if !strings.Contains(string(result.CurlCommand), "--http2") {
+		t.Errorf("Expected --http2 in the curl PoC, got %q", result.CurlCommand)
+	}
+}
+
+func TestHTTP2FallbackToHTTP1(t *testing.T) {
+	server := newProtoServer(t, false)
+	u, _ := url.Parse(server.URL)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.EnableHTTP2 = true
+
+	for i := 0; i < 2; i++ {
+		result := sendOne(t, opts, payload.BypassPayload{
+			OriginalURL:  server.URL + "/admin",
+			Method:       "GET",
+			Scheme:       "https",
+			Host:         u.Host,
+			RawURI:       "/admin",
+			BypassModule: "http2_test",
+		})
+
+		if result.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", result.StatusCode)
+		}
+		if headers := string(result.ResponseHeaders); !strings.Contains(headers, "X-Proto: HTTP/1.1") {
+			t.Errorf("Expected fallback to HTTP/1.1, got:\n%s", headers)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestIPv6LiteralHost(t *testing.T) {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	hosts := make(chan string, 4)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.Host + " " + r.URL.Path
+	}))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer client.Close()
+
+	// Plain IPv6 literal, and with a URL encoded zone id that must be kept in the Host header
+	for _, host := range []string{"[::1]:" + port, "[::1%25lo]:" + port} {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+
+		bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: host, RawURI: "/admin"}
+		if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+		if _, err := client.DoRequest(req, resp, bypassPayload); err != nil {
+			t.Fatalf("Request to %s failed: %v", host, err)
+		}
+		if got, want := <-hosts, host+" /admin"; got != want {
+			t.Errorf("Server got %q, want %q", got, want)
+		}
+
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+}
+
+func TestCurlCommandIPv6LiteralHost(t *testing.T) {
+	bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: "[fe80::1%25eth0]:8080", RawURI: "/admin"}
+	cmd := string(rawhttp.BuildCurlCommandPoc(bypassPayload, nil))
+
+	// -g (globoff) is required for curl to accept the brackets
+	if !strings.Contains(cmd, "-skgi") {
+		t.Errorf("Expected globbing to be disabled in %q", cmd)
+	}
+	if !strings.HasSuffix(cmd, "'http://[fe80::1%25eth0]:8080/admin'") {
+		t.Errorf("Expected the bracketed host to be kept in %q", cmd)
+	}
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestRequestDelayJitter(t *testing.T) {
+	newClient := func(delay time.Duration, jitter int) *rawhttp.HTTPClient {
+		opts := rawhttp.DefaultHTTPClientOptions()
+		opts.RequestDelay = delay
+		opts.RequestDelayJitter = jitter
+		return rawhttp.NewHTTPClient(opts)
+	}
+
+	client := newClient(100*time.Millisecond, 0)
+	defer client.Close()
+	if delay := client.RequestDelay(); delay != 100*time.Millisecond {
+		t.Errorf("Expected the fixed delay without jitter, got %v", delay)
+	}
+
+	// Jitter without a delay sleeps nothing
+	noDelay := newClient(0, 50)
+	defer noDelay.Close()
+	if delay := noDelay.RequestDelay(); delay != 0 {
+		t.Errorf("Expected no delay without -delay, got %v", delay)
+	}
+
+	jittered := newClient(100*time.Millisecond, 30)
+	defer jittered.Close()
+	var below, above int
+	for i := 0; i < 1000; i++ {
+		delay := jittered.RequestDelay()
+		if delay < 70*time.Millisecond || delay > 130*time.Millisecond {
+			t.Fatalf("Expected a delay within 70-130ms, got %v", delay)
+		}
+		if delay < 100*time.Millisecond {
+			below++
+		} else if delay > 100*time.Millisecond {
+			above++
+		}
+	}
+	if below < 100 || above < 100 {
+		t.Errorf("Expected delays spread on both sides of 100ms, got %d below and %d above", below, above)
+	}
+
+	// Values above 100% are capped, the delay never goes negative
+	capped := newClient(10*time.Millisecond, 250)
+	defer capped.Close()
+	for i := 0; i < 100; i++ {
+		if delay := capped.RequestDelay(); delay < 0 || delay > 20*time.Millisecond {
+			t.Fatalf("Expected a delay within 0-20ms, got %v", delay)
+		}
+	}
+}
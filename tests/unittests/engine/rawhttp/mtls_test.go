@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// newTestCert issues a certificate signed by parent (self-signed if parent is nil)
+func newTestCert(t *testing.T, cn string, isCA bool, usage x509.ExtKeyUsage, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{cn},
+	}
+
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key, der
+}
+
+func writeKeyPair(t *testing.T, dir, name string, der []byte, key *ecdsa.PrivateKey) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestHTTPClientPresentsClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+
+	ca, caKey, _ := newTestCert(t, "test-ca", true, x509.ExtKeyUsageAny, nil, nil)
+	_, serverKey, serverDER := newTestCert(t, "test", false, x509.ExtKeyUsageServerAuth, ca, caKey)
+	_, clientKey, clientDER := newTestCert(t, "gobypass403-client", false, x509.ExtKeyUsageClientAuth, ca, caKey)
+
+	clientCertFile, clientKeyFile := writeKeyPair(t, dir, "client", clientDER, clientKey)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	server := fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			peerCerts := ctx.TLSConnectionState().PeerCertificates
+			if len(peerCerts) == 0 {
+				ctx.SetStatusCode(fasthttp.StatusForbidden)
+				return
+			}
+			ctx.SetStatusCode(fasthttp.StatusOK)
+			ctx.SetBodyString(peerCerts[0].Subject.CommonName)
+		},
+	}
+	defer server.Shutdown()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	tlsLn := tls.NewListener(ln, &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	go server.Serve(tlsLn)
+
+	send := func(certFile, keyFile string) (*fasthttp.Response, error) {
+		opts := rawhttp.DefaultHTTPClientOptions()
+		opts.ClientCertFile = certFile
+		opts.ClientKeyFile = keyFile
+		opts.MaxRetries = 0
+		opts.Dialer = func(addr string) (net.Conn, error) {
+			return ln.Dial()
+		}
+		client := rawhttp.NewHTTPClient(opts)
+		defer client.Close()
+
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		resp := fasthttp.AcquireResponse()
+
+		req.SetRequestURI("https://test/admin")
+		_, err := client.DoRequest(req, resp, payload.BypassPayload{})
+		return resp, err
+	}
+
+	resp, err := send(clientCertFile, clientKeyFile)
+	if err != nil {
+		t.Fatalf("mTLS request failed: %v", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK || string(resp.Body()) != "gobypass403-client" {
+		t.Errorf("Expected 200 with client CN, got %d %q", resp.StatusCode(), resp.Body())
+	}
+	fasthttp.ReleaseResponse(resp)
+
+	// Without a client certificate the handshake must be rejected
+	resp, err = send("", "")
+	if err == nil && resp.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected the server to reject a client without certificate")
+	}
+	fasthttp.ReleaseResponse(resp)
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+// closedAddr returns the address of a listener that was closed, connections to it are refused
+func closedAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestProxyRotatorRoundRobin(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	targetAddr := target.Listener.Addr().String()
+
+	addr1, destinations1 := startSOCKS5Server(t)
+	addr2, destinations2 := startSOCKS5Server(t)
+	dead := "socks5://" + closedAddr(t)
+
+	rotator := rawhttp.NewProxyRotator([]string{"socks5://" + addr1, dead, "socks5://" + addr2}, 5*time.Second)
+
+	// The dead proxy is skipped on failure, then dropped from the rotation
+	for i := 0; i < 3*rawhttp.MaxProxyConsecutiveFailures; i++ {
+		conn, err := rotator.Dial(targetAddr)
+		if err != nil {
+			t.Fatalf("Dial %d failed: %v", i, err)
+		}
+		conn.Close()
+	}
+
+	if alive := rotator.Alive(); alive != 2 {
+		t.Errorf("Expected the dead proxy to be dropped, %d proxies alive", alive)
+	}
+	n1, n2 := len(destinations1()), len(destinations2())
+	if n1 == 0 || n2 == 0 || n1+n2 != 3*rawhttp.MaxProxyConsecutiveFailures {
+		t.Errorf("Expected connections spread over both live proxies, got %d and %d", n1, n2)
+	}
+}
+
+func TestProxyRotatorAllProxiesDropped(t *testing.T) {
+	rotator := rawhttp.NewProxyRotator([]string{"socks5://" + closedAddr(t), "http://" + closedAddr(t)}, time.Second)
+
+	var err error
+	for i := 0; i < rawhttp.MaxProxyConsecutiveFailures; i++ {
+		if _, err = rotator.Dial("127.0.0.1:80"); err == nil {
+			t.Fatalf("Dial %d through dead proxies succeeded", i)
+		}
+	}
+
+	if rotator.Alive() != 0 || !errors.Is(err, rawhttp.ErrNoProxyAvailable) {
+		t.Errorf("Expected all proxies dropped, got %d alive (err: %v)", rotator.Alive(), err)
+	}
+}
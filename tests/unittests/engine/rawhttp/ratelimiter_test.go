@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	if rl := rawhttp.NewRateLimiter(0); rl != nil {
+		t.Fatalf("Expected nil RateLimiter for rate 0")
+	}
+
+	rl := rawhttp.NewRateLimiter(100)
+
+	// 21 tokens from several goroutines: the first is free, the next 20 take 10ms each
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 21; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !rl.Wait(context.Background()) {
+				t.Errorf("Wait failed without cancellation")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("Expected 21 requests at 100 req/s to take at least 200ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	rl := rawhttp.NewRateLimiter(1)
+	if !rl.Wait(context.Background()) {
+		t.Fatalf("Expected the first token to be available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if rl.Wait(ctx) {
+		t.Errorf("Expected Wait to fail once the context is done")
+	}
+}
+
+func TestRequestWorkerPoolRequestRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.RequestRate = 50
+	pool := rawhttp.NewRequestWorkerPool(opts, 10)
+	defer pool.Close()
+
+	var jobs []payload.BypassPayload
+	for i := 0; i < 11; i++ {
+		jobs = append(jobs, payload.BypassPayload{Method: "GET", Scheme: "http", Host: u.Host, RawURI: "/admin"})
+	}
+
+	start := time.Now()
+	count := 0
+	for range pool.ProcessRequests(context.Background(), jobs) {
+		count++
+	}
+
+	if count != len(jobs) {
+		t.Errorf("Expected %d responses, got %d", len(jobs), count)
+	}
+	// 10 workers, but 11 requests at 50 req/s still need 200ms
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("Expected 11 requests at 50 req/s to take at least 200ms, took %v", elapsed)
+	}
+}
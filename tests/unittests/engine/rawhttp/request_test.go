@@ -5,7 +5,11 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -510,3 +514,302 @@ func TestResponseProcessingWithSpacedHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestBuilderKeepsHeaderValueSpaces(t *testing.T) {
+	client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer client.Close()
+
+	for _, value := range []string{"example.com ", "example.com\t"} {
+		req := fasthttp.AcquireRequest()
+		bypassPayload := payload.BypassPayload{
+			Method:       "GET",
+			Scheme:       "http",
+			Host:         "example.com",
+			RawURI:       "/admin",
+			Headers:      []payload.Headers{{Header: "Host", Value: value}},
+			BypassModule: "http_host_mutations",
+		}
+		if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+
+		if !bytes.Contains(req.Header.Header(), []byte("Host: "+value+"\r\n")) {
+			t.Errorf("Expected Host value %q on the wire, got:\n%q", value, req.Header.Header())
+		}
+		fasthttp.ReleaseRequest(req)
+	}
+}
+
+func TestRequestBuilderPayloadAcceptReplacesDefault(t *testing.T) {
+	client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer client.Close()
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	bypassPayload := payload.BypassPayload{
+		Method:       "GET",
+		Scheme:       "http",
+		Host:         "example.com",
+		RawURI:       "/admin",
+		Headers:      []payload.Headers{{Header: "Accept", Value: "application/json"}},
+		BypassModule: "http_headers_accept",
+	}
+	if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+		t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+	}
+
+	headers := req.Header.Header()
+	if bytes.Count(headers, []byte("Accept:")) != 1 || !bytes.Contains(headers, []byte("Accept: application/json\r\n")) {
+		t.Errorf("Expected only the payload Accept header, got:\n%q", headers)
+	}
+}
+
+func TestRequestBuilderAcceptEncoding(t *testing.T) {
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.AcceptEncoding = "gzip, br"
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	tests := []struct {
+		name    string
+		headers []payload.Headers
+		want    string
+	}{
+		{name: "option", want: "Accept-Encoding: gzip, br\r\n"},
+		{name: "payload header", headers: []payload.Headers{{Header: "accept-encoding", Value: "identity"}}, want: "accept-encoding: identity\r\n"},
+	}
+
+	for _, tt := range tests {
+		req := fasthttp.AcquireRequest()
+		bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: "example.com", RawURI: "/admin", Headers: tt.headers}
+		if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+
+		headers := req.Header.Header()
+		if bytes.Count(bytes.ToLower(headers), []byte("accept-encoding:")) != 1 || !bytes.Contains(headers, []byte(tt.want)) {
+			t.Errorf("%s: expected only %q, got:\n%q", tt.name, tt.want, headers)
+		}
+		fasthttp.ReleaseRequest(req)
+	}
+}
+
+func TestRequestBuilderCloseConnectionModules(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, tt := range []struct {
+		module    string
+		wantClose bool
+		wantConns int32
+	}{
+		{module: "mid_paths", wantClose: false, wantConns: 1},
+		{module: "nginx_bypasses", wantClose: true, wantConns: 3},
+	} {
+		conns.Store(0)
+		client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+		for i := 0; i < 3; i++ {
+			bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: host, RawURI: "/admin", BypassModule: tt.module}
+			req := fasthttp.AcquireRequest()
+			resp := fasthttp.AcquireResponse()
+			if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+				t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+			}
+			if req.ConnectionClose() != tt.wantClose {
+				t.Errorf("%s: expected ConnectionClose %v", tt.module, tt.wantClose)
+			}
+			if _, err := client.DoRequest(req, resp, bypassPayload); err != nil {
+				t.Fatalf("%s: DoRequest failed: %v", tt.module, err)
+			}
+			fasthttp.ReleaseRequest(req)
+			fasthttp.ReleaseResponse(resp)
+		}
+		if got := conns.Load(); got != tt.wantConns {
+			t.Errorf("%s: expected %d connections for 3 requests, got %d", tt.module, tt.wantConns, got)
+		}
+		client.Close()
+	}
+}
+
+func TestRequestBuilderExtensionConfusionPayloads(t *testing.T) {
+	client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer client.Close()
+
+	targetURL := "http://example.com/admin/config.php?id=1"
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{TargetURL: targetURL, BypassModule: "extension_confusion"})
+	jobs := pg.GenerateExtensionConfusionPayloads(targetURL, "extension_confusion")
+	if len(jobs) == 0 {
+		t.Fatalf("No extension_confusion payloads generated")
+	}
+
+	// The encoded bytes (%00, %09, %2e...) must reach the wire as generated, not decoded or normalized
+	for _, job := range jobs {
+		req := fasthttp.AcquireRequest()
+		if err := rawhttp.BuildRawHTTPRequest(client, req, job); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed for %s: %v", job.RawURI, err)
+		}
+		if got := string(req.Header.RequestURI()); got != job.RawURI {
+			t.Errorf("Request URI %q, want %q", got, job.RawURI)
+		}
+		if !strings.Contains(req.String(), "GET "+job.RawURI+" HTTP/1.1\r\n") {
+			t.Errorf("Request line of %s not sent verbatim:\n%s", job.RawURI, req.String())
+		}
+		fasthttp.ReleaseRequest(req)
+	}
+}
+
+func TestRequestBuilderNormalizePath(t *testing.T) {
+	verbatim := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer verbatim.Close()
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.DisablePathNormalizing = false
+	normalizing := rawhttp.NewHTTPClient(opts)
+	defer normalizing.Close()
+
+	tests := []struct {
+		rawURI string
+		want   string
+	}{
+		{rawURI: "/admin/%2e%2e/secret", want: "/secret"},
+		{rawURI: "/a//b/./c/../admin?x=/../y", want: "/a/b/admin?x=/../y"},
+		{rawURI: "//admin", want: "/admin"},
+		{rawURI: "/%61dmin", want: "/admin"},
+		{rawURI: "/admin/..;/", want: "/admin/..;/"},
+		{rawURI: "http://localhost/admin/../x", want: "http://localhost/admin/../x"},
+	}
+
+	for _, tt := range tests {
+		bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: "example.com", RawURI: tt.rawURI}
+
+		for _, c := range []struct {
+			client *rawhttp.HTTPClient
+			want   string
+		}{{verbatim, tt.rawURI}, {normalizing, tt.want}} {
+			req := fasthttp.AcquireRequest()
+			if err := rawhttp.BuildRawHTTPRequest(c.client, req, bypassPayload); err != nil {
+				t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+			}
+			if got := string(req.Header.RequestURI()); got != c.want {
+				t.Errorf("%s (DisablePathNormalizing=%v): got %q, want %q", tt.rawURI, c.client.GetHTTPClientOptions().DisablePathNormalizing, got, c.want)
+			}
+			fasthttp.ReleaseRequest(req)
+		}
+
+		if !strings.HasPrefix(tt.rawURI, "/") {
+			continue
+		}
+		if poc := string(rawhttp.BuildCurlCommandWithOpts(bypassPayload, opts, nil)); !strings.Contains(poc, "'http://example.com"+tt.want+"'") {
+			t.Errorf("%s: curl PoC does not send the normalized path: %s", tt.rawURI, poc)
+		}
+	}
+}
+
+func TestRequestBuilderMergesPayloadCookieWithCLICookie(t *testing.T) {
+	bypassPayload := payload.BypassPayload{
+		Method:       "GET",
+		Scheme:       "http",
+		Host:         "example.com",
+		RawURI:       "/admin",
+		Headers:      []payload.Headers{{Header: "Cookie", Value: "isadmin=1"}},
+		BypassModule: "http_cookies",
+	}
+
+	for _, preserveOrder := range []bool{false, true} {
+		opts := rawhttp.DefaultHTTPClientOptions()
+		opts.CustomHTTPHeaders = []string{"Cookie: session=abc"}
+		opts.PreserveHeaderOrder = preserveOrder
+		client := rawhttp.NewHTTPClient(opts)
+
+		req := fasthttp.AcquireRequest()
+		if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+		headers := req.Header.Header()
+		if bytes.Count(headers, []byte("Cookie:")) != 1 || !bytes.Contains(headers, []byte("Cookie: session=abc; isadmin=1\r\n")) {
+			t.Errorf("preserve=%v: expected a single merged Cookie header, got:\n%q", preserveOrder, headers)
+		}
+		fasthttp.ReleaseRequest(req)
+
+		curl := string(rawhttp.BuildCurlCommandWithOpts(bypassPayload, client.GetHTTPClientOptions(), nil))
+		if strings.Count(curl, "Cookie:") != 1 || !strings.Contains(curl, "-H 'Cookie: session=abc; isadmin=1'") {
+			t.Errorf("preserve=%v: expected the merged Cookie header in the curl PoC, got %s", preserveOrder, curl)
+		}
+		client.Close()
+	}
+}
+
+func TestRequestBuilderAbsoluteURIViaEchoServer(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	// The server echoes the raw request target and Host header it parsed
+	s := &fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			fmt.Fprintf(ctx, "%s\n%s", ctx.Request.Header.RequestURI(), ctx.Request.Header.Peek("Host"))
+		},
+		DisableHeaderNamesNormalizing: true,
+	}
+	go s.Serve(ln)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.Dialer = func(addr string) (net.Conn, error) {
+		return ln.Dial()
+	}
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	targetURL := "http://example.com/admin?id=1"
+	pg := payload.NewPayloadGenerator(payload.PayloadGeneratorOptions{
+		TargetURL:    targetURL,
+		BypassModule: "http_absolute_uri",
+	})
+	jobs := pg.GenerateHTTPAbsoluteURIPayloads(targetURL, "http_absolute_uri")
+
+	absolute := 0
+	for _, job := range jobs {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		if err := rawhttp.BuildRawHTTPRequest(client, req, job); err != nil {
+			t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+		}
+		if _, err := client.DoRequest(req, resp, job); err != nil {
+			t.Fatalf("%s: request failed: %v", job.RawURI, err)
+		}
+
+		requestURI, host, _ := strings.Cut(string(resp.Body()), "\n")
+		if requestURI != job.RawURI {
+			t.Errorf("Expected request target %q on the request line, server got %q", job.RawURI, requestURI)
+		}
+		if strings.Contains(job.RawURI, "://") {
+			absolute++
+		}
+
+		wantHost := "example.com"
+		for _, h := range job.Headers {
+			if h.Header == "Host" {
+				wantHost = h.Value
+			}
+		}
+		if host != wantHost {
+			t.Errorf("%s: expected Host %q, server got %q", job.RawURI, wantHost, host)
+		}
+
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}
+
+	if absolute == 0 {
+		t.Error("Expected absolute-form request lines")
+	}
+}
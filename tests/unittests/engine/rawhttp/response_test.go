@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestIsOpenRedirect(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		payload  payload.BypassPayload
+		want     bool
+	}{
+		{
+			name:     "relative redirect",
+			location: "/login",
+			payload:  payload.BypassPayload{Host: "example.com", RawURI: "/admin"},
+			want:     false,
+		},
+		{
+			name:     "same host redirect",
+			location: "https://example.com/login",
+			payload:  payload.BypassPayload{Host: "example.com:443", RawURI: "/admin"},
+			want:     false,
+		},
+		{
+			name:     "injected host header reflected",
+			location: "http://evil.com/admin/",
+			payload: payload.BypassPayload{Host: "example.com", RawURI: "/admin",
+				Headers: []payload.Headers{{Header: "X-Forwarded-Host", Value: "evil.com:8080"}}},
+			want: true,
+		},
+		{
+			name:     "injected url header reflected",
+			location: "https://127.0.0.1/",
+			payload: payload.BypassPayload{Host: "example.com", RawURI: "/",
+				Headers: []payload.Headers{{Header: "X-Original-URL", Value: "https://127.0.0.1/admin"}}},
+			want: true,
+		},
+		{
+			name:     "host injected in request uri",
+			location: "//evil.com/",
+			payload:  payload.BypassPayload{Host: "example.com", RawURI: "//evil.com/%2e%2e"},
+			want:     true,
+		},
+		{
+			name:     "off-host redirect not derived from payload",
+			location: "https://sso.example.org/auth",
+			payload: payload.BypassPayload{Host: "example.com", RawURI: "/admin",
+				Headers: []payload.Headers{{Header: "X-Forwarded-For", Value: "127.0.0.1"}}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rawhttp.IsOpenRedirect([]byte(tt.location), tt.payload); got != tt.want {
+				t.Errorf("IsOpenRedirect(%q) = %v, want %v", tt.location, got, tt.want)
+			}
+		})
+	}
+}
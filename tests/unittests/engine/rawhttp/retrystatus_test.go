@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// retryStatusClient returns a client retrying 429/503 against handler, and the request counter
+func retryStatusClient(t *testing.T, handler func(ctx *fasthttp.RequestCtx, request int)) (*rawhttp.HTTPClient, *int) {
+	t.Helper()
+	requests := 0
+	server := fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			requests++
+			handler(ctx, requests)
+		},
+	}
+	ln := fasthttputil.NewInmemoryListener()
+	go server.Serve(ln)
+	t.Cleanup(func() {
+		server.Shutdown()
+		ln.Close()
+	})
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.RetryDelay = 10 * time.Millisecond
+	opts.RetryStatusCodes = []int{429, 503}
+	opts.Dialer = func(addr string) (net.Conn, error) {
+		return ln.Dial()
+	}
+	client := rawhttp.NewHTTPClient(opts)
+	t.Cleanup(client.Close)
+	return client, &requests
+}
+
+// sendRetryStatus sends a request and returns the final status code and the time it took
+func sendRetryStatus(t *testing.T, client *rawhttp.HTTPClient) (int, time.Duration) {
+	t.Helper()
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI("http://test/admin")
+	start := time.Now()
+	if _, err := client.DoRequest(req, resp, payload.BypassPayload{}); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp.StatusCode(), time.Since(start)
+}
+
+func TestHTTPClientRetryStatus(t *testing.T) {
+	// Transient 503 with a Retry-After, then the real response
+	client, requests := retryStatusClient(t, func(ctx *fasthttp.RequestCtx, request int) {
+		if request == 1 {
+			ctx.Response.Header.Set("Retry-After", "1")
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			return
+		}
+		ctx.SetStatusCode(fasthttp.StatusOK)
+	})
+
+	statusCode, elapsed := sendRetryStatus(t, client)
+	if statusCode != 200 || *requests != 2 {
+		t.Errorf("Expected the 503 to be retried once, got status %d after %d requests", statusCode, *requests)
+	}
+	if elapsed < 900*time.Millisecond {
+		t.Errorf("Expected the retry to wait for Retry-After, waited %v", elapsed)
+	}
+}
+
+func TestHTTPClientRetryStatusBounded(t *testing.T) {
+	client, requests := retryStatusClient(t, func(ctx *fasthttp.RequestCtx, request int) {
+		ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+	})
+
+	// Initial request + MaxRetries (2), the last 429 is kept as the response
+	statusCode, _ := sendRetryStatus(t, client)
+	if statusCode != 429 || *requests != 3 {
+		t.Errorf("Expected 3 requests ending with a 429, got status %d after %d requests", statusCode, *requests)
+	}
+
+	// Other status codes are never retried
+	client, requests = retryStatusClient(t, func(ctx *fasthttp.RequestCtx, request int) {
+		ctx.SetStatusCode(fasthttp.StatusForbidden)
+	})
+	if statusCode, _ := sendRetryStatus(t, client); statusCode != 403 || *requests != 1 {
+		t.Errorf("Expected a single request for a 403, got status %d after %d requests", statusCode, *requests)
+	}
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestSNIOverride(t *testing.T) {
+	ca, caKey, _ := newTestCert(t, "test-ca", true, x509.ExtKeyUsageAny, nil, nil)
+	_, serverKey, serverDER := newTestCert(t, "test", false, x509.ExtKeyUsageServerAuth, ca, caKey)
+
+	var mu sync.Mutex
+	var serverNames []string
+	var hostHeaders []string
+
+	server := fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			mu.Lock()
+			hostHeaders = append(hostHeaders, string(ctx.Host()))
+			mu.Unlock()
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		},
+	}
+	defer server.Shutdown()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	serverCert := tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+	go server.Serve(tls.NewListener(ln, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			serverNames = append(serverNames, hello.ServerName)
+			mu.Unlock()
+			return nil, nil
+		},
+	}))
+
+	tests := []struct {
+		name        string
+		sni         string
+		fingerprint string
+		http2       bool
+		wantSNI     string
+	}{
+		{name: "default", wantSNI: "protected.test"},
+		{name: "override", sni: "allowed.test", wantSNI: "allowed.test"},
+		{name: "override with tls fingerprint", sni: "allowed.test", fingerprint: "chrome", wantSNI: "allowed.test"},
+		{name: "override with http2", sni: "allowed.test", http2: true, wantSNI: "allowed.test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			serverNames, hostHeaders = nil, nil
+			mu.Unlock()
+
+			opts := rawhttp.DefaultHTTPClientOptions()
+			opts.SNI = tt.sni
+			opts.TLSFingerprint = tt.fingerprint
+			opts.EnableHTTP2 = tt.http2
+			opts.MaxRetries = 0
+			opts.Dialer = func(addr string) (net.Conn, error) {
+				if addr != "protected.test:443" {
+					t.Errorf("Expected a connection to the target host, got %s", addr)
+				}
+				return ln.Dial()
+			}
+			client := rawhttp.NewHTTPClient(opts)
+			defer client.Close()
+
+			req := fasthttp.AcquireRequest()
+			resp := fasthttp.AcquireResponse()
+			defer fasthttp.ReleaseRequest(req)
+			defer fasthttp.ReleaseResponse(resp)
+
+			req.SetRequestURI("https://protected.test/admin")
+			bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "https", Host: "protected.test", RawURI: "/admin"}
+			if _, err := client.DoRequest(req, resp, bypassPayload); err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(serverNames) == 0 {
+				t.Fatalf("Server saw no ClientHello")
+			}
+			// The h2 attempt and the HTTP/1.1 fallback both present the SNI
+			for _, serverName := range serverNames {
+				if serverName != tt.wantSNI {
+					t.Errorf("Expected SNI %q, got %q", tt.wantSNI, serverName)
+				}
+			}
+			if len(hostHeaders) != 1 || hostHeaders[0] != "protected.test" {
+				t.Errorf("Expected the Host header unchanged, got %v", hostHeaders)
+			}
+		})
+	}
+}
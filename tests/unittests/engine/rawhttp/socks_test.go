@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+)
+
+// startSOCKS5Server starts a minimal no-auth SOCKS5 server (CONNECT only) and
+// records the destination of every tunneled connection
+func startSOCKS5Server(t *testing.T) (string, func() []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start SOCKS5 listener: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	var destinations []string
+
+	handle := func(client net.Conn) {
+		defer client.Close()
+
+		// Greeting: VER, NMETHODS, METHODS
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(client, header); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(client, make([]byte, header[1])); err != nil {
+			return
+		}
+		client.Write([]byte{0x05, 0x00})
+
+		// Request: VER, CMD, RSV, ATYP, DST.ADDR, DST.PORT
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(client, req); err != nil || req[1] != 0x01 {
+			return
+		}
+		var host string
+		switch req[3] {
+		case 0x01:
+			ip := make([]byte, 4)
+			io.ReadFull(client, ip)
+			host = net.IP(ip).String()
+		case 0x03:
+			l := make([]byte, 1)
+			io.ReadFull(client, l)
+			name := make([]byte, l[0])
+			io.ReadFull(client, name)
+			host = string(name)
+		case 0x04:
+			ip := make([]byte, 16)
+			io.ReadFull(client, ip)
+			host = net.IP(ip).String()
+		default:
+			return
+		}
+		portBytes := make([]byte, 2)
+		io.ReadFull(client, portBytes)
+		dest := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes))))
+
+		mu.Lock()
+		destinations = append(destinations, dest)
+		mu.Unlock()
+
+		upstream, err := net.DialTimeout("tcp", dest, 5*time.Second)
+		if err != nil {
+			client.Write([]byte{0x05, 0x05, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+			return
+		}
+		defer upstream.Close()
+		client.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+
+		go io.Copy(upstream, client)
+		io.Copy(client, upstream)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handle(conn)
+		}
+	}()
+
+	return ln.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, destinations...)
+	}
+}
+
+func TestSOCKS5ProxyDialer(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "path=%s", r.URL.Path)
+	}))
+	defer target.Close()
+
+	socksAddr, destinations := startSOCKS5Server(t)
+	targetAddr := target.Listener.Addr().String()
+
+	for _, scheme := range []string{"socks5", "socks5h"} {
+		t.Run(scheme, func(t *testing.T) {
+			client := &fasthttp.Client{
+				Dial: rawhttp.CreateHTTPClientDialer(5*time.Second, scheme+"://"+socksAddr),
+			}
+
+			statusCode, body, err := client.GetTimeout(nil, target.URL+"/admin", 5*time.Second)
+			if err != nil {
+				t.Fatalf("Request through SOCKS5 proxy failed: %v", err)
+			}
+			if statusCode != 200 || string(body) != "path=/admin" {
+				t.Errorf("Unexpected response: %d %q", statusCode, body)
+			}
+		})
+	}
+
+	seen := destinations()
+	if len(seen) == 0 {
+		t.Fatalf("No connection went through the SOCKS5 proxy")
+	}
+	for _, dest := range seen {
+		if dest != targetAddr {
+			t.Errorf("Unexpected SOCKS5 destination %s, want %s", dest, targetAddr)
+		}
+	}
+}
+
+func TestSOCKS5ProxyDialerUnreachableProxy(t *testing.T) {
+	// Grab a free port and close it, nothing listens there anymore
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	dial := rawhttp.CreateHTTPClientDialer(2*time.Second, "socks5://"+addr)
+	if conn, err := dial("example.com:80"); err == nil {
+		conn.Close()
+		t.Errorf("Expected an error dialing through an unreachable SOCKS5 proxy")
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func TestThrottlerRetryAfter(t *testing.T) {
+	config := rawhttp.DefaultThrottleConfig()
+	config.BaseRequestDelay = time.Millisecond
+	config.RequestDelayJitter = 0
+	throttler := rawhttp.NewThrottler(config)
+	throttler.EnableThrottler()
+
+	throttler.SetRetryAfter(300 * time.Millisecond)
+
+	start := time.Now()
+	throttler.ThrottleRequest()
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("Expected ThrottleRequest to honor Retry-After (~300ms), waited %v", elapsed)
+	}
+
+	// Deadline passed, back to the regular backoff
+	start = time.Now()
+	throttler.ThrottleRequest()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected Retry-After to be consumed, waited %v", elapsed)
+	}
+}
+
+func TestThrottlerRetryAfterCapped(t *testing.T) {
+	config := rawhttp.DefaultThrottleConfig()
+	config.BaseRequestDelay = time.Millisecond
+	config.RequestDelayJitter = 0
+	config.MaxRetryAfter = 200 * time.Millisecond
+	throttler := rawhttp.NewThrottler(config)
+	throttler.EnableThrottler()
+
+	throttler.SetRetryAfter(time.Hour)
+
+	start := time.Now()
+	throttler.ThrottleRequest()
+	elapsed := time.Since(start)
+	if elapsed < 150*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected Retry-After capped at 200ms, waited %v", elapsed)
+	}
+}
+
+func TestHTTPClientHonorsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter func() string
+	}{
+		{"delay-seconds", func() string { return "1" }},
+		{"http-date", func() string { return time.Now().Add(2 * time.Second).UTC().Format(time.RFC1123) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			server := fasthttp.Server{
+				Handler: func(ctx *fasthttp.RequestCtx) {
+					requests++
+					if requests == 1 {
+						ctx.Response.Header.Set("Retry-After", tc.retryAfter())
+						ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+						return
+					}
+					ctx.SetStatusCode(fasthttp.StatusOK)
+				},
+			}
+			defer server.Shutdown()
+
+			ln := fasthttputil.NewInmemoryListener()
+			defer ln.Close()
+			go server.Serve(ln)
+
+			opts := rawhttp.DefaultHTTPClientOptions()
+			opts.AutoThrottle = true
+			opts.Dialer = func(addr string) (net.Conn, error) {
+				return ln.Dial()
+			}
+			client := rawhttp.NewHTTPClient(opts)
+			defer client.Close()
+
+			send := func() {
+				req := fasthttp.AcquireRequest()
+				resp := fasthttp.AcquireResponse()
+				defer fasthttp.ReleaseRequest(req)
+				defer fasthttp.ReleaseResponse(resp)
+
+				req.SetRequestURI("http://test/admin")
+				if _, err := client.DoRequest(req, resp, payload.BypassPayload{}); err != nil {
+					t.Fatalf("Request failed: %v", err)
+				}
+			}
+
+			send() // 429 + Retry-After
+
+			start := time.Now()
+			send()
+			if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+				t.Errorf("Expected the next request to wait for Retry-After, waited %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestHTTPClientIgnoresRetryAfterOfOtherStatusCodes(t *testing.T) {
+	requests := 0
+	server := fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			requests++
+			if requests == 1 {
+				// Throttled status code, but Retry-After is only honored on 429 and 503
+				ctx.Response.Header.Set("Retry-After", "3")
+				ctx.SetStatusCode(fasthttp.StatusInsufficientStorage)
+				return
+			}
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		},
+	}
+	defer server.Shutdown()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go server.Serve(ln)
+
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.AutoThrottle = true
+	opts.Dialer = func(addr string) (net.Conn, error) {
+		return ln.Dial()
+	}
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	send := func() {
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+
+		req.SetRequestURI("http://test/admin")
+		if _, err := client.DoRequest(req, resp, payload.BypassPayload{}); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+
+	send() // 507 + Retry-After
+
+	start := time.Now()
+	send()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the Retry-After of a 507 to be ignored, waited %v", elapsed)
+	}
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+// isGREASE reports whether v is a GREASE value (RFC 8701), sent by browsers but never by crypto/tls
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+func TestTLSFingerprintDialer(t *testing.T) {
+	ca, caKey, _ := newTestCert(t, "test-ca", true, x509.ExtKeyUsageAny, nil, nil)
+	_, serverKey, serverDER := newTestCert(t, "test", false, x509.ExtKeyUsageServerAuth, ca, caKey)
+
+	var mu sync.Mutex
+	var hellos []*tls.ClientHelloInfo
+
+	server := fasthttp.Server{
+		Handler: func(ctx *fasthttp.RequestCtx) {
+			ctx.SetStatusCode(fasthttp.StatusOK)
+		},
+	}
+	defer server.Shutdown()
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	serverCert := tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey}
+	go server.Serve(tls.NewListener(ln, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			mu.Lock()
+			hellos = append(hellos, hello)
+			mu.Unlock()
+			return nil, nil
+		},
+	}))
+
+	send := func(t *testing.T, fingerprint string) *tls.ClientHelloInfo {
+		mu.Lock()
+		hellos = nil
+		mu.Unlock()
+
+		opts := rawhttp.DefaultHTTPClientOptions()
+		opts.TLSFingerprint = fingerprint
+		opts.MaxRetries = 0
+		opts.Dialer = func(addr string) (net.Conn, error) {
+			return ln.Dial()
+		}
+		client := rawhttp.NewHTTPClient(opts)
+		defer client.Close()
+
+		req := fasthttp.AcquireRequest()
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseRequest(req)
+		defer fasthttp.ReleaseResponse(resp)
+
+		req.SetRequestURI("https://test/admin")
+		if _, err := client.DoRequest(req, resp, payload.BypassPayload{}); err != nil {
+			t.Fatalf("Request with TLS fingerprint %q failed: %v", fingerprint, err)
+		}
+		if resp.StatusCode() != fasthttp.StatusOK {
+			t.Fatalf("Expected 200, got %d", resp.StatusCode())
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(hellos) == 0 {
+			t.Fatalf("Server saw no ClientHello")
+		}
+		return hellos[0]
+	}
+
+	// Baseline: the Go handshake never sends GREASE
+	if hello := send(t, ""); slices.ContainsFunc(hello.CipherSuites, isGREASE) {
+		t.Errorf("Default Go ClientHello unexpectedly contains GREASE")
+	}
+
+	for _, fingerprint := range rawhttp.TLSFingerprints {
+		t.Run(fingerprint, func(t *testing.T) {
+			hello := send(t, fingerprint)
+
+			if slices.Contains(hello.SupportedProtos, "h2") {
+				t.Errorf("ClientHello offers h2, ALPN must be restricted to http/1.1: %v", hello.SupportedProtos)
+			}
+			if hello.ServerName != "test" {
+				t.Errorf("Expected SNI %q, got %q", "test", hello.ServerName)
+			}
+			if fingerprint == "chrome" && !slices.ContainsFunc(hello.CipherSuites, isGREASE) {
+				t.Errorf("Chrome ClientHello should contain GREASE cipher suites")
+			}
+		})
+	}
+}
+
+func TestTLSFingerprintInvalid(t *testing.T) {
+	if rawhttp.IsValidTLSFingerprint("netscape") {
+		t.Errorf("Expected netscape to be an invalid TLS fingerprint")
+	}
+	if !rawhttp.IsValidTLSFingerprint("Chrome") {
+		t.Errorf("Expected TLS fingerprint names to be case-insensitive")
+	}
+
+	dial := func(addr string) (net.Conn, error) { return nil, nil }
+	if _, err := rawhttp.CreateTLSFingerprintDialer(dial, "netscape", time.Second, "", "", ""); err == nil {
+		t.Errorf("Expected an error for an unsupported TLS fingerprint")
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/valyala/fasthttp"
+)
+
+// buildUserAgent builds a request with the client and returns its User-Agent header
+func buildUserAgent(t *testing.T, client *rawhttp.HTTPClient) string {
+	t.Helper()
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	bypassPayload := payload.BypassPayload{Method: "GET", Scheme: "http", Host: "example.com", RawURI: "/admin"}
+	if err := rawhttp.BuildRawHTTPRequest(client, req, bypassPayload); err != nil {
+		t.Fatalf("BuildRawHTTPRequest failed: %v", err)
+	}
+	return string(req.Header.UserAgent())
+}
+
+func TestUserAgentRotation(t *testing.T) {
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.UserAgents = []string{"agent-one", "agent-two", "agent-three"}
+	client := rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+
+	for i := 0; i < 6; i++ {
+		if got, want := buildUserAgent(t, client), opts.UserAgents[i%3]; got != want {
+			t.Errorf("Request %d: expected User-Agent %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestUserAgentDefaultAndOverride(t *testing.T) {
+	client := rawhttp.NewHTTPClient(rawhttp.DefaultHTTPClientOptions())
+	defer client.Close()
+	if got := buildUserAgent(t, client); got != string(rawhttp.CustomUserAgent) {
+		t.Errorf("Expected the default User-Agent, got %q", got)
+	}
+
+	// -H User-Agent wins over the rotation
+	opts := rawhttp.DefaultHTTPClientOptions()
+	opts.UserAgents = []string{"agent-one", "agent-two"}
+	opts.CustomHTTPHeaders = []string{"User-Agent: custom"}
+	client = rawhttp.NewHTTPClient(opts)
+	defer client.Close()
+	if got := buildUserAgent(t, client); got != "custom" {
+		t.Errorf("Expected the -H User-Agent, got %q", got)
+	}
+}
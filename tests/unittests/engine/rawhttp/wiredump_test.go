@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+)
+
+func TestFormatWireDump(t *testing.T) {
+	rawRequest := []byte("GET /admin\x00 HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	responseHead := []byte("HTTP/1.1 200 OK\r\nServer: nginx\r\n\r\n")
+
+	dump := rawhttp.FormatWireDump(rawRequest, responseHead, nil)
+	for _, want := range []string{
+		">>> Request (43 bytes)\nGET /admin\\x00 HTTP/1.1\r\nHost: example.com\r\n",
+		"<<< Response head\nHTTP/1.1 200 OK\r\nServer: nginx\r\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Expected wire dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+
+	dump = rawhttp.FormatWireDump(rawRequest, nil, errors.New("connection reset"))
+	if !strings.Contains(dump, "<<< Error\nconnection reset") || strings.Contains(dump, "Response head") {
+		t.Errorf("Expected the request error instead of a response head, got:\n%s", dump)
+	}
+}
+
+func TestRequestWorkerPoolDumpWireMatched(t *testing.T) {
+	body := strings.Repeat("A", 64*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "wire")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	bypassPayload := payload.BypassPayload{
+		Method:       "GET",
+		Scheme:       "http",
+		Host:         u.Host,
+		RawURI:       "/admin%00.json",
+		Headers:      []payload.Headers{{Header: "X-Original-URL", Value: "/admin"}},
+		BypassModule: "extension_confusion",
+	}
+
+	run := func(dumpWire string) *rawhttp.RawHTTPResponseDetails {
+		opts := rawhttp.DefaultHTTPClientOptions()
+		opts.DumpWire = dumpWire
+		pool := rawhttp.NewRequestWorkerPool(opts, 1)
+		defer pool.Close()
+
+		var result *rawhttp.RawHTTPResponseDetails
+		for response := range pool.ProcessRequests(context.Background(), []payload.BypassPayload{bypassPayload}) {
+			result = response
+		}
+		if result == nil {
+			t.Fatalf("Expected a response with -dump-wire %q", dumpWire)
+		}
+		return result
+	}
+
+	result := run(rawhttp.WireDumpMatched)
+	rawRequest := string(result.RawRequest)
+	if !strings.HasPrefix(rawRequest, "GET /admin%00.json HTTP/1.1\r\n") || !strings.Contains(rawRequest, "X-Original-URL: /admin\r\n") {
+		t.Errorf("Expected the raw request bytes kept for matched results, got %q", rawRequest)
+	}
+	if !strings.Contains(string(result.ResponseHeaders), "X-Test: wire") {
+		t.Errorf("Expected the response head, got %q", result.ResponseHeaders)
+	}
+	// The streamed body is still read for the preview
+	if result.StatusCode != http.StatusOK || len(result.ResponsePreview) == 0 || result.ResponsePreview[0] != 'A' {
+		t.Errorf("Expected status 200 with a body preview, got %d and %d preview bytes", result.StatusCode, len(result.ResponsePreview))
+	}
+	rawhttp.ReleaseResponseDetails(result)
+
+	if result := run(""); len(result.RawRequest) != 0 {
+		t.Errorf("Expected no raw request kept without -dump-wire, got %q", result.RawRequest)
+	}
+}
@@ -0,0 +1,65 @@
+package recon
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/recon"
+)
+
+func TestBareHost(t *testing.T) {
+	tests := map[string]string{
+		"example.com":      "example.com",
+		"127.0.0.1":        "127.0.0.1",
+		"[::1]":            "::1",
+		"[fe80::1%25eth0]": "fe80::1%eth0",
+		"[2001:db8::1]":    "2001:db8::1",
+	}
+	for host, want := range tests {
+		if got := recon.BareHost(host); got != want {
+			t.Errorf("BareHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestProcessHostIPv6Literal(t *testing.T) {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The probe must send a bracketed IPv6 Host
+		if r.Host != "[::1]" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	service := recon.NewReconService()
+	if err := service.Run([]string{"http://[::1]:" + port + "/admin"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if failed := service.GetFailedHosts(); len(failed) > 0 {
+		t.Fatalf("IPv6 literal failed recon: %v", failed)
+	}
+
+	// Cached under the URL host and under the bare address
+	for _, key := range []string{"[::1]:" + port, "::1"} {
+		result, err := service.GetReconCache().Get(key)
+		if err != nil || result == nil {
+			t.Fatalf("No cache result for %s: %v", key, err)
+		}
+		if ports := result.IPv6Services["http"]["::1"]; !slices.Contains(ports, port) {
+			t.Errorf("Expected http service on [::1]:%s for %s, got %v", port, key, result.IPv6Services)
+		}
+		if len(result.IPv4Services) != 0 || len(result.CNAMEs) != 0 {
+			t.Errorf("Unexpected IPv4 services or CNAMEs for an IPv6 literal: %+v", result)
+		}
+	}
+}
@@ -299,3 +299,31 @@ func TestReconCache(t *testing.T) {
 		}
 	}
 }
+
+func TestReconService_Run_ReportsFailedHosts(t *testing.T) {
+	service := recon.NewReconServiceWithOptions(&recon.ReconOptions{
+		Concurrency:        2,
+		Timeout:            30 * time.Second,
+		DisableProgressBar: true,
+	})
+
+	// Nothing listens on these, so recon must record why they were dropped
+	testURLs := []string{
+		"http://127.0.0.1:89/",
+		"http://127.0.0.1:98/",
+	}
+
+	if err := service.Run(testURLs); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	failed := service.GetFailedHosts()
+	for _, host := range []string{"127.0.0.1:89", "127.0.0.1:98"} {
+		reason, ok := failed[host]
+		if !ok {
+			t.Errorf("Expected %s to be reported as failed", host)
+			continue
+		}
+		t.Logf("%s failed recon: %s", host, reason)
+	}
+}
@@ -0,0 +1,24 @@
+package recon
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/recon"
+)
+
+func TestReconCachePurge(t *testing.T) {
+	cache := recon.NewReconCache()
+
+	if err := cache.Set("example.com", &recon.ReconResult{Hostname: "example.com"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if res, _ := cache.Get("example.com"); res == nil {
+		t.Fatalf("Expected cached result for example.com")
+	}
+
+	cache.Purge()
+
+	if res, _ := cache.Get("example.com"); res != nil {
+		t.Errorf("Expected empty cache after Purge, got %+v", res)
+	}
+}
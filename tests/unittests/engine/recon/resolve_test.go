@@ -0,0 +1,80 @@
+package recon
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/recon"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startFakeDNSServer answers A queries for name with ip, NXDOMAIN for anything else
+func startFakeDNSServer(t *testing.T, name string, ip [4]byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil || len(msg.Questions) == 0 {
+				continue
+			}
+			q := msg.Questions[0]
+
+			msg.Header.Response = true
+			msg.Header.Authoritative = true
+			msg.Answers = nil
+			switch {
+			case q.Name.String() != name:
+				msg.Header.RCode = dnsmessage.RCodeNameError
+			case q.Type == dnsmessage.TypeA:
+				msg.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: ip},
+				}}
+			}
+
+			out, err := msg.Pack()
+			if err != nil {
+				continue
+			}
+			conn.WriteTo(out, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestDNSResolverCustomServers(t *testing.T) {
+	server := startFakeDNSServer(t, "restricted.internal.", [4]byte{10, 1, 2, 3})
+
+	recon.SetDNSServers([]string{server})
+	resolver := recon.NewDNSResolver()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Only resolvable through the custom server (split-horizon DNS)
+	ips, err := resolver.LookupIPAddr(ctx, "restricted.internal")
+	if err != nil {
+		t.Fatalf("LookupIPAddr failed: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].IP.Equal(net.IPv4(10, 1, 2, 3)) {
+		t.Errorf("Expected 10.1.2.3, got %v", ips)
+	}
+
+	if _, err := resolver.LookupIPAddr(ctx, "unknown.internal"); err == nil {
+		t.Errorf("Expected unknown.internal not to resolve")
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/report"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+	GB403ErrorHandler "github.com/slicingmelon/gobypass403/core/utils/error"
+)
+
+func TestWriteHTMLReportEscapesResponseContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	scans := []report.ScanResult{{
+		TargetURL: "https://example.com/admin",
+		Findings: []*scanner.Result{{
+			TargetURL:           "https://example.com/admin",
+			BypassModule:        "char_encode",
+			StatusCode:          200,
+			ContentLength:       1234,
+			Title:               "<script>alert(1)</script>",
+			ResponseHeaders:     "HTTP/1.1 200 OK\r\nServer: nginx\r\n",
+			ResponseBodyPreview: "</pre></td></tr><img src=x onerror=alert(2)>",
+			CurlCMD:             "curl -skgi --path-as-is 'https://example.com/%61dmin'",
+			DebugToken:          "not-a-valid-token",
+		}},
+	}}
+
+	if err := report.WriteHTMLReport(path, scans, "1.2.3"); err != nil {
+		t.Fatalf("Failed to write HTML report: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read HTML report: %v", err)
+	}
+	html := string(data)
+
+	for _, raw := range []string{"<script>alert(1)</script>", "<img src=x onerror=alert(2)>"} {
+		if strings.Contains(html, raw) {
+			t.Errorf("Response content was not escaped: %s", raw)
+		}
+	}
+	for _, want := range []string{"&lt;script&gt;alert(1)&lt;/script&gt;", "char_encode", "failed to decode debug token", "v1.2.3"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+}
+
+func TestWriteJSONReportIncludesErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	scans := []report.ScanResult{
+		{
+			TargetURL: "https://example.com/admin",
+			Findings:  []*scanner.Result{{TargetURL: "https://example.com/admin", BypassModule: "mid_paths", StatusCode: 200, ContentLength: 42}},
+			Errors: &GB403ErrorHandler.ErrorSummary{
+				Total:   7,
+				ByClass: map[string]int64{"timeout": 7},
+				ByHost:  map[string]int64{"https://example.com": 7},
+			},
+		},
+		{TargetURL: "https://example.org/admin"},
+	}
+
+	if err := report.WriteJSONReport(path, scans, "1.2.3", 0); err != nil {
+		t.Fatalf("Failed to write JSON report: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read JSON report: %v", err)
+	}
+
+	var doc struct {
+		Total int `json:"total"`
+		Scans []struct {
+			TargetURL string                          `json:"target_url"`
+			Errors    *GB403ErrorHandler.ErrorSummary `json:"errors"`
+			Results   []scanner.WebhookFinding        `json:"results"`
+		} `json:"scans"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON report: %v\n%s", err, data)
+	}
+
+	if doc.Total != 1 || len(doc.Scans) != 2 {
+		t.Fatalf("Expected 2 scans and 1 finding, got %d scans and %d findings", len(doc.Scans), doc.Total)
+	}
+	if r := doc.Scans[0].Results; len(r) != 1 || r[0].BypassModule != "mid_paths" || r[0].StatusCode != 200 {
+		t.Errorf("Unexpected results: %+v", r)
+	}
+	if e := doc.Scans[0].Errors; e == nil || e.Total != 7 || e.ByClass["timeout"] != 7 {
+		t.Errorf("Unexpected errors: %+v", e)
+	}
+	if doc.Scans[1].Errors != nil || doc.Scans[1].Results == nil {
+		t.Errorf("Expected no errors and an empty results array for %s", doc.Scans[1].TargetURL)
+	}
+}
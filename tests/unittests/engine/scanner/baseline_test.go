@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestBaselineMatcherIgnoresDynamicContent(t *testing.T) {
+	ignore, err := scanner.CompileIgnorePatterns([]string{`csrf=[a-f0-9]+`})
+	if err != nil {
+		t.Fatalf("Failed to compile ignore patterns: %v", err)
+	}
+	bm := scanner.NewBaselineMatcher(ignore)
+
+	targetURL := "https://example.com/admin"
+	baselineBody := []byte("<html>Forbidden - 2024-05-01T10:20:30Z csrf=deadbeef</html>")
+	bm.SetBaseline(targetURL, 403, baselineBody)
+
+	sameBody := []byte("<html>Forbidden - 2025-01-09T23:59:01Z csrf=0123abcd</html>")
+	if !bm.MatchesBaseline(targetURL, 403, sameBody) {
+		t.Errorf("Expected body with different date/csrf token to match the baseline")
+	}
+
+	if bm.MatchesBaseline(targetURL, 200, sameBody) {
+		t.Errorf("Expected different status code not to match the baseline")
+	}
+
+	if bm.MatchesBaseline(targetURL, 403, []byte("<html>Welcome admin</html>")) {
+		t.Errorf("Expected different body not to match the baseline")
+	}
+
+	if bm.MatchesBaseline("https://example.com/other", 403, sameBody) {
+		t.Errorf("Expected URL without a baseline not to match")
+	}
+}
+
+func TestBaselineMatcherInvalidPattern(t *testing.T) {
+	if _, err := scanner.CompileIgnorePatterns([]string{`(unclosed`}); err == nil {
+		t.Errorf("Expected an error for an invalid ignore pattern")
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerFlagsBlockPages(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cloudflare":
+			w.Header().Set("Server", "cloudflare")
+			w.Write([]byte("<html><head><title>Attention Required! | Cloudflare</title></head></html>"))
+		case "/cloudflare-page":
+			// Ordinary page with the script Cloudflare injects for its JS detections
+			w.Header().Set("Server", "cloudflare")
+			w.Write([]byte(`<html><title>Admin</title><script src="/cdn-cgi/challenge-platform/scripts/jsd/main.js"></script></html>`))
+		case "/aws":
+			w.Header().Set("X-Amzn-Waf-Action", "captcha")
+			w.Write([]byte("<html>captcha</html>"))
+		case "/akamai":
+			// Akamai body marker without the Akamai headers
+			w.Write([]byte("<html><title>Access Denied</title></html>"))
+		default:
+			w.Write([]byte("<html><title>Admin</title></html>"))
+		}
+	}))
+	defer server.Close()
+
+	expected := map[string]string{
+		server.URL + "/cloudflare":      "cloudflare",
+		server.URL + "/cloudflare-page": "",
+		server.URL + "/aws":             "aws-waf",
+		server.URL + "/akamai":          "",
+		server.URL + "/admin":           "",
+	}
+	var targets []string
+	for targetURL := range expected {
+		targets = append(targets, targetURL)
+	}
+
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      2,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		JSONLFile:               jsonlFile,
+	}, targets)
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d", len(expected), len(findings))
+	}
+	for _, f := range findings {
+		if want := expected[f.TargetURL]; f.BlockedBy != want {
+			t.Errorf("%s: expected BlockedBy %q, got %q", f.TargetURL, want, f.BlockedBy)
+		}
+	}
+
+	results, err := scanner.GetResultsFromDB(server.URL + "/cloudflare")
+	if err != nil || len(results) != 1 || results[0].BlockedBy != "cloudflare" {
+		t.Errorf("Expected the cloudflare block page in the results DB, got %v (err: %v)", results, err)
+	}
+}
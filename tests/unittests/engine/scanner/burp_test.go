@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/rawhttp"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestBurpWriter(t *testing.T) {
+	job := payload.BypassPayload{
+		Method:       "GET",
+		Scheme:       "https",
+		Host:         "example.com",
+		RawURI:       "/admin;/",
+		Headers:      []payload.Headers{{Header: "X-Forwarded-For", Value: "127.0.0.1"}},
+		BypassModule: "mid_paths",
+	}
+	token := payload.GeneratePayloadToken(job)
+
+	clientOpts := &rawhttp.HTTPClientOptions{CustomHTTPHeaders: []string{"Cookie: session=abc"}}
+	clientOpts.PreprocessCustomHeaders()
+
+	path := filepath.Join(t.TempDir(), "out", "findings.xml")
+	w := scanner.NewBurpWriter(path, "1.2.3", clientOpts)
+	w.WriteResult("https://example.com/admin", &scanner.Result{
+		TargetURL:           "https://example.com/admin;/",
+		BypassModule:        "mid_paths",
+		StatusCode:          200,
+		ContentType:         "text/html; charset=utf-8",
+		ResponseHeaders:     "HTTP/1.1 200 OK\r\nContent-Type: text/html; charset=utf-8\r\n\r\n",
+		ResponseBodyPreview: "<html>admin</html>",
+		DebugToken:          token,
+	})
+	w.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read Burp export: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("Missing XML declaration: %.60s", data)
+	}
+
+	var items scanner.BurpItems
+	if err := xml.Unmarshal(data, &items); err != nil {
+		t.Fatalf("Invalid Burp XML: %v", err)
+	}
+	if len(items.Items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items.Items))
+	}
+
+	item := items.Items[0]
+	if item.URL.Value != "https://example.com/admin;/" || item.Host.Name != "example.com" || item.Port != "443" ||
+		item.Protocol != "https" || item.Method.Value != "GET" || item.Path.Value != "/admin;/" ||
+		item.Status != 200 || item.MimeType != "HTML" {
+		t.Errorf("Unexpected item: %+v", item)
+	}
+	if !strings.Contains(item.Comment, "mid_paths") || !strings.Contains(item.Comment, token) {
+		t.Errorf("Comment should carry the module and debug token, got %q", item.Comment)
+	}
+
+	request, err := base64.StdEncoding.DecodeString(item.Request.Data)
+	if err != nil || !item.Request.Base64 {
+		t.Fatalf("Request is not base64: %v", err)
+	}
+	wantRequest := "GET /admin;/ HTTP/1.1\r\nCookie: session=abc\r\nX-Forwarded-For: 127.0.0.1\r\nHost: example.com\r\n\r\n"
+	if string(request) != wantRequest {
+		t.Errorf("Unexpected request:\n%q\nwant:\n%q", request, wantRequest)
+	}
+
+	response, err := base64.StdEncoding.DecodeString(item.Response.Data)
+	if err != nil {
+		t.Fatalf("Response is not base64: %v", err)
+	}
+	if string(response) != "HTTP/1.1 200 OK\r\nContent-Type: text/html; charset=utf-8\r\n\r\n<html>admin</html>" {
+		t.Errorf("Unexpected response: %q", response)
+	}
+	if item.ResponseLength != len(response) {
+		t.Errorf("responselength %d, want %d", item.ResponseLength, len(response))
+	}
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestCalibrationBaselineMatches(t *testing.T) {
+	cb := &scanner.CalibrationBaseline{
+		Samples: []scanner.CalibrationSample{
+			{RawURI: "/admin", StatusCode: 403, ContentLength: 4000, BodyBytes: 1024, BodyHash: "aaaa"},
+			{RawURI: "/gb403-1234", StatusCode: 404, ContentLength: -1, BodyBytes: 150, BodyHash: "bbbb"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		sample scanner.CalibrationSample
+		want   bool
+	}{
+		{"same hash", scanner.CalibrationSample{StatusCode: 403, ContentLength: 9999, BodyHash: "aaaa"}, true},
+		{"length within percent tolerance", scanner.CalibrationSample{StatusCode: 403, ContentLength: 4070, BodyHash: "cccc"}, true},
+		{"length outside tolerance", scanner.CalibrationSample{StatusCode: 403, ContentLength: 4200, BodyHash: "cccc"}, false},
+		{"different status", scanner.CalibrationSample{StatusCode: 200, ContentLength: 4000, BodyHash: "aaaa"}, false},
+		{"unknown length uses body bytes", scanner.CalibrationSample{StatusCode: 404, ContentLength: -1, BodyBytes: 170, BodyHash: "dddd"}, true},
+		{"unknown length outside tolerance", scanner.CalibrationSample{StatusCode: 404, ContentLength: -1, BodyBytes: 900, BodyHash: "dddd"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := cb.Matches(tt.sample); got != tt.want {
+			t.Errorf("%s: Matches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var nilBaseline *scanner.CalibrationBaseline
+	if nilBaseline.Matches(scanner.CalibrationSample{StatusCode: 403}) {
+		t.Errorf("Expected a nil baseline not to match")
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerCaptureKeepsStatusCode(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	// Same body everywhere, only the status code tells the responses apart
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if strings.Contains(r.Header.Get("Accept"), "json") {
+			w.WriteHeader(http.StatusAccepted)
+		}
+		w.Write([]byte(`<html><title>Admin</title><body>panel</body></html>`))
+	}))
+	defer server.Close()
+
+	csvFile := filepath.Join(dir, "findings.csv")
+	captureFields, err := scanner.ParseCaptureFields("title")
+	if err != nil {
+		t.Fatalf("ParseCaptureFields failed: %v", err)
+	}
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check,http_headers_accept",
+		MatchStatusCodes:        []int{200, 202},
+		ConcurrentRequests:      2,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		DedupeResponses:         true,
+		CaptureFields:           captureFields,
+		CSVFile:                 csvFile,
+	}, []string{server.URL + "/capture"})
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	f, err := os.Open(csvFile)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+
+	var statuses []string
+	for _, row := range rows[1:] {
+		if row[1] == "http_headers_accept" {
+			statuses = append(statuses, row[2])
+		}
+		if row[4] != "text/html" {
+			t.Errorf("Expected the content type to be kept with -capture title, got %q", row[4])
+		}
+	}
+	slices.Sort(statuses)
+
+	// One cluster per status code, not a single cluster of status 0 findings
+	if want := []string{"200", "202"}; !slices.Equal(statuses, want) {
+		t.Errorf("Expected one deduped http_headers_accept finding per status code %v, got %v", want, statuses)
+	}
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestCheckpointSaveLoadAndValidate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "checkpoint.json")
+	urls := []string{"https://example.com/admin", "https://example.com/secret"}
+	modules := "dumb_check,mid_paths"
+
+	cp := scanner.NewCheckpoint(path, urls, modules)
+	if err := cp.MarkCompleted(urls[0], "dumb_check"); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	// Only the checkpoint itself must be left behind, no temp files
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only checkpoint.json in the output dir, got %d entries", len(entries))
+	}
+
+	loaded, err := scanner.LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Failed to load checkpoint: %v", err)
+	}
+
+	if !loaded.IsCompleted(urls[0], "dumb_check") {
+		t.Errorf("Expected dumb_check to be completed for %s", urls[0])
+	}
+	if loaded.IsCompleted(urls[0], "mid_paths") || loaded.IsCompleted(urls[1], "dumb_check") {
+		t.Errorf("Unexpected completed pair")
+	}
+
+	// URL order doesn't matter
+	if err := loaded.Validate([]string{urls[1], urls[0]}, modules); err != nil {
+		t.Errorf("Expected checkpoint to match the same URL set: %v", err)
+	}
+	if err := loaded.Validate(urls[:1], modules); err == nil {
+		t.Errorf("Expected a different URL set to be rejected")
+	}
+	if err := loaded.Validate(urls, "dumb_check"); err == nil {
+		t.Errorf("Expected different modules to be rejected")
+	}
+}
+
+func TestLoadCheckpointMissingFile(t *testing.T) {
+	if _, err := scanner.LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("Expected an error for a missing checkpoint")
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScoreConfidence(t *testing.T) {
+	baseline := &scanner.BaselineResponse{StatusCode: 403, Length: 1000, ContentType: "text/html"}
+
+	tests := []struct {
+		name string
+		sig  scanner.ConfidenceSignals
+		want float64
+	}{
+		{
+			name: "all signals",
+			sig: scanner.ConfidenceSignals{StatusCode: 200, Length: 2500, ContentType: "application/json",
+				Baseline: baseline, Calibrated: true, IsLikelyBypass: true},
+			want: 1,
+		},
+		{
+			name: "same as the baseline",
+			sig: scanner.ConfidenceSignals{StatusCode: 403, Length: 1000, ContentType: "text/html; charset=utf-8",
+				Baseline: baseline, Calibrated: true},
+			want: 0.15,
+		},
+		{
+			name: "block page with a success status",
+			sig: scanner.ConfidenceSignals{StatusCode: 200, Length: 1000, ContentType: "text/html",
+				Baseline: baseline, BlockedBy: "cloudflare", Calibrated: true},
+			want: 0.35,
+		},
+		{
+			name: "another error, small length delta",
+			sig: scanner.ConfidenceSignals{StatusCode: 404, Length: 1250, ContentType: "text/html",
+				Baseline: baseline, Calibrated: true, IsLikelyBypass: true},
+			want: 0.63,
+		},
+		{
+			name: "no baseline and no calibration",
+			sig:  scanner.ConfidenceSignals{StatusCode: 200, Length: 10},
+			want: 0.58,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanner.ScoreConfidence(tt.sig); got != tt.want {
+				t.Errorf("ScoreConfidence() = %v, want %v", got, tt.want)
+			}
+			// Deterministic, the same signals always score the same
+			if got := scanner.ScoreConfidence(tt.sig); got != tt.want {
+				t.Errorf("ScoreConfidence() changed on the second call: %v", got)
+			}
+		})
+	}
+}
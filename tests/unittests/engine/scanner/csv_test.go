@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestCSVWriterQuotesFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.csv")
+	w, err := scanner.NewCSVWriter(path)
+	if err != nil {
+		t.Fatalf("Failed to create CSV writer: %v", err)
+	}
+
+	res := &scanner.Result{
+		TargetURL:     "https://example.com/%61dmin",
+		BypassModule:  "char_encode",
+		StatusCode:    200,
+		ContentLength: 1234,
+		ContentType:   "text/html; charset=utf-8",
+		Title:         "Admin, \"Dashboard\"\nHome",
+		ServerInfo:    "nginx",
+		DebugToken:    "token123",
+		CurlCMD:       "curl -skgi --path-as-is 'https://example.com/%61dmin'",
+	}
+	w.WriteResult("https://example.com/admin", res)
+
+	// Rows are flushed as they are written, before Close
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open CSV file: %v", err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	w.Close()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected header + 1 row, got %d rows", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0], scanner.CSVHeader) {
+		t.Errorf("Unexpected header: %v", rows[0])
+	}
+
+	want := []string{res.TargetURL, "char_encode", "200", "1234", res.ContentType, res.Title, "nginx", "", "token123", res.CurlCMD}
+	if !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("Unexpected row:\n got %q\nwant %q", rows[1], want)
+	}
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestDedupeResultsClustersNearIdenticalResponses(t *testing.T) {
+	page := func(path string) string {
+		return "<html><head><title>Admin</title></head><body>Welcome to the admin dashboard. " +
+			"Manage users, settings, billing and audit logs from here. Requested " + path + "</body></html>"
+	}
+
+	results := []*scanner.Result{
+		{BypassModule: "char_encode", StatusCode: 200, Title: "Admin", ContentLength: 1200, ResponseBodyPreview: page("/%61dmin")},
+		{BypassModule: "char_encode", StatusCode: 200, Title: "Admin", ContentLength: 1204, ResponseBodyPreview: page("/a%64min")},
+		{BypassModule: "char_encode", StatusCode: 200, Title: "Admin", ContentLength: 1200, ResponseBodyPreview: page("/ad%6din")},
+		{BypassModule: "char_encode", StatusCode: 403, Title: "Admin", ContentLength: 1200, ResponseBodyPreview: page("/admi%6e")},
+		{BypassModule: "char_encode", StatusCode: 200, Title: "Login", ContentLength: 1200, ResponseBodyPreview: page("/%61dmin")},
+		{BypassModule: "char_encode", StatusCode: 200, Title: "Admin", ContentLength: 5000, ResponseBodyPreview: page("/%61dmin")},
+		{BypassModule: "char_encode", StatusCode: 200, Title: "Admin", ContentLength: 1200, ResponseBodyPreview: strings.Repeat("totally different content ", 10)},
+	}
+
+	unique := scanner.DedupeResults(results)
+	if len(unique) != 5 {
+		t.Fatalf("Expected 5 clusters, got %d", len(unique))
+	}
+
+	if unique[0] != results[0] {
+		t.Errorf("Expected the first finding to be the cluster representative")
+	}
+	if unique[0].DuplicateCount != 2 {
+		t.Errorf("Expected 2 duplicates merged into the first cluster, got %d", unique[0].DuplicateCount)
+	}
+	for _, res := range unique[1:] {
+		if res.DuplicateCount != 0 {
+			t.Errorf("Expected no duplicates for %d/%s/%d, got %d", res.StatusCode, res.Title, res.ContentLength, res.DuplicateCount)
+		}
+	}
+}
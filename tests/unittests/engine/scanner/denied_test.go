@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerHidesOriginalDeniedStatus(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.Header.Get("Accept"), "json"):
+			w.Write([]byte(`{"admin":true}`))
+		case r.Header.Get("X-Requested-With") != "":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	for _, showDenied := range []bool{false, true} {
+		jsonlFile := filepath.Join(dir, "findings.jsonl")
+		if showDenied {
+			jsonlFile = filepath.Join(dir, "findings-denied.jsonl")
+		}
+
+		s := scanner.NewScanner(&scanner.ScannerOpts{
+			BypassModule:            "dumb_check,http_headers_accept",
+			ConcurrentRequests:      2,
+			Timeout:                 5000,
+			ResponseBodyPreviewSize: 1024,
+			DisableProgressBar:      true,
+			ShowDenied:              showDenied,
+			JSONLFile:               jsonlFile,
+		}, []string{server.URL + "/admin"})
+		if err := s.Run(context.Background()); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+
+		findings, err := scanner.ReadJSONLFindings(jsonlFile)
+		if err != nil {
+			t.Fatalf("ReadJSONLFindings failed: %v", err)
+		}
+
+		statuses := make(map[int]int)
+		for _, f := range findings {
+			statuses[f.StatusCode]++
+		}
+		if statuses[http.StatusOK] == 0 || statuses[http.StatusUnauthorized] == 0 {
+			t.Errorf("showDenied=%v: expected the 200 and 401 findings, got %v", showDenied, statuses)
+		}
+		if denied := statuses[http.StatusForbidden] > 0; denied != showDenied {
+			t.Errorf("showDenied=%v: unexpected 403 findings, got %v", showDenied, statuses)
+		}
+	}
+}
+
+func TestScannerAuthHeadersAndBaseline(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	var authed, unauthed atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer user-token":
+			unauthed.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.Contains(r.Header.Get("Accept"), "json"):
+			authed.Add(1)
+			w.Write([]byte(`{"admin":true}`))
+		default:
+			authed.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "http_headers_accept",
+		ConcurrentRequests:      2,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		AuthHeaders:             []string{"Authorization: Bearer user-token"},
+		JSONLFile:               jsonlFile,
+	}, []string{server.URL + "/admin"})
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Only the baseline request goes without the credentials
+	if got := unauthed.Load(); got != 1 {
+		t.Errorf("Expected 1 request without credentials, got %d", got)
+	}
+	if authed.Load() < 2 {
+		t.Errorf("Expected the payloads sent with the credentials, got %d requests", authed.Load())
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+	if len(findings) == 0 {
+		t.Fatalf("Expected findings")
+	}
+	for _, f := range findings {
+		// The authenticated 403 of the original request is the denied status
+		if f.StatusCode != http.StatusOK {
+			t.Errorf("Expected only 200 findings, got %d", f.StatusCode)
+		}
+		if !strings.Contains(f.CurlCMD, "-H 'Authorization: Bearer user-token'") {
+			t.Errorf("Expected the credentials in the curl PoC, got %s", f.CurlCMD)
+		}
+	}
+}
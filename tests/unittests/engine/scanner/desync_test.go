@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerRecordsTimedOutSmugglingProbes(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	// A back-end that only understands the xchunked obfuscation: those probes wait for the rest
+	// of the body, every other probe is denied
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				var head strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					head.WriteString(line)
+					if line == "\r\n" {
+						break
+					}
+				}
+				switch {
+				case strings.HasPrefix(head.String(), "GET "):
+					conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok"))
+				case strings.Contains(head.String(), "xchunked"):
+					// Hold the connection until the client gives up
+					io.Copy(io.Discard, reader)
+				default:
+					conn.Write([]byte("HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+				}
+			}(conn)
+		}
+	}()
+
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check,request_smuggling_probe",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      4,
+		Timeout:                 1000,
+		MaxRetries:              2,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		JSONLFile:               jsonlFile,
+	}, []string{"http://" + ln.Addr().String() + "/admin"})
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+
+	desyncs := make(map[string]bool)
+	for _, f := range findings {
+		if f.BypassModule != "request_smuggling_probe" {
+			continue
+		}
+		if f.StatusCode != 0 || f.Desync == "" {
+			t.Errorf("Expected a timed out probe tagged with its variant, got status %d desync %q", f.StatusCode, f.Desync)
+		}
+		desyncs[f.Desync] = true
+	}
+	if len(desyncs) != 2 || !desyncs[`CL.TE "Transfer-Encoding: xchunked"`] || !desyncs[`TE.CL "Transfer-Encoding: xchunked"`] {
+		t.Errorf("Expected the CL.TE and TE.CL xchunked probes as findings, got %v", desyncs)
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+// diffResult builds a finding with a freshly generated (random nonce) debug token
+func diffResult(module, rawURI string, status int, length int64) *scanner.Result {
+	p := payload.BypassPayload{
+		OriginalURL:  "https://example.com/admin",
+		Method:       "GET",
+		Scheme:       "https",
+		Host:         "example.com",
+		RawURI:       rawURI,
+		BypassModule: module,
+	}
+	return &scanner.Result{
+		TargetURL:     "https://example.com/admin",
+		BypassModule:  module,
+		StatusCode:    status,
+		ContentLength: length,
+		DebugToken:    payload.GeneratePayloadToken(p),
+	}
+}
+
+func TestDiffFindings(t *testing.T) {
+	oldResults := []*scanner.Result{
+		diffResult("end_paths", "/admin/", 200, 100),
+		diffResult("end_paths", "/admin;", 200, 100),
+		diffResult("mid_paths", "/./admin", 200, 100),
+	}
+	newResults := []*scanner.Result{
+		diffResult("end_paths", "/admin/", 200, 100),  // unchanged, new token nonce
+		diffResult("end_paths", "/admin;", 200, 250),  // changed length
+		diffResult("end_paths", "/admin..;", 200, 80), // added
+		diffResult("mid_paths", "/./admin", 403, 100), // status change: removed + added
+	}
+
+	diff := scanner.DiffFindings(oldResults, newResults)
+
+	if len(diff.Removed) != 1 || diff.Removed[0].StatusCode != 200 || diff.Removed[0].BypassModule != "mid_paths" {
+		t.Errorf("Expected the 200 mid_paths finding to be removed, got %d removed", len(diff.Removed))
+	}
+	if len(diff.Added) != 2 {
+		t.Fatalf("Expected 2 added findings, got %d", len(diff.Added))
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].New.ContentLength != 250 {
+		t.Fatalf("Expected 1 changed finding, got %d", len(diff.Changed))
+	}
+	if got := diff.Changed[0].Changes; len(got) != 1 || got[0] != "length: 100 -> 250" {
+		t.Errorf("Unexpected changes: %v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := diff.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var doc map[string][]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid diff JSON: %v", err)
+	}
+	if len(doc["added"]) != 2 || len(doc["removed"]) != 1 || len(doc["changed"]) != 1 {
+		t.Errorf("Unexpected diff JSON: %s", buf.String())
+	}
+}
+
+func TestLoadFindingsJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.jsonl")
+	w, err := scanner.NewJSONLWriter(path, "test", 0)
+	if err != nil {
+		t.Fatalf("NewJSONLWriter failed: %v", err)
+	}
+	res := diffResult("end_paths", "/admin/", 200, 100)
+	res.Title = "Admin"
+	w.WriteResult("https://example.com/admin", res)
+	w.Close()
+
+	results, err := scanner.LoadFindings(path)
+	if err != nil {
+		t.Fatalf("LoadFindings failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 finding, got %d", len(results))
+	}
+	got := results[0]
+	if got.TargetURL != res.TargetURL || got.BypassModule != res.BypassModule || got.StatusCode != 200 ||
+		got.Title != "Admin" || got.DebugToken != res.DebugToken {
+		t.Errorf("Finding not read back correctly: %+v", got)
+	}
+
+	if _, err := scanner.LoadFindings(filepath.Join(t.TempDir(), "missing.db")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error for a missing results DB, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestDryRunText(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:       "dumb_check,case_substitution",
+		DisableProgressBar: true,
+	}, []string{"http://127.0.0.1:1/admin"})
+
+	var buf bytes.Buffer
+	if err := s.DryRun(&buf, false); err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# [dumb_check] http://127.0.0.1:1 token=",
+		"GET /admin HTTP/1.1\nHost: 127.0.0.1:1\n",
+		"# [case_substitution] http://127.0.0.1:1 token=",
+		"GET /Admin HTTP/1.1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected dry-run output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestDryRunJSON(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:       "headers_scheme",
+		DisableProgressBar: true,
+	}, []string{"http://127.0.0.1:1/admin"})
+
+	var buf bytes.Buffer
+	if err := s.DryRun(&buf, true); err != nil {
+		t.Fatalf("DryRun failed: %v", err)
+	}
+
+	lines := 0
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		lines++
+		var p struct {
+			BypassModule string `json:"bypass_module"`
+			RawURI       string `json:"raw_uri"`
+			Headers      []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"headers"`
+			DebugToken string `json:"debug_token"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &p); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", sc.Text(), err)
+		}
+		if p.BypassModule != "headers_scheme" || p.RawURI == "" || len(p.Headers) == 0 {
+			t.Errorf("Unexpected payload: %+v", p)
+		}
+
+		// The debug token must round-trip to the same payload
+		decoded, err := payload.DecodePayloadToken(p.DebugToken)
+		if err != nil {
+			t.Fatalf("Failed to decode debug token: %v", err)
+		}
+		if decoded.RawURI != p.RawURI {
+			t.Errorf("Token RawURI %q does not match %q", decoded.RawURI, p.RawURI)
+		}
+	}
+	if lines == 0 {
+		t.Errorf("Expected headers_scheme payloads, got none")
+	}
+}
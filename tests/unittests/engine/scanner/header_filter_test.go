@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestHeaderMatcher(t *testing.T) {
+	headers := []byte("HTTP/1.1 200 OK\r\nServer: nginx/1.25.3\r\nX-Accel-Redirect: /internal/admin\r\nContent-Type: text/html\r\n\r\n")
+
+	cases := []struct {
+		header string
+		want   bool
+	}{
+		{"Server: nginx", true},
+		{"server: NGINX", true},
+		{"Server: apache", false},
+		{"X-Accel-Redirect", true},
+		{"x-accel-redirect:", true},
+		{"X-Accel", false},
+		{"Content-Type: text/html", true},
+		{"HTTP/1.1 200 OK", false}, // the status line is not a header
+	}
+	for _, c := range cases {
+		m, err := scanner.ParseHeaderMatcher(c.header)
+		if err != nil {
+			if c.want {
+				t.Errorf("ParseHeaderMatcher(%q) failed: %v", c.header, err)
+			}
+			continue
+		}
+		if got := m.Matches(headers); got != c.want {
+			t.Errorf("%q: Matches() = %v, want %v", c.header, got, c.want)
+		}
+	}
+
+	for _, invalid := range []string{"", ": nginx", "X Header: value"} {
+		if _, err := scanner.ParseHeaderMatcher(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestJSONLWriterAppendsOneLinePerFinding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.jsonl")
+
+	// Two writers on the same file, the second one must append
+	for i, module := range []string{"char_encode", "mid_paths"} {
+		w, err := scanner.NewJSONLWriter(path, "1.2.3", 0)
+		if err != nil {
+			t.Fatalf("Failed to create JSONL writer: %v", err)
+		}
+		w.WriteResult("https://example.com/admin", &scanner.Result{
+			TargetURL:    "https://example.com/admin",
+			BypassModule: module,
+			StatusCode:   200 + i,
+			Title:        "multi\nline",
+		})
+		w.Close()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open JSONL file: %v", err)
+	}
+	defer f.Close()
+
+	var findings []scanner.WebhookFinding
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var finding scanner.WebhookFinding
+		if err := json.Unmarshal(sc.Bytes(), &finding); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", sc.Text(), err)
+		}
+		findings = append(findings, finding)
+	}
+
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(findings))
+	}
+	if findings[0].BypassModule != "char_encode" || findings[1].BypassModule != "mid_paths" || findings[1].StatusCode != 201 {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+	if findings[0].Target != "https://example.com/admin" || findings[0].Version != "1.2.3" {
+		t.Errorf("Unexpected target/version: %s/%s", findings[0].Target, findings[0].Version)
+	}
+}
+
+func TestJSONLWriterOutputSchemaVersions(t *testing.T) {
+	dir := t.TempDir()
+	res := &scanner.Result{
+		TargetURL:      "https://example.com/admin",
+		BypassModule:   "headers_ip",
+		StatusCode:     200,
+		IsLikelyBypass: true,
+	}
+
+	for _, schemaVersion := range []int{0, 1, 2} {
+		path := filepath.Join(dir, "findings.jsonl")
+		os.Remove(path)
+
+		w, err := scanner.NewJSONLWriter(path, "1.2.3", schemaVersion)
+		if err != nil {
+			t.Fatalf("Failed to create JSONL writer: %v", err)
+		}
+		w.WriteResult("https://example.com/admin", res)
+		w.Close()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read JSONL file: %v", err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", data, err)
+		}
+
+		want := schemaVersion
+		if want == 0 {
+			want = scanner.OutputSchemaVersion
+		}
+		if doc["schema_version"] != float64(want) {
+			t.Errorf("Expected schema_version %d, got %v", want, doc["schema_version"])
+		}
+		// is_likely_bypass came after the original layout
+		if _, ok := doc["is_likely_bypass"]; ok != (want > 1) {
+			t.Errorf("Schema %d: unexpected is_likely_bypass presence in %s", want, data)
+		}
+		if _, ok := doc["confidence"]; ok != (want > 2) {
+			t.Errorf("Schema %d: unexpected confidence presence in %s", want, data)
+		}
+		if doc["bypass_module"] != "headers_ip" || doc["status_code"] != float64(200) {
+			t.Errorf("Schema %d: unexpected finding %s", want, data)
+		}
+	}
+
+	if err := scanner.ValidateOutputSchemaVersion(scanner.OutputSchemaVersion + 1); err == nil {
+		t.Error("Expected an error for an unknown schema version")
+	}
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerReportsLengthDeltaAgainstBaseline(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	denied := "<html><title>Forbidden</title></html>"
+	allowed := `{"users":["admin","root"],"debug":true}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Write([]byte(allowed))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(denied))
+	}))
+	defer server.Close()
+
+	targetURL := server.URL + "/admin"
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check,http_headers_accept",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      2,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		JSONLFile:               jsonlFile,
+	}, []string{targetURL})
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+	if len(findings) == 0 {
+		t.Fatal("Expected findings")
+	}
+
+	wantDelta := int64(len(allowed) - len(denied))
+	for _, f := range findings {
+		if f.Baseline == nil {
+			t.Errorf("%s finding has no baseline", f.BypassModule)
+			continue
+		}
+		if f.Baseline.StatusCode != http.StatusForbidden || f.Baseline.Length != int64(len(denied)) {
+			t.Errorf("Expected baseline 403/%d, got %d/%d", len(denied), f.Baseline.StatusCode, f.Baseline.Length)
+		}
+		if f.LengthDelta != wantDelta {
+			t.Errorf("Expected length delta %d, got %d", wantDelta, f.LengthDelta)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerMaxDuration(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:       "mid_paths,end_paths",
+		MatchStatusCodes:   []int{200},
+		ConcurrentRequests: 2,
+		Timeout:            5000,
+		MaxDuration:        500 * time.Millisecond,
+		DisableProgressBar: true,
+	}, []string{server.URL + "/admin", server.URL + "/private"})
+
+	start := time.Now()
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Queued jobs are dropped, not drained: only the requests in flight at the deadline complete
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Scan did not stop at the deadline, took %s", elapsed)
+	}
+	if n := requests.Load(); n > 30 {
+		t.Errorf("Expected the remaining payloads to be skipped, %d requests were sent", n)
+	}
+}
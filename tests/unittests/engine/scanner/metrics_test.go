@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+// getLiveStats queries the /stats endpoint of the metrics handler
+func getLiveStats(t *testing.T, handler http.Handler) scanner.LiveStats {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/stats", nil))
+
+	var stats scanner.LiveStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Errorf("Invalid /stats JSON %q: %v", rec.Body.String(), err)
+	}
+	return stats
+}
+
+func TestScannerMetricsHandler(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+
+	var handler http.Handler
+	var once sync.Once
+	var during scanner.LiveStats
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Snapshot taken while the end_paths module is running
+		if r.URL.Path != "/admin" {
+			once.Do(func() { during = getLiveStats(t, handler) })
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	targetURL := server.URL + "/admin"
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:       "end_paths",
+		MatchStatusCodes:   []int{200},
+		ConcurrentRequests: 2,
+		Timeout:            5000,
+		DisableProgressBar: true,
+	}, []string{targetURL})
+	handler = s.MetricsHandler()
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if during.BypassModule != "end_paths" || during.TargetURL != targetURL || during.ModulePayloads == 0 {
+		t.Errorf("Expected the running module in the live stats, got %+v", during)
+	}
+	if during.ActiveWorkers < 1 || during.URLsTotal != 1 || during.Uptime == "" {
+		t.Errorf("Unexpected live stats: %+v", during)
+	}
+
+	after := getLiveStats(t, handler)
+	if after.BypassModule != "" || after.URLsScanned != 1 {
+		t.Errorf("Expected no running module once the scan is done, got %+v", after)
+	}
+	var sent uint64
+	for _, st := range s.ModuleStats() {
+		sent += st.Requests
+	}
+	if after.RequestsSent != sent || sent == 0 {
+		t.Errorf("Expected %d requests sent, got %d", sent, after.RequestsSent)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/pprof/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected the pprof index, got status %d", rec.Code)
+	}
+}
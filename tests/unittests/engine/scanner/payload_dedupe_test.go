@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestFilterDuplicatePayloads(t *testing.T) {
+	scanner.ResetSeenRawURIs()
+	defer scanner.ResetSeenRawURIs()
+
+	base := payload.BypassPayload{Method: "GET", Scheme: "https", Host: "example.com", RawURI: "/admin"}
+
+	first := scanner.FilterDuplicatePayloads([]payload.BypassPayload{
+		{Method: "GET", Scheme: "https", Host: "example.com", RawURI: "/admin", BypassModule: "dumb_check"},
+	}, "dumb_check")
+	if len(first) != 1 {
+		t.Fatalf("Expected the first payload to be kept, got %d", len(first))
+	}
+
+	withHeaders := base
+	withHeaders.Headers = []payload.Headers{{Header: "X-Forwarded-For", Value: "127.0.0.1"}, {Header: "X-Real-IP", Value: "127.0.0.1"}}
+	sameHeaders := base
+	sameHeaders.Headers = []payload.Headers{{Header: "X-Forwarded-For", Value: "127.0.0.1"}, {Header: "X-Real-IP", Value: "127.0.0.1"}}
+	// Sent as written and in order, other requests than withHeaders
+	reordered := base
+	reordered.Headers = []payload.Headers{{Header: "X-Real-IP", Value: "127.0.0.1"}, {Header: "X-Forwarded-For", Value: "127.0.0.1"}}
+	lowerCase := base
+	lowerCase.Headers = []payload.Headers{{Header: "x-forwarded-for", Value: "127.0.0.1"}, {Header: "x-real-ip", Value: "127.0.0.1"}}
+	withBody := base
+	withBody.Body = "a=b"
+	otherURI := base
+	otherURI.RawURI = "/Admin"
+	sameAsFirst := base
+	sameAsFirst.Host = "EXAMPLE.com"
+
+	second := scanner.FilterDuplicatePayloads([]payload.BypassPayload{sameAsFirst, otherURI, withHeaders, sameHeaders, reordered, lowerCase, withBody}, "case_substitution")
+	if len(second) != 5 {
+		t.Fatalf("Expected 5 payloads left, got %d: %+v", len(second), second)
+	}
+	if second[0].RawURI != "/Admin" || second[1].Headers[0].Header != "X-Forwarded-For" ||
+		second[2].Headers[0].Header != "X-Real-IP" || second[3].Headers[0].Header != "x-forwarded-for" || second[4].Body != "a=b" {
+		t.Errorf("Unexpected payloads kept: %+v", second)
+	}
+
+	// A new target URL starts from scratch
+	scanner.ResetSeenRawURIs()
+	if again := scanner.FilterDuplicatePayloads([]payload.BypassPayload{base}, "case_substitution"); len(again) != 1 {
+		t.Errorf("Expected the payload to be kept after a reset, got %d", len(again))
+	}
+}
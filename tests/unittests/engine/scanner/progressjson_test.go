@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerProgressJSON(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+
+	// Slow enough for end_paths to span at least one progress interval
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	targetURL := server.URL + "/admin"
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:       "end_paths",
+		MatchStatusCodes:   []int{200},
+		ConcurrentRequests: 2,
+		Timeout:            5000,
+		DisableProgressBar: true,
+	}, []string{targetURL})
+
+	var buf bytes.Buffer
+	s.SetProgressOutput(&buf)
+
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var events []scanner.ProgressEvent
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var ev scanner.ProgressEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("Invalid progress event %q: %v", sc.Text(), err)
+		}
+		if ev.BypassModule != "end_paths" || ev.TargetURL != targetURL || ev.Total == 0 {
+			t.Errorf("Unexpected progress event: %+v", ev)
+		}
+		events = append(events, ev)
+	}
+
+	if len(events) < 3 {
+		t.Fatalf("Expected start, progress and end events, got %d events", len(events))
+	}
+	first, last := events[0], events[len(events)-1]
+	if first.Event != "module_start" || first.Completed != 0 {
+		t.Errorf("Expected a module_start event first, got %+v", first)
+	}
+	if last.Event != "module_end" || last.Completed != uint64(last.Total) || last.DurationMs == 0 {
+		t.Errorf("Expected a module_end event with all requests completed, got %+v", last)
+	}
+	for _, ev := range events[1 : len(events)-1] {
+		if ev.Event != "module_progress" {
+			t.Errorf("Expected module_progress events between start and end, got %q", ev.Event)
+		}
+	}
+}
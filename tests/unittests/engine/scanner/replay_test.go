@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestReplayFromToken(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.Path != "/admin;/" || string(body) != "a=1" || r.Header.Get("X-Original-URL") != "/admin" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("X-Replay", "ok")
+		w.Write([]byte("welcome admin"))
+	}))
+	defer server.Close()
+
+	token := payload.GeneratePayloadToken(payload.BypassPayload{
+		Method:       "POST",
+		Scheme:       "http",
+		Host:         strings.TrimPrefix(server.URL, "http://"),
+		RawURI:       "/admin;/",
+		Headers:      []payload.Headers{{Header: "X-Original-URL", Value: "/admin"}},
+		Body:         "a=1",
+		BypassModule: "end_paths",
+	})
+
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		CustomHTTPHeaders:       []string{"X-Custom: 1"},
+	}, nil)
+	defer s.Close()
+
+	results, err := s.ResendRequestFromToken(token, 3)
+	if err != nil {
+		t.Fatalf("ResendRequestFromToken failed: %v", err)
+	}
+	if hits.Load() != 3 || len(results) != 3 {
+		t.Fatalf("Expected 3 replayed requests, got %d requests and %d results", hits.Load(), len(results))
+	}
+	for _, res := range results {
+		if res.StatusCode != 200 {
+			t.Errorf("Expected the replayed request to be identical (body included), got status %d", res.StatusCode)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := s.PrintReplay(&out, token, results); err != nil {
+		t.Fatalf("PrintReplay failed: %v", err)
+	}
+	for _, want := range []string{
+		"=== Request ===",
+		"POST " + server.URL + "/admin;/ HTTP/1.1",
+		"X-Original-URL: /admin",
+		"X-Custom: 1",
+		"a=1",
+		"=== Response 3/3",
+		"X-Replay: ok",
+		"welcome admin",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Replay output is missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := s.PrintReplay(&out, "not-a-token", results); err == nil {
+		t.Errorf("Expected an error for an invalid debug token")
+	}
+}
+
+func TestPrintDecodedToken(t *testing.T) {
+	token := payload.GeneratePayloadToken(payload.BypassPayload{
+		Method:       "POST",
+		Scheme:       "https",
+		Host:         "example.com",
+		RawURI:       "/admin;/?a=<b>",
+		Headers:      []payload.Headers{{Header: "X-Original-URL", Value: "/admin"}},
+		Body:         "a=1",
+		BypassModule: "headers_url",
+	})
+
+	var out bytes.Buffer
+	if err := scanner.PrintDecodedToken(&out, `"token=`+token+`"`); err != nil {
+		t.Fatalf("PrintDecodedToken failed: %v", err)
+	}
+
+	text, jsonPart, ok := strings.Cut(out.String(), "=== JSON ===\n")
+	if !ok {
+		t.Fatalf("Missing JSON section:\n%s", out.String())
+	}
+	for _, want := range []string{
+		"Bypass module: headers_url",
+		"URL:           https://example.com/admin;/?a=<b>",
+		"X-Original-URL: /admin",
+		"Body:          3 bytes",
+		"POST https://example.com/admin;/?a=<b> HTTP/1.1",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output:\n%s", want, text)
+		}
+	}
+
+	var decoded struct {
+		BypassModule string `json:"bypass_module"`
+		Method       string `json:"method"`
+		RawURI       string `json:"raw_uri"`
+		Body         string `json:"body"`
+		DebugToken   string `json:"debug_token"`
+	}
+	if err := json.Unmarshal([]byte(jsonPart), &decoded); err != nil {
+		t.Fatalf("Invalid JSON output: %v\n%s", err, jsonPart)
+	}
+	if decoded.BypassModule != "headers_url" || decoded.Method != "POST" || decoded.RawURI != "/admin;/?a=<b>" ||
+		decoded.Body != "a=1" || decoded.DebugToken != token {
+		t.Errorf("Unexpected JSON output: %+v", decoded)
+	}
+
+	if err := scanner.PrintDecodedToken(io.Discard, "garbage"); err == nil {
+		t.Errorf("Expected an error for an invalid token")
+	}
+}
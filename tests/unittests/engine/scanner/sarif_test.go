@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestSarifWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "results.sarif")
+	w := scanner.NewSarifWriter(path, "1.2.3")
+
+	w.WriteResult("https://example.com/admin", &scanner.Result{
+		TargetURL:    "https://example.com/%61dmin",
+		BypassModule: "char_encode",
+		StatusCode:   200,
+		CurlCMD:      "curl -skgi --path-as-is 'https://example.com/%61dmin'",
+		DebugToken:   "token123",
+	})
+	w.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read SARIF report: %v", err)
+	}
+
+	var report scanner.SarifReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid SARIF JSON: %v", err)
+	}
+
+	if report.Version != "2.1.0" || len(report.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF version/runs: %s/%d", report.Version, len(report.Runs))
+	}
+
+	run := report.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(payload.BypassModulesRegistry) {
+		t.Errorf("Expected one rule per bypass module, got %d", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(run.Results))
+	}
+
+	res := run.Results[0]
+	if res.RuleID != "char_encode" || run.Tool.Driver.Rules[res.RuleIndex].ID != "char_encode" {
+		t.Errorf("Result not mapped to its bypass module rule: %s (index %d)", res.RuleID, res.RuleIndex)
+	}
+	if res.Level != "warning" {
+		t.Errorf("Expected level warning, got %s", res.Level)
+	}
+	if res.Locations[0].PhysicalLocation.ArtifactLocation.URI != "https://example.com/admin" {
+		t.Errorf("Unexpected location: %s", res.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+	if res.Properties["debugToken"] != "token123" {
+		t.Errorf("Expected debug token in properties, got %v", res.Properties["debugToken"])
+	}
+}
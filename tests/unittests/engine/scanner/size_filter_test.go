@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestParseSizeRanges(t *testing.T) {
+	ranges, err := scanner.ParseSizeRanges("1234, 100-200,0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []scanner.SizeRange{{Min: 1234, Max: 1234}, {Min: 100, Max: 200}, {Min: 0, Max: 0}}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("ParseSizeRanges() = %v, want %v", ranges, want)
+	}
+
+	for _, invalid := range []string{"abc", "200-100", "-5", "10-", "1,x-2"} {
+		if _, err := scanner.ParseSizeRanges(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestScannerMinContentLengthChunked(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/empty":
+			w.Header().Set("Content-Length", "0")
+		case "/tiny":
+			w.Write([]byte("denied"))
+		case "/chunked-tiny":
+			w.(http.Flusher).Flush() // no Content-Length, chunked
+			w.Write([]byte("denied"))
+		case "/chunked-large":
+			w.(http.Flusher).Flush()
+			w.Write([]byte(strings.Repeat("a", 4096))) // more than the preview
+		default:
+			w.Write([]byte(strings.Repeat("<p>admin</p>", 20)))
+		}
+	}))
+	defer server.Close()
+
+	var targets []string
+	for _, path := range []string{"/empty", "/tiny", "/chunked-tiny", "/chunked-large", "/admin"} {
+		targets = append(targets, server.URL+path)
+	}
+
+	jsonlFile := filepath.Join(dir, "findings-min-cl.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      2,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		MinContentLength:        100,
+		JSONLFile:               jsonlFile,
+	}, targets)
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, strings.TrimPrefix(f.TargetURL, server.URL))
+	}
+	slices.Sort(got)
+
+	if want := []string{"/admin", "/chunked-large"}; !slices.Equal(got, want) {
+		t.Errorf("Expected findings for %v, got %v", want, got)
+	}
+}
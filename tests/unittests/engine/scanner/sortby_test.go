@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestSortResults(t *testing.T) {
+	newResults := func() []*scanner.Result {
+		return []*scanner.Result{
+			{DebugToken: "a", StatusCode: 403, ContentLength: 100, ResponseTime: 30, Confidence: 0.25},
+			{DebugToken: "b", StatusCode: 200, ResponseBodyBytes: 900, ResponseTime: 850, Confidence: 0.8},
+			{DebugToken: "c", StatusCode: 200, ContentLength: 500, ResponseTime: 30, Confidence: 0.9},
+			{DebugToken: "d", StatusCode: 302, ContentLength: 0, ResponseTime: 120, Confidence: 0.8},
+		}
+	}
+
+	tests := []struct {
+		sortBy string
+		want   string
+	}{
+		{"", "abcd"},       // discovery order
+		{"time", "bdac"},   // slowest first, ties in discovery order
+		{"length", "bcad"}, // body bytes when there is no Content-Length
+		{"status", "bcda"},
+		{"confidence", "cbda"}, // highest first, ties in discovery order
+	}
+	for _, tt := range tests {
+		results := newResults()
+		scanner.SortResults(results, tt.sortBy)
+
+		got := ""
+		for _, r := range results {
+			got += r.DebugToken
+		}
+		if got != tt.want {
+			t.Errorf("SortResults(%q) = %s, want %s", tt.sortBy, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestTargetDirName(t *testing.T) {
+	tests := map[string]string{
+		"https://Example.com/admin":         "example.com",
+		"https://example.com:8443/admin":    "example.com_8443",
+		"http://[::1]:8080/admin":           "__1_8080",
+		"http://[fe80::1%25eth0]/admin":     "fe80__1_25eth0",
+		"https://user:pw@example.com/admin": "example.com",
+		"http://../admin":                   "unknown_host",
+	}
+	for targetURL, want := range tests {
+		if got := scanner.TargetDirName(targetURL); got != want {
+			t.Errorf("TargetDirName(%q) = %q, want %q", targetURL, got, want)
+		}
+	}
+}
+
+func TestSplitJSONLWriterWritesOneFilePerHost(t *testing.T) {
+	outDir := t.TempDir()
+	w := scanner.NewSplitJSONLWriter(outDir, "1.2.3", 0)
+
+	for _, f := range []struct{ targetURL, module string }{
+		{"https://a.example.com/admin", "mid_paths"},
+		{"https://b.example.com:8443/secret", "headers_ip"},
+		{"https://a.example.com/other", "char_encode"},
+	} {
+		w.WriteResult(f.targetURL, &scanner.Result{TargetURL: f.targetURL, BypassModule: f.module, StatusCode: 200})
+	}
+	w.Close()
+
+	want := map[string][]string{
+		"a.example.com":      {"mid_paths", "char_encode"},
+		"b.example.com_8443": {"headers_ip"},
+	}
+	for dirName, modules := range want {
+		findings, err := scanner.ReadJSONLFindings(filepath.Join(outDir, dirName, "findings.jsonl"))
+		if err != nil {
+			t.Fatalf("Failed to read findings of %s: %v", dirName, err)
+		}
+		if len(findings) != len(modules) {
+			t.Fatalf("%s: expected %d findings, got %d", dirName, len(modules), len(findings))
+		}
+		for i, module := range modules {
+			if findings[i].BypassModule != module {
+				t.Errorf("%s: expected finding %d from %s, got %s", dirName, i, module, findings[i].BypassModule)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestSummarizeModuleStats(t *testing.T) {
+	stats := []scanner.ModuleStats{
+		{TargetURL: "https://a.example.com/admin", BypassModule: "mid_paths", Payloads: 100, Requests: 100, Findings: 2, Duration: 2 * time.Second},
+		{TargetURL: "https://a.example.com/admin", BypassModule: "end_paths", Payloads: 50, Requests: 40, Findings: 0, Duration: time.Second},
+		{TargetURL: "https://b.example.com/admin", BypassModule: "mid_paths", Payloads: 100, Requests: 100, Findings: 1, Duration: 2 * time.Second},
+	}
+
+	summary := scanner.SummarizeModuleStats(stats)
+	if len(summary) != 2 {
+		t.Fatalf("Expected 2 modules, got %d", len(summary))
+	}
+
+	// Modules keep the order of their first run
+	mid, end := summary[0], summary[1]
+	if mid.BypassModule != "mid_paths" || end.BypassModule != "end_paths" {
+		t.Fatalf("Unexpected module order: %s, %s", mid.BypassModule, end.BypassModule)
+	}
+
+	if mid.Payloads != 200 || mid.Requests != 200 || mid.Findings != 3 || mid.Duration != 4*time.Second {
+		t.Errorf("Unexpected mid_paths totals: %+v", mid)
+	}
+	if mid.AvgRate != 50 {
+		t.Errorf("Expected mid_paths avg rate of 50 req/s, got %d", mid.AvgRate)
+	}
+	if end.AvgRate != 40 {
+		t.Errorf("Expected end_paths avg rate of 40 req/s, got %d", end.AvgRate)
+	}
+}
+
+func TestSummarizeModuleStatsShortRun(t *testing.T) {
+	summary := scanner.SummarizeModuleStats([]scanner.ModuleStats{
+		{BypassModule: "dumb_check", Payloads: 1, Requests: 1, Duration: time.Millisecond},
+	})
+	if len(summary) != 1 || summary[0].AvgRate != 0 {
+		t.Errorf("Expected a 0 req/s rate for a run too short to measure, got %+v", summary)
+	}
+}
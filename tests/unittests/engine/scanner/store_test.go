@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestMemoryResultStore(t *testing.T) {
+	store := scanner.NewMemoryResultStore()
+	defer store.Close()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Append([]*scanner.Result{{TargetURL: "https://example.com/admin", DebugToken: fmt.Sprint(i)}})
+		}()
+	}
+	wg.Wait()
+
+	if err := store.Append([]*scanner.Result{
+		{TargetURL: "https://example.com/other", DebugToken: "a"},
+		{TargetURL: "https://example.com/admin", DebugToken: "b"},
+	}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	results, err := store.Results("https://example.com/admin")
+	if err != nil {
+		t.Fatalf("Results failed: %v", err)
+	}
+	if len(results) != 21 || results[20].DebugToken != "b" {
+		t.Fatalf("Expected the 21 findings of the target URL in discovery order, got %d", len(results))
+	}
+
+	// The returned slice belongs to the caller (reports sort it in place)
+	results[0] = nil
+	if again, _ := store.Results("https://example.com/admin"); again[0] == nil {
+		t.Error("Results must return a copy of the stored findings")
+	}
+
+	if results, _ := store.Results("https://example.com/missing"); len(results) != 0 {
+		t.Errorf("Expected no findings for another URL, got %d", len(results))
+	}
+}
+
+func TestBoltResultStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.bolt")
+	store, err := scanner.NewBoltResultStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+
+	if err := store.Append([]*scanner.Result{
+		{TargetURL: "https://example.com/admin", BypassModule: "headers_ip", StatusCode: 200, DebugToken: "a", Confidence: 0.85,
+			Baseline: &scanner.BaselineResponse{StatusCode: 403, Length: 120}, LengthDelta: 880},
+		{TargetURL: "https://example.com/other", BypassModule: "mid_paths", StatusCode: 302, DebugToken: "b"},
+	}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append([]*scanner.Result{{TargetURL: "https://example.com/admin", BypassModule: "end_paths", StatusCode: 200, DebugToken: "c"}}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	check := func(results []*scanner.Result, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Results failed: %v", err)
+		}
+		if len(results) != 2 || results[0].DebugToken != "a" || results[1].DebugToken != "c" {
+			t.Fatalf("Expected the findings of the target URL in discovery order, got %d", len(results))
+		}
+		if res := results[0]; res.BypassModule != "headers_ip" || res.Confidence != 0.85 ||
+			res.Baseline == nil || res.Baseline.StatusCode != 403 || res.LengthDelta != 880 {
+			t.Errorf("Finding not stored as is: %+v", res)
+		}
+	}
+	check(store.Results("https://example.com/admin"))
+
+	// Findings are read back from the file once the store is closed, e.g. for the reports
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	check(store.Results("https://example.com/admin"))
+
+	all, err := scanner.LoadFindings(path)
+	if err != nil || len(all) != 3 {
+		t.Errorf("Expected the 3 findings of the file for -diff, got %d (%v)", len(all), err)
+	}
+}
+
+func TestOpenResultStoreUnknownBackend(t *testing.T) {
+	if err := scanner.OpenResultStore("mysql", filepath.Join(t.TempDir(), "results.db"), 2); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerUniqueKeepsFirstFindingPerResponse(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch {
+		case strings.Contains(r.Header.Get("Accept"), "json"):
+			w.Write([]byte(`<html><title>API</title></html>`))
+		case r.Header.Get("X-Requested-With") != "":
+			// Same title, different length
+			w.Write([]byte(`<html><title>API</title><body>xhr</body></html>`))
+		default:
+			w.Write([]byte(`<html><title>Admin</title></html>`))
+		}
+	}))
+	defer server.Close()
+
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check,http_headers_accept",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      1,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		Unique:                  true,
+		JSONLFile:               jsonlFile,
+	}, []string{server.URL + "/admin"})
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+
+	seen := make(map[string]int)
+	for _, f := range findings {
+		seen[f.Title]++
+	}
+	// Admin (dumb_check and the Accept headers without json), API, and API with the xhr body
+	if len(findings) != 3 || seen["Admin"] != 1 || seen["API"] != 2 {
+		t.Errorf("Expected 3 unique findings (Admin, API twice with different lengths), got %d: %v", len(findings), seen)
+	}
+	if findings[0].BypassModule != "dumb_check" {
+		t.Errorf("Expected the first Admin finding to come from dumb_check, got %s", findings[0].BypassModule)
+	}
+}
+
+func TestScannerUniqueAfterMinConfidence(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	// Same status, length and (no) title everywhere, only the content type differs
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.Header.Get("Accept"), "json") {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/plain")
+		}
+		w.Write([]byte(`{"admin": true}`))
+	}))
+	defer server.Close()
+
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check,http_headers_accept",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      1,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		DisableProgressBar:      true,
+		Unique:                  true,
+		MinConfidence:           0.3,
+		JSONLFile:               jsonlFile,
+	}, []string{server.URL + "/unique-confidence"})
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+	// The dumb_check response scores below -min-confidence, it must not claim the -unique slot
+	if len(findings) != 1 || !strings.Contains(findings[0].ContentType, "json") {
+		t.Fatalf("Expected the json response as the only finding, got %d", len(findings))
+	}
+}
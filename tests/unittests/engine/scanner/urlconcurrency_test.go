@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/slicingmelon/gobypass403/core/engine/payload"
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestScannerURLConcurrency(t *testing.T) {
+	if err := payload.InitializePayloadsDir(); err != nil {
+		t.Fatalf("Failed to initialize payloads: %v", err)
+	}
+	dir := t.TempDir()
+	if err := scanner.InitDB(filepath.Join(dir, "results.db"), 2); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><title>Admin</title></html>`))
+	}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/admin", server.URL + "/private", server.URL + "/internal"}
+	jsonlFile := filepath.Join(dir, "findings.jsonl")
+	s := scanner.NewScanner(&scanner.ScannerOpts{
+		BypassModule:            "dumb_check,http_headers_accept",
+		MatchStatusCodes:        []int{200},
+		ConcurrentRequests:      2,
+		HostConcurrency:         2,
+		URLConcurrency:          3,
+		Timeout:                 5000,
+		ResponseBodyPreviewSize: 1024,
+		Unique:                  true,
+		JSONLFile:               jsonlFile,
+	}, urls)
+	if err := s.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// The per-host cap is shared by the worker pools of all URLs
+	if peak := maxInFlight.Load(); peak > 2 {
+		t.Errorf("Expected at most 2 requests in flight on the host, got %d", peak)
+	}
+
+	findings, err := scanner.ReadJSONLFindings(jsonlFile)
+	if err != nil {
+		t.Fatalf("ReadJSONLFindings failed: %v", err)
+	}
+
+	// -unique keeps one finding per URL, each URL has its own state
+	perURL := make(map[string]int)
+	for _, f := range findings {
+		perURL[f.TargetURL]++
+	}
+	for _, u := range urls {
+		if perURL[u] != 1 {
+			t.Errorf("Expected 1 unique finding for %s, got %d (%v)", u, perURL[u], perURL)
+		}
+	}
+}
+
+func TestScannerSharesHostLimiter(t *testing.T) {
+	// Created once by NewScanner whatever -url-concurrency, every worker pool gets the same one
+	opts := &scanner.ScannerOpts{ConcurrentRequests: 10, HostConcurrency: 2, URLConcurrency: 1}
+	scanner.NewScanner(opts, []string{"http://127.0.0.1/admin"})
+	if opts.HostLimiter == nil {
+		t.Errorf("Expected a shared host limiter with -host-concurrency and one URL at a time")
+	}
+
+	opts = &scanner.ScannerOpts{ConcurrentRequests: 10, URLConcurrency: 3}
+	scanner.NewScanner(opts, []string{"http://127.0.0.1/admin"})
+	if opts.HostLimiter == nil {
+		t.Errorf("Expected a shared host limiter capped at -c with -url-concurrency")
+	}
+
+	opts = &scanner.ScannerOpts{ConcurrentRequests: 10, URLConcurrency: 1}
+	scanner.NewScanner(opts, []string{"http://127.0.0.1/admin"})
+	if opts.HostLimiter != nil {
+		t.Errorf("Expected no host limiter without -host-concurrency")
+	}
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/slicingmelon/gobypass403/core/engine/scanner"
+)
+
+func TestWebhookWriterPostsFindings(t *testing.T) {
+	var mu sync.Mutex
+	var received []scanner.WebhookFinding
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Fail the very first delivery to exercise the retry path
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected Content-Type application/json, got %q", ct)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var finding scanner.WebhookFinding
+		if err := json.Unmarshal(body, &finding); err != nil {
+			t.Errorf("Failed to decode webhook body: %v", err)
+		}
+		received = append(received, finding)
+	}))
+	defer server.Close()
+
+	writer := scanner.NewWebhookWriter(server.URL, "test", 0)
+	writer.WriteResult("https://example.com/admin", &scanner.Result{
+		TargetURL:    "https://example.com/admin/.",
+		BypassModule: "end_paths",
+		StatusCode:   200,
+	})
+	writer.WriteResult("https://example.com/admin", &scanner.Result{
+		TargetURL:    "https://example.com/%2fadmin",
+		BypassModule: "path_prefix",
+		StatusCode:   200,
+	})
+	writer.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 findings delivered, got %d", len(received))
+	}
+	for _, f := range received {
+		if f.Tool != "gobypass403" || f.Version != "test" || f.Target != "https://example.com/admin" {
+			t.Errorf("Unexpected metadata in finding: %+v", f)
+		}
+	}
+}
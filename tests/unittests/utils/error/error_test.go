@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	GB403ErrorHandler "github.com/slicingmelon/gobypass403/core/utils/error"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := map[string]string{
+		"dial tcp: lookup nope.invalid: no such host": "dns",
+		"timeout": "timeout",
+		"dialing to the given TCP address timed out":                            "timeout",
+		"dial tcp 127.0.0.1:1: connect: connection refused":                     "connection_refused",
+		"read tcp 10.0.0.1:4431->10.0.0.2:443: read: connection reset by peer":  "connection_reset",
+		"the server closed connection before returning the first response byte": "connection_closed",
+		"tls: handshake failure":                                                "tls",
+		"socks connect tcp 127.0.0.1:1080->example.com:443: unknown error":      "proxy",
+		"something else": "other",
+	}
+	for msg, want := range tests {
+		if got := GB403ErrorHandler.ClassifyError(msg); got != want {
+			t.Errorf("ClassifyError(%q) = %s, want %s", msg, got, want)
+		}
+	}
+}
+
+func TestErrorSummary(t *testing.T) {
+	handler := GB403ErrorHandler.NewErrorHandler()
+
+	for i := 0; i < 3; i++ {
+		handler.HandleError(errors.New("timeout"), GB403ErrorHandler.ErrorContext{Host: "https://a.example.com", BypassModule: "mid_paths"})
+	}
+	handler.HandleError(errors.New("read: connection reset by peer"), GB403ErrorHandler.ErrorContext{Host: "https://a.example.com"})
+	handler.HandleError(errors.New("lookup b.example.com: no such host"), GB403ErrorHandler.ErrorContext{Host: "https://b.example.com"})
+
+	summary := handler.Summary("https://a.example.com")
+	if summary == nil {
+		t.Fatal("Expected errors for https://a.example.com")
+	}
+	if summary.Total != 4 || summary.ByClass["timeout"] != 3 || summary.ByClass["connection_reset"] != 1 {
+		t.Errorf("Unexpected summary of https://a.example.com: %+v", summary)
+	}
+	if len(summary.ByHost) != 1 || summary.ByHost["https://a.example.com"] != 4 {
+		t.Errorf("Unexpected hosts: %v", summary.ByHost)
+	}
+
+	if all := handler.Summary(); all == nil || all.Total != 5 || all.ByHost["https://b.example.com"] != 1 {
+		t.Errorf("Unexpected summary of all hosts: %+v", all)
+	}
+	if none := handler.Summary("https://c.example.com"); none != nil {
+		t.Errorf("Expected no errors for https://c.example.com, got %+v", none)
+	}
+}
@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+func TestDisableColor(t *testing.T) {
+	GB403Logger.DisableColor()
+	if GB403Logger.ColorEnabled() {
+		t.Fatalf("Expected colors to be disabled")
+	}
+
+	// Styled text and tables printed through pterm carry no ANSI escape codes
+	outputs := []string{
+		pterm.FgGreen.Sprint("green"),
+		pterm.NewStyle(pterm.BgCyan, pterm.FgBlack).Sprintf(" %s ", "mid_paths"),
+	}
+	table, err := pterm.DefaultTable.WithHasHeader().WithData(pterm.TableData{
+		{"Module", "Status"},
+		{"end_paths", pterm.FgGreen.Sprint("200")},
+	}).Srender()
+	if err != nil {
+		t.Fatalf("Table render failed: %v", err)
+	}
+	outputs = append(outputs, table)
+
+	for _, out := range outputs {
+		if strings.Contains(out, "\x1b[") {
+			t.Errorf("Unexpected ANSI escape code in %q", out)
+		}
+	}
+}
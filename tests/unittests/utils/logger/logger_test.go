@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	GB403Logger "github.com/slicingmelon/gobypass403/core/utils/logger"
+)
+
+func TestJSONLogOutput(t *testing.T) {
+	var buf bytes.Buffer
+	GB403Logger.DefaultLogger.EnableJSON(&buf)
+
+	GB403Logger.Error().BypassModule("mid_paths").DebugToken("abc123").Metadata("status", "403").Msgf("Request failed: %s\n", "timeout")
+	GB403Logger.Info().Msgf("plain message")
+	GB403Logger.Verbose().Msgf("not logged, verbose is off")
+	GB403Logger.PrintBypassModuleInfo("end_paths", 42, "https://example.com/admin")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 JSON lines, got %d:\n%s", len(lines), buf.String())
+	}
+
+	records := make([]map[string]string, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+			t.Fatalf("Line %d is not a JSON object: %v\n%s", i, err, line)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, records[i]["timestamp"]); err != nil {
+			t.Errorf("Line %d has an invalid timestamp: %q", i, records[i]["timestamp"])
+		}
+	}
+
+	first := records[0]
+	if first["level"] != "error" || first["module"] != "mid_paths" || first["debug_token"] != "abc123" ||
+		first["status"] != "403" || first["message"] != "Request failed: timeout" {
+		t.Errorf("Unexpected record: %v", first)
+	}
+
+	if _, ok := records[1]["module"]; ok || records[1]["level"] != "info" || records[1]["message"] != "plain message" {
+		t.Errorf("Unexpected record: %v", records[1])
+	}
+
+	if records[2]["module"] != "end_paths" || records[2]["payloads"] != "42" || records[2]["target_url"] != "https://example.com/admin" {
+		t.Errorf("Unexpected module info record: %v", records[2])
+	}
+}